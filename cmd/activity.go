@@ -36,6 +36,8 @@ func newActivityCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	cmd.AddCommand(
 		NewListCommand(config),
 		NewSummaryCommand(config),
+		NewRenameCommand(config),
+		NewBurndownCommand(config),
 	)
 
 	return cmd
@@ -0,0 +1,252 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_activity
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	burndown "github.com/geaaru/time-master/pkg/burndown"
+	loader "github.com/geaaru/time-master/pkg/loader"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	time "github.com/geaaru/time-master/pkg/time"
+
+	"github.com/spf13/cobra"
+)
+
+func formatHours(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", float64(*v)/3600)
+}
+
+func printBurndown(b *burndown.Burndown) {
+	total, _ := time.Seconds2Duration(b.TotalEffort)
+	worked, _ := time.Seconds2Duration(b.WorkedEffort)
+	remaining, _ := time.Seconds2Duration(b.RemainingEffort)
+
+	fmt.Println(fmt.Sprintf("Activity %s (%s - %s, now %s)", b.Activity, b.Start, b.End, b.Now))
+	line := fmt.Sprintf("Total effort: %s  Worked: %s  Remaining: %s", total, worked, remaining)
+	if b.ProjectedEnd != "" {
+		line += "  Projected end: " + b.ProjectedEnd
+	}
+	fmt.Println(line + "\n")
+
+	max := b.TotalEffort
+	if b.WorkedEffort > max {
+		max = b.WorkedEffort
+	}
+
+	series := []struct {
+		Label string
+		Value func(p *burndown.BurndownPoint) *int64
+	}{
+		{"Worked", func(p *burndown.BurndownPoint) *int64 { return p.Worked }},
+		{"Remaining", func(p *burndown.BurndownPoint) *int64 { return p.Remaining }},
+		{"Ideal", func(p *burndown.BurndownPoint) *int64 { return &p.Ideal }},
+		{"Projection", func(p *burndown.BurndownPoint) *int64 { return p.Projected }},
+	}
+
+	for _, s := range series {
+		values := []int64{}
+		found := false
+		for idx := range b.Points {
+			v := s.Value(&b.Points[idx])
+			if v == nil {
+				values = append(values, -1)
+			} else {
+				values = append(values, *v)
+				found = true
+			}
+		}
+		if found {
+			fmt.Println(fmt.Sprintf("%-11s %s", s.Label, burndown.Sparkline(values, max)))
+		}
+	}
+}
+
+func NewBurndownCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "burndown <activity>",
+		Short: "Show the burndown and burnup series of an activity.",
+		Long: `Show the burndown and burnup series of an activity.
+
+The series contains the total effort, the worked effort, the remaining
+effort and the ideal line. With a prevision the projection of the
+remaining effort is based on the work planned by the scenario.
+
+$> tm activity burndown ACT1 --weekly
+
+$> tm activity burndown ACT1 --scenario /tmp/prevision.yml --svg /tmp/act1.svg
+`,
+		Args: cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			csvOutput, _ := cmd.Flags().GetBool("csv")
+			if jsonOutput && csvOutput {
+				fmt.Println("Both option --csv and --json not admitted.")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			csvOutput, _ := cmd.Flags().GetBool("csv")
+			svgFile, _ := cmd.Flags().GetString("svg")
+			weekly, _ := cmd.Flags().GetBool("weekly")
+			scenarioFile, _ := cmd.Flags().GetString("scenario")
+			now, _ := cmd.Flags().GetString("now")
+			from, _ := cmd.Flags().GetString("from")
+			to, _ := cmd.Flags().GetString("to")
+
+			// Create Instance
+			tm := loader.NewTimeMasterInstance(config)
+
+			err := tm.Load()
+			if err != nil {
+				fmt.Println("Error on load data: " + err.Error())
+				os.Exit(1)
+			}
+
+			activity, _, err := tm.GetActivityByName(args[0])
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			effort, err := activity.GetPlannedEffortTotSecs(config.GetWork().WorkHours)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			bd := burndown.NewTmBurndown(config)
+			bd.Now = now
+			bd.From = from
+			bd.To = to
+			if weekly {
+				bd.Period = burndown.BURNDOWN_WEEKLY
+			}
+
+			researchOpts := specs.TimesheetResearch{
+				ByActivity: true,
+			}
+			rtaList, err := tm.GetAggregatedTimesheets(researchOpts, "", "", []string{},
+				[]string{"^" + strings.ReplaceAll(activity.Name, ".", `\.`) + `\.`})
+			if err != nil {
+				fmt.Println("Error on elaborate timesheet: " + err.Error())
+				os.Exit(1)
+			}
+			for _, rta := range *rtaList {
+				if rta.Task == activity.Name {
+					bd.AddWorked(rta.Period.StartPeriod, rta.GetSeconds())
+				}
+			}
+
+			if scenarioFile != "" {
+				prevision, err := specs.ScenarioScheduleFromFile(scenarioFile)
+				if err != nil {
+					fmt.Println("Error on load scenario file: " + err.Error())
+					os.Exit(1)
+				}
+				err = bd.SetPrevision(activity.Name, prevision)
+				if err != nil {
+					fmt.Println("Error on read prevision: " + err.Error())
+					os.Exit(1)
+				}
+			}
+
+			b, err := bd.Build(activity.Name, effort)
+			if err != nil {
+				fmt.Println("Error on build burndown: " + err.Error())
+				os.Exit(1)
+			}
+
+			if svgFile != "" {
+				data, err := b.Svg()
+				if err != nil {
+					fmt.Println("Error on create SVG chart: " + err.Error())
+					os.Exit(1)
+				}
+				err = ioutil.WriteFile(svgFile, data, 0644)
+				if err != nil {
+					fmt.Println("Error on write data on file: " + err.Error())
+					os.Exit(1)
+				}
+			}
+
+			if jsonOutput {
+				data, err := json.Marshal(b)
+				if err != nil {
+					fmt.Println("Error on convert data to json: " + err.Error())
+					os.Exit(1)
+				}
+				fmt.Println(string(data))
+
+			} else if csvOutput {
+				w := csv.NewWriter(os.Stdout)
+				records := [][]string{
+					{"Date", "Total", "Worked", "Remaining", "Ideal", "Projected"},
+				}
+				for idx := range b.Points {
+					p := &b.Points[idx]
+					records = append(records, []string{
+						p.Date, formatHours(&p.Total), formatHours(p.Worked),
+						formatHours(p.Remaining), formatHours(&p.Ideal), formatHours(p.Projected),
+					})
+				}
+
+				for _, record := range records {
+					if err := w.Write(record); err != nil {
+						fmt.Println("error writing record to csv:", err)
+						os.Exit(1)
+					}
+				}
+
+				// Write any buffered data to the underlying writer (standard output).
+				w.Flush()
+
+				if err := w.Error(); err != nil {
+					fmt.Println(err.Error())
+					os.Exit(1)
+				}
+
+			} else if svgFile == "" {
+				printBurndown(b)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Bool("json", false, "Print output in JSON format.")
+	flags.Bool("csv", false, "Print output in CSV format (values in hours).")
+	flags.String("svg", "", "Write the chart to the specified SVG file.")
+	flags.Bool("weekly", false, "Weekly series instead of daily.")
+	flags.String("scenario", "", "Specify path of the scenario prevision for the projection.")
+	flags.String("now", "", "Now date in the format YYYY-MM-DD. Default the now of the prevision or today.")
+	flags.String("from", "", "Specify from date in format YYYY-MM-DD.")
+	flags.String("to", "", "Specify to date in format YYYY-MM-DD.")
+
+	return cmd
+}
@@ -24,10 +24,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 
 	loader "github.com/geaaru/time-master/pkg/loader"
 	specs "github.com/geaaru/time-master/pkg/specs"
 	time "github.com/geaaru/time-master/pkg/time"
+	xlsx "github.com/geaaru/time-master/pkg/xlsx"
 
 	tablewriter "github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -53,6 +55,87 @@ func retrieveWorkTimeByActivity(tm *loader.TimeMasterInstance, activity,
 	return "0", 0, 0, 0, nil
 }
 
+// Return the cell of a percentage formatted as string.
+func percentCell(v string) xlsx.Cell {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return xlsx.String(v)
+	}
+	return xlsx.Percent(f)
+}
+
+func writeActivitiesXlsx(tm *loader.TimeMasterInstance, config *specs.TimeMasterConfig,
+	activities []specs.ActivityReport, scenario string, minimal bool,
+	labelsColumn []string, file string) error {
+
+	wb := xlsx.NewWorkbook()
+	wb.Currency = config.GetWork().Currency
+
+	headers := []string{"Name", "Description"}
+	totColumns := []int{}
+	if !minimal {
+		headers = append(headers, "% (of Plan)", "# Tasks", "Work (hours)", "Effort (hours)")
+		totColumns = append(totColumns, 3, 4, 5)
+		if scenario != "" {
+			headers = append(headers,
+				"Business Progress", "Cost", "Offer", "Revenue Plan", "Profit", "% Profit")
+			totColumns = append(totColumns, 7, 8, 9, 10)
+		}
+	}
+	headers = append(headers, labelsColumn...)
+
+	// One sheet for every client.
+	clientMap := tm.GetActivityClientMap()
+	for _, activity := range activities {
+		client := clientMap[activity.Name]
+		sheet := wb.GetSheet(xlsx.GetSheetName(client))
+		if sheet == nil {
+			sheet = wb.AddSheet(client, headers...)
+		}
+
+		row := []xlsx.Cell{xlsx.String(activity.Name), xlsx.String(activity.Description)}
+		if !minimal {
+			row = append(row,
+				percentCell(activity.WorkPerc),
+				xlsx.Integer(int64(len(activity.Tasks))),
+				xlsx.Hours(activity.WorkSecs),
+				xlsx.Hours(activity.Effort),
+			)
+
+			if scenario != "" {
+				profit := float64(0)
+				if (activity.Offer > 0 && activity.WorkSecs > 0) || activity.IsTimeAndMaterial() {
+					profit = activity.Profit
+				}
+				row = append(row,
+					percentCell(activity.BusinessProgressPerc),
+					xlsx.Currency(activity.Cost),
+					xlsx.Currency(float64(activity.Offer)),
+					xlsx.Currency(activity.RevenuePlan),
+					xlsx.Currency(profit),
+					percentCell(activity.ProfitPerc),
+				)
+			}
+		}
+
+		for _, l := range labelsColumn {
+			row = append(row, xlsx.String(activity.GetLabelValue(l, "")))
+		}
+
+		sheet.AddRow(row...)
+	}
+
+	if len(wb.Sheets) == 0 {
+		wb.AddSheet("Activities", headers...)
+	}
+
+	for _, sheet := range wb.Sheets {
+		sheet.AddTotalRow(fmt.Sprintf("Total (%d)", len(sheet.Rows)), totColumns...)
+	}
+
+	return wb.WriteFile(file)
+}
+
 func NewSummaryCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	var activityNames []string
 	var excludeActivityNames []string
@@ -77,6 +160,14 @@ func NewSummaryCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				fmt.Println("Both option --closed and --only-closed not admitted.")
 				os.Exit(1)
 			}
+
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			csvOutput, _ := cmd.Flags().GetBool("csv")
+			xlsxFile, _ := cmd.Flags().GetString("xlsx")
+			if xlsxFile != "" && (jsonOutput || csvOutput) {
+				fmt.Println("Option --xlsx not admitted with --csv or --json.")
+				os.Exit(1)
+			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 
@@ -86,6 +177,7 @@ func NewSummaryCommand(config *specs.TimeMasterConfig) *cobra.Command {
 			labelsInAnd, _ := cmd.Flags().GetBool("labels-in-and")
 			jsonOutput, _ := cmd.Flags().GetBool("json")
 			csvOutput, _ := cmd.Flags().GetBool("csv")
+			xlsxFile, _ := cmd.Flags().GetString("xlsx")
 			scenario, _ := cmd.Flags().GetString("scenario-name")
 			scenarioFile, _ := cmd.Flags().GetString("scenario")
 			from, _ := cmd.Flags().GetString("from")
@@ -166,7 +258,14 @@ func NewSummaryCommand(config *specs.TimeMasterConfig) *cobra.Command {
 			activitiesReport := []specs.ActivityReport{}
 
 			if len(activities) == 0 {
-				if jsonOutput {
+				if xlsxFile != "" {
+					err := writeActivitiesXlsx(tm, config, activitiesReport, scenario,
+						minimal, labelsColumn, xlsxFile)
+					if err != nil {
+						fmt.Println("Error on write xlsx file: " + err.Error())
+						os.Exit(1)
+					}
+				} else if jsonOutput {
 
 					data, err := json.Marshal(activitiesReport)
 					if err != nil {
@@ -285,7 +384,14 @@ func NewSummaryCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				totRevenueRate += revenue
 			}
 
-			if jsonOutput {
+			if xlsxFile != "" {
+				err := writeActivitiesXlsx(tm, config, activitiesReport, scenario,
+					minimal, labelsColumn, xlsxFile)
+				if err != nil {
+					fmt.Println("Error on write xlsx file: " + err.Error())
+					os.Exit(1)
+				}
+			} else if jsonOutput {
 				data, err := json.Marshal(activitiesReport)
 				if err != nil {
 					fmt.Println("Error on convert activities to json: " + err.Error())
@@ -441,6 +547,7 @@ func NewSummaryCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	flags.Bool("closed", false, "Include closed activities.")
 	flags.Bool("json", false, "Print output in JSON format.")
 	flags.Bool("csv", false, "Print output in CSV format.")
+	flags.String("xlsx", "", "Write output to the specified XLSX file.")
 	flags.Bool("only-closed", false, "Show only closed activities.")
 	flags.Bool("labels-in-and", false, "Filter labels in AND. Default match is in OR.")
 	flags.String("scenario-name", "", "Specify scenario name for cost/revenue.")
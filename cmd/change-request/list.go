@@ -27,6 +27,7 @@ import (
 
 	loader "github.com/geaaru/time-master/pkg/loader"
 	specs "github.com/geaaru/time-master/pkg/specs"
+	xlsx "github.com/geaaru/time-master/pkg/xlsx"
 
 	tablewriter "github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -36,6 +37,15 @@ func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   "list",
 		Short: "list of Change Requests.",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			csvOutput, _ := cmd.Flags().GetBool("csv")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			xlsxFile, _ := cmd.Flags().GetString("xlsx")
+			if xlsxFile != "" && (jsonOutput || csvOutput) {
+				fmt.Println("Option --xlsx not admitted with --csv or --json.")
+				os.Exit(1)
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 
 			// Create Instance
@@ -49,21 +59,57 @@ func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 
 			csvOutput, _ := cmd.Flags().GetBool("csv")
 			jsonOutput, _ := cmd.Flags().GetBool("json")
+			xlsxFile, _ := cmd.Flags().GetString("xlsx")
 
 			res := []specs.ChangeRequestReport{}
 
+			wb := xlsx.NewWorkbook()
+			wb.Currency = config.GetWork().Currency
+			headers := []string{
+				"Activity", "CR", "Description", "Previous Offer", "Offer",
+			}
+
 			for _, c := range *tm.GetClients() {
+				var sheet *xlsx.Sheet
 				for _, a := range *c.GetActivities() {
 					crs := a.GetChangeRequests()
 					if crs != nil && len(*crs) > 0 {
 						for _, cr := range *crs {
-							res = append(res, *specs.NewChangeRequestReport(&cr, a.Name))
+							crr := specs.NewChangeRequestReport(&cr, a.Name)
+							res = append(res, *crr)
+
+							// One sheet for every client.
+							if sheet == nil {
+								sheet = wb.AddSheet(c.Name, headers...)
+							}
+							sheet.AddRow(
+								xlsx.String(crr.ActivityName),
+								xlsx.String(crr.Name),
+								xlsx.String(crr.Description),
+								xlsx.Currency(float64(crr.PreviousOffer)),
+								xlsx.Currency(float64(crr.Offer)),
+							)
 						}
 					}
 				}
 			}
 
-			if csvOutput {
+			if xlsxFile != "" {
+
+				if len(wb.Sheets) == 0 {
+					wb.AddSheet("Change Requests", headers...)
+				}
+				for _, sheet := range wb.Sheets {
+					sheet.AddTotalRow(fmt.Sprintf("Total (%d)", len(sheet.Rows)), 3, 4)
+				}
+
+				err := wb.WriteFile(xlsxFile)
+				if err != nil {
+					fmt.Println("Error on write xlsx file: " + err.Error())
+					os.Exit(1)
+				}
+
+			} else if csvOutput {
 
 				w := csv.NewWriter(os.Stdout)
 				records := make([][]string, len(res)+1)
@@ -133,6 +179,7 @@ func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	flags := cmd.Flags()
 	flags.Bool("csv", false, "Print output in CSV format")
 	flags.Bool("json", false, "Print output in JSON format")
+	flags.String("xlsx", "", "Write output to the specified XLSX file.")
 
 	return cmd
 }
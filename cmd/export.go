@@ -0,0 +1,42 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	. "github.com/geaaru/time-master/cmd/export"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	"github.com/spf13/cobra"
+)
+
+func newExportCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "export [command] [OPTIONS]",
+		Short: "Export project data for external tools.",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		NewTjpCommand(config),
+		NewMsProjectCommand(config),
+	)
+
+	return cmd
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_export
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	exporter "github.com/geaaru/time-master/pkg/exporter"
+	loader "github.com/geaaru/time-master/pkg/loader"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	"github.com/spf13/cobra"
+)
+
+func NewTjpCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "tjp",
+		Short: "Export clients, activities, resources and timesheets as TaskJuggler project.",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			toFile, _ := cmd.Flags().GetString("to")
+			stdOut, _ := cmd.Flags().GetBool("stdout")
+			if toFile == "" && !stdOut {
+				fmt.Println("One of the options --to or --stdout is mandatory.")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			toFile, _ := cmd.Flags().GetString("to")
+			stdOut, _ := cmd.Flags().GetBool("stdout")
+			now, _ := cmd.Flags().GetString("now")
+			name, _ := cmd.Flags().GetString("name")
+			skipTimesheets, _ := cmd.Flags().GetBool("skip-timesheets")
+
+			// Create Instance
+			tm := loader.NewTimeMasterInstance(config)
+
+			err := tm.Load()
+			if err != nil {
+				fmt.Println("Error on load data: " + err.Error())
+				os.Exit(1)
+			}
+
+			exp := exporter.NewTmTjpExporter(config)
+			exp.Now = now
+			if name != "" {
+				exp.ProjectName = name
+			}
+			exp.SetClients(*tm.GetClients())
+			exp.SetResources(*tm.GetResources())
+			if !skipTimesheets {
+				err = exp.SetTimesheets(*tm.GetTimesheets())
+				if err != nil {
+					fmt.Println("Error on read timesheets: " + err.Error())
+					os.Exit(1)
+				}
+			}
+
+			data, err := exp.Export()
+			if err != nil {
+				fmt.Println("Error on export project: " + err.Error())
+				os.Exit(1)
+			}
+
+			if toFile != "" {
+				err := ioutil.WriteFile(toFile, data, 0644)
+				if err != nil {
+					fmt.Println("Error on write data on file: " + err.Error())
+					os.Exit(1)
+				}
+			}
+
+			if stdOut {
+				fmt.Println(string(data))
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.String("to", "", "Path of the tjp file to write.")
+	flags.BoolP("stdout", "o", false, "Write to stdout.")
+	flags.String("now", "", "Now date of the project in the format YYYY-MM-DD. Default today.")
+	flags.String("name", "", "Name of the project. Default Time Master.")
+	flags.Bool("skip-timesheets", false, "Don't export the timesheets as bookings.")
+
+	return cmd
+}
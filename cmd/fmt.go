@@ -0,0 +1,180 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	formatter "github.com/geaaru/time-master/pkg/formatter"
+	loader "github.com/geaaru/time-master/pkg/loader"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tools "github.com/geaaru/time-master/pkg/tools"
+
+	"github.com/spf13/cobra"
+)
+
+type fmtFile struct {
+	Path string
+	Type string
+}
+
+func getWorkspaceFiles(tm *loader.TimeMasterInstance) []fmtFile {
+	ans := []fmtFile{}
+	visited := make(map[string]bool, 0)
+
+	add := func(f, t string) {
+		if f != "" && !visited[f] {
+			visited[f] = true
+			ans = append(ans, fmtFile{Path: f, Type: t})
+		}
+	}
+
+	for _, c := range *tm.GetClients() {
+		add(c.File, formatter.FILE_CLIENT)
+		for _, a := range *c.GetActivities() {
+			add(a.File, formatter.FILE_ACTIVITY)
+		}
+	}
+	for _, r := range *tm.GetResources() {
+		add(r.File, formatter.FILE_RESOURCE)
+	}
+	for _, s := range *tm.GetScenarios() {
+		add(s.File, formatter.FILE_SCENARIO)
+	}
+	for _, a := range *tm.GetTimesheets() {
+		add(a.File, formatter.FILE_TIMESHEET)
+	}
+
+	return ans
+}
+
+func newFmtCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "fmt [file1] ... [fileN]",
+		Short: "Format data files in the canonical form.",
+		Long: `Format data files in the canonical form.
+
+Without arguments all the files of clients, activities, resources,
+scenarios and timesheets are processed.
+
+Without --write, --check or --diff the formatted content of the file
+is printed to stdout and only one file is admitted.
+
+The keys are sorted in a canonical order, the empty fields are removed,
+the efforts are normalized in days (or hours) based on work_hours and the
+timesheets are sorted by date and user.
+
+$> tm fmt --check
+
+$> tm fmt --write clients/client1.yml
+`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			check, _ := cmd.Flags().GetBool("check")
+			write, _ := cmd.Flags().GetBool("write")
+			if check && write {
+				fmt.Println("Both option --check and --write not admitted.")
+				os.Exit(1)
+			}
+			showDiff, _ := cmd.Flags().GetBool("diff")
+			if !check && !write && !showDiff && len(args) != 1 {
+				fmt.Println("Print of the formatted content admitted only with one file. Use --write, --check or --diff.")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var files []fmtFile
+
+			check, _ := cmd.Flags().GetBool("check")
+			write, _ := cmd.Flags().GetBool("write")
+			showDiff, _ := cmd.Flags().GetBool("diff")
+
+			if len(args) > 0 {
+				for _, f := range args {
+					files = append(files, fmtFile{Path: f})
+				}
+			} else {
+				// Create Instance
+				tm := loader.NewTimeMasterInstance(config)
+
+				err := tm.Load()
+				if err != nil {
+					fmt.Println("Error on load data:" + err.Error() + "\n")
+					os.Exit(1)
+				}
+
+				files = getWorkspaceFiles(tm)
+			}
+
+			f := formatter.NewTmFormatter(config)
+			unformatted := 0
+
+			for _, file := range files {
+				original, content, err := f.FormatFile(file.Path, file.Type)
+				if err != nil {
+					fmt.Println("Error on format file " + err.Error())
+					os.Exit(1)
+				}
+
+				if !write && !check && !showDiff {
+					fmt.Print(string(content))
+					continue
+				}
+
+				if string(original) == string(content) {
+					continue
+				}
+
+				unformatted++
+
+				if write {
+					err = ioutil.WriteFile(file.Path, content, 0644)
+					if err != nil {
+						fmt.Println("Error on write file " + file.Path + ": " + err.Error())
+						os.Exit(1)
+					}
+					fmt.Println(file.Path)
+				} else if check {
+					fmt.Println(file.Path)
+				}
+
+				if showDiff {
+					fmt.Print(tools.UnifiedDiff(
+						filepath.Join("a", file.Path), filepath.Join("b", file.Path),
+						string(original), string(content)))
+				}
+			}
+
+			if check && unformatted > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	pflags := cmd.Flags()
+	pflags.Bool("check", false,
+		"Print the files not formatted and exit with error if there are files to format.")
+	pflags.BoolP("write", "w", false, "Write the formatted content to the files.")
+	pflags.BoolP("diff", "d", false, "Show the diff of the changes.")
+
+	return cmd
+}
@@ -23,8 +23,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	gantt "github.com/geaaru/time-master/pkg/gantt"
+	loader "github.com/geaaru/time-master/pkg/loader"
 	specs "github.com/geaaru/time-master/pkg/specs"
 
 	"github.com/spf13/cobra"
@@ -42,6 +44,38 @@ func newGanttCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				os.Exit(1)
 			}
 
+			format, _ := cmd.Flags().GetString("format")
+			switch format {
+			case "frappe", "mermaid", "plantuml", "html", "svg":
+			default:
+				fmt.Println("Supported formats are only 'frappe', 'mermaid', 'plantuml', 'html' or 'svg'.")
+				os.Exit(1)
+			}
+
+			byResource, _ := cmd.Flags().GetBool("by-resource")
+			if byResource && format != "svg" {
+				fmt.Println("The by-resource option is supported only with the format 'svg'.")
+				os.Exit(1)
+			}
+
+			hierarchical, _ := cmd.Flags().GetBool("hierarchical")
+			if hierarchical && format != "frappe" && format != "html" {
+				fmt.Println("The hierarchical option is supported only with the formats 'frappe' or 'html'.")
+				os.Exit(1)
+			}
+			if hierarchical && byResource {
+				fmt.Println("The hierarchical and by-resource options are mutually exclusive.")
+				os.Exit(1)
+			}
+
+			viewMode, _ := cmd.Flags().GetString("view-mode")
+			switch viewMode {
+			case "", "Day", "Week", "Month":
+			default:
+				fmt.Println("Supported view modes are only 'Day', 'Week' or 'Month'.")
+				os.Exit(1)
+			}
+
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			pFile, _ := cmd.Flags().GetString("prevision")
@@ -49,6 +83,13 @@ func newGanttCommand(config *specs.TimeMasterConfig) *cobra.Command {
 			stdOut, _ := cmd.Flags().GetBool("stdout")
 			byEndTime, _ := cmd.Flags().GetBool("by-endtime")
 			showActivity, _ := cmd.Flags().GetBool("show-activity")
+			showWeekends, _ := cmd.Flags().GetBool("show-weekends")
+			format, _ := cmd.Flags().GetString("format")
+			viewMode, _ := cmd.Flags().GetString("view-mode")
+			frappeDir, _ := cmd.Flags().GetString("frappe-dir")
+			holidays, _ := cmd.Flags().GetStringSlice("holidays")
+			byResource, _ := cmd.Flags().GetBool("by-resource")
+			hierarchical, _ := cmd.Flags().GetBool("hierarchical")
 
 			prevision, err := specs.ScenarioScheduleFromFile(pFile)
 			if err != nil {
@@ -56,7 +97,7 @@ func newGanttCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				os.Exit(1)
 			}
 
-			producer, err := gantt.NewProducer(config, "frappe")
+			producer, err := gantt.NewProducer(config, format)
 			if err != nil {
 				fmt.Println("Error on create producer: " + err.Error())
 				os.Exit(1)
@@ -65,6 +106,33 @@ func newGanttCommand(config *specs.TimeMasterConfig) *cobra.Command {
 			opts := gantt.ProducerOpts{
 				ShowActivityOnTasks: showActivity,
 				OrderByEndTime:      byEndTime,
+				ShowWeekends:        showWeekends,
+				ViewMode:            viewMode,
+				AssetsDir:           frappeDir,
+				Holidays:            []specs.Period{},
+				ByResource:          byResource,
+				Hierarchical:        hierarchical,
+			}
+
+			if byResource {
+				// The resources are used for the names and the absences.
+				tm := loader.NewTimeMasterInstance(config)
+				err := tm.Load()
+				if err != nil {
+					fmt.Println("Error on load data: " + err.Error())
+					os.Exit(1)
+				}
+				opts.Resources = *tm.GetResources()
+			}
+
+			for _, h := range holidays {
+				// Format: YYYY-MM-DD or YYYY-MM-DD:YYYY-MM-DD
+				dates := strings.SplitN(h, ":", 2)
+				p := specs.Period{StartPeriod: dates[0], EndPeriod: dates[0]}
+				if len(dates) > 1 {
+					p.EndPeriod = dates[1]
+				}
+				opts.Holidays = append(opts.Holidays, p)
 			}
 
 			data, err := producer.Build(prevision, opts)
@@ -95,6 +163,20 @@ func newGanttCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	flags.Bool("show-activity", false,
 		"Add activity name as prefix of task description")
 	flags.Bool("by-endtime", false, "Order tasks by end time instead of start time.")
+	flags.StringP("format", "f", "frappe",
+		"Format of the gantt data. Supported values: frappe|mermaid|plantuml|html|svg.")
+	flags.Bool("show-weekends", false,
+		"Show the weekends on the mermaid and plantuml diagrams.")
+	flags.String("view-mode", "",
+		"Initial view mode of the html page: Day|Week|Month. Default Week.")
+	flags.Bool("by-resource", false,
+		"Produce a row for every resource with the timesheets of the prevision (svg format).")
+	flags.Bool("hierarchical", false,
+		"Group the tasks under collapsible summary bars of clients, activities and parent tasks (frappe and html formats).")
+	flags.StringSlice("holidays", []string{},
+		"Days (YYYY-MM-DD) or periods (YYYY-MM-DD:YYYY-MM-DD) of holidays shaded on the svg gantt.")
+	flags.String("frappe-dir", "",
+		"Directory with the frappe-gantt.js and frappe-gantt.css files to embed in the html page instead of the vendored ones.")
 
 	return cmd
 }
@@ -35,6 +35,7 @@ func newImportCommand(config *specs.TimeMasterConfig) *cobra.Command {
 
 	cmd.AddCommand(
 		NewTimesheetCommand(config),
+		NewMsProjectCommand(config),
 	)
 
 	return cmd
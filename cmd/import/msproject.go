@@ -0,0 +1,221 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	importer "github.com/geaaru/time-master/pkg/importer"
+	loader "github.com/geaaru/time-master/pkg/loader"
+	msproject "github.com/geaaru/time-master/pkg/msproject"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	"github.com/spf13/cobra"
+)
+
+func loadMsProjectMapperFile(file string) (*importer.TmMsProjectMapper, error) {
+	fileAbs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(fileAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	return importer.TmMsProjectMapperFromYaml(content)
+}
+
+func NewMsProjectCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "msproject <file.xml>",
+		Short: "Import activities and tasks from a MS Project XML file.",
+		Long: `Import activities and tasks from a MS Project XML (MSPDI) file.
+
+The activities are written under the first activities directory of the
+client or under the directory defined with the --dir option.
+
+Without --activity-level all the tasks are imported in a single activity.
+With --activity-level the tasks of the level are converted to activities.
+
+$> tm import msproject plan.xml --client CLIENT1 --activity PLAN
+
+$> tm import msproject plan.xml --dir activities/ --activity-level 1 --mapper-file mapper.yml
+`,
+		Args: cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			client, _ := cmd.Flags().GetString("client")
+			dir, _ := cmd.Flags().GetString("dir")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			skeleton, _ := cmd.Flags().GetBool("mapper-skeleton")
+			if client == "" && dir == "" && !dryRun && !skeleton {
+				fmt.Println("One of the options --client or --dir is mandatory.")
+				os.Exit(1)
+			}
+			if client != "" && dir != "" {
+				fmt.Println("Both option --client and --dir not admitted.")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			clientName, _ := cmd.Flags().GetString("client")
+			dir, _ := cmd.Flags().GetString("dir")
+			mapperFile, _ := cmd.Flags().GetString("mapper-file")
+			activityName, _ := cmd.Flags().GetString("activity")
+			activityLevel, _ := cmd.Flags().GetInt("activity-level")
+			force, _ := cmd.Flags().GetBool("force")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			skeleton, _ := cmd.Flags().GetBool("mapper-skeleton")
+
+			// Create Instance
+			tm := loader.NewTimeMasterInstance(config)
+
+			err := tm.Load()
+			if err != nil {
+				fmt.Println("Error on load data: " + err.Error())
+				os.Exit(1)
+			}
+
+			if clientName != "" {
+				client, err := tm.GetClientByName(clientName)
+				if err != nil {
+					fmt.Println("Error on retrieve client: " + err.Error())
+					os.Exit(1)
+				}
+				if len(client.ActivitiesDirs) == 0 {
+					fmt.Println("Client " + clientName + " without activities_dirs.")
+					os.Exit(1)
+				}
+				dir = path.Join(path.Dir(client.File), client.ActivitiesDirs[0])
+			}
+
+			data, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				fmt.Println("Error on read file " + args[0] + ": " + err.Error())
+				os.Exit(1)
+			}
+
+			project, err := msproject.Parse(data)
+			if err != nil {
+				fmt.Println("Error on parse file " + args[0] + ": " + err.Error())
+				os.Exit(1)
+			}
+
+			imp := importer.NewTmMsProjectImporter(config, *tm.GetResources())
+			imp.ActivityLevel = activityLevel
+			imp.ActivityName = activityName
+
+			if mapperFile != "" {
+				mapper, err := loadMsProjectMapperFile(mapperFile)
+				if err != nil {
+					fmt.Println("Error on load file " + mapperFile + ": " + err.Error())
+					os.Exit(1)
+				}
+				err = imp.ImportMapper(mapper)
+				if err != nil {
+					fmt.Println("Error on import mapper " + mapperFile + ": " + err.Error())
+					os.Exit(1)
+				}
+			}
+
+			activities, err := imp.GetActivities(project)
+			if err != nil {
+				fmt.Println("Error on convert project: " + err.Error())
+				os.Exit(1)
+			}
+
+			if skeleton {
+				data, err := imp.MapperSkeleton()
+				if err != nil {
+					fmt.Println("Error on create mapper skeleton: " + err.Error())
+					os.Exit(1)
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			// Validate all the activities before write the files.
+			files := make(map[string]string, 0)
+			for idx := range activities {
+				a := &activities[idx]
+
+				file := importer.GetActivityFile(a, dir)
+				if prev, ok := files[file]; ok {
+					fmt.Println("Activities " + prev + " and " + a.Name + " with the same file " + file + ".")
+					os.Exit(1)
+				}
+				files[file] = a.Name
+
+				if force {
+					continue
+				}
+				if _, _, err := tm.GetActivityByName(a.Name); err == nil {
+					fmt.Println("Activity " + a.Name + " already present. Use --force to overwrite it.")
+					os.Exit(1)
+				}
+				if _, err := os.Stat(file); err == nil && !dryRun {
+					fmt.Println("File " + file + " already present. Use --force to overwrite it.")
+					os.Exit(1)
+				}
+			}
+
+			for idx := range activities {
+				a := &activities[idx]
+
+				if dryRun {
+					content, err := imp.MarshalActivity(a)
+					if err != nil {
+						fmt.Println("Error on marshal activity " + a.Name + ": " + err.Error())
+						os.Exit(1)
+					}
+					fmt.Println(string(content))
+					continue
+				}
+
+				_, err := imp.WriteActivity(a, dir, force)
+				if err != nil {
+					fmt.Println("Error on write activity " + a.Name + ": " + err.Error())
+					os.Exit(1)
+				}
+			}
+
+			if !imp.GetReport().IsEmpty() {
+				printImportReport(imp.GetReport(), os.Stderr)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.String("client", "", "Write the activities under the first activities directory of the client.")
+	flags.String("dir", "", "Directory where write the activities files.")
+	flags.String("mapper-file", "", "Path of the mapper file with the resources mapping.")
+	flags.String("activity", "", "Name of the activity when all the tasks are imported in a single activity.")
+	flags.Int("activity-level", 0, "Outline level of the tasks converted to activities. Default 0 (single activity).")
+	flags.Bool("force", false, "Overwrite existing activities.")
+	flags.Bool("dry-run", false, "Print the activities without write the files.")
+	flags.Bool("mapper-skeleton", false, "Print the mapper with the unmapped resources.")
+
+	return cmd
+}
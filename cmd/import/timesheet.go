@@ -21,6 +21,7 @@ package cmd_client
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -28,6 +29,7 @@ import (
 	importer "github.com/geaaru/time-master/pkg/importer"
 	specs "github.com/geaaru/time-master/pkg/specs"
 
+	tablewriter "github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 )
@@ -60,20 +62,114 @@ func loadKimaiMapperFile(file string) (*importer.TmKimaiMapper, error) {
 	return importer.TmKimaiMapperFromYaml(content)
 }
 
+func loadCsvMapperFile(file string) (*importer.TmCsvMapper, error) {
+	fileAbs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(fileAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	return importer.TmCsvMapperFromYaml(content)
+}
+
+func printImportReport(report *importer.TmImportReport, w io.Writer) {
+	printImportReportSection("Unmapped entries:", report.Unmapped, w)
+	printImportReportSection("Ignored entries:", report.Ignored, w)
+}
+
+func printImportReportSection(title string, entries []*importer.TmImportReportEntry, w io.Writer) {
+	if len(entries) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, title)
+	table := tablewriter.NewWriter(w)
+	table.SetBorders(tablewriter.Border{
+		Left:   true,
+		Top:    true,
+		Right:  true,
+		Bottom: true})
+	table.SetHeader([]string{"Type", "Value", "Entries", "Hours"})
+	table.SetColWidth(100)
+	for _, e := range entries {
+		table.Append([]string{
+			e.Kind, e.Value, fmt.Sprintf("%d", e.Entries), e.GetHours(),
+		})
+	}
+	table.Render()
+}
+
+func loadTrackingMapperFile(file string) (*importer.TmTrackingMapper, error) {
+	fileAbs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(fileAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	return importer.TmTrackingMapperFromYaml(content)
+}
+
+func loadIcsMapperFile(file string) (*importer.TmIcsMapper, error) {
+	fileAbs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(fileAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	return importer.TmIcsMapperFromYaml(content)
+}
+
 func NewTimesheetCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	var cmd = &cobra.Command{
-		Use:   "timesheet [file]",
+		Use:   "timesheet [file|jql]",
 		Short: "Import CSV timesheet",
+		Long: `Import CSV timesheet or the worklogs from the Jira API.
+
+With the import type jira-api the argument is the JQL query of the issues
+and the worklogs are filtered with the --from and --to options.
+
+With the import type kimai-api the timesheets of the period defined by the
+--from and --to options are retrieved from the Kimai API. The --users option
+limits the import to the specified users.
+
+With the import type ics the accepted meetings of an iCalendar file are
+imported. The --from and --to options define the period of the meetings,
+without the --to option the meetings until now are imported.`,
 		PreRun: func(cmd *cobra.Command, args []string) {
 
-			if len(args) == 0 {
-				fmt.Println("Missing import file!")
+			importType, _ := cmd.Flags().GetString("import-type")
+			switch importType {
+			case "jira", "jira-api", "kimai", "kimai-api", "csv", "toggl", "clockify", "ics":
+			default:
+				fmt.Println("import-type supported is only 'jira', 'jira-api', 'kimai', 'kimai-api', " +
+					"'csv', 'toggl', 'clockify' or 'ics'")
 				os.Exit(1)
 			}
 
-			importType, _ := cmd.Flags().GetString("import-type")
-			if importType != "jira" && importType != "kimai" {
-				fmt.Println("import-type supported is only 'jira' or 'kimai'")
+			if len(args) == 0 && importType != "kimai-api" {
+				if importType == "jira-api" {
+					fmt.Println("Missing JQL query!")
+				} else {
+					fmt.Println("Missing import file!")
+				}
+				os.Exit(1)
+			}
+
+			csvMapperFile, _ := cmd.Flags().GetString("csv-mapper-file")
+			if importType == "csv" && csvMapperFile == "" {
+				fmt.Println("Missing csv-mapper-file option")
 				os.Exit(1)
 			}
 
@@ -82,6 +178,14 @@ func NewTimesheetCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				fmt.Println("Missing dir option")
 				os.Exit(1)
 			}
+
+			merge, _ := cmd.Flags().GetBool("merge")
+			removeMissing, _ := cmd.Flags().GetBool("remove-missing")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			if !merge && (removeMissing || dryRun) {
+				fmt.Println("The options remove-missing and dry-run require the merge option")
+				os.Exit(1)
+			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 
@@ -94,51 +198,135 @@ func NewTimesheetCommand(config *specs.TimeMasterConfig) *cobra.Command {
 			jiraMapperFile, _ := cmd.Flags().GetString("jira-mapper-file")
 			kimaiMapperFile, _ := cmd.Flags().GetString("kimai-mapper-file")
 			stdout, _ := cmd.Flags().GetBool("stdout")
+			merge, _ := cmd.Flags().GetBool("merge")
+			removeMissing, _ := cmd.Flags().GetBool("remove-missing")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
 
 			opts := importer.ImportOpts{
 				SplitResource: splitForUser,
+				Merge:         merge,
+				RemoveMissing: removeMissing,
+				DryRun:        dryRun,
 			}
 
+			var jiraImp *importer.TmJiraImporter
+			var kimaiImp *importer.TmKimaiImporter
+			var trackingImp *importer.TmTrackingImporter
+
 			switch importType {
 			case "jira":
-				imp = importer.NewTmJiraImporter(config, dir, targetPrefix, opts)
+				jiraImp = importer.NewTmJiraImporter(config, dir, targetPrefix, opts)
+				imp = jiraImp
+			case "jira-api":
+				from, _ := cmd.Flags().GetString("from")
+				to, _ := cmd.Flags().GetString("to")
+				apiImp := importer.NewTmJiraApiImporter(config, dir, targetPrefix, opts)
+				apiImp.SetPeriod(from, to)
+				jiraImp = apiImp.TmJiraImporter
+				imp = apiImp
+			case "csv":
+				imp = importer.NewTmCsvImporter(config, dir, targetPrefix, opts)
+			case "toggl":
+				togglImp := importer.NewTmTogglImporter(config, dir, targetPrefix, opts)
+				trackingImp = togglImp.TmTrackingImporter
+				imp = togglImp
+			case "clockify":
+				clockifyImp := importer.NewTmClockifyImporter(config, dir, targetPrefix, opts)
+				trackingImp = clockifyImp.TmTrackingImporter
+				imp = clockifyImp
+			case "ics":
+				from, _ := cmd.Flags().GetString("from")
+				to, _ := cmd.Flags().GetString("to")
+				icsImp := importer.NewTmIcsImporter(config, dir, targetPrefix, opts)
+				icsImp.SetPeriod(from, to)
+
+				icsMapperFile, _ := cmd.Flags().GetString("ics-mapper-file")
+				if icsMapperFile != "" {
+					mapper, err := loadIcsMapperFile(icsMapperFile)
+					if err != nil {
+						fmt.Println("Error on load file " + icsMapperFile + ": " + err.Error())
+						os.Exit(1)
+					}
+					err = icsImp.ImportMapper(mapper)
+					if err != nil {
+						fmt.Println("Error on import mapper " + icsMapperFile + ": " + err.Error())
+						os.Exit(1)
+					}
+				}
+				imp = icsImp
+			case "kimai-api":
+				from, _ := cmd.Flags().GetString("from")
+				to, _ := cmd.Flags().GetString("to")
+				users, _ := cmd.Flags().GetStringSlice("users")
+				apiImp := importer.NewTmKimaiApiImporter(config, dir, targetPrefix, opts)
+				apiImp.SetPeriod(from, to)
+				apiImp.SetUsers(users)
+				kimaiImp = apiImp.TmKimaiImporter
+				imp = apiImp
 			default:
 				// Default kimai
-				imp = importer.NewTmKimaiImporter(config, dir, targetPrefix, opts)
+				kimaiImp = importer.NewTmKimaiImporter(config, dir, targetPrefix, opts)
+				imp = kimaiImp
 			}
 
-			if jiraMapperFile != "" && importType == "jira" {
+			if jiraMapperFile != "" && jiraImp != nil {
 				mapper, err := loadMapperFile(jiraMapperFile)
 				if err != nil {
 					fmt.Println("Error on load file " + jiraMapperFile + ": " + err.Error())
 					os.Exit(1)
 				}
-				(imp.(*importer.TmJiraImporter)).ImportMapper(mapper)
+				err = jiraImp.ImportMapper(mapper)
+				if err != nil {
+					fmt.Println("Error on import mapper " + jiraMapperFile + ": " + err.Error())
+					os.Exit(1)
+				}
+			}
 
-				jiraBefore202009, _ := cmd.Flags().GetBool("jira-before202009")
-				jiraBefore202401, _ := cmd.Flags().GetBool("jira-before202401")
-				jiraBefore202408, _ := cmd.Flags().GetBool("jira-before202408")
-				if jiraBefore202009 {
-					(imp.(*importer.TmJiraImporter)).SetBefore202009()
+			if kimaiMapperFile != "" && kimaiImp != nil {
+				mapper, err := loadKimaiMapperFile(kimaiMapperFile)
+				if err != nil {
+					fmt.Println("Error on load file " + kimaiMapperFile + ": " + err.Error())
+					os.Exit(1)
+				}
+				err = kimaiImp.ImportMapper(mapper)
+				if err != nil {
+					fmt.Println("Error on import mapper " + kimaiMapperFile + ": " + err.Error())
+					os.Exit(1)
 				}
-				if jiraBefore202401 {
-					(imp.(*importer.TmJiraImporter)).SetBefore202401()
+			}
+
+			trackingMapperFile, _ := cmd.Flags().GetString("tracking-mapper-file")
+			if trackingMapperFile != "" && trackingImp != nil {
+				mapper, err := loadTrackingMapperFile(trackingMapperFile)
+				if err != nil {
+					fmt.Println("Error on load file " + trackingMapperFile + ": " + err.Error())
+					os.Exit(1)
 				}
-				if jiraBefore202408 {
-					(imp.(*importer.TmJiraImporter)).SetBefore202408()
+				err = trackingImp.ImportMapper(mapper)
+				if err != nil {
+					fmt.Println("Error on import mapper " + trackingMapperFile + ": " + err.Error())
+					os.Exit(1)
 				}
 			}
 
-			if kimaiMapperFile != "" && importType == "kimai" {
-				mapper, err := loadKimaiMapperFile(kimaiMapperFile)
+			if importType == "csv" {
+				csvMapperFile, _ := cmd.Flags().GetString("csv-mapper-file")
+				mapper, err := loadCsvMapperFile(csvMapperFile)
 				if err != nil {
-					fmt.Println("Error on load file " + kimaiMapperFile + ": " + err.Error())
+					fmt.Println("Error on load file " + csvMapperFile + ": " + err.Error())
+					os.Exit(1)
+				}
+				err = (imp.(*importer.TmCsvImporter)).ImportMapper(mapper)
+				if err != nil {
+					fmt.Println("Error on import mapper " + csvMapperFile + ": " + err.Error())
 					os.Exit(1)
 				}
-				(imp.(*importer.TmKimaiImporter)).ImportMapper(mapper)
 			}
 
-			sourceFile := args[0]
+			sourceFile := ""
+			if len(args) > 0 {
+				sourceFile = args[0]
+			}
 
 			err := imp.LoadTimesheets(sourceFile)
 			if err != nil {
@@ -157,6 +345,21 @@ func NewTimesheetCommand(config *specs.TimeMasterConfig) *cobra.Command {
 					fmt.Println(string(data))
 				}
 
+			} else if merge {
+
+				summary, err := imp.MergeTimesheets()
+				if err != nil {
+					fmt.Println("Error on merge timesheets: " + err.Error())
+					os.Exit(1)
+				}
+
+				for _, f := range summary.Files {
+					if dryRun && f.Diff != "" {
+						fmt.Print(f.Diff)
+					}
+					fmt.Println(f.String())
+				}
+
 			} else {
 
 				err = imp.WriteTimesheets()
@@ -167,26 +370,79 @@ func NewTimesheetCommand(config *specs.TimeMasterConfig) *cobra.Command {
 
 			}
 
+			report := imp.GetReport()
+			if !report.IsEmpty() {
+				// With stdout the report is printed on stderr to maintain valid the yaml.
+				if stdout {
+					printImportReport(report, os.Stderr)
+				} else {
+					printImportReport(report, os.Stdout)
+				}
+			}
+
+			skeletonFile, _ := cmd.Flags().GetString("mapper-skeleton")
+			if skeletonFile != "" {
+				data, err := imp.MapperSkeleton()
+				if err != nil {
+					fmt.Println("Error on create mapper skeleton: " + err.Error())
+					os.Exit(1)
+				}
+
+				err = ioutil.WriteFile(skeletonFile, data, 0644)
+				if err != nil {
+					fmt.Println("Error on write file " + skeletonFile + ": " + err.Error())
+					os.Exit(1)
+				}
+			}
+
 		},
 	}
 
 	flags := cmd.Flags()
 	flags.StringP("import-type", "i", "kimai",
-		"Define type of the imported file. Supported values: jira|kimai.")
+		"Define type of the imported file. Supported values: jira|jira-api|kimai|kimai-api|csv|toggl|clockify|ics.")
 	flags.StringP("dir", "d", "", "Directory where import timesheets.")
 	flags.StringP("target-prefix", "p", "", "Prefix of the file/files to create.")
 	flags.BoolP("split-for-user", "s", false,
 		"Create a timesheet file for every user.")
 	flags.Bool("stdout", false, "Print timesheets to stdout instead of write files.")
+	flags.String("mapper-skeleton", "",
+		"Write a mapper file with the unmapped users and tasks to fill.")
+	flags.Bool("merge", false,
+		"Merge the imported entries with the existing files by source and external id.")
+	flags.Bool("remove-missing", false,
+		"On merge remove the entries of the same source and period not present on the import.")
+	flags.Bool("dry-run", false, "On merge print the diff without write the files.")
 
 	// Kimai options
 	flags.StringP("kimai-mapper-file", "k", "", "Import Kimai resource mapper file.")
 
+	// CSV options
+	flags.StringP("csv-mapper-file", "m", "",
+		"Mapper file with the layout of the CSV file and the tasks mapping.")
+
+	// API options
+	flags.String("from", "", "Start date (YYYY-MM-DD) of the entries to import from the API or the calendar.")
+	flags.String("to", "", "End date (YYYY-MM-DD) of the entries to import from the API or the calendar.")
+
+	flags.StringSlice("users", []string{},
+		"Username or alias of the users to import from the Kimai API. Default all users.")
+
+	// Toggl and Clockify options
+	flags.StringP("tracking-mapper-file", "t", "",
+		"Mapper file of the users and projects of the Toggl or Clockify export.")
+
+	// ICS options
+	flags.String("ics-mapper-file", "",
+		"Mapper file of the attendees and the meetings of the iCalendar file.")
+
 	// Jira options
 	flags.StringP("jira-mapper-file", "j", "", "Import Jira resource mapper file.")
-	flags.Bool("jira-before202009", false, "Import CSV created before 2020-09")
-	flags.Bool("jira-before202401", false, "Import CSV created before 2024-01")
-	flags.Bool("jira-before202408", false, "Import CSV created before 2024-08")
+	// The columns of the Jira CSV are detected from the header.
+	for _, f := range []string{"jira-before202009", "jira-before202401", "jira-before202408"} {
+		flags.Bool(f, false, "Not used. The columns are detected from the CSV header.")
+		flags.MarkDeprecated(f, "the columns are detected from the CSV header.")
+	}
 
 	return cmd
 }
@@ -0,0 +1,192 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_invoice
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	invoice "github.com/geaaru/time-master/pkg/invoice"
+	loader "github.com/geaaru/time-master/pkg/loader"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	"github.com/spf13/cobra"
+)
+
+func NewBuildCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "build",
+		Short: "Build the invoice of a client for a month.",
+		Long: `Build the invoice of the time and material activities of a client.
+
+The billable timesheets of the month are rounded for every day, user and
+task and priced with the daily offer of the activity. The issued invoice
+is stored in the invoices directory and the billed time is excluded from
+the next invoices.
+
+$> tm invoice build --client CLIENT1 --month 2026-09
+
+$> tm invoice build --client CLIENT1 --month 2026-09 --by-resource --vat 22 --html -f /tmp/invoice.html
+
+$> tm invoice build --client CLIENT1 --month 2026-09 --dry-run
+`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			client, _ := cmd.Flags().GetString("client")
+			month, _ := cmd.Flags().GetString("month")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			htmlOutput, _ := cmd.Flags().GetBool("html")
+			if client == "" || month == "" {
+				fmt.Println("The options --client and --month are mandatory.")
+				os.Exit(1)
+			}
+			if jsonOutput && htmlOutput {
+				fmt.Println("Option --json not admitted with --html.")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			cname, _ := cmd.Flags().GetString("client")
+			month, _ := cmd.Flags().GetString("month")
+			date, _ := cmd.Flags().GetString("date")
+			byResource, _ := cmd.Flags().GetBool("by-resource")
+			rounding, _ := cmd.Flags().GetString("rounding")
+			roundingMode, _ := cmd.Flags().GetString("rounding-mode")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			htmlOutput, _ := cmd.Flags().GetBool("html")
+			file, _ := cmd.Flags().GetString("file")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			// Create Instance
+			tm := loader.NewTimeMasterInstance(config)
+
+			err := tm.Load()
+			if err != nil {
+				fmt.Println("Error on load data: " + err.Error())
+				os.Exit(1)
+			}
+
+			client, err := tm.GetClientByName(cname)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			builder := invoice.NewTmInvoiceBuilder(config)
+			if date != "" {
+				builder.Date = date
+			}
+			if byResource {
+				builder.GroupBy = invoice.INVOICE_BY_RESOURCE
+			}
+			if cmd.Flags().Changed("vat") {
+				builder.VatPerc, _ = cmd.Flags().GetFloat64("vat")
+			}
+			if cmd.Flags().Changed("rounding") {
+				builder.Rounding = rounding
+			}
+			if roundingMode != "" {
+				builder.RoundingMode = roundingMode
+			}
+			for _, r := range *tm.GetResources() {
+				builder.Resources[r.User] = r.Name
+			}
+
+			registry := invoice.NewTmInvoiceRegistry(config)
+			if !dryRun {
+				err = registry.Lock()
+				if err != nil {
+					fmt.Println("Error on lock invoices registry: " + err.Error())
+					os.Exit(1)
+				}
+				defer registry.Unlock()
+			}
+
+			issued, err := registry.Load()
+			if err != nil {
+				fmt.Println("Error on load issued invoices: " + err.Error())
+				os.Exit(1)
+			}
+			builder.SetIssued(issued)
+
+			inv, err := builder.Build(client, month, *tm.GetTimesheets())
+			if err != nil {
+				fmt.Println("Error on build invoice: " + err.Error())
+				os.Exit(1)
+			}
+
+			err = inv.SetNumber(issued, config.GetInvoice().NumberPrefix)
+			if err != nil {
+				fmt.Println("Error on assign invoice number: " + err.Error())
+				os.Exit(1)
+			}
+
+			var data []byte
+			if jsonOutput {
+				data, err = json.MarshalIndent(inv, "", "  ")
+			} else if htmlOutput {
+				data, err = inv.Html()
+			} else {
+				data = []byte(inv.Markdown())
+			}
+			if err != nil {
+				fmt.Println("Error on render invoice: " + err.Error())
+				os.Exit(1)
+			}
+
+			if !dryRun {
+				err = registry.Write(inv)
+				if err != nil {
+					fmt.Println("Error on write invoice: " + err.Error())
+					os.Exit(1)
+				}
+			}
+
+			if file != "" {
+				err = ioutil.WriteFile(file, data, 0644)
+				if err != nil {
+					fmt.Println("Error on write file: " + err.Error())
+					os.Exit(1)
+				}
+				if !dryRun {
+					fmt.Println(fmt.Sprintf("Invoice %s issued.", inv.Number))
+				}
+			} else {
+				fmt.Println(string(data))
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.String("client", "", "Name of the client to invoice.")
+	flags.String("month", "", "Month of the timesheets in the format YYYY-MM.")
+	flags.String("date", "", "Date of the invoice in the format YYYY-MM-DD. Default today.")
+	flags.Bool("by-resource", false, "Line items per resource instead of per task.")
+	flags.Float64("vat", 0, "VAT percentage. Default the value of the config.")
+	flags.String("rounding", "", "Unit used to round the time of every day (ex. 30m). Default the value of the config.")
+	flags.String("rounding-mode", "", "Rounding mode: up|down|nearest. Default the value of the config.")
+	flags.Bool("json", false, "Print output in JSON format.")
+	flags.Bool("html", false, "Print output in HTML format.")
+	flags.StringP("file", "f", "", "Write the invoice to the specified file.")
+	flags.Bool("dry-run", false, "Build the invoice without register it.")
+
+	return cmd
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_invoice
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	invoice "github.com/geaaru/time-master/pkg/invoice"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+
+	tablewriter "github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "list",
+		Short: "list of the issued invoices.",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, _ := cmd.Flags().GetString("client")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			registry := invoice.NewTmInvoiceRegistry(config)
+			issued, err := registry.Load()
+			if err != nil {
+				fmt.Println("Error on load issued invoices: " + err.Error())
+				os.Exit(1)
+			}
+
+			res := []*invoice.Invoice{}
+			for _, inv := range issued {
+				if client != "" && inv.Client != client {
+					continue
+				}
+				res = append(res, inv)
+			}
+
+			if jsonOutput {
+				data, _ := json.Marshal(res)
+				fmt.Println(string(data))
+				return
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetBorders(tablewriter.Border{
+				Left:   true,
+				Top:    true,
+				Right:  true,
+				Bottom: true})
+			table.SetHeader([]string{
+				"Number", "Date", "Client", "Month", "Hours", "Subtotal", "Total",
+			})
+			table.SetAutoWrapText(false)
+
+			for _, inv := range res {
+				hours, _ := tmtime.Seconds2Duration(inv.GetBilledSeconds())
+				table.Append([]string{
+					inv.Number, inv.Date, inv.Client, inv.Month, hours,
+					inv.FormatAmount(inv.Subtotal),
+					inv.FormatAmount(inv.Total),
+				})
+			}
+
+			table.Render()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.String("client", "", "Show only the invoices of the client.")
+	flags.Bool("json", false, "Print output in JSON format.")
+
+	return cmd
+}
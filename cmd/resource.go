@@ -36,6 +36,7 @@ func newResourceCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	cmd.AddCommand(
 		NewListCommand(config),
 		NewTimesheetCommand(config),
+		NewImportAbsencesCommand(config),
 	)
 
 	return cmd
@@ -0,0 +1,187 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_resource
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	ical "github.com/geaaru/time-master/pkg/ical"
+	importer "github.com/geaaru/time-master/pkg/importer"
+	loader "github.com/geaaru/time-master/pkg/loader"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tools "github.com/geaaru/time-master/pkg/tools"
+	tablewriter "github.com/olekukonko/tablewriter"
+
+	"github.com/spf13/cobra"
+)
+
+func NewImportAbsencesCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "import-absences <ics>",
+		Short: "Import holidays and sick leave from an iCalendar file.",
+		Long: `Import holidays, sick leave and unemployed periods from an iCalendar file.
+
+The users of the events are the attendees or, without attendees, the name of
+the calendar. They are mapped through the mapper file or with the emails,
+the names and the users of the resources. The events are classified with the
+rules of the mapper file. Only the all-day events are imported without the
+--timed-events option. The days already present are not duplicated.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+
+			mapperFile, _ := cmd.Flags().GetString("mapper-file")
+			defaultType, _ := cmd.Flags().GetString("default-type")
+			from, _ := cmd.Flags().GetString("from")
+			to, _ := cmd.Flags().GetString("to")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			timedEvents, _ := cmd.Flags().GetBool("timed-events")
+
+			// Create Instance
+			tm := loader.NewTimeMasterInstance(config)
+
+			err := tm.Load()
+			if err != nil {
+				fmt.Println("Error on load data:" + err.Error() + "\n")
+				os.Exit(1)
+			}
+
+			mapper := &importer.TmAbsencesMapper{}
+			if mapperFile != "" {
+				data, err := ioutil.ReadFile(mapperFile)
+				if err != nil {
+					fmt.Println("Error on read file " + mapperFile + ": " + err.Error())
+					os.Exit(1)
+				}
+				mapper, err = importer.TmAbsencesMapperFromYaml(data)
+				if err != nil {
+					fmt.Println("Error on parse file " + mapperFile + ": " + err.Error())
+					os.Exit(1)
+				}
+			}
+			if defaultType != "" {
+				mapper.DefaultType = defaultType
+			}
+
+			imp := importer.NewTmAbsencesImporter(config, *tm.GetResources())
+			imp.SetPeriod(from, to)
+			imp.TimedEvents = timedEvents
+			err = imp.ImportMapper(mapper)
+			if err != nil {
+				fmt.Println("Error on import mapper: " + err.Error())
+				os.Exit(1)
+			}
+
+			data, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				fmt.Println("Error on read file " + args[0] + ": " + err.Error())
+				os.Exit(1)
+			}
+
+			cal, err := ical.Parse(data)
+			if err != nil {
+				fmt.Println("Error on parse file " + args[0] + ": " + err.Error())
+				os.Exit(1)
+			}
+
+			absences, err := imp.GetAbsences(cal)
+			if err != nil {
+				fmt.Println("Error on elaborate events: " + err.Error())
+				os.Exit(1)
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetBorders(tablewriter.Border{
+				Left:   true,
+				Top:    true,
+				Right:  true,
+				Bottom: true})
+			table.SetHeader([]string{"User", "Type", "Start", "End", "Event", "Status"})
+			table.SetColWidth(100)
+
+			for idx, r := range *tm.GetResources() {
+				resource := &(*tm.GetResources())[idx]
+
+				orig, content, added, err := imp.MergeResourceFile(resource, absences)
+				if err != nil {
+					fmt.Println("Error on merge absences of " + r.User + ": " + err.Error())
+					os.Exit(1)
+				}
+
+				for idx := range absences {
+					a := &absences[idx]
+					if a.User != r.User || !importer.IsAbsencePresent(resource, a) {
+						continue
+					}
+					table.Append([]string{a.User, a.Type, a.StartPeriod, a.EndPeriod, a.Summary, "present"})
+				}
+				// The absences partially present are added without the days present.
+				for _, a := range added {
+					table.Append([]string{a.User, a.Type, a.StartPeriod, a.EndPeriod, a.Summary, "added"})
+				}
+
+				if len(added) == 0 {
+					continue
+				}
+
+				if dryRun {
+					fmt.Print(tools.UnifiedDiff(
+						filepath.Join("a", resource.File), filepath.Join("b", resource.File),
+						string(orig), string(content)))
+					continue
+				}
+
+				err = ioutil.WriteFile(resource.File, content, 0644)
+				if err != nil {
+					fmt.Println("Error on write file " + resource.File + ": " + err.Error())
+					os.Exit(1)
+				}
+			}
+
+			table.Render()
+
+			report := imp.Report
+			for _, e := range report.Unmapped {
+				fmt.Println(fmt.Sprintf("No resource found for %s (%d events).", e.Value, e.Entries))
+			}
+			for _, e := range report.Ignored {
+				if e.Kind == importer.REPORT_RULE {
+					fmt.Println(fmt.Sprintf("Ignored event %s with a recurrence rule not supported.", e.Value))
+					continue
+				}
+				fmt.Println(fmt.Sprintf("Ignored event %s (%d events).", e.Value, e.Entries))
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("mapper-file", "m", "", "Mapper file of the users and of the events rules.")
+	flags.String("default-type", "",
+		"Type of the events without rules matched (holidays|sick|unemployed). Default ignored.")
+	flags.String("from", "", "Import only the absences after the date (YYYY-MM-DD).")
+	flags.String("to", "", "Import only the absences before the date (YYYY-MM-DD).")
+	flags.Bool("dry-run", false, "Print the diff of the resources files without write them.")
+	flags.Bool("timed-events", false,
+		"Import also the events with a time as absences of the whole days. Default only the all-day events.")
+
+	return cmd
+}
@@ -79,12 +79,15 @@ func initCommand(rootCmd *cobra.Command, config *specs.TimeMasterConfig) {
 		newChangeRequestCommand(config),
 		newPrintCommand(config),
 		newValidateCommand(config),
+		newFmtCommand(config),
 		newImportCommand(config),
 		newResourceCommand(config),
 		newTimesheetCommand(config),
 		newTaskCommand(config),
 		newScenarioCommand(config),
 		newGanttCommand(config),
+		newExportCommand(config),
+		newInvoiceCommand(config),
 	)
 }
 
@@ -36,6 +36,7 @@ func newScenarioCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	cmd.AddCommand(
 		NewListCommand(config),
 		NewBuildCommand(config),
+		NewExportIcsCommand(config),
 	)
 
 	return cmd
@@ -0,0 +1,153 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_scenario
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	exporter "github.com/geaaru/time-master/pkg/exporter"
+	loader "github.com/geaaru/time-master/pkg/loader"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	"github.com/spf13/cobra"
+)
+
+func NewExportIcsCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "export-ics",
+		Short: "Export the planned work of the resources of a prevision as iCalendar files.",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			pFile, _ := cmd.Flags().GetString("prevision")
+			toFile, _ := cmd.Flags().GetString("to")
+			toDir, _ := cmd.Flags().GetString("to-dir")
+
+			if pFile == "" {
+				fmt.Println("Mandatory --prevision option missing.")
+				os.Exit(1)
+			}
+
+			if (toFile == "" && toDir == "") || (toFile != "" && toDir != "") {
+				fmt.Println("One of the options --to or --to-dir is mandatory.")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			pFile, _ := cmd.Flags().GetString("prevision")
+			toFile, _ := cmd.Flags().GetString("to")
+			toDir, _ := cmd.Flags().GetString("to-dir")
+			onlyPlanned, _ := cmd.Flags().GetBool("only-planned")
+			users, _ := cmd.Flags().GetStringSlice("resource")
+
+			prevision, err := specs.ScenarioScheduleFromFile(pFile)
+			if err != nil {
+				fmt.Println("Error on load prevision file: " + err.Error())
+				os.Exit(1)
+			}
+
+			// The resources are used for the names and the emails and
+			// the clients for the descriptions of the events.
+			tm := loader.NewTimeMasterInstance(config)
+			err = tm.Load()
+			if err != nil {
+				fmt.Println("Error on load data: " + err.Error())
+				os.Exit(1)
+			}
+
+			exp := exporter.NewTmIcsExporter(config)
+			exp.OnlyPlanned = onlyPlanned
+			exp.SetResources(*tm.GetResources())
+			exp.SetClients(*tm.GetClients())
+
+			if len(users) > 0 {
+				filterResources(prevision, users)
+			}
+
+			if toFile != "" {
+				cal, err := exp.BuildCalendar(prevision)
+				if err != nil {
+					fmt.Println("Error on build calendar: " + err.Error())
+					os.Exit(1)
+				}
+
+				err = ioutil.WriteFile(toFile, cal.Marshal(), 0644)
+				if err != nil {
+					fmt.Println("Error on write file: " + err.Error())
+					os.Exit(1)
+				}
+
+				fmt.Println(fmt.Sprintf("Created file %s with %d events.", toFile, len(cal.Events)))
+				return
+			}
+
+			calendars, err := exp.BuildCalendars(prevision)
+			if err != nil {
+				fmt.Println("Error on build calendars: " + err.Error())
+				os.Exit(1)
+			}
+
+			err = os.MkdirAll(toDir, os.ModePerm)
+			if err != nil {
+				fmt.Println("Error on create directory: " + err.Error())
+				os.Exit(1)
+			}
+
+			for _, c := range calendars {
+				f := filepath.Join(toDir, c.User+".ics")
+				err = ioutil.WriteFile(f, c.Calendar.Marshal(), 0644)
+				if err != nil {
+					fmt.Println("Error on write file: " + err.Error())
+					os.Exit(1)
+				}
+
+				fmt.Println(fmt.Sprintf("Created file %s with %d events.", f, len(c.Calendar.Events)))
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.String("prevision", "", "Path of the file with the scenario prevision.")
+	flags.String("to", "", "Path of the file with the events of all the resources.")
+	flags.String("to-dir", "", "Directory where write a file <user>.ics for every resource.")
+	flags.Bool("only-planned", false, "Export only the work from the now date of the scenario.")
+	flags.StringSlice("resource", []string{}, "Export only the work of the selected resources.")
+
+	return cmd
+}
+
+// Remove the timesheets of the resources not selected.
+func filterResources(s *specs.ScenarioSchedule, users []string) {
+	m := make(map[string]bool, 0)
+	for _, u := range users {
+		m[u] = true
+	}
+
+	for idx := range s.Schedule {
+		timesheets := []specs.ResourceTimesheet{}
+		for _, rt := range s.Schedule[idx].Timesheets {
+			if _, ok := m[rt.User]; ok {
+				timesheets = append(timesheets, rt)
+			}
+		}
+		s.Schedule[idx].Timesheets = timesheets
+	}
+}
@@ -35,6 +35,7 @@ func newTaskCommand(config *specs.TimeMasterConfig) *cobra.Command {
 
 	cmd.AddCommand(
 		NewListCommand(config),
+		NewRenameCommand(config),
 	)
 
 	return cmd
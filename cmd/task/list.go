@@ -24,15 +24,86 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	loader "github.com/geaaru/time-master/pkg/loader"
 	specs "github.com/geaaru/time-master/pkg/specs"
 	time "github.com/geaaru/time-master/pkg/time"
+	xlsx "github.com/geaaru/time-master/pkg/xlsx"
 
 	tablewriter "github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
+func writeTasksXlsx(tm *loader.TimeMasterInstance, config *specs.TimeMasterConfig,
+	res []specs.Task, rtaMap map[string]*specs.ResourceTsAggregated,
+	showWorkHours, showCost, minimal bool, file string) error {
+
+	wb := xlsx.NewWorkbook()
+	wb.Currency = config.GetWork().Currency
+
+	headers := []string{"Task", "Description"}
+	totColumns := []int{}
+	if showWorkHours {
+		headers = append(headers, "Work (hours)")
+		totColumns = append(totColumns, len(headers)-1)
+		if showCost {
+			headers = append(headers, "Cost")
+			totColumns = append(totColumns, len(headers)-1)
+		}
+	}
+	if !minimal {
+		headers = append(headers, "Effort (hours)")
+		totColumns = append(totColumns, len(headers)-1)
+	}
+
+	// One sheet for every client.
+	clientMap := tm.GetActivityClientMap()
+	for _, t := range res {
+		client := clientMap[strings.SplitN(t.Name, ".", 2)[0]]
+		sheet := wb.GetSheet(xlsx.GetSheetName(client))
+		if sheet == nil {
+			sheet = wb.AddSheet(client, headers...)
+		}
+
+		row := []xlsx.Cell{xlsx.String(t.Name), xlsx.String(t.Description)}
+		if showWorkHours {
+			var work int64
+			var cost float64
+			if rta, ok := rtaMap[t.Name]; ok && rta != nil {
+				work = rta.GetSeconds()
+				cost = rta.GetCost()
+			}
+			row = append(row, xlsx.Hours(work))
+			if showCost {
+				row = append(row, xlsx.Currency(cost))
+			}
+		}
+		if !minimal {
+			effort := int64(0)
+			if t.GetEffort() != "" {
+				var err error
+				effort, err = time.ParseDuration(t.GetEffort(), config.GetWork().WorkHours)
+				if err != nil {
+					return err
+				}
+			}
+			row = append(row, xlsx.Hours(effort))
+		}
+		sheet.AddRow(row...)
+	}
+
+	if len(wb.Sheets) == 0 {
+		wb.AddSheet("Tasks", headers...)
+	}
+
+	for _, sheet := range wb.Sheets {
+		sheet.AddTotalRow(fmt.Sprintf("Total (%d)", len(sheet.Rows)), totColumns...)
+	}
+
+	return wb.WriteFile(file)
+}
+
 func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	var users []string
 	var clients []string
@@ -54,6 +125,7 @@ func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 			jsonOutput, _ := cmd.Flags().GetBool("json")
 			jsonTask, _ := cmd.Flags().GetBool("json-task")
 			csvOutput, _ := cmd.Flags().GetBool("csv")
+			xlsxFile, _ := cmd.Flags().GetString("xlsx")
 
 			if onlyClosed && closed {
 				fmt.Println("Both option --closed and --only-closed not admitted.")
@@ -70,6 +142,11 @@ func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				os.Exit(1)
 			}
 
+			if xlsxFile != "" && (jsonOutput || csvOutput) {
+				fmt.Println("Option --xlsx not admitted with --csv or --json.")
+				os.Exit(1)
+			}
+
 			if jsonTask && !jsonOutput {
 				fmt.Println("Use --json-task only with --json")
 				os.Exit(1)
@@ -94,6 +171,7 @@ func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 			csvOutput, _ := cmd.Flags().GetBool("csv")
 			jsonOutput, _ := cmd.Flags().GetBool("json")
 			jsonTask, _ := cmd.Flags().GetBool("json-task")
+			xlsxFile, _ := cmd.Flags().GetString("xlsx")
 			minimal, _ := cmd.Flags().GetBool("minimal")
 			showWorkHours, _ := cmd.Flags().GetBool("show-work-hours")
 			showCost, _ := cmd.Flags().GetBool("show-cost")
@@ -156,7 +234,15 @@ func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				os.Exit(1)
 			}
 
-			if jsonOutput {
+			if xlsxFile != "" {
+
+				err := writeTasksXlsx(tm, config, res, rtaMap, showWorkHours, showCost, minimal, xlsxFile)
+				if err != nil {
+					fmt.Println("Error on write xlsx file: " + err.Error())
+					os.Exit(1)
+				}
+
+			} else if jsonOutput {
 
 				jsonData := []specs.TaskReport{}
 
@@ -386,6 +472,7 @@ func NewListCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	flags := cmd.Flags()
 	flags.Bool("csv", false, "Print output in CSV format")
 	flags.Bool("json", false, "Print output in JSON format")
+	flags.String("xlsx", "", "Write output to the specified XLSX file.")
 	flags.Bool("json-task", false, "Add also task object for every entry.")
 	flags.Bool("closed", false, "Include tasks of closed activities.")
 	flags.Bool("only-closed", false, "Show only tasks of closed activities.")
@@ -0,0 +1,103 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_task
+
+import (
+	"fmt"
+	"os"
+
+	loader "github.com/geaaru/time-master/pkg/loader"
+	refactor "github.com/geaaru/time-master/pkg/refactor"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	"github.com/spf13/cobra"
+)
+
+func NewRenameCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "rename <old-task-name> <new-task-name>",
+		Short: "Rename or move a task and update all references.",
+		Long: `Rename or move a task and update all references.
+
+The full name of the task is rewritten in the depends of the
+tasks, in the timesheets, in the scenarios and in the mapper files
+passed as argument.
+
+$> tm task rename ACT1.task1 ACT1.task2
+
+Move a task to another activity:
+
+$> tm task rename ACT1.task1 ACT2.task1 --dry-run
+`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				fmt.Println("Invalid arguments. Required old and new task name.")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			jiraMappers, _ := cmd.Flags().GetStringSlice("jira-mapper-file")
+			kimaiMappers, _ := cmd.Flags().GetStringSlice("kimai-mapper-file")
+
+			// Create Instance
+			tm := loader.NewTimeMasterInstance(config)
+
+			err := tm.Load()
+			if err != nil {
+				fmt.Println("Error on load data:" + err.Error() + "\n")
+				os.Exit(1)
+			}
+
+			r, err := refactor.NewTmRefactor(tm, append(jiraMappers, kimaiMappers...))
+			if err != nil {
+				fmt.Println("Error on read files: " + err.Error())
+				os.Exit(1)
+			}
+
+			err = r.RenameTask(args[0], args[1])
+			if err != nil {
+				fmt.Println("Error on rename task: " + err.Error())
+				os.Exit(1)
+			}
+
+			if dryRun {
+				fmt.Print(r.Diff())
+				return
+			}
+
+			err = r.ApplyAndValidate(config)
+			if err != nil {
+				fmt.Println("Error on apply changes: " + err.Error())
+				os.Exit(1)
+			}
+
+			fmt.Println(fmt.Sprintf("Task %s renamed to %s (%d files updated).",
+				args[0], args[1], len(r.GetChangedFiles())))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Bool("dry-run", false, "Show the diff of the changes without write the files.")
+	flags.StringSlice("jira-mapper-file", []string{}, "Jira mapper file to update.")
+	flags.StringSlice("kimai-mapper-file", []string{}, "Kimai mapper file to update.")
+
+	return cmd
+}
@@ -35,6 +35,9 @@ func newTimesheetCommand(config *specs.TimeMasterConfig) *cobra.Command {
 
 	cmd.AddCommand(
 		NewShowCommand(config),
+		NewStartCommand(config),
+		NewStopCommand(config),
+		NewStatusCommand(config),
 	)
 
 	return cmd
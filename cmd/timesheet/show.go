@@ -24,10 +24,12 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 
 	loader "github.com/geaaru/time-master/pkg/loader"
 	specs "github.com/geaaru/time-master/pkg/specs"
 	time "github.com/geaaru/time-master/pkg/time"
+	xlsx "github.com/geaaru/time-master/pkg/xlsx"
 
 	tablewriter "github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -308,6 +310,119 @@ func prepareNormalTable(dates *[]string, rtaMap *map[string]specs.ResourceTsAggr
 	return nil
 }
 
+func writeTimesheetsByUserXlsx(rtaList *[]specs.ResourceTsAggregated, file string) error {
+	users := []string{}
+	dates := []string{}
+	mapDates := make(map[string]bool, 0)
+	mapUsers := make(map[string]map[string]int64, 0)
+
+	for _, rta := range *rtaList {
+		if !mapDates[rta.Period.StartPeriod] {
+			mapDates[rta.Period.StartPeriod] = true
+			dates = append(dates, rta.Period.StartPeriod)
+		}
+		if _, ok := mapUsers[rta.User]; !ok {
+			mapUsers[rta.User] = make(map[string]int64, 0)
+			users = append(users, rta.User)
+		}
+		mapUsers[rta.User][rta.Period.StartPeriod] += rta.GetSeconds()
+	}
+
+	sort.Strings(users)
+	sort.Strings(dates)
+
+	wb := xlsx.NewWorkbook()
+	sheet := wb.AddSheet("Timesheets", append(append([]string{"User"}, dates...), "Total Effort")...)
+
+	totColumns := []int{}
+	for idx := range dates {
+		totColumns = append(totColumns, idx+1)
+	}
+	totColumns = append(totColumns, len(dates)+1)
+
+	for _, user := range users {
+		row := []xlsx.Cell{xlsx.String(user)}
+		tot := int64(0)
+		for _, date := range dates {
+			secs, ok := mapUsers[user][date]
+			if ok {
+				row = append(row, xlsx.Hours(secs))
+			} else {
+				row = append(row, xlsx.String(""))
+			}
+			tot += secs
+		}
+		row = append(row, xlsx.Hours(tot))
+		sheet.AddRow(row...)
+	}
+
+	sheet.AddTotalRow("Total", totColumns...)
+
+	return wb.WriteFile(file)
+}
+
+func writeTimesheetsXlsx(tm *loader.TimeMasterInstance, dates *[]string,
+	rtaMap *map[string]specs.ResourceTsAggregated,
+	researchOpts *specs.TimesheetResearch, file string) error {
+
+	sort.Strings(*dates)
+
+	headers := []string{}
+	if !researchOpts.IgnoreTime {
+		if researchOpts.Monthly {
+			headers = append(headers, "Month")
+		} else {
+			headers = append(headers, "Date")
+		}
+	}
+	if researchOpts.ByUser {
+		headers = append(headers, "User")
+	}
+	if researchOpts.ByTask {
+		headers = append(headers, "Task")
+	} else if researchOpts.ByActivity {
+		headers = append(headers, "Activity")
+	}
+	headers = append(headers, "Effort (hours)")
+
+	wb := xlsx.NewWorkbook()
+	clientMap := tm.GetActivityClientMap()
+
+	for _, d := range *dates {
+		rta := (*rtaMap)[d]
+
+		// One sheet for every client when the timesheets are
+		// aggregated by task or by activity.
+		name := "Timesheets"
+		if researchOpts.ByTask || researchOpts.ByActivity {
+			name = clientMap[strings.SplitN(rta.Task, ".", 2)[0]]
+		}
+		sheet := wb.GetSheet(xlsx.GetSheetName(name))
+		if sheet == nil {
+			sheet = wb.AddSheet(name, headers...)
+		}
+
+		row := []xlsx.Cell{}
+		if !researchOpts.IgnoreTime {
+			row = append(row, xlsx.DateString(rta.Period.StartPeriod))
+		}
+		if researchOpts.ByUser {
+			row = append(row, xlsx.String(rta.User))
+		}
+		if researchOpts.ByTask || researchOpts.ByActivity {
+			row = append(row, xlsx.String(rta.Task))
+		}
+		row = append(row, xlsx.Hours(rta.GetSeconds()))
+		sheet.AddRow(row...)
+	}
+
+	for _, sheet := range wb.Sheets {
+		sheet.AddTotalRow("Total", len(headers)-1)
+	}
+
+	return wb.WriteFile(file)
+}
+
 func NewShowCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	var tasks []string
 	var users []string
@@ -331,6 +446,13 @@ func NewShowCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				fmt.Println("Option --data-by-user usable only with --by-users option")
 				os.Exit(1)
 			}
+
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			xlsxFile, _ := cmd.Flags().GetString("xlsx")
+			if jsonOutput && xlsxFile != "" {
+				fmt.Println("Both option --json and --xlsx not admitted.")
+				os.Exit(1)
+			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 
@@ -344,6 +466,7 @@ func NewShowCommand(config *specs.TimeMasterConfig) *cobra.Command {
 			scenario, _ := cmd.Flags().GetString("scenario")
 			dataByUser, _ := cmd.Flags().GetBool("data-by-user")
 			jsonOutput, _ := cmd.Flags().GetBool("json")
+			xlsxFile, _ := cmd.Flags().GetString("xlsx")
 
 			// Create Instance
 			tm := loader.NewTimeMasterInstance(config)
@@ -383,7 +506,14 @@ func NewShowCommand(config *specs.TimeMasterConfig) *cobra.Command {
 				os.Exit(0)
 			}
 
-			if dataByUser {
+			if dataByUser && xlsxFile != "" {
+				err = writeTimesheetsByUserXlsx(rtaList, xlsxFile)
+				if err != nil {
+					fmt.Println("Error on write xlsx file: " + err.Error())
+					os.Exit(1)
+				}
+
+			} else if dataByUser {
 				err = prepareTableByUser(rtaList, jsonOutput)
 				if err != nil {
 					fmt.Println("Error: " + err.Error())
@@ -408,10 +538,18 @@ func NewShowCommand(config *specs.TimeMasterConfig) *cobra.Command {
 					rtaMap[key] = rta
 				}
 
-				err = prepareNormalTable(&dates, &rtaMap, &researchOpts, jsonOutput)
-				if err != nil {
-					fmt.Println("Error: " + err.Error())
-					os.Exit(1)
+				if xlsxFile != "" {
+					err = writeTimesheetsXlsx(tm, &dates, &rtaMap, &researchOpts, xlsxFile)
+					if err != nil {
+						fmt.Println("Error on write xlsx file: " + err.Error())
+						os.Exit(1)
+					}
+				} else {
+					err = prepareNormalTable(&dates, &rtaMap, &researchOpts, jsonOutput)
+					if err != nil {
+						fmt.Println("Error: " + err.Error())
+						os.Exit(1)
+					}
 				}
 			}
 		},
@@ -420,6 +558,7 @@ func NewShowCommand(config *specs.TimeMasterConfig) *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolP("monthly", "m", false, "Timesheets aggregated for month instead of day.")
 	flags.Bool("json", false, "Print output in JSON format.")
+	flags.String("xlsx", "", "Write output to the specified XLSX file.")
 	flags.Bool("by-tasks", false, "Timesheets aggregated for tasks.")
 	flags.Bool("by-users", false, "Timesheets aggregated for users.")
 	flags.Bool("data-by-user", false, "Show data per user horizontally.")
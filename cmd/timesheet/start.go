@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_timesheet
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	loader "github.com/geaaru/time-master/pkg/loader"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tracker "github.com/geaaru/time-master/pkg/tracker"
+
+	"github.com/spf13/cobra"
+)
+
+func getTrackerUser(cmd *cobra.Command) string {
+	user, _ := cmd.Flags().GetString("user")
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	return user
+}
+
+func printTrackerTimesheets(rts []specs.ResourceTimesheet) {
+	for _, rt := range rts {
+		fmt.Println(fmt.Sprintf("Stopped task %s for user %s: %s of %s",
+			rt.Task, rt.User, rt.Duration, rt.Period.StartPeriod))
+	}
+}
+
+func NewStartCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "start [task]",
+		Short: "Start the timer of a task.",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				fmt.Println("Missing task")
+				os.Exit(1)
+			}
+
+			if getTrackerUser(cmd) == "" {
+				fmt.Println("Missing user")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			user := getTrackerUser(cmd)
+			note, _ := cmd.Flags().GetString("note")
+			skipCheck, _ := cmd.Flags().GetBool("skip-check")
+			task := args[0]
+
+			if !skipCheck {
+				// Create Instance
+				tm := loader.NewTimeMasterInstance(config)
+
+				err := tm.Load()
+				if err != nil {
+					fmt.Println("Error on load data:" + err.Error() + "\n")
+					os.Exit(1)
+				}
+
+				if _, ok := tm.GetAllTaskMap()[task]; !ok {
+					fmt.Println("No task " + task + " found.")
+					os.Exit(1)
+				}
+			}
+
+			t := tracker.NewTmTracker(config)
+			rts, err := t.Start(user, task, note, time.Now())
+			if err != nil {
+				fmt.Println("Error on start timer: " + err.Error())
+				os.Exit(1)
+			}
+
+			printTrackerTimesheets(rts)
+			fmt.Println(fmt.Sprintf("Started task %s for user %s.", task, user))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("user", "u", "", "User of the timer. Default is $USER.")
+	flags.StringP("note", "n", "", "Note to add to the timesheet.")
+	flags.Bool("skip-check", false, "Skip the check of the task existence.")
+
+	return cmd
+}
@@ -0,0 +1,83 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_timesheet
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+	tracker "github.com/geaaru/time-master/pkg/tracker"
+
+	"github.com/spf13/cobra"
+)
+
+func NewStatusCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the running timer.",
+		Args:  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if getTrackerUser(cmd) == "" {
+				fmt.Println("Missing user")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			user := getTrackerUser(cmd)
+
+			t := tracker.NewTmTracker(config)
+			timer, err := t.Status(user)
+			if err != nil {
+				fmt.Println("Error on read tracker state: " + err.Error())
+				os.Exit(1)
+			}
+
+			if timer == nil {
+				fmt.Println("No timer running for user " + user + ".")
+				os.Exit(0)
+			}
+
+			start, err := timer.GetStartTime()
+			if err != nil {
+				fmt.Println("Invalid start time: " + err.Error())
+				os.Exit(1)
+			}
+
+			elapsed := "0s"
+			if secs := int64(time.Since(start).Seconds()); secs > 0 {
+				elapsed, _ = tmtime.Seconds2Duration(secs)
+			}
+
+			fmt.Println(fmt.Sprintf("Task %s running since %s (%s).",
+				timer.Task, timer.Start, elapsed))
+			if timer.Note != "" {
+				fmt.Println("Note: " + timer.Note)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("user", "u", "", "User of the timer. Default is $USER.")
+
+	return cmd
+}
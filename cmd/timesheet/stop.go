@@ -0,0 +1,69 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd_timesheet
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tracker "github.com/geaaru/time-master/pkg/tracker"
+
+	"github.com/spf13/cobra"
+)
+
+func NewStopCommand(config *specs.TimeMasterConfig) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running timer and write the timesheet.",
+		Args:  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if getTrackerUser(cmd) == "" {
+				fmt.Println("Missing user")
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			user := getTrackerUser(cmd)
+			note, _ := cmd.Flags().GetString("note")
+
+			t := tracker.NewTmTracker(config)
+			rts, err := t.Stop(user, note, time.Now())
+			if err != nil {
+				fmt.Println("Error on stop timer: " + err.Error())
+				os.Exit(1)
+			}
+
+			if len(rts) == 0 {
+				fmt.Println("Timer stopped without elapsed time.")
+			} else {
+				printTrackerTimesheets(rts)
+				fmt.Println("Timesheets written on " + t.GetTimesheetsFile(user))
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("user", "u", "", "User of the timer. Default is $USER.")
+	flags.StringP("note", "n", "", "Note to add to the timesheet.")
+
+	return cmd
+}
@@ -0,0 +1,282 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package burndown
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	gotime "time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	time "github.com/geaaru/time-master/pkg/time"
+)
+
+const (
+	BURNDOWN_DAILY  = "daily"
+	BURNDOWN_WEEKLY = "weekly"
+)
+
+// BurndownPoint is the status of the activity at the end of a day.
+// Worked and Remaining are available until the now date, Projected
+// from the now date when a prevision is available.
+type BurndownPoint struct {
+	Date      string `json:"date"`
+	Total     int64  `json:"total_sec"`
+	Worked    *int64 `json:"worked_sec,omitempty"`
+	Remaining *int64 `json:"remaining_sec,omitempty"`
+	Ideal     int64  `json:"ideal_sec"`
+	Projected *int64 `json:"projected_sec,omitempty"`
+}
+
+type Burndown struct {
+	Activity string `json:"activity"`
+	Period   string `json:"period"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Now      string `json:"now"`
+
+	TotalEffort     int64 `json:"total_effort_sec"`
+	WorkedEffort    int64 `json:"worked_effort_sec"`
+	RemainingEffort int64 `json:"remaining_effort_sec"`
+	// Date when the projected remaining effort is zero.
+	ProjectedEnd string `json:"projected_end,omitempty"`
+
+	Points []BurndownPoint `json:"points"`
+}
+
+// TmBurndown computes the burndown series of an activity from the
+// effort, the work done and the work planned by a prevision.
+type TmBurndown struct {
+	Config *specs.TimeMasterConfig
+
+	Period string
+	// Now date in the format YYYY-MM-DD. The work done after now is
+	// ignored and the work planned before now is ignored.
+	Now  string
+	From string
+	To   string
+
+	worked  map[string]int64
+	planned map[string]int64
+}
+
+func NewTmBurndown(config *specs.TimeMasterConfig) *TmBurndown {
+	return &TmBurndown{
+		Config:  config,
+		Period:  BURNDOWN_DAILY,
+		worked:  make(map[string]int64, 0),
+		planned: make(map[string]int64, 0),
+	}
+}
+
+// AddWorked adds the seconds worked on the date (YYYY-MM-DD).
+func (b *TmBurndown) AddWorked(date string, secs int64) {
+	b.worked[date[0:10]] += secs
+}
+
+// AddPlanned adds the seconds planned on the date (YYYY-MM-DD).
+func (b *TmBurndown) AddPlanned(date string, secs int64) {
+	b.planned[date[0:10]] += secs
+}
+
+// SetPrevision adds the work planned for the activity from the
+// timesheets of the prevision.
+func (b *TmBurndown) SetPrevision(activity string, s *specs.ScenarioSchedule) error {
+	workHours := b.Config.GetWork().WorkHours
+	if workHours == 0 {
+		workHours = 8
+	}
+
+	for _, ts := range s.Schedule {
+		if ts.Task == nil || (ts.Name != activity && !strings.HasPrefix(ts.Name, activity+".")) {
+			continue
+		}
+		for _, rt := range ts.Timesheets {
+			if rt.Period == nil || len(rt.Period.StartPeriod) < 10 {
+				continue
+			}
+			secs, err := time.ParseDuration(rt.Duration, workHours)
+			if err != nil {
+				return errors.New(fmt.Sprintf("Invalid duration %s of the task %s: %s",
+					rt.Duration, ts.Name, err.Error()))
+			}
+			b.AddPlanned(rt.Period.StartPeriod, secs)
+		}
+	}
+
+	if b.Now == "" && s.Scenario != nil && len(s.Scenario.NowTime) >= 10 {
+		b.Now = s.Scenario.NowTime[0:10]
+	}
+
+	return nil
+}
+
+func getKeys(m map[string]int64) []string {
+	ans := []string{}
+	for k := range m {
+		ans = append(ans, k)
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+// Return the range of the dates. The default range starts from the
+// first day of work and ends with the last day of work or planned work.
+func (b *TmBurndown) getRange(now string) (string, string) {
+	start := b.From
+	end := b.To
+
+	dates := append(getKeys(b.worked), getKeys(b.planned)...)
+	dates = append(dates, now)
+	sort.Strings(dates)
+
+	if start == "" {
+		start = dates[0]
+	}
+	if end == "" {
+		end = dates[len(dates)-1]
+	}
+	return start, end
+}
+
+// Build returns the burndown of the activity with the total effort.
+func (b *TmBurndown) Build(activity string, effort int64) (*Burndown, error) {
+	if b.Period != BURNDOWN_DAILY && b.Period != BURNDOWN_WEEKLY {
+		return nil, errors.New("Invalid period " + b.Period)
+	}
+
+	now := b.Now
+	if now == "" {
+		now = gotime.Now().Format("2006-01-02")
+	}
+	if _, err := time.ParseTimestamp(now, true); err != nil {
+		return nil, errors.New("Invalid now date " + now + ": " + err.Error())
+	}
+
+	startDate, endDate := b.getRange(now)
+	start, err := time.ParseTimestamp(startDate, true)
+	if err != nil {
+		return nil, errors.New("Invalid from date " + startDate + ": " + err.Error())
+	}
+	end, err := time.ParseTimestamp(endDate, true)
+	if err != nil {
+		return nil, errors.New("Invalid to date " + endDate + ": " + err.Error())
+	}
+	if end.Before(start) {
+		return nil, errors.New("The from date is after the to date")
+	}
+
+	ans := &Burndown{
+		Activity:    activity,
+		Period:      b.Period,
+		Start:       startDate,
+		End:         endDate,
+		Now:         now,
+		TotalEffort: effort,
+		Points:      []BurndownPoint{},
+	}
+
+	// The work done before the start reduces the remaining effort.
+	var worked, planned int64
+	for d, secs := range b.worked {
+		if d < startDate && d <= now {
+			worked += secs
+		}
+	}
+	for d, secs := range b.worked {
+		if d <= now {
+			ans.WorkedEffort += secs
+		}
+	}
+	ans.RemainingEffort = remaining(effort, ans.WorkedEffort)
+	initial := remaining(effort, worked)
+	hasPrevision := len(b.planned) > 0
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	for idx := 0; idx < days; idx++ {
+		day := start.AddDate(0, 0, idx)
+		d := day.Format("2006-01-02")
+
+		if d <= now {
+			worked += b.worked[d]
+		} else {
+			planned += b.planned[d]
+		}
+
+		p := BurndownPoint{Date: d, Total: effort, Ideal: initial}
+		if days > 1 {
+			p.Ideal = initial - initial*int64(idx)/int64(days-1)
+		}
+		if d <= now {
+			w := worked
+			r := remaining(effort, worked)
+			p.Worked = &w
+			p.Remaining = &r
+		}
+		if hasPrevision && d >= now {
+			pr := remaining(effort, ans.WorkedEffort+planned)
+			p.Projected = &pr
+			if pr == 0 && ans.ProjectedEnd == "" {
+				ans.ProjectedEnd = d
+			}
+		}
+
+		// The weekly series contains the first day, the sundays,
+		// the now date and the last day.
+		if b.Period == BURNDOWN_WEEKLY && idx > 0 && idx < days-1 &&
+			day.Weekday() != gotime.Sunday && d != now {
+			continue
+		}
+		ans.Points = append(ans.Points, p)
+	}
+
+	return ans, nil
+}
+
+func remaining(effort, worked int64) int64 {
+	if worked > effort {
+		return 0
+	}
+	return effort - worked
+}
+
+// Sparkline returns the values as a line of unicode blocks scaled
+// between zero and max. The negative values are blanks.
+func Sparkline(values []int64, max int64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	ans := []rune{}
+	for _, v := range values {
+		if v < 0 {
+			ans = append(ans, ' ')
+			continue
+		}
+		idx := 0
+		if max > 0 && v > 0 {
+			idx = int(v * int64(len(blocks)-1) / max)
+			if idx >= len(blocks) {
+				idx = len(blocks) - 1
+			}
+		}
+		ans = append(ans, blocks[idx])
+	}
+	return string(ans)
+}
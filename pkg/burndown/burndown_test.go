@@ -0,0 +1,158 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package burndown_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/burndown"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Burndown Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	hour := int64(3600)
+
+	Context("Build", func() {
+
+		It("Daily", func() {
+			bd := NewTmBurndown(config)
+			bd.Now = "2026-09-03"
+			bd.AddWorked("2026-09-01", 4*hour)
+			bd.AddWorked("2026-09-02", 8*hour)
+			bd.AddWorked("2026-09-03", 4*hour)
+
+			b, err := bd.Build("ACT1", 40*hour)
+			Expect(err).Should(BeNil())
+			Expect(b.Start).To(Equal("2026-09-01"))
+			Expect(b.End).To(Equal("2026-09-03"))
+			Expect(b.WorkedEffort).To(Equal(16 * hour))
+			Expect(b.RemainingEffort).To(Equal(24 * hour))
+			Expect(len(b.Points)).To(Equal(3))
+
+			Expect(*b.Points[0].Worked).To(Equal(4 * hour))
+			Expect(*b.Points[1].Remaining).To(Equal(28 * hour))
+			Expect(b.Points[0].Ideal).To(Equal(40 * hour))
+			Expect(b.Points[1].Ideal).To(Equal(20 * hour))
+			Expect(b.Points[2].Ideal).To(Equal(int64(0)))
+			Expect(b.Points[2].Projected).To(BeNil())
+		})
+
+		It("Work before the start", func() {
+			bd := NewTmBurndown(config)
+			bd.Now = "2026-09-10"
+			bd.From = "2026-09-08"
+			bd.AddWorked("2026-09-01", 10*hour)
+			bd.AddWorked("2026-09-09", 2*hour)
+			// Work after now is ignored.
+			bd.AddWorked("2026-09-11", 2*hour)
+
+			b, err := bd.Build("ACT1", 20*hour)
+			Expect(err).Should(BeNil())
+			Expect(b.End).To(Equal("2026-09-11"))
+			Expect(b.WorkedEffort).To(Equal(12 * hour))
+			Expect(b.Points[0].Ideal).To(Equal(10 * hour))
+			Expect(*b.Points[0].Remaining).To(Equal(10 * hour))
+			Expect(*b.Points[1].Remaining).To(Equal(8 * hour))
+			Expect(b.Points[3].Worked).To(BeNil())
+		})
+
+		It("Prevision and weekly", func() {
+			bd := NewTmBurndown(config)
+			bd.Period = BURNDOWN_WEEKLY
+			bd.AddWorked("2026-09-01", 8*hour)
+
+			err := bd.SetPrevision("ACT1", &specs.ScenarioSchedule{
+				Scenario: &specs.Scenario{Name: "s1", NowTime: "2026-09-02"},
+				Schedule: []specs.TaskScheduled{
+					{
+						Task: &specs.Task{Name: "ACT1.dev"},
+						Timesheets: []specs.ResourceTimesheet{
+							// Planned before now is ignored.
+							*specs.NewResourceTimesheet("geaaru", "2026-09-01", "ACT1.dev", "8h"),
+							*specs.NewResourceTimesheet("geaaru", "2026-09-04", "ACT1.dev", "8h"),
+							*specs.NewResourceTimesheet("geaaru", "2026-09-14", "ACT1.dev", "8h"),
+						},
+					},
+					{
+						Task:       &specs.Task{Name: "ACT10.dev"},
+						Timesheets: []specs.ResourceTimesheet{*specs.NewResourceTimesheet("geaaru", "2026-09-20", "ACT10.dev", "8h")},
+					},
+				},
+			})
+			Expect(err).Should(BeNil())
+
+			b, err := bd.Build("ACT1", 24*hour)
+			Expect(err).Should(BeNil())
+			Expect(b.Now).To(Equal("2026-09-02"))
+			Expect(b.End).To(Equal("2026-09-14"))
+			Expect(b.ProjectedEnd).To(Equal("2026-09-14"))
+
+			dates := []string{}
+			for _, p := range b.Points {
+				dates = append(dates, p.Date)
+			}
+			Expect(dates).To(Equal([]string{
+				"2026-09-01", "2026-09-02", "2026-09-06", "2026-09-13", "2026-09-14",
+			}))
+
+			Expect(b.Points[0].Projected).To(BeNil())
+			Expect(*b.Points[1].Projected).To(Equal(16 * hour))
+			Expect(*b.Points[1].Remaining).To(Equal(16 * hour))
+			Expect(*b.Points[2].Projected).To(Equal(8 * hour))
+			Expect(b.Points[2].Remaining).To(BeNil())
+			Expect(*b.Points[4].Projected).To(Equal(int64(0)))
+		})
+
+		It("Invalid range", func() {
+			bd := NewTmBurndown(config)
+			bd.From = "2026-09-10"
+			bd.To = "2026-09-01"
+			_, err := bd.Build("ACT1", hour)
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	Context("Output", func() {
+
+		It("Sparkline", func() {
+			Expect(Sparkline([]int64{0, 4, 8, -1, 10}, 8)).To(Equal("▁▄█ █"))
+		})
+
+		It("Svg", func() {
+			bd := NewTmBurndown(config)
+			bd.Now = "2026-09-02"
+			bd.AddWorked("2026-09-01", 8*hour)
+			bd.AddPlanned("2026-09-03", 8*hour)
+
+			b, err := bd.Build("ACT1", 16*hour)
+			Expect(err).Should(BeNil())
+
+			data, err := b.Svg()
+			Expect(err).Should(BeNil())
+			Expect(string(data)).To(ContainSubstring("<title>Burndown ACT1</title>"))
+			Expect(string(data)).To(ContainSubstring(`class="line remaining"`))
+			Expect(string(data)).To(ContainSubstring(`class="line projected"`))
+			Expect(string(data)).To(ContainSubstring(`class="now"`))
+		})
+	})
+})
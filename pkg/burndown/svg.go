@@ -0,0 +1,194 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package burndown
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	time "github.com/geaaru/time-master/pkg/time"
+)
+
+const (
+	svgWidth      = 900
+	svgHeight     = 420
+	svgMarginLeft = 70
+	svgMarginTop  = 40
+	svgPlotWidth  = 800
+	svgPlotHeight = 300
+	svgYTicks     = 5
+)
+
+func svgEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// Svg returns the chart of the burndown in SVG format with the lines
+// of the total effort, the worked effort (burnup), the remaining
+// effort, the ideal line and the projection.
+func (b *Burndown) Svg() ([]byte, error) {
+	var buf bytes.Buffer
+	printf := func(format string, args ...interface{}) {
+		buf.WriteString(fmt.Sprintf(format, args...))
+	}
+
+	start, err := time.ParseTimestamp(b.Start, true)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.ParseTimestamp(b.End, true)
+	if err != nil {
+		return nil, err
+	}
+	days := end.Sub(start).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+
+	maxValue := b.TotalEffort
+	for _, p := range b.Points {
+		if p.Worked != nil && *p.Worked > maxValue {
+			maxValue = *p.Worked
+		}
+	}
+	// Scale in hours rounded up to a multiple of the ticks.
+	maxHours := (maxValue/3600/svgYTicks + 1) * svgYTicks
+
+	getX := func(date string) (float64, error) {
+		t, err := time.ParseTimestamp(date, true)
+		if err != nil {
+			return 0, err
+		}
+		return svgMarginLeft + t.Sub(start).Hours()/24/days*svgPlotWidth, nil
+	}
+	getY := func(secs int64) float64 {
+		return svgMarginTop + svgPlotHeight - float64(secs)/3600/float64(maxHours)*svgPlotHeight
+	}
+
+	printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		svgWidth, svgHeight, svgWidth, svgHeight)
+	printf("<title>%s</title>\n", svgEscape("Burndown "+b.Activity))
+	buf.WriteString(`<style>
+text { font-family: sans-serif; font-size: 11px; fill: #333; }
+.title { font-size: 14px; font-weight: bold; }
+.grid { stroke: #e0e0e0; stroke-width: 1; }
+.axis { stroke: #999; stroke-width: 1; }
+.line { fill: none; stroke-width: 2; }
+.total { stroke: #9e9e9e; stroke-dasharray: 6 3; }
+.worked { stroke: #43a047; }
+.remaining { stroke: #1e88e5; }
+.ideal { stroke: #bdbdbd; stroke-dasharray: 2 3; }
+.projected { stroke: #1e88e5; stroke-dasharray: 6 3; }
+.now { stroke: #d32f2f; stroke-width: 1.5; stroke-dasharray: 4 2; }
+.now-label { fill: #d32f2f; font-size: 10px; }
+</style>
+<rect x="0" y="0" width="100%" height="100%" fill="#fff"/>
+`)
+	printf(`<text x="%d" y="22" class="title">%s</text>`+"\n", svgMarginLeft,
+		svgEscape(fmt.Sprintf("Burndown %s (%s - %s)", b.Activity, b.Start, b.End)))
+
+	// Grid and labels of the hours.
+	for i := 0; i <= svgYTicks; i++ {
+		hours := maxHours * int64(i) / svgYTicks
+		y := getY(hours * 3600)
+		printf(`<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="grid"/>`+"\n",
+			svgMarginLeft, y, svgMarginLeft+svgPlotWidth, y)
+		printf(`<text x="%d" y="%.1f" text-anchor="end">%dh</text>`+"\n",
+			svgMarginLeft-6, y+4, hours)
+	}
+
+	// Labels of the dates. At most 10 labels.
+	step := (len(b.Points) + 9) / 10
+	if step < 1 {
+		step = 1
+	}
+	for idx, p := range b.Points {
+		if idx%step != 0 && idx != len(b.Points)-1 {
+			continue
+		}
+		x, err := getX(p.Date)
+		if err != nil {
+			return nil, err
+		}
+		printf(`<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n",
+			x, svgMarginTop+svgPlotHeight+18, p.Date[5:10])
+	}
+
+	printf(`<line x1="%d" y1="%d" x2="%d" y2="%d" class="axis"/>`+"\n",
+		svgMarginLeft, svgMarginTop, svgMarginLeft, svgMarginTop+svgPlotHeight)
+	printf(`<line x1="%d" y1="%d" x2="%d" y2="%d" class="axis"/>`+"\n",
+		svgMarginLeft, svgMarginTop+svgPlotHeight, svgMarginLeft+svgPlotWidth, svgMarginTop+svgPlotHeight)
+
+	series := []struct {
+		Class string
+		Label string
+		Value func(p *BurndownPoint) *int64
+	}{
+		{"total", "Total effort", func(p *BurndownPoint) *int64 { return &p.Total }},
+		{"ideal", "Ideal", func(p *BurndownPoint) *int64 { return &p.Ideal }},
+		{"worked", "Worked", func(p *BurndownPoint) *int64 { return p.Worked }},
+		{"remaining", "Remaining", func(p *BurndownPoint) *int64 { return p.Remaining }},
+		{"projected", "Projection", func(p *BurndownPoint) *int64 { return p.Projected }},
+	}
+
+	legendX := svgMarginLeft
+	for _, s := range series {
+		points := []string{}
+		for idx := range b.Points {
+			v := s.Value(&b.Points[idx])
+			if v == nil {
+				continue
+			}
+			x, err := getX(b.Points[idx].Date)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, getY(*v)))
+		}
+		if len(points) == 0 {
+			continue
+		}
+		printf(`<polyline points="%s" class="line %s"/>`+"\n", strings.Join(points, " "), s.Class)
+
+		y := svgMarginTop + svgPlotHeight + 44
+		printf(`<line x1="%d" y1="%d" x2="%d" y2="%d" class="line %s"/>`+"\n",
+			legendX, y-4, legendX+24, y-4, s.Class)
+		printf(`<text x="%d" y="%d">%s</text>`+"\n", legendX+30, y, s.Label)
+		legendX += 130
+	}
+
+	if b.Now >= b.Start && b.Now <= b.End {
+		x, err := getX(b.Now)
+		if err != nil {
+			return nil, err
+		}
+		printf(`<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" class="now"/>`+"\n",
+			x, svgMarginTop, x, svgMarginTop+svgPlotHeight)
+		printf(`<text x="%.1f" y="%d" class="now-label">now</text>`+"\n", x+3, svgMarginTop+10)
+	}
+
+	buf.WriteString("</svg>\n")
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package exporter_test
+
+import (
+	"testing"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestExporter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Exporter Suite")
+}
+
+// The clients used by the tests of the exporters.
+func newClients() []specs.Client {
+	return []specs.Client{
+		{
+			Name:        "ACME Inc.",
+			Description: `The "ACME" company`,
+			Activities: []specs.Activity{
+				{
+					Name:        "ACT1",
+					Description: "Project 1",
+					Tasks: []specs.Task{
+						{
+							Name:              "analysis",
+							Description:       "Analysis",
+							Effort:            "2d",
+							AllocatedResource: []string{"d.rondina"},
+							Tasks: []specs.Task{
+								{Name: "1st-draft", Effort: "3h"},
+							},
+						},
+						{
+							Name:              "dev",
+							Effort:            "1d",
+							AllocatedResource: []string{"d.rondina", "mario"},
+							Depends:           []string{"ACT1.analysis", "ACT1.missing"},
+						},
+						{
+							Name:      "release",
+							Milestone: "2026-10-30",
+							Depends:   []string{"ACT1.dev"},
+						},
+						{
+							Name:              "support",
+							Effort:            "1h",
+							Completed:         true,
+							AllocatedResource: []string{"d.rondina"},
+						},
+					},
+				},
+				{
+					Name:     "OLD",
+					Disabled: true,
+					Tasks:    []specs.Task{{Name: "x", Effort: "1d"}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,410 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package exporter
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ical "github.com/geaaru/time-master/pkg/ical"
+	log "github.com/geaaru/time-master/pkg/logger"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+)
+
+// TmIcsExporter creates the iCalendar files with the planned work
+// of the resources of a prevision.
+type TmIcsExporter struct {
+	Logger *log.TmLogger
+	Config *specs.TimeMasterConfig
+
+	// Export only the timesheets from the now date of the scenario.
+	OnlyPlanned bool
+
+	resources  map[string]*specs.Resource
+	activities map[string]*specs.Activity
+	clients    map[string]string
+}
+
+// ResourceAssignment contains the consecutive work days of a
+// resource on a task.
+type ResourceAssignment struct {
+	User    string
+	Task    string
+	Start   string
+	End     string
+	Seconds int64
+	Days    int
+	// Position of the assignment between the assignments of the
+	// resource on the task (from 1).
+	Ordinal int
+}
+
+// ResourceCalendar is the calendar with the events of a resource.
+type ResourceCalendar struct {
+	User     string
+	Calendar *ical.Calendar
+}
+
+func NewTmIcsExporter(config *specs.TimeMasterConfig) *TmIcsExporter {
+	return &TmIcsExporter{
+		Logger:     log.NewTmLogger(config),
+		Config:     config,
+		resources:  make(map[string]*specs.Resource, 0),
+		activities: make(map[string]*specs.Activity, 0),
+		clients:    make(map[string]string, 0),
+	}
+}
+
+// SetResources sets the resources used for the names and the
+// emails of the attendees of the events.
+func (e *TmIcsExporter) SetResources(resources []specs.Resource) {
+	for idx := range resources {
+		e.resources[resources[idx].User] = &resources[idx]
+	}
+}
+
+// SetClients sets the clients and the activities used for the
+// descriptions when the prevision is without client data.
+func (e *TmIcsExporter) SetClients(clients []specs.Client) {
+	for cidx := range clients {
+		for aidx := range clients[cidx].Activities {
+			a := &clients[cidx].Activities[aidx]
+			e.activities[a.Name] = a
+			e.clients[a.Name] = clients[cidx].Name
+		}
+	}
+}
+
+func (e *TmIcsExporter) getResourceName(user string) string {
+	if r, ok := e.resources[user]; ok && r.Name != "" {
+		return r.Name
+	}
+	return user
+}
+
+// GetAssignments returns the assignments of the resources of the
+// prevision sorted by user, start date and task. The consecutive work
+// days of a resource on the same task are merged.
+func (e *TmIcsExporter) GetAssignments(s *specs.ScenarioSchedule) ([]ResourceAssignment, error) {
+	ans := []ResourceAssignment{}
+
+	if e.Config.GetWork().WorkHours <= 0 {
+		return nil, errors.New("Invalid work hours")
+	}
+
+	now := ""
+	if e.OnlyPlanned && s.Scenario != nil && len(s.Scenario.NowTime) >= 10 {
+		now = s.Scenario.NowTime[0:10]
+	}
+
+	// Aggregate the timesheets by user, task and day.
+	days := []ResourceAssignment{}
+	mDays := make(map[string]int, 0)
+	for _, ts := range s.Schedule {
+		for _, rt := range ts.Timesheets {
+			if rt.Period == nil || rt.Period.StartPeriod < now {
+				continue
+			}
+
+			secs, err := tmtime.ParseDuration(rt.Duration, e.Config.GetWork().WorkHours)
+			if err != nil {
+				return ans, err
+			}
+
+			date := rt.Period.StartPeriod
+			if len(date) > 10 {
+				date = date[0:10]
+			}
+
+			key := fmt.Sprintf("%s|%s|%s", rt.User, rt.Task, date)
+			if idx, ok := mDays[key]; ok {
+				days[idx].Seconds += secs
+				continue
+			}
+
+			days = append(days, ResourceAssignment{
+				User:    rt.User,
+				Task:    rt.Task,
+				Start:   date,
+				End:     date,
+				Seconds: secs,
+				Days:    1,
+			})
+			mDays[key] = len(days) - 1
+		}
+	}
+
+	sort.SliceStable(days, func(i, j int) bool {
+		if days[i].User != days[j].User {
+			return days[i].User < days[j].User
+		}
+		if days[i].Task != days[j].Task {
+			return days[i].Task < days[j].Task
+		}
+		return days[i].Start < days[j].Start
+	})
+
+	for _, d := range days {
+		if len(ans) > 0 {
+			last := &ans[len(ans)-1]
+			if last.User == d.User && last.Task == d.Task {
+				next, err := tmtime.GetNextWorkDay(last.End)
+				if err != nil {
+					return ans, err
+				}
+				if d.Start <= next {
+					last.End = d.End
+					last.Seconds += d.Seconds
+					last.Days++
+					continue
+				}
+			}
+		}
+		ans = append(ans, d)
+	}
+
+	ordinals := make(map[string]int, 0)
+	for idx := range ans {
+		key := ans[idx].User + "|" + ans[idx].Task
+		ordinals[key]++
+		ans[idx].Ordinal = ordinals[key]
+	}
+
+	sort.SliceStable(ans, func(i, j int) bool {
+		if ans[i].User != ans[j].User {
+			return ans[i].User < ans[j].User
+		}
+		if ans[i].Start != ans[j].Start {
+			return ans[i].Start < ans[j].Start
+		}
+		return ans[i].Task < ans[j].Task
+	})
+
+	return ans, nil
+}
+
+// GetUid returns the id of the event of the assignment. The id is
+// based on the position of the assignment in the task so a
+// rescheduled assignment updates the existing event of the calendar.
+func GetUid(scenario string, a *ResourceAssignment) string {
+	h := sha1.Sum([]byte(strings.Join([]string{
+		scenario, a.User, a.Task, fmt.Sprintf("%d", a.Ordinal)}, "|")))
+	return hex.EncodeToString(h[:])[0:20] + "@time-master"
+}
+
+// BuildCalendars returns a calendar for every resource with
+// assignments.
+func (e *TmIcsExporter) BuildCalendars(s *specs.ScenarioSchedule) ([]ResourceCalendar, error) {
+	ans := []ResourceCalendar{}
+
+	events, users, err := e.buildEvents(s, false)
+	if err != nil {
+		return ans, err
+	}
+
+	for _, u := range users {
+		ans = append(ans, ResourceCalendar{
+			User: u,
+			Calendar: &ical.Calendar{
+				Name:   fmt.Sprintf("%s - %s", e.getCalendarName(s), e.getResourceName(u)),
+				Events: events[u],
+			},
+		})
+	}
+
+	return ans, nil
+}
+
+// BuildCalendar returns a single calendar with the assignments of
+// all the resources. The name of the resource is the prefix of the
+// summary of the events.
+func (e *TmIcsExporter) BuildCalendar(s *specs.ScenarioSchedule) (*ical.Calendar, error) {
+	ans := &ical.Calendar{
+		Name:   e.getCalendarName(s),
+		Events: []*ical.Event{},
+	}
+
+	events, users, err := e.buildEvents(s, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		ans.Events = append(ans.Events, events[u]...)
+	}
+
+	return ans, nil
+}
+
+func (e *TmIcsExporter) getCalendarName(s *specs.ScenarioSchedule) string {
+	if s.Scenario != nil && s.Scenario.Name != "" {
+		return "Time Master - " + s.Scenario.Name
+	}
+	return "Time Master"
+}
+
+func (e *TmIcsExporter) buildEvents(s *specs.ScenarioSchedule, withResource bool) (map[string][]*ical.Event, []string, error) {
+	ans := make(map[string][]*ical.Event, 0)
+	users := []string{}
+
+	assignments, err := e.GetAssignments(s)
+	if err != nil {
+		return ans, users, err
+	}
+
+	scenario := ""
+	stamp := time.Now()
+	if s.Scenario != nil {
+		scenario = s.Scenario.Name
+		// Use the now of the scenario to avoid changes of the
+		// files between the builds of the same data.
+		if s.Scenario.NowTime != "" {
+			if t, err := tmtime.ParseTimestamp(s.Scenario.NowTime, true); err == nil {
+				stamp = t
+			}
+		}
+	}
+
+	tasks := make(map[string]*specs.TaskScheduled, 0)
+	for idx := range s.Schedule {
+		tasks[s.Schedule[idx].Name] = &s.Schedule[idx]
+	}
+
+	for idx := range assignments {
+		a := &assignments[idx]
+
+		start, err := tmtime.ParseTimestamp(a.Start, true)
+		if err != nil {
+			return ans, users, err
+		}
+		end, err := tmtime.ParseTimestamp(a.End, true)
+		if err != nil {
+			return ans, users, err
+		}
+
+		summary, descr, categories, err := e.getEventTexts(a, tasks[a.Task], scenario)
+		if err != nil {
+			return ans, users, err
+		}
+		if withResource {
+			summary = e.getResourceName(a.User) + ": " + summary
+		}
+
+		event := &ical.Event{
+			Uid:         GetUid(scenario, a),
+			Summary:     summary,
+			Description: descr,
+			Categories:  categories,
+			Start:       start,
+			// The end date of the all-day events is exclusive.
+			End:       end.AddDate(0, 0, 1),
+			AllDay:    true,
+			Attendees: []ical.Attendee{},
+			Stamp:     stamp,
+		}
+
+		if r, ok := e.resources[a.User]; ok && len(r.Email) > 0 {
+			event.Attendees = append(event.Attendees, ical.Attendee{
+				Name:  e.getResourceName(a.User),
+				Email: r.Email[0],
+			})
+		}
+
+		if _, ok := ans[a.User]; !ok {
+			users = append(users, a.User)
+		}
+		ans[a.User] = append(ans[a.User], event)
+	}
+
+	return ans, users, nil
+}
+
+// Return the summary, the description and the categories of the
+// event of the assignment.
+func (e *TmIcsExporter) getEventTexts(a *ResourceAssignment, ts *specs.TaskScheduled, scenario string) (string, string, []string, error) {
+	activity := strings.Split(a.Task, ".")[0]
+	activityDescr := ""
+	client := e.clients[activity]
+	taskDescr := ""
+
+	if act, ok := e.activities[activity]; ok {
+		activityDescr = act.Description
+	}
+
+	if ts != nil {
+		taskDescr = ts.Task.Description
+		if ts.Activity != nil {
+			activity = ts.Activity.Name
+			activityDescr = ts.Activity.Description
+		}
+		if ts.Client != nil {
+			client = ts.Client.Name
+		}
+	}
+
+	taskLine := "Task: " + a.Task
+	if taskDescr == "" {
+		taskDescr = a.Task
+	} else {
+		taskLine += " - " + taskDescr
+	}
+
+	summary := activity + ": " + taskDescr
+	if client != "" {
+		summary = client + " / " + summary
+	}
+
+	worked, err := tmtime.Seconds2Duration(a.Seconds)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	lines := []string{
+		taskLine,
+		"Activity: " + activity,
+	}
+	if activityDescr != "" {
+		lines[1] += " - " + activityDescr
+	}
+	if client != "" {
+		lines = append(lines, "Client: "+client)
+	}
+	lines = append(lines,
+		fmt.Sprintf("Period: %s - %s", a.Start, a.End),
+		fmt.Sprintf("Work days: %d", a.Days),
+		"Time: "+worked,
+	)
+	if scenario != "" {
+		lines = append(lines, "Scenario: "+scenario)
+	}
+
+	categories := []string{activity}
+	if client != "" {
+		categories = append(categories, client)
+	}
+
+	return summary, strings.Join(lines, "\n"), categories, nil
+}
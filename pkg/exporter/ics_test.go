@@ -0,0 +1,174 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package exporter_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/exporter"
+	ical "github.com/geaaru/time-master/pkg/ical"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ICS Exporter Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	newSchedule := func() *specs.ScenarioSchedule {
+		return &specs.ScenarioSchedule{
+			Scenario: &specs.Scenario{Name: "Plan", NowTime: "2026-09-03"},
+			Schedule: []specs.TaskScheduled{
+				{
+					Task: &specs.Task{Name: "ACT1.analysis", Description: "Analysis"},
+					Timesheets: []specs.ResourceTimesheet{
+						// Friday, Monday and Tuesday are consecutive work days.
+						*specs.NewResourceTimesheet("geaaru", "2026-09-04", "ACT1.analysis", "4h"),
+						*specs.NewResourceTimesheet("geaaru", "2026-09-07", "ACT1.analysis", "8h"),
+						*specs.NewResourceTimesheet("geaaru", "2026-09-08", "ACT1.analysis", "2h"),
+						*specs.NewResourceTimesheet("geaaru", "2026-09-08", "ACT1.analysis", "2h"),
+						*specs.NewResourceTimesheet("geaaru", "2026-09-10", "ACT1.analysis", "8h"),
+					},
+				},
+				{
+					Task: &specs.Task{Name: "ACT2.dev"},
+					Timesheets: []specs.ResourceTimesheet{
+						*specs.NewResourceTimesheet("mario", "2026-09-01", "ACT2.dev", "8h"),
+						*specs.NewResourceTimesheet("geaaru", "2026-09-02", "ACT2.dev", "8h"),
+					},
+				},
+			},
+		}
+	}
+
+	Context("Assignments", func() {
+
+		It("Merge consecutive work days", func() {
+			exp := NewTmIcsExporter(config)
+			assignments, err := exp.GetAssignments(newSchedule())
+			Expect(err).Should(BeNil())
+			Expect(assignments).To(Equal([]ResourceAssignment{
+				{User: "geaaru", Task: "ACT2.dev", Start: "2026-09-02", End: "2026-09-02", Seconds: 8 * 3600, Days: 1, Ordinal: 1},
+				{User: "geaaru", Task: "ACT1.analysis", Start: "2026-09-04", End: "2026-09-08", Seconds: 16 * 3600, Days: 3, Ordinal: 1},
+				{User: "geaaru", Task: "ACT1.analysis", Start: "2026-09-10", End: "2026-09-10", Seconds: 8 * 3600, Days: 1, Ordinal: 2},
+				{User: "mario", Task: "ACT2.dev", Start: "2026-09-01", End: "2026-09-01", Seconds: 8 * 3600, Days: 1, Ordinal: 1},
+			}))
+		})
+
+		It("Only planned", func() {
+			exp := NewTmIcsExporter(config)
+			exp.OnlyPlanned = true
+			assignments, err := exp.GetAssignments(newSchedule())
+			Expect(err).Should(BeNil())
+			Expect(len(assignments)).To(Equal(2))
+			Expect(assignments[0].Start).To(Equal("2026-09-04"))
+		})
+	})
+
+	Context("Calendars", func() {
+
+		It("One calendar for resource", func() {
+			exp := NewTmIcsExporter(config)
+			exp.SetResources([]specs.Resource{
+				{User: "geaaru", Name: "Daniele Rondina", Email: []string{"geaaru@example.com"}},
+			})
+			exp.SetClients([]specs.Client{
+				{
+					Name: "CLIENT1",
+					Activities: []specs.Activity{
+						{Name: "ACT1", Description: "Project 1"},
+					},
+				},
+			})
+
+			calendars, err := exp.BuildCalendars(newSchedule())
+			Expect(err).Should(BeNil())
+			Expect(len(calendars)).To(Equal(2))
+			Expect(calendars[0].User).To(Equal("geaaru"))
+			Expect(calendars[0].Calendar.Name).To(Equal("Time Master - Plan - Daniele Rondina"))
+			Expect(calendars[1].Calendar.Name).To(Equal("Time Master - Plan - mario"))
+
+			e := calendars[0].Calendar.Events[1]
+			Expect(e.Summary).To(Equal("CLIENT1 / ACT1: Analysis"))
+			Expect(e.Description).To(Equal("Task: ACT1.analysis - Analysis\n" +
+				"Activity: ACT1 - Project 1\n" +
+				"Client: CLIENT1\n" +
+				"Period: 2026-09-04 - 2026-09-08\n" +
+				"Work days: 3\n" +
+				"Time: 16h\n" +
+				"Scenario: Plan"))
+			Expect(e.Categories).To(Equal([]string{"ACT1", "CLIENT1"}))
+			Expect(e.Start.Format("2006-01-02")).To(Equal("2026-09-04"))
+			Expect(e.End.Format("2006-01-02")).To(Equal("2026-09-09"))
+			Expect(e.Attendees).To(Equal([]ical.Attendee{{Name: "Daniele Rondina", Email: "geaaru@example.com"}}))
+
+			Expect(calendars[0].Calendar.Events[0].Summary).To(Equal("ACT2: ACT2.dev"))
+		})
+
+		It("Stable uids and combined calendar", func() {
+			exp := NewTmIcsExporter(config)
+			cal, err := exp.BuildCalendar(newSchedule())
+			Expect(err).Should(BeNil())
+			Expect(len(cal.Events)).To(Equal(4))
+			Expect(cal.Events[3].Summary).To(Equal("mario: ACT2: ACT2.dev"))
+
+			// A new day of work at the end of the assignment doesn't
+			// change the uid of the event.
+			s := newSchedule()
+			s.Schedule[0].Timesheets = append(s.Schedule[0].Timesheets,
+				*specs.NewResourceTimesheet("geaaru", "2026-09-11", "ACT1.analysis", "8h"))
+			cal2, err := exp.BuildCalendar(s)
+			Expect(err).Should(BeNil())
+			Expect(len(cal2.Events)).To(Equal(4))
+			for idx := range cal.Events {
+				Expect(cal2.Events[idx].Uid).To(Equal(cal.Events[idx].Uid))
+			}
+			Expect(cal2.Events[2].End.Format("2006-01-02")).To(Equal("2026-09-12"))
+
+			// Same data, same file.
+			cal3, err := exp.BuildCalendar(s)
+			Expect(err).Should(BeNil())
+			Expect(cal3.Marshal()).To(Equal(cal2.Marshal()))
+		})
+
+		It("Rescheduled assignment keeps the uid", func() {
+			exp := NewTmIcsExporter(config)
+			cal, err := exp.BuildCalendar(newSchedule())
+			Expect(err).Should(BeNil())
+
+			// The analysis is delayed by a work day.
+			s := newSchedule()
+			s.Schedule[0].Timesheets = []specs.ResourceTimesheet{
+				*specs.NewResourceTimesheet("geaaru", "2026-09-07", "ACT1.analysis", "4h"),
+				*specs.NewResourceTimesheet("geaaru", "2026-09-08", "ACT1.analysis", "8h"),
+				*specs.NewResourceTimesheet("geaaru", "2026-09-09", "ACT1.analysis", "4h"),
+				*specs.NewResourceTimesheet("geaaru", "2026-09-11", "ACT1.analysis", "8h"),
+			}
+			cal2, err := exp.BuildCalendar(s)
+			Expect(err).Should(BeNil())
+			Expect(len(cal2.Events)).To(Equal(4))
+			Expect(cal2.Events[1].Start.Format("2006-01-02")).To(Equal("2026-09-07"))
+			for idx := range cal.Events {
+				Expect(cal2.Events[idx].Uid).To(Equal(cal.Events[idx].Uid))
+			}
+		})
+	})
+})
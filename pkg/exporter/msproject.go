@@ -0,0 +1,695 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	log "github.com/geaaru/time-master/pkg/logger"
+	msproject "github.com/geaaru/time-master/pkg/msproject"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+)
+
+// TmMsProjectExporter creates a MS Project XML (MSPDI) file with the
+// activities of the clients and, optionally, with the dates and the
+// progress of a prevision.
+type TmMsProjectExporter struct {
+	Logger *log.TmLogger
+	Config *specs.TimeMasterConfig
+
+	ProjectName string
+	// Start date (YYYY-MM-DD) of the tasks without prevision and current
+	// date of the project. Default the now of the prevision or today.
+	Now string
+
+	resources []specs.Resource
+	clients   []specs.Client
+	prevision *specs.ScenarioSchedule
+
+	project   *msproject.Project
+	scheduled map[string]*specs.TaskScheduled
+	uids      map[string]int
+	rUids     map[string]int
+	nextUid   int
+}
+
+// Task of the project with the data used to build the summaries.
+type mspTask struct {
+	Index    int
+	Task     *specs.Task
+	FullName string
+	Children []*mspTask
+	WorkSecs int64
+}
+
+func NewTmMsProjectExporter(config *specs.TimeMasterConfig) *TmMsProjectExporter {
+	return &TmMsProjectExporter{
+		Logger:      log.NewTmLogger(config),
+		Config:      config,
+		ProjectName: "Time Master",
+		resources:   []specs.Resource{},
+		clients:     []specs.Client{},
+	}
+}
+
+func (e *TmMsProjectExporter) SetResources(resources []specs.Resource) {
+	e.resources = resources
+}
+
+func (e *TmMsProjectExporter) SetClients(clients []specs.Client) {
+	e.clients = clients
+}
+
+// SetPrevision sets the prevision used for the dates, the progress
+// and the assignments of the tasks.
+func (e *TmMsProjectExporter) SetPrevision(s *specs.ScenarioSchedule) {
+	e.prevision = s
+}
+
+// Return the start and the finish time of the work day.
+func (e *TmMsProjectExporter) getDayTimes() (string, string) {
+	end := 8 + e.Config.GetWork().WorkHours
+	if e.Config.GetWork().WorkHours > 4 {
+		// One hour of lunch break.
+		end++
+	}
+	return "08:00:00", fmt.Sprintf("%02d:00:00", end)
+}
+
+func (e *TmMsProjectExporter) getStart(date string) string {
+	start, _ := e.getDayTimes()
+	return date[0:10] + "T" + start
+}
+
+func (e *TmMsProjectExporter) getFinish(date string) string {
+	_, finish := e.getDayTimes()
+	return date[0:10] + "T" + finish
+}
+
+// Return the number of work days between the dates (inclusive).
+func getWorkDays(start, end string) (int, error) {
+	ans := 0
+	d := start
+	if ok, err := tmtime.IsAWorkDay(d); err != nil {
+		return 0, err
+	} else if !ok {
+		d, err = tmtime.GetNextWorkDay(d)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for d <= end {
+		ans++
+		next, err := tmtime.GetNextWorkDay(d)
+		if err != nil {
+			return 0, err
+		}
+		d = next
+	}
+	return ans, nil
+}
+
+// Return the date after the work days from the start date (inclusive).
+func addWorkDays(start string, days int) (string, error) {
+	d := start
+	if ok, err := tmtime.IsAWorkDay(d); err != nil {
+		return "", err
+	} else if !ok {
+		d, err = tmtime.GetNextWorkDay(d)
+		if err != nil {
+			return "", err
+		}
+	}
+	for i := 1; i < days; i++ {
+		next, err := tmtime.GetNextWorkDay(d)
+		if err != nil {
+			return "", err
+		}
+		d = next
+	}
+	return d, nil
+}
+
+func (e *TmMsProjectExporter) getNow() string {
+	if e.Now != "" {
+		return e.Now
+	}
+	if e.prevision != nil && e.prevision.Scenario != nil && len(e.prevision.Scenario.NowTime) >= 10 {
+		return e.prevision.Scenario.NowTime[0:10]
+	}
+	return time.Now().Format("2006-01-02")
+}
+
+func (e *TmMsProjectExporter) addTask(t msproject.Task) *mspTask {
+	t.UID = e.nextUid
+	t.ID = e.nextUid
+	t.PredecessorLink = []msproject.PredecessorLink{}
+	e.nextUid++
+	e.project.Tasks = append(e.project.Tasks, t)
+	return &mspTask{
+		Index:    len(e.project.Tasks) - 1,
+		Children: []*mspTask{},
+	}
+}
+
+func (e *TmMsProjectExporter) getResourceUid(user string) int {
+	if uid, ok := e.rUids[user]; ok {
+		return uid
+	}
+
+	uid := len(e.project.Resources) + 1
+	e.project.Resources = append(e.project.Resources, msproject.Resource{
+		UID:      uid,
+		ID:       uid,
+		Name:     user,
+		Type:     msproject.RESOURCE_TYPE_WORK,
+		Initials: user,
+	})
+	e.rUids[user] = uid
+	return uid
+}
+
+// Export returns the MSPDI project. The clients and the activities
+// are summary tasks with the tasks of the activities as children.
+func (e *TmMsProjectExporter) Export() (*msproject.Project, error) {
+	if e.Config.GetWork().WorkHours <= 0 {
+		return nil, errors.New("Invalid work hours")
+	}
+
+	start, finish := e.getDayTimes()
+	now := e.getNow()
+	if _, err := tmtime.ParseTimestamp(now, true); err != nil {
+		return nil, errors.New("Invalid now date " + now + ": " + err.Error())
+	}
+
+	e.project = &msproject.Project{
+		SaveVersion:       14,
+		Name:              e.ProjectName,
+		Title:             e.ProjectName,
+		ScheduleFromStart: 1,
+		CalendarUID:       1,
+		DefaultStartTime:  start,
+		DefaultFinishTime: finish,
+		MinutesPerDay:     e.Config.GetWork().WorkHours * 60,
+		MinutesPerWeek:    e.Config.GetWork().WorkHours * 60 * 5,
+		DaysPerMonth:      20,
+		CurrentDate:       e.getStart(now),
+		Calendars:         []msproject.Calendar{e.getCalendar()},
+		Tasks:             []msproject.Task{},
+		Resources:         []msproject.Resource{},
+		Assignments:       []msproject.Assignment{},
+	}
+	e.scheduled = make(map[string]*specs.TaskScheduled, 0)
+	e.uids = make(map[string]int, 0)
+	e.rUids = make(map[string]int, 0)
+	e.nextUid = 1
+
+	for _, r := range e.resources {
+		uid := e.getResourceUid(r.User)
+		res := &e.project.Resources[uid-1]
+		if r.Name != "" {
+			res.Name = r.Name
+		}
+		if len(r.Email) > 0 {
+			res.EmailAddress = r.Email[0]
+		}
+	}
+
+	if e.prevision != nil {
+		for idx := range e.prevision.Schedule {
+			ts := &e.prevision.Schedule[idx]
+			e.scheduled[ts.Name] = ts
+		}
+	}
+
+	roots := []*mspTask{}
+	depends := make(map[int][]string, 0)
+
+	for cidx := range e.clients {
+		c := &e.clients[cidx]
+		descr := c.Description
+		if descr == "" {
+			descr = c.Name
+		}
+
+		client := e.addTask(msproject.Task{
+			Name:         descr,
+			WBS:          fmt.Sprintf("%d", len(roots)+1),
+			OutlineLevel: 1,
+			Summary:      1,
+		})
+
+		for aidx := range c.Activities {
+			a := &c.Activities[aidx]
+			if a.Disabled || len(a.Tasks) == 0 {
+				continue
+			}
+
+			name := a.Description
+			if name == "" {
+				name = a.Name
+			}
+			activity := e.addTask(msproject.Task{
+				Name: name,
+				WBS: fmt.Sprintf("%s.%d", e.project.Tasks[client.Index].WBS,
+					len(client.Children)+1),
+				OutlineLevel: 2,
+				Summary:      1,
+				Notes:        a.Note,
+			})
+			activity.FullName = a.Name
+			client.Children = append(client.Children, activity)
+
+			for tidx := range a.Tasks {
+				err := e.addActivityTask(&a.Tasks[tidx], activity, a.Closed, depends, now)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if len(client.Children) == 0 {
+			// Remove the client without activities.
+			e.project.Tasks = e.project.Tasks[0:client.Index]
+			e.nextUid--
+			continue
+		}
+		roots = append(roots, client)
+	}
+
+	if len(roots) == 0 {
+		return nil, errors.New("No tasks available")
+	}
+
+	for _, r := range roots {
+		if err := e.updateSummary(r); err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve the dependencies after the creation of all the tasks.
+	for idx := range e.project.Tasks {
+		t := &e.project.Tasks[idx]
+		for _, d := range depends[t.UID] {
+			uid, ok := e.uids[d]
+			if !ok {
+				e.Logger.Warning(fmt.Sprintf("Dependency %s of the task %s not available ignored.",
+					d, t.Name))
+				continue
+			}
+			t.PredecessorLink = append(t.PredecessorLink, msproject.PredecessorLink{
+				PredecessorUID: uid,
+				Type:           msproject.LINK_FS,
+			})
+		}
+	}
+
+	for _, t := range e.project.Tasks {
+		if e.project.StartDate == "" || t.Start < e.project.StartDate {
+			e.project.StartDate = t.Start
+		}
+		if e.project.FinishDate == "" || t.Finish > e.project.FinishDate {
+			e.project.FinishDate = t.Finish
+		}
+	}
+
+	return e.project, nil
+}
+
+func (e *TmMsProjectExporter) getCalendar() msproject.Calendar {
+	start, finish := e.getDayTimes()
+	times := []msproject.WorkingTime{{FromTime: start, ToTime: finish}}
+	if e.Config.GetWork().WorkHours > 4 {
+		times = []msproject.WorkingTime{
+			{FromTime: start, ToTime: "12:00:00"},
+			{FromTime: "13:00:00", ToTime: finish},
+		}
+	}
+
+	ans := msproject.Calendar{
+		UID:            1,
+		Name:           "Standard",
+		IsBaseCalendar: 1,
+		WeekDays:       []msproject.WeekDay{},
+	}
+	for day := 1; day <= 7; day++ {
+		wd := msproject.WeekDay{DayType: day}
+		// 1 is Sunday and 7 is Saturday.
+		if day > 1 && day < 7 {
+			wd.DayWorking = 1
+			wd.WorkingTimes = times
+		}
+		ans.WeekDays = append(ans.WeekDays, wd)
+	}
+	return ans
+}
+
+func (e *TmMsProjectExporter) addActivityTask(t *specs.Task, parent *mspTask, completed bool,
+	depends map[int][]string, now string) error {
+
+	pt := &e.project.Tasks[parent.Index]
+	name := t.Description
+	if name == "" {
+		name = t.Name
+	}
+
+	node := e.addTask(msproject.Task{
+		Name:         name,
+		WBS:          fmt.Sprintf("%s.%d", pt.WBS, len(parent.Children)+1),
+		OutlineLevel: pt.OutlineLevel + 1,
+		Priority:     500,
+		Notes:        t.Note,
+	})
+	node.Task = t
+	node.FullName = parent.FullName + "." + t.Name
+	parent.Children = append(parent.Children, node)
+
+	uid := e.project.Tasks[node.Index].UID
+	e.uids[node.FullName] = uid
+	depends[uid] = t.Depends
+	completed = completed || t.Completed
+
+	if len(t.Tasks) == 0 {
+		return e.setLeafTask(node, completed, now)
+	}
+
+	e.project.Tasks[node.Index].Summary = 1
+
+	// A summary task of MS Project can't have work. The effort and
+	// the timesheets of the parent task are moved to a new subtask.
+	if t.Effort != "" || e.hasTimesheets(node.FullName) {
+		main := e.addTask(msproject.Task{
+			Name:         name + " (main)",
+			WBS:          fmt.Sprintf("%s.1", e.project.Tasks[node.Index].WBS),
+			OutlineLevel: e.project.Tasks[node.Index].OutlineLevel + 1,
+			Priority:     500,
+		})
+		main.Task = t
+		main.FullName = node.FullName
+		node.Children = append(node.Children, main)
+		if err := e.setLeafTask(main, completed, now); err != nil {
+			return err
+		}
+	}
+
+	for idx := range t.Tasks {
+		// The subtasks inherit the resources of the parent.
+		st := t.Tasks[idx]
+		if len(st.AllocatedResource) == 0 {
+			st.AllocatedResource = t.AllocatedResource
+		}
+		if err := e.addActivityTask(&st, node, completed, depends, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *TmMsProjectExporter) hasTimesheets(name string) bool {
+	ts, ok := e.scheduled[name]
+	return ok && len(ts.Timesheets) > 0
+}
+
+// Set dates, work, progress and assignments of a task without subtasks.
+func (e *TmMsProjectExporter) setLeafTask(node *mspTask, completed bool, now string) error {
+	t := &e.project.Tasks[node.Index]
+	minutesPerDay := e.Config.GetWork().WorkHours * 60
+
+	var effort int64
+	if node.Task.Effort != "" {
+		secs, err := tmtime.ParseDuration(node.Task.Effort, e.Config.GetWork().WorkHours)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid effort %s of the task %s: %s",
+				node.Task.Effort, node.FullName, err.Error()))
+		}
+		effort = secs
+	}
+
+	ts, scheduled := e.scheduled[node.FullName]
+	startDate := now
+	endDate := ""
+	if scheduled && ts.Period != nil && len(ts.Period.StartPeriod) >= 10 {
+		startDate = ts.Period.StartPeriod[0:10]
+		if len(ts.Period.EndPeriod) >= 10 {
+			endDate = ts.Period.EndPeriod[0:10]
+		}
+	}
+
+	if node.Task.Milestone != "" {
+		if len(node.Task.Milestone) >= 10 {
+			startDate = node.Task.Milestone[0:10]
+		}
+		t.Milestone = 1
+		t.Start = e.getStart(startDate)
+		t.Finish = t.Start
+		t.Duration = msproject.FormatDuration(0)
+		t.DurationFormat = msproject.DURATION_FORMAT_DAYS
+		t.Work = msproject.FormatDuration(0)
+		if completed {
+			t.PercentComplete = 100
+		}
+		return nil
+	}
+
+	assignments := e.getAssignments(node, ts, effort, now)
+	var work int64
+	for _, a := range assignments {
+		secs, _ := msproject.ParseDuration(a.Work, minutesPerDay)
+		work += secs
+	}
+	if work < effort {
+		work = effort
+	}
+	node.WorkSecs = work
+
+	if endDate == "" {
+		// Without prevision the duration is the effort divided
+		// between the resources.
+		n := len(node.Task.AllocatedResource)
+		if n == 0 {
+			n = 1
+		}
+		days := int(math.Ceil(float64(work) / float64(n) / float64(minutesPerDay*60)))
+		if days < 1 {
+			days = 1
+		}
+		d, err := addWorkDays(startDate, days)
+		if err != nil {
+			return err
+		}
+		endDate = d
+		startDate, _ = addWorkDays(startDate, 1)
+	}
+
+	days, err := getWorkDays(startDate, endDate)
+	if err != nil {
+		return err
+	}
+	if days == 0 {
+		// Work logged only on holidays.
+		days = 1
+	}
+
+	t.Start = e.getStart(startDate)
+	t.Finish = e.getFinish(endDate)
+	t.Duration = msproject.FormatDuration(int64(days*minutesPerDay) * 60)
+	t.DurationFormat = msproject.DURATION_FORMAT_DAYS
+	t.Work = msproject.FormatDuration(work)
+
+	if completed {
+		t.PercentComplete = 100
+	} else if scheduled {
+		t.PercentComplete = int(math.Round(ts.Progress))
+	}
+
+	for _, a := range assignments {
+		a.UID = len(e.project.Assignments) + 1
+		a.TaskUID = t.UID
+		if a.Start == "" {
+			a.Start = t.Start
+			a.Finish = t.Finish
+		}
+		e.project.Assignments = append(e.project.Assignments, a)
+	}
+
+	return nil
+}
+
+// Return the assignments of the task from the timesheets of the
+// prevision or from the allocated resources.
+func (e *TmMsProjectExporter) getAssignments(node *mspTask, ts *specs.TaskScheduled, effort int64, now string) []msproject.Assignment {
+	ans := []msproject.Assignment{}
+
+	if ts != nil && len(ts.Timesheets) > 0 {
+		type userWork struct {
+			Work   int64
+			Actual int64
+			Start  string
+			End    string
+		}
+		users := []string{}
+		m := make(map[string]*userWork, 0)
+
+		for _, rt := range ts.Timesheets {
+			if rt.Period == nil || len(rt.Period.StartPeriod) < 10 {
+				continue
+			}
+			secs, err := tmtime.ParseDuration(rt.Duration, e.Config.GetWork().WorkHours)
+			if err != nil {
+				e.Logger.Warning(fmt.Sprintf("Invalid duration %s of the task %s ignored.",
+					rt.Duration, node.FullName))
+				continue
+			}
+			date := rt.Period.StartPeriod[0:10]
+
+			uw, ok := m[rt.User]
+			if !ok {
+				uw = &userWork{Start: date, End: date}
+				m[rt.User] = uw
+				users = append(users, rt.User)
+			}
+			uw.Work += secs
+			if date < now {
+				uw.Actual += secs
+			}
+			if date < uw.Start {
+				uw.Start = date
+			}
+			if date > uw.End {
+				uw.End = date
+			}
+		}
+
+		for _, u := range users {
+			uw := m[u]
+			a := msproject.Assignment{
+				ResourceUID:   e.getResourceUid(u),
+				Work:          msproject.FormatDuration(uw.Work),
+				ActualWork:    msproject.FormatDuration(uw.Actual),
+				RemainingWork: msproject.FormatDuration(uw.Work - uw.Actual),
+				Start:         e.getStart(uw.Start),
+				Finish:        e.getFinish(uw.End),
+				Units:         1,
+			}
+			if uw.Work > 0 {
+				a.PercentWorkComplete = int(uw.Actual * 100 / uw.Work)
+			}
+			ans = append(ans, a)
+		}
+
+		return ans
+	}
+
+	n := int64(len(node.Task.AllocatedResource))
+	for _, u := range node.Task.AllocatedResource {
+		ans = append(ans, msproject.Assignment{
+			ResourceUID: e.getResourceUid(u),
+			Work:        msproject.FormatDuration(effort / n),
+			Units:       1,
+		})
+	}
+
+	return ans
+}
+
+// Update dates, work and progress of the summary tasks.
+func (e *TmMsProjectExporter) updateSummary(node *mspTask) error {
+	if len(node.Children) == 0 {
+		return nil
+	}
+
+	t := &e.project.Tasks[node.Index]
+	var work int64
+	var done float64
+	t.Start = ""
+	t.Finish = ""
+
+	for _, c := range node.Children {
+		if err := e.updateSummary(c); err != nil {
+			return err
+		}
+		ct := &e.project.Tasks[c.Index]
+		if t.Start == "" || ct.Start < t.Start {
+			t.Start = ct.Start
+		}
+		if t.Finish == "" || ct.Finish > t.Finish {
+			t.Finish = ct.Finish
+		}
+		work += c.WorkSecs
+		done += float64(c.WorkSecs) * float64(ct.PercentComplete) / 100
+	}
+
+	days, err := getWorkDays(t.Start[0:10], t.Finish[0:10])
+	if err != nil {
+		return err
+	}
+
+	node.WorkSecs = work
+	t.Work = msproject.FormatDuration(work)
+	t.Duration = msproject.FormatDuration(int64(days*e.Config.GetWork().WorkHours) * 3600)
+	t.DurationFormat = msproject.DURATION_FORMAT_DAYS
+	if work > 0 {
+		t.PercentComplete = int(math.Round(done * 100 / float64(work)))
+	}
+
+	return nil
+}
+
+// FilterClients returns the clients and the activities selected.
+// The empty lists select all the clients or the activities.
+func FilterClients(clients []specs.Client, names, activities []string) []specs.Client {
+	ans := []specs.Client{}
+
+	match := func(list []string, v string) bool {
+		if len(list) == 0 {
+			return true
+		}
+		for _, n := range list {
+			if strings.EqualFold(n, v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, c := range clients {
+		if !match(names, c.Name) {
+			continue
+		}
+		client := c
+		client.Activities = []specs.Activity{}
+		for _, a := range c.Activities {
+			if match(activities, a.Name) {
+				client.Activities = append(client.Activities, a)
+			}
+		}
+		if len(client.Activities) > 0 {
+			ans = append(ans, client)
+		}
+	}
+
+	return ans
+}
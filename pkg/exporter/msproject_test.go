@@ -0,0 +1,187 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package exporter_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/exporter"
+	msproject "github.com/geaaru/time-master/pkg/msproject"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MS Project Exporter Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	resources := []specs.Resource{
+		{User: "d.rondina", Name: "Daniele Rondina", Email: []string{"geaaru@example.com"}},
+	}
+
+	Context("Without prevision", func() {
+
+		It("Tasks, resources and assignments", func() {
+			exp := NewTmMsProjectExporter(config)
+			exp.Now = "2026-09-07"
+			exp.SetClients(newClients())
+			exp.SetResources(resources)
+
+			p, err := exp.Export()
+			Expect(err).Should(BeNil())
+			// The disabled activity is excluded.
+			Expect(len(p.Tasks)).To(Equal(8))
+
+			Expect(p.Tasks[0].Name).To(Equal(`The "ACME" company`))
+			Expect(p.Tasks[0].Summary).To(Equal(1))
+			Expect(p.Tasks[0].Work).To(Equal("PT28H0M0S"))
+			Expect(p.Tasks[1].Name).To(Equal("Project 1"))
+			Expect(p.Tasks[1].WBS).To(Equal("1.1"))
+			Expect(p.Tasks[1].OutlineLevel).To(Equal(2))
+
+			analysis := p.Tasks[2]
+			Expect(analysis.Name).To(Equal("Analysis"))
+			Expect(analysis.WBS).To(Equal("1.1.1"))
+			Expect(analysis.Summary).To(Equal(1))
+			Expect(analysis.Start).To(Equal("2026-09-07T08:00:00"))
+			Expect(analysis.Finish).To(Equal("2026-09-08T17:00:00"))
+			Expect(analysis.Work).To(Equal("PT19H0M0S"))
+			Expect(analysis.Duration).To(Equal("PT16H0M0S"))
+			Expect(p.Tasks[3].Name).To(Equal("Analysis (main)"))
+			Expect(p.Tasks[4].WBS).To(Equal("1.1.1.2"))
+
+			// The dependency on the missing task is ignored.
+			dev := p.Tasks[5]
+			Expect(dev.Finish).To(Equal("2026-09-07T17:00:00"))
+			Expect(dev.PredecessorLink).To(Equal([]msproject.PredecessorLink{
+				{PredecessorUID: analysis.UID, Type: msproject.LINK_FS},
+			}))
+
+			release := p.Tasks[6]
+			Expect(release.Milestone).To(Equal(1))
+			Expect(release.Start).To(Equal("2026-10-30T08:00:00"))
+			Expect(release.Duration).To(Equal("PT0H0M0S"))
+			Expect(release.PredecessorLink).To(Equal([]msproject.PredecessorLink{
+				{PredecessorUID: dev.UID, Type: msproject.LINK_FS},
+			}))
+
+			Expect(p.Tasks[7].PercentComplete).To(Equal(100))
+
+			Expect(p.Resources).To(Equal([]msproject.Resource{
+				{
+					UID: 1, ID: 1, Name: "Daniele Rondina", Type: msproject.RESOURCE_TYPE_WORK,
+					Initials: "d.rondina", EmailAddress: "geaaru@example.com",
+				},
+				{UID: 2, ID: 2, Name: "mario", Type: msproject.RESOURCE_TYPE_WORK, Initials: "mario"},
+			}))
+
+			Expect(len(p.Assignments)).To(Equal(5))
+			Expect(p.Assignments[2].TaskUID).To(Equal(dev.UID))
+			Expect(p.Assignments[2].Work).To(Equal("PT4H0M0S"))
+			Expect(p.Assignments[3].ResourceUID).To(Equal(2))
+		})
+
+		It("Parent task with effort", func() {
+			clients := []specs.Client{
+				{
+					Name: "CLIENT1",
+					Activities: []specs.Activity{
+						{
+							Name: "ACT1",
+							Tasks: []specs.Task{
+								{
+									Name: "dev", Effort: "1d",
+									Tasks: []specs.Task{{Name: "backend", Effort: "2d"}},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			exp := NewTmMsProjectExporter(config)
+			exp.Now = "2026-09-07"
+			exp.SetClients(clients)
+
+			p, err := exp.Export()
+			Expect(err).Should(BeNil())
+			Expect(len(p.Tasks)).To(Equal(5))
+			Expect(p.Tasks[2].Summary).To(Equal(1))
+			Expect(p.Tasks[2].Work).To(Equal("PT24H0M0S"))
+			Expect(p.Tasks[3].Name).To(Equal("dev (main)"))
+			Expect(p.Tasks[3].Work).To(Equal("PT8H0M0S"))
+			Expect(p.Tasks[4].WBS).To(Equal("1.1.1.2"))
+		})
+	})
+
+	Context("With prevision", func() {
+
+		It("Dates and actual work", func() {
+			exp := NewTmMsProjectExporter(config)
+			exp.SetClients(newClients())
+			exp.SetResources(resources)
+			exp.SetPrevision(&specs.ScenarioSchedule{
+				Scenario: &specs.Scenario{Name: "Plan", NowTime: "2026-09-09"},
+				Schedule: []specs.TaskScheduled{
+					{
+						Task:     &specs.Task{Name: "ACT1.dev"},
+						Period:   &specs.Period{StartPeriod: "2026-09-08", EndPeriod: "2026-09-10"},
+						Progress: 50,
+						Timesheets: []specs.ResourceTimesheet{
+							*specs.NewResourceTimesheet("d.rondina", "2026-09-08", "ACT1.dev", "8h"),
+							*specs.NewResourceTimesheet("d.rondina", "2026-09-10", "ACT1.dev", "8h"),
+						},
+					},
+				},
+			})
+
+			p, err := exp.Export()
+			Expect(err).Should(BeNil())
+			Expect(p.CurrentDate).To(Equal("2026-09-09T08:00:00"))
+
+			dev := p.Tasks[5]
+			Expect(dev.Start).To(Equal("2026-09-08T08:00:00"))
+			Expect(dev.Finish).To(Equal("2026-09-10T17:00:00"))
+			Expect(dev.Duration).To(Equal("PT24H0M0S"))
+			Expect(dev.PercentComplete).To(Equal(50))
+
+			a := p.Assignments[2]
+			Expect(a.TaskUID).To(Equal(dev.UID))
+			Expect(a.Work).To(Equal("PT16H0M0S"))
+			Expect(a.ActualWork).To(Equal("PT8H0M0S"))
+			Expect(a.RemainingWork).To(Equal("PT8H0M0S"))
+			Expect(a.PercentWorkComplete).To(Equal(50))
+		})
+	})
+
+	Context("Filter", func() {
+
+		It("Activities", func() {
+			clients := FilterClients(newClients(), []string{}, []string{"old"})
+			Expect(len(clients)).To(Equal(1))
+			Expect(len(clients[0].Activities)).To(Equal(1))
+			Expect(clients[0].Activities[0].Name).To(Equal("OLD"))
+
+			Expect(FilterClients(newClients(), []string{"CLIENT2"}, []string{})).To(
+				Equal([]specs.Client{}))
+		})
+	})
+})
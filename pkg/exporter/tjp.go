@@ -0,0 +1,674 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/geaaru/time-master/pkg/logger"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+)
+
+const (
+	// Timing resolution of the project in seconds. The bookings and the
+	// efforts are rounded to this value.
+	TJP_RESOLUTION = 15 * 60
+	// Start time of the bookings of a day.
+	TJP_DAY_START = 9 * 3600
+)
+
+var tjpInvalidIdChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// TmTjpExporter creates a TaskJuggler (tj3) project with the clients,
+// the activities, the resources and the timesheets of the instance.
+type TmTjpExporter struct {
+	Logger *log.TmLogger
+	Config *specs.TimeMasterConfig
+
+	// Id and name of the project.
+	ProjectId   string
+	ProjectName string
+	// Now date of the project in the format YYYY-MM-DD. Default today.
+	Now string
+
+	resources []specs.Resource
+	clients   []specs.Client
+	bookings  map[string][]tjpBooking
+
+	// Map task full name -> absolute id of the tjp task
+	ids map[string]string
+}
+
+type tjpBooking struct {
+	User    string
+	Date    string
+	Seconds int64
+	Start   int64
+}
+
+type tjpTask struct {
+	Id          string
+	Name        string
+	Task        *specs.Task
+	FullName    string
+	Resources   []string
+	Completed   bool
+	Children    []*tjpTask
+	AbsoluteId  string
+	BookingTask string
+	// Subtask with the effort and the timesheets of a parent task.
+	Main bool
+}
+
+func NewTmTjpExporter(config *specs.TimeMasterConfig) *TmTjpExporter {
+	return &TmTjpExporter{
+		Logger:      log.NewTmLogger(config),
+		Config:      config,
+		ProjectId:   "time_master",
+		ProjectName: "Time Master",
+		resources:   []specs.Resource{},
+		clients:     []specs.Client{},
+		bookings:    make(map[string][]tjpBooking, 0),
+		ids:         make(map[string]string, 0),
+	}
+}
+
+func (e *TmTjpExporter) SetResources(resources []specs.Resource) {
+	e.resources = resources
+}
+
+func (e *TmTjpExporter) SetClients(clients []specs.Client) {
+	e.clients = clients
+}
+
+// SetTimesheets sets the timesheets exported as bookings. The
+// timesheets of the same resource and day are booked one after
+// the other from 09:00.
+func (e *TmTjpExporter) SetTimesheets(agendas []specs.AgendaTimesheets) error {
+	if e.Config.GetWork().WorkHours <= 0 {
+		return errors.New("Invalid work hours")
+	}
+
+	e.bookings = make(map[string][]tjpBooking, 0)
+	cursors := make(map[string]int64, 0)
+
+	for _, agenda := range agendas {
+		for _, rt := range agenda.Timesheets {
+			if rt.Period == nil || rt.Period.StartPeriod == "" {
+				continue
+			}
+
+			secs, err := tmtime.ParseDuration(rt.Duration, e.Config.GetWork().WorkHours)
+			if err != nil {
+				return errors.New(fmt.Sprintf(
+					"Invalid duration %s of the timesheet of %s on %s: %s",
+					rt.Duration, rt.User, rt.Period.StartPeriod, err.Error()))
+			}
+			if secs <= 0 {
+				continue
+			}
+			secs, _ = tmtime.RoundSeconds(secs, TJP_RESOLUTION, "up")
+
+			date := rt.Period.StartPeriod
+			if len(date) > 10 {
+				date = date[0:10]
+			}
+
+			key := rt.User + "|" + date
+			start, ok := cursors[key]
+			if !ok {
+				start = TJP_DAY_START
+			}
+			cursors[key] = start + secs
+
+			e.bookings[rt.Task] = append(e.bookings[rt.Task], tjpBooking{
+				User:    rt.User,
+				Date:    date,
+				Seconds: secs,
+				Start:   start,
+			})
+		}
+	}
+
+	return nil
+}
+
+// GetTjpId returns a valid TaskJuggler id from the name.
+func GetTjpId(name string) string {
+	ans := tjpInvalidIdChars.ReplaceAllString(name, "_")
+	if ans == "" || (ans[0] >= '0' && ans[0] <= '9') {
+		ans = "_" + ans
+	}
+	return ans
+}
+
+// Return an id not used by the siblings.
+func getUniqueId(name string, used map[string]bool) string {
+	id := GetTjpId(name)
+	ans := id
+	for idx := 2; used[ans]; idx++ {
+		ans = fmt.Sprintf("%s_%d", id, idx)
+	}
+	used[ans] = true
+	return ans
+}
+
+func tjpString(s string) string {
+	s = strings.ReplaceAll(s, `"`, "'")
+	s = strings.ReplaceAll(s, "\r", "")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return `"` + strings.TrimSpace(s) + `"`
+}
+
+func tjpDuration(secs int64) string {
+	if secs%3600 == 0 {
+		return fmt.Sprintf("%dh", secs/3600)
+	}
+	return fmt.Sprintf("%dmin", secs/60)
+}
+
+// Build the tree of the tasks of an activity.
+func (e *TmTjpExporter) buildTask(t *specs.Task, parent *tjpTask, used map[string]bool) *tjpTask {
+	ans := &tjpTask{
+		Id:        getUniqueId(t.Name, used),
+		Name:      t.Description,
+		Task:      t,
+		FullName:  parent.FullName + "." + t.Name,
+		Resources: t.AllocatedResource,
+		Completed: t.Completed || parent.Completed,
+		Children:  []*tjpTask{},
+	}
+	ans.AbsoluteId = parent.AbsoluteId + "." + ans.Id
+	if ans.Name == "" {
+		ans.Name = t.Name
+	}
+	// The subtasks inherit the resources of the parent.
+	if len(ans.Resources) == 0 {
+		ans.Resources = parent.Resources
+	}
+	e.ids[ans.FullName] = ans.AbsoluteId
+
+	childrenIds := make(map[string]bool, 0)
+	for idx := range t.Tasks {
+		ans.Children = append(ans.Children, e.buildTask(&t.Tasks[idx], ans, childrenIds))
+	}
+
+	// A TaskJuggler container can't have effort or bookings. The
+	// effort and the timesheets of the parent task are moved to a
+	// new subtask.
+	_, hasBookings := e.bookings[ans.FullName]
+	if len(ans.Children) > 0 && (t.Effort != "" || hasBookings) {
+		main := &tjpTask{
+			Id:          getUniqueId("main", childrenIds),
+			Name:        ans.Name + " (main)",
+			Task:        t,
+			FullName:    ans.FullName,
+			Resources:   ans.Resources,
+			Completed:   ans.Completed,
+			Children:    []*tjpTask{},
+			BookingTask: ans.FullName,
+			Main:        true,
+		}
+		main.AbsoluteId = ans.AbsoluteId + "." + main.Id
+		ans.Children = append([]*tjpTask{main}, ans.Children...)
+	} else if len(ans.Children) == 0 {
+		ans.BookingTask = ans.FullName
+	}
+
+	return ans
+}
+
+// Build the tree of the tasks of the clients and the activities.
+func (e *TmTjpExporter) buildTree() []*tjpTask {
+	ans := []*tjpTask{}
+	e.ids = make(map[string]string, 0)
+	clientsIds := make(map[string]bool, 0)
+
+	for cidx := range e.clients {
+		c := &e.clients[cidx]
+		client := &tjpTask{
+			Id:       getUniqueId(c.Name, clientsIds),
+			Name:     c.Description,
+			Children: []*tjpTask{},
+		}
+		client.AbsoluteId = client.Id
+		if client.Name == "" {
+			client.Name = c.Name
+		}
+
+		activitiesIds := make(map[string]bool, 0)
+		for aidx := range c.Activities {
+			a := &c.Activities[aidx]
+			if a.Disabled {
+				continue
+			}
+
+			activity := &tjpTask{
+				Id:        getUniqueId(a.Name, activitiesIds),
+				Name:      a.Description,
+				FullName:  a.Name,
+				Completed: a.Closed,
+				Children:  []*tjpTask{},
+			}
+			activity.AbsoluteId = client.AbsoluteId + "." + activity.Id
+			if activity.Name == "" {
+				activity.Name = a.Name
+			}
+
+			tasksIds := make(map[string]bool, 0)
+			for tidx := range a.Tasks {
+				activity.Children = append(activity.Children,
+					e.buildTask(&a.Tasks[tidx], activity, tasksIds))
+			}
+
+			if len(activity.Children) > 0 {
+				client.Children = append(client.Children, activity)
+			}
+		}
+
+		if len(client.Children) > 0 {
+			ans = append(ans, client)
+		}
+	}
+
+	return ans
+}
+
+// Return the resources and the users not available between the
+// resources but used by tasks or timesheets.
+func (e *TmTjpExporter) getResources(tree []*tjpTask) []specs.Resource {
+	ans := []specs.Resource{}
+	m := make(map[string]bool, 0)
+
+	for _, r := range e.resources {
+		if _, ok := m[r.User]; !ok {
+			m[r.User] = true
+			ans = append(ans, r)
+		}
+	}
+
+	add := func(u string) {
+		if _, ok := m[u]; !ok {
+			m[u] = true
+			ans = append(ans, specs.Resource{User: u, Name: u})
+		}
+	}
+
+	var visit func(t *tjpTask)
+	visit = func(t *tjpTask) {
+		for _, u := range t.Resources {
+			add(u)
+		}
+		for _, c := range t.Children {
+			visit(c)
+		}
+	}
+	for _, t := range tree {
+		visit(t)
+	}
+
+	tasks := []string{}
+	for task := range e.bookings {
+		tasks = append(tasks, task)
+	}
+	sort.Strings(tasks)
+	for _, task := range tasks {
+		for _, b := range e.bookings[task] {
+			add(b.User)
+		}
+	}
+
+	return ans
+}
+
+// Return the first and the last dates of the timesheets
+// and of the milestones.
+func (e *TmTjpExporter) getDatesRange() (string, string) {
+	first := ""
+	last := ""
+
+	update := func(d string) {
+		if d == "" {
+			return
+		}
+		if first == "" || d < first {
+			first = d
+		}
+		if last == "" || d > last {
+			last = d
+		}
+	}
+
+	for task, bookings := range e.bookings {
+		if _, ok := e.ids[task]; !ok {
+			continue
+		}
+		for _, b := range bookings {
+			update(b.Date)
+		}
+	}
+
+	for _, c := range e.clients {
+		for _, a := range c.Activities {
+			if a.Disabled {
+				continue
+			}
+			for _, t := range a.GetAllTasksList() {
+				if t.Milestone != "" && len(t.Milestone) >= 10 {
+					update(t.Milestone[0:10])
+				}
+			}
+		}
+	}
+
+	return first, last
+}
+
+// Export returns the TaskJuggler project.
+func (e *TmTjpExporter) Export() ([]byte, error) {
+	var b strings.Builder
+
+	if e.Config.GetWork().WorkHours <= 0 {
+		return nil, errors.New("Invalid work hours")
+	}
+
+	tree := e.buildTree()
+	if len(tree) == 0 {
+		return nil, errors.New("No tasks available")
+	}
+	resources := e.getResources(tree)
+
+	now := e.Now
+	if now == "" {
+		now = time.Now().Format("2006-01-02")
+	}
+	nowTime, err := tmtime.ParseTimestamp(now, true)
+	if err != nil {
+		return nil, errors.New("Invalid now date " + now + ": " + err.Error())
+	}
+
+	first, last := e.getDatesRange()
+	if first == "" || first > now {
+		first = now
+	}
+	if last == "" || last < now {
+		last = now
+	}
+	firstTime, err := tmtime.ParseTimestamp(first, true)
+	if err != nil {
+		return nil, err
+	}
+	lastTime, err := tmtime.ParseTimestamp(last, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The project starts at the beginning of the month of the first
+	// timesheet and ends one year after the last date to leave space
+	// to the scheduling of the tasks.
+	projectStart := time.Date(firstTime.Year(), firstTime.Month(), 1, 0, 0, 0, 0, time.UTC)
+	projectEnd := lastTime.AddDate(1, 0, 0)
+
+	b.WriteString("/* Generated by time-master export tjp */\n\n")
+	b.WriteString(fmt.Sprintf("project %s %s %s - %s {\n", GetTjpId(e.ProjectId),
+		tjpString(e.ProjectName), projectStart.Format("2006-01-02"),
+		projectEnd.Format("2006-01-02")))
+	b.WriteString(fmt.Sprintf("  now %s\n", nowTime.Format("2006-01-02")))
+	b.WriteString(fmt.Sprintf("  dailyworkinghours %d\n", e.Config.GetWork().WorkHours))
+	b.WriteString(fmt.Sprintf("  timingresolution %dmin\n", TJP_RESOLUTION/60))
+	b.WriteString("}\n")
+
+	rIds := make(map[string]string, 0)
+	used := make(map[string]bool, 0)
+	for _, r := range resources {
+		rIds[r.User] = getUniqueId(r.User, used)
+	}
+
+	for _, r := range resources {
+		e.writeResource(&b, &r, rIds[r.User], projectStart, projectEnd)
+	}
+
+	for _, t := range tree {
+		err := e.writeTask(&b, t, rIds, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for task := range e.bookings {
+		if _, ok := e.ids[task]; !ok {
+			e.Logger.Warning(fmt.Sprintf("Timesheets of the task %s not available ignored.", task))
+		}
+	}
+
+	b.WriteString("\ntaskreport tasks \"tasks\" {\n")
+	b.WriteString("  formats html\n")
+	b.WriteString("  columns bsi, name, start, end, effort, resources, complete, chart\n")
+	b.WriteString("}\n")
+	b.WriteString("\nresourcereport resources \"resources\" {\n")
+	b.WriteString("  formats html\n")
+	b.WriteString("  columns no, name, effort, chart\n")
+	b.WriteString("}\n")
+
+	return []byte(b.String()), nil
+}
+
+func (e *TmTjpExporter) writeResource(b *strings.Builder, r *specs.Resource, id string, projectStart, projectEnd time.Time) {
+	name := r.Name
+	if name == "" {
+		name = r.User
+	}
+
+	b.WriteString(fmt.Sprintf("\nresource %s %s {\n", id, tjpString(name)))
+	if len(r.Email) > 0 {
+		b.WriteString(fmt.Sprintf("  email %s\n", tjpString(r.Email[0])))
+	}
+
+	periods := []*specs.Period{}
+	for _, h := range r.Holidays {
+		periods = append(periods, h.Period)
+	}
+	for _, s := range r.Sick {
+		periods = append(periods, s.Period)
+	}
+	for _, u := range r.Unemployed {
+		periods = append(periods, u.Period)
+	}
+
+	for _, p := range periods {
+		if p == nil || p.StartPeriod == "" {
+			continue
+		}
+		start, err := tmtime.ParseTimestamp(p.StartPeriod, true)
+		if err != nil {
+			e.Logger.Warning(fmt.Sprintf("Invalid period of the resource %s: %s", r.User, err.Error()))
+			continue
+		}
+		// The periods without end are open.
+		end := projectEnd
+		if p.EndPeriod != "" {
+			end, err = tmtime.ParseTimestamp(p.EndPeriod, true)
+			if err != nil {
+				e.Logger.Warning(fmt.Sprintf("Invalid period of the resource %s: %s", r.User, err.Error()))
+				continue
+			}
+			// The end date of the TaskJuggler intervals is excluded.
+			end = end.AddDate(0, 0, 1)
+		}
+
+		// The intervals must be inside the project.
+		if start.Before(projectStart) {
+			start = projectStart
+		}
+		if end.After(projectEnd) {
+			end = projectEnd
+		}
+		if !start.Before(end) {
+			continue
+		}
+
+		b.WriteString(fmt.Sprintf("  vacation %s - %s\n",
+			start.Format("2006-01-02"), end.Format("2006-01-02")))
+	}
+
+	b.WriteString("}\n")
+}
+
+func (e *TmTjpExporter) writeTask(b *strings.Builder, t *tjpTask, rIds map[string]string, indent string) error {
+	if indent == "" {
+		b.WriteString("\n")
+	}
+	b.WriteString(fmt.Sprintf("%stask %s %s {\n", indent, t.Id, tjpString(t.Name)))
+	in := indent + "  "
+
+	if t.Task != nil && !t.Main {
+		deps := []string{}
+		for _, d := range t.Task.Depends {
+			if id, ok := e.ids[d]; ok {
+				deps = append(deps, id)
+			} else {
+				e.Logger.Warning(fmt.Sprintf("Dependency %s of the task %s not available ignored.",
+					d, t.FullName))
+			}
+		}
+		if len(deps) > 0 {
+			// The bookings and the dates of the milestones fix the start
+			// of the task and TaskJuggler reports an error if the
+			// dependencies are violated.
+			if e.hasBookings(t) || t.Task.Milestone != "" {
+				b.WriteString(fmt.Sprintf("%s# depends %s (ignored with bookings or milestone date)\n",
+					in, strings.Join(deps, ", ")))
+			} else {
+				b.WriteString(fmt.Sprintf("%sdepends %s\n", in, strings.Join(deps, ", ")))
+			}
+		}
+	}
+
+	if len(t.Children) > 0 {
+		for _, c := range t.Children {
+			err := e.writeTask(b, c, rIds, in)
+			if err != nil {
+				return err
+			}
+		}
+		b.WriteString(indent + "}\n")
+		return nil
+	}
+
+	err := e.writeLeafTask(b, t, rIds, in)
+	if err != nil {
+		return err
+	}
+
+	b.WriteString(indent + "}\n")
+	return nil
+}
+
+// Return true if the task or one of the subtasks has bookings.
+func (e *TmTjpExporter) hasBookings(t *tjpTask) bool {
+	if t.BookingTask != "" && len(e.bookings[t.BookingTask]) > 0 {
+		return true
+	}
+	for _, c := range t.Children {
+		if e.hasBookings(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *TmTjpExporter) writeLeafTask(b *strings.Builder, t *tjpTask, rIds map[string]string, in string) error {
+	bookings := e.bookings[t.BookingTask]
+
+	var booked int64
+	for _, bk := range bookings {
+		booked += bk.Seconds
+	}
+
+	var effort int64
+	if t.Task.Effort != "" && !t.Completed {
+		secs, err := tmtime.ParseDuration(t.Task.Effort, e.Config.GetWork().WorkHours)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid effort %s of the task %s: %s",
+				t.Task.Effort, t.FullName, err.Error()))
+		}
+		effort, _ = tmtime.RoundSeconds(secs, TJP_RESOLUTION, "up")
+	}
+	// The effort of the completed tasks and of the tasks with more
+	// time booked than planned is the booked time.
+	if booked > effort {
+		effort = booked
+	}
+
+	resources := t.Resources
+	if len(resources) == 0 {
+		// Allocate the users of the timesheets.
+		m := make(map[string]bool, 0)
+		for _, bk := range bookings {
+			if _, ok := m[bk.User]; !ok {
+				m[bk.User] = true
+				resources = append(resources, bk.User)
+			}
+		}
+	}
+
+	milestone := t.Task.Milestone != "" && len(bookings) == 0
+	if !milestone && (effort == 0 || len(resources) == 0) {
+		// Without effort or resources the task could be only a milestone.
+		b.WriteString(fmt.Sprintf("%s# No effort or resources defined on time-master.\n", in))
+		milestone = true
+	}
+
+	if milestone {
+		b.WriteString(in + "milestone\n")
+		if len(t.Task.Milestone) >= 10 {
+			b.WriteString(fmt.Sprintf("%sstart %s\n", in, t.Task.Milestone[0:10]))
+		}
+		if t.Completed {
+			b.WriteString(in + "complete 100\n")
+		}
+		return nil
+	}
+
+	b.WriteString(fmt.Sprintf("%seffort %s\n", in, tjpDuration(effort)))
+
+	ids := []string{}
+	for _, r := range resources {
+		ids = append(ids, rIds[r])
+	}
+	b.WriteString(fmt.Sprintf("%sallocate %s\n", in, strings.Join(ids, ", ")))
+	if t.Completed {
+		b.WriteString(in + "complete 100\n")
+	}
+
+	for _, bk := range bookings {
+		b.WriteString(fmt.Sprintf("%sbooking %s %s-%02d:%02d +%s { overtime 2 }\n",
+			in, rIds[bk.User], bk.Date, bk.Start/3600, (bk.Start%3600)/60,
+			tjpDuration(bk.Seconds)))
+	}
+
+	return nil
+}
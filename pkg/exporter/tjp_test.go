@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package exporter_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/exporter"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TJP Exporter Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	resources := []specs.Resource{
+		{
+			User:  "d.rondina",
+			Name:  "Daniele Rondina",
+			Email: []string{"geaaru@example.com"},
+			Holidays: []specs.ResourceHolidays{
+				{Period: &specs.Period{StartPeriod: "2026-10-05", EndPeriod: "2026-10-09"}},
+			},
+			Unemployed: []specs.ResourceUnemployed{
+				{Period: &specs.Period{StartPeriod: "2027-01-01"}},
+			},
+		},
+	}
+
+	agendas := []specs.AgendaTimesheets{
+		{
+			Timesheets: []specs.ResourceTimesheet{
+				*specs.NewResourceTimesheet("d.rondina", "2026-09-01", "ACT1.analysis.1st-draft", "2h"),
+				*specs.NewResourceTimesheet("d.rondina", "2026-09-01", "ACT1.support", "1h10m"),
+				*specs.NewResourceTimesheet("luigi", "2026-09-02", "ACT1.analysis", "4h"),
+				*specs.NewResourceTimesheet("luigi", "2026-09-02", "ACT9.unknown", "4h"),
+			},
+		},
+	}
+
+	Context("Ids", func() {
+
+		It("Valid TaskJuggler ids", func() {
+			Expect(GetTjpId("ACME Inc.")).To(Equal("ACME_Inc_"))
+			Expect(GetTjpId("1st-draft")).To(Equal("_1st_draft"))
+			Expect(GetTjpId("task_1")).To(Equal("task_1"))
+		})
+	})
+
+	Context("Export", func() {
+
+		It("Project", func() {
+			exp := NewTmTjpExporter(config)
+			exp.Now = "2026-10-01"
+			exp.SetClients(newClients())
+			exp.SetResources(resources)
+			err := exp.SetTimesheets(agendas)
+			Expect(err).Should(BeNil())
+
+			data, err := exp.Export()
+			Expect(err).Should(BeNil())
+			Expect(string(data)).To(Equal(`/* Generated by time-master export tjp */
+
+project time_master "Time Master" 2026-09-01 - 2027-10-30 {
+  now 2026-10-01
+  dailyworkinghours 8
+  timingresolution 15min
+}
+
+resource d_rondina "Daniele Rondina" {
+  email "geaaru@example.com"
+  vacation 2026-10-05 - 2026-10-10
+  vacation 2027-01-01 - 2027-10-30
+}
+
+resource mario "mario" {
+}
+
+resource luigi "luigi" {
+}
+
+task ACME_Inc_ "The 'ACME' company" {
+  task ACT1 "Project 1" {
+    task analysis "Analysis" {
+      task main "Analysis (main)" {
+        effort 16h
+        allocate d_rondina
+        booking luigi 2026-09-02-09:00 +4h { overtime 2 }
+      }
+      task _1st_draft "1st-draft" {
+        effort 3h
+        allocate d_rondina
+        booking d_rondina 2026-09-01-09:00 +2h { overtime 2 }
+      }
+    }
+    task dev "dev" {
+      depends ACME_Inc_.ACT1.analysis
+      effort 8h
+      allocate d_rondina, mario
+    }
+    task release "release" {
+      # depends ACME_Inc_.ACT1.dev (ignored with bookings or milestone date)
+      milestone
+      start 2026-10-30
+    }
+    task support "support" {
+      effort 75min
+      allocate d_rondina
+      complete 100
+      booking d_rondina 2026-09-01-11:00 +75min { overtime 2 }
+    }
+  }
+}
+
+taskreport tasks "tasks" {
+  formats html
+  columns bsi, name, start, end, effort, resources, complete, chart
+}
+
+resourcereport resources "resources" {
+  formats html
+  columns no, name, effort, chart
+}
+`))
+		})
+
+		It("Without tasks", func() {
+			exp := NewTmTjpExporter(config)
+			_, err := exp.Export()
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("Without work hours", func() {
+			exp := NewTmTjpExporter(specs.NewTimeMasterConfig(nil))
+			exp.SetClients(newClients())
+			err := exp.SetTimesheets(agendas)
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal("Invalid work hours"))
+		})
+	})
+})
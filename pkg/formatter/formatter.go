@@ -0,0 +1,509 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package formatter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	log "github.com/geaaru/time-master/pkg/logger"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FILE_CLIENT    = "client"
+	FILE_ACTIVITY  = "activity"
+	FILE_RESOURCE  = "resource"
+	FILE_TIMESHEET = "timesheet"
+	FILE_SCENARIO  = "scenario"
+)
+
+// The canonical order of the keys. The keys not present are
+// appended in the original order.
+var (
+	clientKeys = []string{
+		"name", "description", "activities_dirs", "activities",
+	}
+	activityKeys = []string{
+		"name", "description", "note", "priority", "disabled", "closed",
+		"offer", "time_material", "time_material_daily_offer",
+		"labels", "flags", "tasks", "change_requests",
+	}
+	taskKeys = []string{
+		"name", "description", "note", "priority", "period", "effort",
+		"completed", "milestone", "resources", "depends", "flags", "labels",
+		"recursive", "subtasks",
+	}
+	recursiveKeys = []string{
+		"enable", "mode", "duration", "exclude",
+	}
+	changeRequestKeys = []string{
+		"name", "description", "note", "previous_offer", "offer",
+		"labels", "flags",
+	}
+	resourceKeys = []string{
+		"user", "name", "email", "phone", "holidays", "sick", "unemployed",
+	}
+	periodKeys = []string{
+		"start_period", "end_period",
+	}
+	agendaKeys = []string{
+		"name", "timesheets",
+	}
+	timesheetKeys = []string{
+		"period", "user", "task", "duration", "note", "source", "external_id",
+		"cost", "revenue",
+	}
+	scenarioKeys = []string{
+		"name", "description", "extends", "now", "scheduler", "resources_cost",
+		"rates", "activities_priorities", "task_prorities", "overrides",
+	}
+	overridesKeys = []string{
+		"activities", "tasks", "resources",
+	}
+	taskOverrideKeys = []string{
+		"name", "effort", "completed", "start_period", "resources",
+		"add_resources", "cost", "rate",
+	}
+	resourceOverrideKeys = []string{
+		"user", "name", "available_from", "available_to", "holidays", "sick",
+		"unemployed",
+	}
+	scenarioTaskKeys = []string{
+		"name", "priority", "override_resources",
+	}
+	scenarioRateKeys = []string{
+		"period", "user", "rate", "cost",
+	}
+)
+
+// The keys of the specs that could be removed when empty.
+var optionalKeys = func() map[string]bool {
+	ans := make(map[string]bool, 0)
+	for _, keys := range [][]string{
+		clientKeys, activityKeys, taskKeys, recursiveKeys, changeRequestKeys,
+		resourceKeys, periodKeys, agendaKeys, timesheetKeys, scenarioKeys,
+		overridesKeys, taskOverrideKeys, resourceOverrideKeys, scenarioTaskKeys,
+		scenarioRateKeys,
+	} {
+		for _, k := range keys {
+			ans[k] = true
+		}
+	}
+	return ans
+}()
+
+// The keys with free-form maps where the user values are maintained.
+var freeFormKeys = map[string]bool{
+	"labels": true,
+}
+
+type TmFormatter struct {
+	Logger *log.TmLogger
+	Config *specs.TimeMasterConfig
+}
+
+func NewTmFormatter(config *specs.TimeMasterConfig) *TmFormatter {
+	ans := &TmFormatter{
+		Config: config,
+		Logger: log.NewTmLogger(config),
+	}
+
+	// Initialize logging
+	if config.GetLogging().EnableLogFile && config.GetLogging().Path != "" {
+		err := ans.Logger.InitLogger2File()
+		if err != nil {
+			ans.Logger.Fatal("Error on initialize logfile")
+		}
+	}
+
+	return ans
+}
+
+// DetectFileType returns the type of the file from the keys of the document.
+func DetectFileType(root *yaml.Node) string {
+	if root == nil || root.Kind != yaml.MappingNode {
+		return ""
+	}
+
+	keys := make(map[string]bool, 0)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keys[root.Content[i].Value] = true
+	}
+
+	switch {
+	case keys["activities"] || keys["activities_dirs"]:
+		return FILE_CLIENT
+	case keys["timesheets"]:
+		return FILE_TIMESHEET
+	case keys["user"]:
+		return FILE_RESOURCE
+	case keys["task_prorities"] || keys["activities_priorities"] ||
+		keys["scheduler"] || keys["now"] || keys["rates"] || keys["resources_cost"] ||
+		keys["extends"] || keys["overrides"]:
+		return FILE_SCENARIO
+	case keys["tasks"] || keys["change_requests"] || keys["time_material"] ||
+		keys["offer"] || keys["closed"]:
+		return FILE_ACTIVITY
+	}
+
+	return ""
+}
+
+// FormatFile returns the original and the formatted content of the file.
+// If fileType is empty the type is detected from the content.
+func (f *TmFormatter) FormatFile(file, fileType string) ([]byte, []byte, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ans, err := f.Format(content, fileType)
+	if err != nil {
+		return nil, nil, errors.New(file + ": " + err.Error())
+	}
+
+	return content, ans, nil
+}
+
+func (f *TmFormatter) Format(content []byte, fileType string) ([]byte, error) {
+	var doc yaml.Node
+
+	err := yaml.Unmarshal(content, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		// Empty file
+		return content, nil
+	}
+
+	root := doc.Content[0]
+	if fileType == "" {
+		fileType = DetectFileType(root)
+		if fileType == "" {
+			return nil, errors.New("Unable to detect the type of the file")
+		}
+	}
+
+	// The comment on top of the file is attached to the first key.
+	// I maintain it at the top after the sorting of the keys.
+	headComment := ""
+	if root.Kind == yaml.MappingNode && len(root.Content) > 0 {
+		headComment = root.Content[0].HeadComment
+		root.Content[0].HeadComment = ""
+	}
+
+	removeEmptyFields(root)
+
+	switch fileType {
+	case FILE_CLIENT:
+		err = f.formatClient(root)
+	case FILE_ACTIVITY:
+		err = f.formatActivity(root)
+	case FILE_RESOURCE:
+		err = f.formatResource(root)
+	case FILE_TIMESHEET:
+		err = f.formatAgenda(root)
+	case FILE_SCENARIO:
+		err = f.formatScenario(root)
+	default:
+		err = errors.New("Invalid file type " + fileType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if headComment != "" && len(root.Content) > 0 {
+		if root.Content[0].HeadComment != "" {
+			headComment += "\n" + root.Content[0].HeadComment
+		}
+		root.Content[0].HeadComment = headComment
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	encoder.Close()
+
+	return buf.Bytes(), nil
+}
+
+func (f *TmFormatter) formatClient(n *yaml.Node) error {
+	sortKeys(n, clientKeys)
+	for _, a := range getSequence(n, "activities") {
+		err := f.formatActivity(a)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *TmFormatter) formatActivity(n *yaml.Node) error {
+	sortKeys(n, activityKeys)
+	for _, cr := range getSequence(n, "change_requests") {
+		sortKeys(cr, changeRequestKeys)
+	}
+	return f.formatTasks(getSequence(n, "tasks"))
+}
+
+func (f *TmFormatter) formatTasks(tasks []*yaml.Node) error {
+	for _, t := range tasks {
+		sortKeys(t, taskKeys)
+		sortKeys(getMappingValue(t, "period"), periodKeys)
+
+		err := f.normalizeDuration(getMappingValue(t, "effort"), true)
+		if err != nil {
+			return err
+		}
+
+		if r := getMappingValue(t, "recursive"); r != nil {
+			sortKeys(r, recursiveKeys)
+			err = f.normalizeDuration(getMappingValue(r, "duration"), true)
+			if err != nil {
+				return err
+			}
+			for _, p := range getSequence(r, "exclude") {
+				sortKeys(p, periodKeys)
+			}
+		}
+
+		err = f.formatTasks(getSequence(t, "subtasks"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *TmFormatter) formatResource(n *yaml.Node) error {
+	sortKeys(n, resourceKeys)
+	for _, k := range []string{"holidays", "sick", "unemployed"} {
+		for _, p := range getSequence(n, k) {
+			sortKeys(getMappingValue(p, "period"), periodKeys)
+		}
+	}
+	return nil
+}
+
+func (f *TmFormatter) formatAgenda(n *yaml.Node) error {
+	sortKeys(n, agendaKeys)
+
+	timesheets := getMappingValue(n, "timesheets")
+	if timesheets == nil || timesheets.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	for _, rt := range timesheets.Content {
+		sortKeys(rt, timesheetKeys)
+		sortKeys(getMappingValue(rt, "period"), periodKeys)
+		err := f.normalizeDuration(getMappingValue(rt, "duration"), false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Sort timesheets by date and user.
+	sort.SliceStable(timesheets.Content, func(i, j int) bool {
+		di := getScalar(getMappingValue(timesheets.Content[i], "period"), "start_period")
+		dj := getScalar(getMappingValue(timesheets.Content[j], "period"), "start_period")
+		if di != dj {
+			return di < dj
+		}
+		return getScalar(timesheets.Content[i], "user") < getScalar(timesheets.Content[j], "user")
+	})
+
+	return nil
+}
+
+func (f *TmFormatter) formatScenario(n *yaml.Node) error {
+	sortKeys(n, scenarioKeys)
+	for _, k := range []string{"resources_cost", "rates"} {
+		for _, r := range getSequence(n, k) {
+			sortKeys(r, scenarioRateKeys)
+			sortKeys(getMappingValue(r, "period"), periodKeys)
+		}
+	}
+	for _, k := range []string{"task_prorities", "activities_priorities"} {
+		for _, t := range getSequence(n, k) {
+			sortKeys(t, scenarioTaskKeys)
+		}
+	}
+
+	overrides := getMappingValue(n, "overrides")
+	sortKeys(overrides, overridesKeys)
+	for _, k := range []string{"tasks", "activities"} {
+		for _, o := range getSequence(overrides, k) {
+			sortKeys(o, taskOverrideKeys)
+			err := f.normalizeDuration(getMappingValue(o, "effort"), true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range getSequence(overrides, "resources") {
+		sortKeys(r, resourceOverrideKeys)
+		for _, k := range []string{"holidays", "sick", "unemployed"} {
+			for _, p := range getSequence(r, k) {
+				sortKeys(getMappingValue(p, "period"), periodKeys)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *TmFormatter) normalizeDuration(n *yaml.Node, days bool) error {
+	if n == nil || n.Kind != yaml.ScalarNode || n.Value == "" {
+		return nil
+	}
+
+	secs, err := tmtime.ParseDuration(n.Value, f.Config.GetWork().WorkHours)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Invalid duration %s at line %d: %s",
+			n.Value, n.Line, err.Error()))
+	}
+
+	if secs <= 0 {
+		return nil
+	}
+
+	n.Value, err = tmtime.Seconds2CanonicalDuration(secs,
+		f.Config.GetWork().WorkHours, days)
+	if err != nil {
+		return err
+	}
+	n.Tag = "!!str"
+	n.Style = 0
+
+	return nil
+}
+
+// Reorder the keys of the mapping node with the order defined in keys.
+func sortKeys(n *yaml.Node, keys []string) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return
+	}
+
+	weights := make(map[string]int, len(keys))
+	for idx, k := range keys {
+		weights[k] = idx
+	}
+
+	type pair struct {
+		Key, Value *yaml.Node
+	}
+	pairs := []pair{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		pairs = append(pairs, pair{n.Content[i], n.Content[i+1]})
+	}
+
+	weight := func(p pair) int {
+		if w, ok := weights[p.Key.Value]; ok {
+			return w
+		}
+		return len(keys)
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return weight(pairs[i]) < weight(pairs[j])
+	})
+
+	content := []*yaml.Node{}
+	for _, p := range pairs {
+		content = append(content, p.Key, p.Value)
+	}
+	n.Content = content
+}
+
+// Remove the optional fields of the specs with null values, empty strings
+// or empty collections. The entries of the free-form maps (like labels)
+// and the keys not related to the specs are maintained.
+func removeEmptyFields(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		content := []*yaml.Node{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if _, free := freeFormKeys[k.Value]; !free {
+				removeEmptyFields(v)
+			}
+			if _, ok := optionalKeys[k.Value]; ok && isEmptyNode(v) {
+				continue
+			}
+			content = append(content, k, v)
+		}
+		n.Content = content
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			removeEmptyFields(c)
+		}
+	}
+}
+
+func isEmptyNode(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Tag == "!!null" || (n.Tag == "!!str" && n.Value == "")
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(n.Content) == 0
+	}
+	return false
+}
+
+func getMappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func getSequence(n *yaml.Node, key string) []*yaml.Node {
+	v := getMappingValue(n, key)
+	if v == nil || v.Kind != yaml.SequenceNode {
+		return []*yaml.Node{}
+	}
+	return v.Content
+}
+
+func getScalar(n *yaml.Node, key string) string {
+	v := getMappingValue(n, key)
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return v.Value
+}
@@ -0,0 +1,168 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package formatter_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/geaaru/time-master/pkg/formatter"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Formatter", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+	f := NewTmFormatter(config)
+
+	activity := `# Activity 1
+tasks:
+  - effort: 8h
+    name: task1
+    labels:
+      area: ""
+      team: core
+    note: ""
+    flags: []
+name: ACT1
+description: Activity 1
+labels:
+  customer: ""
+`
+
+	expected := `# Activity 1
+name: ACT1
+description: Activity 1
+labels:
+  customer: ""
+tasks:
+  - name: task1
+    effort: 1d
+    labels:
+      area: ""
+      team: core
+`
+
+	Context("Activity", func() {
+
+		It("Format", func() {
+			ans, err := f.Format([]byte(activity), "")
+			Expect(err).Should(BeNil())
+			Expect(string(ans)).To(Equal(expected))
+		})
+
+		It("Idempotence", func() {
+			ans, err := f.Format([]byte(expected), FILE_ACTIVITY)
+			Expect(err).Should(BeNil())
+			Expect(string(ans)).To(Equal(expected))
+		})
+
+		It("Effort of 8h with work hours 8 is 1d", func() {
+			ans, err := f.Format([]byte("name: ACT1\ntasks:\n  - name: t1\n    effort: 8h\n  - name: t2\n    effort: 12h\n"),
+				FILE_ACTIVITY)
+			Expect(err).Should(BeNil())
+			Expect(string(ans)).To(Equal("name: ACT1\ntasks:\n  - name: t1\n    effort: 1d\n  - name: t2\n    effort: 1.5d\n"))
+		})
+
+		It("Invalid effort", func() {
+			_, err := f.Format([]byte("name: ACT1\ntasks:\n  - name: t1\n    effort: xx\n"),
+				FILE_ACTIVITY)
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	Context("Timesheets", func() {
+
+		It("Sorted by date and user and durations in hours", func() {
+			agenda := `name: agenda1
+timesheets:
+  - user: user2
+    period:
+      start_period: "2021-01-02"
+    duration: 1d
+    task: ACT1.task1
+  - user: user1
+    period:
+      start_period: "2021-01-02"
+    duration: 4h
+    task: ACT1.task1
+`
+			ans, err := f.Format([]byte(agenda), "")
+			Expect(err).Should(BeNil())
+			Expect(string(ans)).To(Equal(`name: agenda1
+timesheets:
+  - period:
+      start_period: "2021-01-02"
+    user: user1
+    task: ACT1.task1
+    duration: 4h
+  - period:
+      start_period: "2021-01-02"
+    user: user2
+    task: ACT1.task1
+    duration: 8h
+`))
+		})
+	})
+
+	Context("Check", func() {
+
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "tm-formatter")
+			Expect(err).Should(BeNil())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("Formatted and not formatted files", func() {
+			formatted := filepath.Join(dir, "formatted.yml")
+			unformatted := filepath.Join(dir, "unformatted.yml")
+			Expect(ioutil.WriteFile(formatted, []byte(expected), 0644)).Should(BeNil())
+			Expect(ioutil.WriteFile(unformatted, []byte(activity), 0644)).Should(BeNil())
+
+			original, content, err := f.FormatFile(formatted, "")
+			Expect(err).Should(BeNil())
+			Expect(string(content)).To(Equal(string(original)))
+
+			original, content, err = f.FormatFile(unformatted, "")
+			Expect(err).Should(BeNil())
+			Expect(string(original)).To(Equal(activity))
+			Expect(string(content)).To(Equal(expected))
+		})
+
+		It("Unknown type", func() {
+			file := filepath.Join(dir, "unknown.yml")
+			Expect(ioutil.WriteFile(file, []byte("foo: bar\n"), 0644)).Should(BeNil())
+
+			_, _, err := f.FormatFile(file, "")
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+})
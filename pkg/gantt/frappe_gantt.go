@@ -21,6 +21,7 @@ package gantt
 
 import (
 	"encoding/json"
+	"errors"
 	"sort"
 	"strings"
 
@@ -40,6 +41,19 @@ type FrappeGanttTask struct {
 	CustomClass  string  `json:"custom_class,omitempty"`
 	StartTime    int64   `json:"-"`
 	EndTime      int64   `json:"-"`
+
+	// Additional data used by the HTML report and other front ends.
+	Client    string   `json:"client,omitempty"`
+	Activity  string   `json:"activity,omitempty"`
+	Resources []string `json:"resources,omitempty"`
+	Effort    string   `json:"effort,omitempty"`
+	WorkTime  string   `json:"work_time,omitempty"`
+
+	// Hierarchy of the tasks: id of the parent bar, level of nesting
+	// and type of the bar (client, activity or task).
+	Parent string `json:"parent,omitempty"`
+	Level  int    `json:"level,omitempty"`
+	Type   string `json:"type,omitempty"`
 }
 
 type FGTaskSorter []FrappeGanttTask
@@ -56,8 +70,25 @@ func NewFrappeGanttProducer(config *specs.TimeMasterConfig) *FrappeGanttProducer
 }
 
 func (f *FrappeGanttProducer) Build(s *specs.ScenarioSchedule, opts ProducerOpts) ([]byte, error) {
+	tasks, err := f.GetTasks(s, opts)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return json.Marshal(tasks)
+}
+
+// GetTasks returns the tasks of the schedule sorted by start
+// or end time. With the hierarchical option the tasks are nested
+// under the summary bars of their clients and activities.
+func (f *FrappeGanttProducer) GetTasks(s *specs.ScenarioSchedule, opts ProducerOpts) ([]FrappeGanttTask, error) {
+	if opts.ByResource {
+		// Frappe Gantt draws a row for every bar.
+		return nil, errors.New("The gantt by resource is available only with the svg format")
+	}
+
 	tasks := []FrappeGanttTask{}
-	ans := []byte{}
+	ans := tasks
 
 	for _, ts := range s.Schedule {
 
@@ -78,6 +109,20 @@ func (f *FrappeGanttProducer) Build(s *specs.ScenarioSchedule, opts ProducerOpts
 			End:       ts.Period.EndPeriod,
 			StartTime: startTime.Unix(),
 			EndTime:   endTime.Unix(),
+			Activity:  GetTaskActivity(&ts),
+			Resources: getTaskResources(&ts),
+			Effort:    ts.Task.Effort,
+		}
+
+		if ts.Client != nil {
+			ft.Client = ts.Client.Name
+		}
+
+		if ts.WorkTime > 0 {
+			ft.WorkTime, err = time.Seconds2Duration(ts.WorkTime)
+			if err != nil {
+				return ans, err
+			}
 		}
 
 		words := strings.Split(ts.Name, ".")
@@ -88,6 +133,10 @@ func (f *FrappeGanttProducer) Build(s *specs.ScenarioSchedule, opts ProducerOpts
 			ft.Name = ts.Description
 		}
 
+		if ft.Name == "" {
+			ft.Name = ts.Name
+		}
+
 		if ts.Task.Milestone != "" {
 			ft.CustomClass = "bar-milestone"
 			ft.Name = words[0] + " - " + ts.Description
@@ -112,7 +161,34 @@ func (f *FrappeGanttProducer) Build(s *specs.ScenarioSchedule, opts ProducerOpts
 		sort.Sort(FGTaskSorter(tasks))
 	}
 
-	return json.Marshal(tasks)
+	if opts.Hierarchical {
+		return GetTasksHierarchy(tasks, opts), nil
+	}
+
+	return tasks, nil
+}
+
+// Return the allocated resources and the users of the timesheets
+// of the task.
+func getTaskResources(ts *specs.TaskScheduled) []string {
+	ans := []string{}
+	m := make(map[string]bool, 0)
+
+	add := func(u string) {
+		if _, ok := m[u]; !ok {
+			m[u] = true
+			ans = append(ans, u)
+		}
+	}
+
+	for _, r := range ts.Task.AllocatedResource {
+		add(r)
+	}
+	for _, rt := range ts.Timesheets {
+		add(rt.User)
+	}
+
+	return ans
 }
 
 func (t FGTaskSorter) Len() int      { return len(t) }
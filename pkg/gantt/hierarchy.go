@@ -0,0 +1,215 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	GANTT_ITEM_CLIENT   = "client"
+	GANTT_ITEM_ACTIVITY = "activity"
+	GANTT_ITEM_TASK     = "task"
+)
+
+type ganttNode struct {
+	Task     FrappeGanttTask
+	Children []*ganttNode
+}
+
+// GetTasksHierarchy returns the tasks in depth-first order with the
+// summary bars of the clients and of the activities. The subtasks are
+// nested under the parent tasks. The summary bars span the children
+// and their progress is the average of the progress of the children
+// weighted by duration.
+func GetTasksHierarchy(tasks []FrappeGanttTask, opts ProducerOpts) []FrappeGanttTask {
+	ans := []FrappeGanttTask{}
+	roots := []*ganttNode{}
+	nodes := make(map[string]*ganttNode, 0)
+
+	for _, t := range tasks {
+		nodes[t.Id] = &ganttNode{Task: t}
+	}
+
+	getSummary := func(id, name, t string) (*ganttNode, bool) {
+		n, ok := nodes[id]
+		if !ok {
+			n = &ganttNode{
+				Task: FrappeGanttTask{
+					Id:          id,
+					Name:        name,
+					Type:        t,
+					CustomClass: "bar-summary bar-" + t,
+				},
+			}
+			nodes[id] = n
+		}
+		return n, !ok
+	}
+
+	for _, t := range tasks {
+		node := nodes[t.Id]
+		node.Task.Type = GANTT_ITEM_TASK
+
+		parent := getParentTask(t.Id, nodes)
+		if parent != nil {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+
+		if t.Activity == "" {
+			roots = append(roots, node)
+			continue
+		}
+
+		activity, isNew := getSummary(GANTT_ITEM_ACTIVITY+":"+t.Activity,
+			t.Activity, GANTT_ITEM_ACTIVITY)
+		activity.Children = append(activity.Children, node)
+		if !isNew {
+			continue
+		}
+		activity.Task.Activity = t.Activity
+		activity.Task.Client = t.Client
+
+		if t.Client == "" {
+			roots = append(roots, activity)
+			continue
+		}
+
+		client, isNew := getSummary(GANTT_ITEM_CLIENT+":"+t.Client,
+			t.Client, GANTT_ITEM_CLIENT)
+		client.Children = append(client.Children, activity)
+		client.Task.Client = t.Client
+		if isNew {
+			roots = append(roots, client)
+		}
+	}
+
+	for _, n := range roots {
+		n.updateSummary()
+	}
+
+	var visit func(n *ganttNode, parent string, level int)
+	visit = func(n *ganttNode, parent string, level int) {
+		n.Task.Parent = parent
+		n.Task.Level = level
+		if len(n.Children) > 0 && n.Task.Type == GANTT_ITEM_TASK &&
+			n.Task.CustomClass == "" {
+			n.Task.CustomClass = "bar-parent"
+		}
+		ans = append(ans, n.Task)
+
+		sortGanttNodes(n.Children, opts.OrderByEndTime)
+		for _, c := range n.Children {
+			visit(c, n.Task.Id, level+1)
+		}
+	}
+
+	sortGanttNodes(roots, opts.OrderByEndTime)
+	for _, n := range roots {
+		visit(n, "", 0)
+	}
+
+	return ans
+}
+
+// Return the node of the nearest parent task available
+// between the tasks. The name of a subtask is the name of the
+// parent task followed by a dot and the name of the subtask.
+func getParentTask(name string, nodes map[string]*ganttNode) *ganttNode {
+	words := strings.Split(name, ".")
+	for i := len(words) - 1; i > 1; i-- {
+		if n, ok := nodes[strings.Join(words[0:i], ".")]; ok {
+			return n
+		}
+	}
+	return nil
+}
+
+// Update the period and the progress of the summary bars
+// from all the descendants. The progress is the average of the
+// progress of the tasks without subtasks weighted by duration.
+func (n *ganttNode) updateSummary() {
+	for _, c := range n.Children {
+		c.updateSummary()
+	}
+
+	if n.Task.Type == GANTT_ITEM_TASK || len(n.Children) == 0 {
+		return
+	}
+
+	resources := make(map[string]bool, 0)
+	weights := 0.0
+	progress := 0.0
+
+	for _, d := range n.getDescendants() {
+		if n.Task.Start == "" || d.Task.StartTime < n.Task.StartTime {
+			n.Task.Start = d.Task.Start
+			n.Task.StartTime = d.Task.StartTime
+		}
+		if n.Task.End == "" || d.Task.EndTime > n.Task.EndTime {
+			n.Task.End = d.Task.End
+			n.Task.EndTime = d.Task.EndTime
+		}
+
+		if d.Task.Type != GANTT_ITEM_TASK {
+			continue
+		}
+
+		for _, r := range d.Task.Resources {
+			if _, ok := resources[r]; !ok {
+				resources[r] = true
+				n.Task.Resources = append(n.Task.Resources, r)
+			}
+		}
+
+		if len(d.Children) == 0 && d.Task.CustomClass != "bar-milestone" {
+			// The end date is inclusive.
+			w := float64(d.Task.EndTime-d.Task.StartTime)/86400 + 1
+			weights += w
+			progress += d.Task.Progress * w
+		}
+	}
+
+	if weights > 0 {
+		n.Task.Progress = math.Round(progress/weights*100) / 100
+	}
+}
+
+// Return all the descendants of the node.
+func (n *ganttNode) getDescendants() []*ganttNode {
+	ans := []*ganttNode{}
+	for _, c := range n.Children {
+		ans = append(ans, c)
+		ans = append(ans, c.getDescendants()...)
+	}
+	return ans
+}
+
+func sortGanttNodes(nodes []*ganttNode, byEndTime bool) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if byEndTime {
+			return nodes[i].Task.EndTime < nodes[j].Task.EndTime
+		}
+		return nodes[i].Task.StartTime < nodes[j].Task.StartTime
+	})
+}
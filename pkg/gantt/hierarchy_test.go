@@ -0,0 +1,122 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt_test
+
+import (
+	"encoding/json"
+
+	. "github.com/geaaru/time-master/pkg/gantt"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hierarchical Gantt Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+
+	schedule := &specs.ScenarioSchedule{
+		Scenario: &specs.Scenario{Name: "Plan"},
+		Schedule: []specs.TaskScheduled{
+			newTaskScheduled("ACT2.dev", "Development", "2026-09-07", "2026-09-16", 0),
+			newTaskScheduled("ACT2.dev.backend", "Backend", "2026-09-07", "2026-09-11", 100),
+			newTaskScheduled("ACT2.dev.frontend", "Frontend", "2026-09-14", "2026-09-16", 0),
+			newTaskScheduled("ACT1.analysis", "Analysis", "2026-09-01", "2026-09-04", 50),
+			newTaskScheduled("ACT2.release", "Release", "2026-09-18", "2026-09-18", 0),
+			newTaskScheduled("ACT3.support", "Support", "2026-09-02", "2026-09-03", 0),
+		},
+	}
+	for idx := 0; idx < 5; idx++ {
+		schedule.Schedule[idx].Client = &specs.Client{Name: "CLIENT1"}
+	}
+	schedule.Schedule[1].Task.AllocatedResource = []string{"geaaru"}
+	schedule.Schedule[4].Task.Milestone = "2026-09-18"
+
+	Context("Frappe", func() {
+
+		It("Summary bars and subtasks", func() {
+			producer := NewFrappeGanttProducer(config)
+			tasks, err := producer.GetTasks(schedule, ProducerOpts{Hierarchical: true})
+			Expect(err).Should(BeNil())
+
+			rows := []string{}
+			for _, t := range tasks {
+				rows = append(rows, t.Id+"|"+t.Parent+"|"+t.Type)
+			}
+			Expect(rows).To(Equal([]string{
+				"client:CLIENT1||client",
+				"activity:ACT1|client:CLIENT1|activity",
+				"ACT1.analysis|activity:ACT1|task",
+				"activity:ACT2|client:CLIENT1|activity",
+				"ACT2.dev|activity:ACT2|task",
+				"ACT2.dev.backend|ACT2.dev|task",
+				"ACT2.dev.frontend|ACT2.dev|task",
+				"ACT2.release|activity:ACT2|task",
+				"activity:ACT3||activity",
+				"ACT3.support|activity:ACT3|task",
+			}))
+
+			Expect(tasks[0].Start).To(Equal("2026-09-01"))
+			Expect(tasks[0].End).To(Equal("2026-09-18"))
+			Expect(tasks[0].CustomClass).To(Equal("bar-summary bar-client"))
+			Expect(tasks[0].Resources).To(Equal([]string{"geaaru"}))
+			Expect(tasks[3].Start).To(Equal("2026-09-07"))
+			Expect(tasks[3].End).To(Equal("2026-09-18"))
+			Expect(tasks[3].Level).To(Equal(1))
+			Expect(tasks[5].Level).To(Equal(3))
+
+			// The parent task keeps the period and the progress of the schedule.
+			Expect(tasks[4].CustomClass).To(Equal("bar-parent"))
+			Expect(tasks[4].End).To(Equal("2026-09-16"))
+			Expect(tasks[4].Progress).To(Equal(0.0))
+
+			// Backend (5 days) at 100% and Frontend (3 days) at 0%.
+			// The parent task and the milestone are excluded.
+			Expect(tasks[3].Progress).To(Equal(62.5))
+			// Analysis (4 days) at 50% and the subtasks of ACT2.
+			Expect(tasks[0].Progress).To(Equal(58.33))
+		})
+
+		It("Json output", func() {
+			producer := NewFrappeGanttProducer(config)
+			data, err := producer.Build(schedule, ProducerOpts{Hierarchical: true})
+			Expect(err).Should(BeNil())
+
+			tasks := []map[string]interface{}{}
+			err = json.Unmarshal(data, &tasks)
+			Expect(err).Should(BeNil())
+			Expect(tasks[5]["parent"]).To(Equal("ACT2.dev"))
+			Expect(tasks[5]["level"]).To(Equal(3.0))
+			Expect(tasks[5]["type"]).To(Equal("task"))
+			_, ok := tasks[0]["parent"]
+			Expect(ok).To(Equal(false))
+		})
+
+		It("Flat output", func() {
+			producer := NewFrappeGanttProducer(config)
+			tasks, err := producer.GetTasks(schedule, ProducerOpts{})
+			Expect(err).Should(BeNil())
+			Expect(len(tasks)).To(Equal(6))
+			Expect(tasks[0].Parent).To(Equal(""))
+			Expect(tasks[0].Type).To(Equal(""))
+		})
+	})
+})
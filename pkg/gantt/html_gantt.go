@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"html/template"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+)
+
+//go:embed assets/gantt.html assets/frappe
+var assets embed.FS
+
+const (
+	// Vendored files of Frappe Gantt (see assets/frappe/README.md).
+	frappeJsFile      = "assets/frappe/frappe-gantt.min.js"
+	frappeCssFile     = "assets/frappe/frappe-gantt.css"
+	frappeLicenseFile = "assets/frappe/LICENSE"
+)
+
+// HtmlGanttProducer produces a self-contained HTML page with the gantt
+// of the Frappe tasks. The page embeds the vendored Frappe Gantt files
+// or the Frappe Gantt files of ProducerOpts.AssetsDir.
+type HtmlGanttProducer struct {
+	*FrappeGanttProducer
+}
+
+type htmlGanttPage struct {
+	Title    string
+	ViewMode string
+	Css      template.CSS
+	Js       template.JS
+	Tasks    []FrappeGanttTask
+}
+
+func NewHtmlGanttProducer(config *specs.TimeMasterConfig) *HtmlGanttProducer {
+	return &HtmlGanttProducer{
+		FrappeGanttProducer: NewFrappeGanttProducer(config),
+	}
+}
+
+// HasFrappeAssets returns true if the Frappe Gantt files are
+// vendored and embedded in the binary.
+func HasFrappeAssets() bool {
+	for _, f := range []string{frappeJsFile, frappeCssFile, frappeLicenseFile} {
+		if _, err := fs.Stat(assets, f); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Return the javascript and the css to embed in the page.
+func (h *HtmlGanttProducer) getAssets(opts ProducerOpts) ([]byte, []byte, error) {
+	var js, css []byte
+	var err error
+
+	if opts.AssetsDir != "" {
+		js, err = ioutil.ReadFile(filepath.Join(opts.AssetsDir, "frappe-gantt.js"))
+		if err != nil {
+			return nil, nil, err
+		}
+		css, err = ioutil.ReadFile(filepath.Join(opts.AssetsDir, "frappe-gantt.css"))
+		if err != nil {
+			return nil, nil, err
+		}
+		return js, css, nil
+	}
+
+	if !HasFrappeAssets() {
+		return nil, nil, errors.New(
+			"Frappe Gantt files not embedded. Vendor them with 'make vendor-frappe-gantt' or use --frappe-dir")
+	}
+
+	js, err = assets.ReadFile(frappeJsFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	css, err = assets.ReadFile(frappeCssFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	license, err := assets.ReadFile(frappeLicenseFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The MIT license requires the notice in the copies.
+	js = append([]byte("/*! Frappe Gantt\n"+
+		strings.ReplaceAll(string(license), "*/", "* /")+"*/\n"), js...)
+
+	return js, css, nil
+}
+
+func (h *HtmlGanttProducer) Build(s *specs.ScenarioSchedule, opts ProducerOpts) ([]byte, error) {
+	tasks, err := h.GetTasks(s, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	js, css, err := h.getAssets(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := assets.ReadFile("assets/gantt.html")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("gantt").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	page := htmlGanttPage{
+		Title:    "Time Master Gantt",
+		ViewMode: "Week",
+		Css:      template.CSS(css),
+		Js:       template.JS(js),
+		Tasks:    tasks,
+	}
+	if s.Scenario != nil && s.Scenario.Name != "" {
+		page.Title = "Time Master Gantt - " + s.Scenario.Name
+	}
+	if opts.ViewMode != "" {
+		page.ViewMode = opts.ViewMode
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/geaaru/time-master/pkg/gantt"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTML Gantt Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+
+	schedule := &specs.ScenarioSchedule{
+		Scenario: &specs.Scenario{Name: "Plan"},
+		Schedule: []specs.TaskScheduled{
+			newTaskScheduled("ACT1.analysis", "Analysis </script>", "2026-09-01", "2026-09-04", 50),
+		},
+	}
+	schedule.Schedule[0].Task.Effort = "4d"
+	schedule.Schedule[0].Task.AllocatedResource = []string{"geaaru"}
+	schedule.Schedule[0].WorkTime = 8 * 3600
+	schedule.Schedule[0].Client = &specs.Client{Name: "CLIENT1"}
+
+	Context("Build", func() {
+
+		It("Self-contained page", func() {
+			if !HasFrappeAssets() {
+				Skip("Frappe Gantt files not vendored")
+			}
+			producer, err := NewProducer(config, "html")
+			Expect(err).Should(BeNil())
+
+			data, err := producer.Build(schedule, ProducerOpts{ViewMode: "Month"})
+			Expect(err).Should(BeNil())
+
+			page := string(data)
+			Expect(page).To(ContainSubstring("<title>Time Master Gantt - Plan</title>"))
+			Expect(page).To(ContainSubstring("/*! Frappe Gantt"))
+			Expect(page).To(ContainSubstring("MIT License"))
+			Expect(page).To(ContainSubstring(".gantt"))
+		})
+
+		It("Missing Frappe Gantt files", func() {
+			if HasFrappeAssets() {
+				Skip("Frappe Gantt files vendored")
+			}
+			producer := NewHtmlGanttProducer(config)
+			_, err := producer.Build(schedule, ProducerOpts{})
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("make vendor-frappe-gantt"))
+		})
+
+		It("Frappe Gantt files", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-gantt")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			err = ioutil.WriteFile(filepath.Join(tmpDir, "frappe-gantt.js"), []byte("var FrappeGantt = 1;"), 0644)
+			Expect(err).Should(BeNil())
+			err = ioutil.WriteFile(filepath.Join(tmpDir, "frappe-gantt.css"), []byte(".gantt { color: red; }"), 0644)
+			Expect(err).Should(BeNil())
+
+			producer := NewHtmlGanttProducer(config)
+			data, err := producer.Build(schedule, ProducerOpts{ViewMode: "Month", AssetsDir: tmpDir})
+			Expect(err).Should(BeNil())
+
+			page := string(data)
+			Expect(page).To(ContainSubstring("<title>Time Master Gantt - Plan</title>"))
+			Expect(page).To(ContainSubstring("var FrappeGantt = 1;"))
+			Expect(page).To(ContainSubstring(".gantt { color: red; }"))
+			Expect(page).To(ContainSubstring(`var viewMode = "Month";`))
+			Expect(page).To(ContainSubstring(`"client":"CLIENT1","activity":"ACT1","resources":["geaaru"],"effort":"4d","work_time":"8h"`))
+			Expect(page).ToNot(ContainSubstring("Analysis </script>"))
+		})
+	})
+})
@@ -0,0 +1,136 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	time "github.com/geaaru/time-master/pkg/time"
+)
+
+// MermaidGanttProducer produces the gantt in the Mermaid format:
+// https://mermaid.js.org/syntax/gantt.html
+type MermaidGanttProducer struct {
+	*DefaultGanttProducer
+}
+
+var mermaidReplacer = strings.NewReplacer(":", " ", "#", "", ";", ",", "\n", " ")
+
+func NewMermaidGanttProducer(config *specs.TimeMasterConfig) *MermaidGanttProducer {
+	return &MermaidGanttProducer{
+		DefaultGanttProducer: newDefaultGanttProducer(config),
+	}
+}
+
+func (m *MermaidGanttProducer) Build(s *specs.ScenarioSchedule, opts ProducerOpts) ([]byte, error) {
+	var buf bytes.Buffer
+
+	sections := GetGanttSections(s, opts)
+
+	// The dependencies are available only for the tasks in the gantt.
+	// The end dates of mermaid are exclusive.
+	ends := make(map[string]string, 0)
+	for _, section := range sections {
+		for _, ts := range section.Tasks {
+			if ts.Task.Milestone != "" {
+				ends[ts.Task.Name] = getEndPeriod(ts)
+				continue
+			}
+			end, err := m.getEnd(ts)
+			if err != nil {
+				return nil, err
+			}
+			ends[ts.Task.Name] = end
+		}
+	}
+
+	buf.WriteString("gantt\n")
+	if s.Scenario != nil && s.Scenario.Name != "" {
+		buf.WriteString("    title " + mermaidReplacer.Replace(s.Scenario.Name) + "\n")
+	}
+	buf.WriteString("    dateFormat YYYY-MM-DD\n")
+	buf.WriteString("    axisFormat %d/%m\n")
+	if !opts.ShowWeekends {
+		buf.WriteString("    excludes weekends\n")
+	}
+
+	for _, section := range sections {
+		buf.WriteString("\n    section " + mermaidReplacer.Replace(section.Name) + "\n")
+
+		for _, ts := range section.Tasks {
+			name := getTaskLabel(ts, opts, mermaidReplacer)
+
+			if ts.Task.Milestone != "" {
+				buf.WriteString(fmt.Sprintf("    %s :milestone, %s, %s, 0d\n",
+					name, getTaskId(ts.Task.Name), getEndPeriod(ts)))
+				continue
+			}
+
+			tags := []string{}
+			progress := GetTaskProgress(ts)
+			if progress >= 100 {
+				tags = append(tags, "done")
+			} else if progress > 0 {
+				tags = append(tags, "active")
+			}
+			if ts.Underestimated {
+				tags = append(tags, "crit")
+			}
+			tags = append(tags, getTaskId(ts.Task.Name))
+
+			// Mermaid defines the dependencies through the start of the task
+			// after the end of the dependencies. It's used only when the
+			// task starts at the latest end of the dependencies, otherwise
+			// the scheduled start is maintained.
+			start := ts.Period.StartPeriod
+			deps := []string{}
+			depsEnd := ""
+			for _, dep := range ts.Task.Depends {
+				if end, ok := ends[dep]; ok {
+					deps = append(deps, getTaskId(dep))
+					if end > depsEnd {
+						depsEnd = end
+					}
+				}
+			}
+			if len(deps) > 0 && len(start) >= 10 && depsEnd == start[0:10] {
+				start = "after " + strings.Join(deps, " ")
+			}
+
+			buf.WriteString(fmt.Sprintf("    %s :%s, %s, %s\n",
+				name, strings.Join(tags, ", "), start, ends[ts.Task.Name]))
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Return the exclusive end date of the task.
+func (m *MermaidGanttProducer) getEnd(ts *specs.TaskScheduled) (string, error) {
+	endTime, err := time.ParseTimestamp(getEndPeriod(ts), true)
+	if err != nil {
+		m.Logger.Error("Error on on parse end date of task ", ts.Task.Name)
+		return "", err
+	}
+	return endTime.AddDate(0, 0, 1).Format("2006-01-02"), nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+)
+
+// PlantUMLGanttProducer produces the gantt in the PlantUML format:
+// https://plantuml.com/gantt-diagram
+type PlantUMLGanttProducer struct {
+	*DefaultGanttProducer
+}
+
+var plantumlReplacer = strings.NewReplacer("[", "(", "]", ")", "\n", " ")
+
+func NewPlantUMLGanttProducer(config *specs.TimeMasterConfig) *PlantUMLGanttProducer {
+	return &PlantUMLGanttProducer{
+		DefaultGanttProducer: newDefaultGanttProducer(config),
+	}
+}
+
+func (p *PlantUMLGanttProducer) Build(s *specs.ScenarioSchedule, opts ProducerOpts) ([]byte, error) {
+	var buf bytes.Buffer
+
+	sections := GetGanttSections(s, opts)
+
+	projectStart := ""
+	ids := make(map[string]bool, 0)
+	for _, section := range sections {
+		for _, ts := range section.Tasks {
+			ids[ts.Task.Name] = true
+			if projectStart == "" || ts.Period.StartPeriod < projectStart {
+				projectStart = ts.Period.StartPeriod
+			}
+		}
+	}
+
+	buf.WriteString("@startgantt\n")
+	if s.Scenario != nil && s.Scenario.Name != "" {
+		buf.WriteString("title " + strings.ReplaceAll(s.Scenario.Name, "\n", " ") + "\n")
+	}
+	if projectStart != "" {
+		buf.WriteString("Project starts " + projectStart + "\n")
+	}
+	if !opts.ShowWeekends {
+		buf.WriteString("saturday are closed\n")
+		buf.WriteString("sunday are closed\n")
+	}
+
+	deps := []string{}
+
+	for _, section := range sections {
+		buf.WriteString("\n-- " + plantumlReplacer.Replace(section.Name) + " --\n")
+
+		for _, ts := range section.Tasks {
+			name := getTaskLabel(ts, opts, plantumlReplacer)
+			id := getTaskId(ts.Task.Name)
+
+			if ts.Task.Milestone != "" {
+				buf.WriteString(fmt.Sprintf("[%s] as [%s] happens %s\n",
+					name, id, getEndPeriod(ts)))
+			} else {
+				buf.WriteString(fmt.Sprintf("[%s] as [%s] starts %s\n",
+					name, id, ts.Period.StartPeriod))
+				buf.WriteString(fmt.Sprintf("[%s] ends %s\n", id, getEndPeriod(ts)))
+
+				progress := GetTaskProgress(ts)
+				if progress > 0 {
+					buf.WriteString(fmt.Sprintf("[%s] is %d%% completed\n", id, int(progress)))
+				}
+				if ts.Underestimated {
+					buf.WriteString(fmt.Sprintf("[%s] is colored in Salmon\n", id))
+				} else if progress > 0 && progress < 100 {
+					// Active task
+					buf.WriteString(fmt.Sprintf("[%s] is colored in LightSkyBlue\n", id))
+				}
+			}
+
+			for _, dep := range ts.Task.Depends {
+				if ids[dep] {
+					deps = append(deps, fmt.Sprintf("[%s] -> [%s]\n", getTaskId(dep), id))
+				}
+			}
+		}
+	}
+
+	if len(deps) > 0 {
+		buf.WriteString("\n")
+		for _, d := range deps {
+			buf.WriteString(d)
+		}
+	}
+
+	buf.WriteString("@endgantt\n")
+
+	return buf.Bytes(), nil
+}
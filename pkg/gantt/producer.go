@@ -38,6 +38,28 @@ type DefaultGanttProducer struct {
 type ProducerOpts struct {
 	ShowActivityOnTasks bool
 	OrderByEndTime      bool
+	// Show the weekends on the text formats (mermaid, plantuml).
+	ShowWeekends bool
+
+	// Initial view mode of the HTML page: Day, Week (default) or Month.
+	ViewMode string
+	// Directory with the Frappe Gantt files frappe-gantt.js and
+	// frappe-gantt.css embedded in the HTML page instead of the
+	// vendored ones.
+	AssetsDir string
+
+	// Holidays shaded on the svg gantt.
+	Holidays []specs.Period
+
+	// Produce a row for every resource with the timesheets of the
+	// prevision instead of a row for every task.
+	ByResource bool
+	// Resources used on the gantt by resource.
+	Resources []specs.Resource
+
+	// Group the tasks under summary bars of clients and activities
+	// and nest the subtasks under the parent tasks.
+	Hierarchical bool
 }
 
 func NewProducer(config *specs.TimeMasterConfig, t string) (TimeMasterGanttProducer, error) {
@@ -45,6 +67,14 @@ func NewProducer(config *specs.TimeMasterConfig, t string) (TimeMasterGanttProdu
 	switch t {
 	case "frappe":
 		ans = NewFrappeGanttProducer(config)
+	case "mermaid":
+		ans = NewMermaidGanttProducer(config)
+	case "plantuml":
+		ans = NewPlantUMLGanttProducer(config)
+	case "html":
+		ans = NewHtmlGanttProducer(config)
+	case "svg":
+		ans = NewSvgGanttProducer(config)
 	default:
 		return ans, errors.New("Invalid producer type")
 	}
@@ -0,0 +1,292 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt
+
+import (
+	"fmt"
+	"sort"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	time "github.com/geaaru/time-master/pkg/time"
+)
+
+const (
+	ABSENCE_HOLIDAYS   = "holidays"
+	ABSENCE_SICK       = "sick"
+	ABSENCE_UNEMPLOYED = "unemployed"
+)
+
+// ResourceDay contains the time of a resource on a task for one day.
+type ResourceDay struct {
+	Date     string
+	Task     string
+	Activity string
+	Seconds  int64
+	// The day is after the now date of the scenario.
+	Planned bool
+}
+
+// ResourceBar contains the consecutive work days of a resource
+// on a task.
+type ResourceBar struct {
+	Task     string
+	Activity string
+	Start    string
+	End      string
+	Seconds  int64
+	Planned  bool
+}
+
+type ResourceAbsence struct {
+	Type   string
+	Period specs.Period
+}
+
+// ResourceLoad contains the timesheets and the absences of a resource
+// used by the gantt by resource.
+type ResourceLoad struct {
+	User      string
+	Name      string
+	Days      []ResourceDay
+	Absences  []ResourceAbsence
+	Overloads []string
+}
+
+// GetResourcesLoad returns the load of the resources of the
+// schedule. The resources of ProducerOpts are used for the name
+// and the absences. The resources without timesheets are included.
+func GetResourcesLoad(s *specs.ScenarioSchedule, opts ProducerOpts, workHours int) ([]ResourceLoad, error) {
+	ans := []ResourceLoad{}
+	mLoads := make(map[string]*ResourceLoad, 0)
+	users := []string{}
+
+	resources := opts.Resources
+	if s.Scenario != nil {
+		var err error
+		resources, err = s.Scenario.ApplyResourcesOverrides(opts.Resources)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for idx := range resources {
+		r := &resources[idx]
+		if _, ok := mLoads[r.User]; ok {
+			continue
+		}
+		l := &ResourceLoad{
+			User:      r.User,
+			Name:      r.Name,
+			Days:      []ResourceDay{},
+			Absences:  getResourceAbsences(r),
+			Overloads: []string{},
+		}
+		mLoads[r.User] = l
+		users = append(users, r.User)
+	}
+
+	now := ""
+	if s.Scenario != nil {
+		now = s.Scenario.NowTime
+		if len(now) > 10 {
+			now = now[0:10]
+		}
+	}
+
+	// Aggregate the timesheets by user, day and task.
+	mDays := make(map[string]int, 0)
+	for idx := range s.Schedule {
+		ts := &s.Schedule[idx]
+		for _, rt := range ts.Timesheets {
+			l, ok := mLoads[rt.User]
+			if !ok {
+				l = &ResourceLoad{
+					User:      rt.User,
+					Name:      rt.User,
+					Days:      []ResourceDay{},
+					Absences:  []ResourceAbsence{},
+					Overloads: []string{},
+				}
+				mLoads[rt.User] = l
+				users = append(users, rt.User)
+			}
+
+			secs, err := time.ParseDuration(rt.Duration, workHours)
+			if err != nil {
+				return nil, err
+			}
+
+			key := fmt.Sprintf("%s|%s|%s", rt.User, rt.Period.StartPeriod, rt.Task)
+			if idx, ok := mDays[key]; ok {
+				l.Days[idx].Seconds += secs
+				continue
+			}
+
+			l.Days = append(l.Days, ResourceDay{
+				Date:     rt.Period.StartPeriod,
+				Task:     rt.Task,
+				Activity: GetTaskActivity(ts),
+				Seconds:  secs,
+				Planned:  now != "" && rt.Period.StartPeriod >= now,
+			})
+			mDays[key] = len(l.Days) - 1
+		}
+	}
+
+	for _, u := range users {
+		l := mLoads[u]
+
+		sort.SliceStable(l.Days, func(i, j int) bool {
+			if l.Days[i].Date != l.Days[j].Date {
+				return l.Days[i].Date < l.Days[j].Date
+			}
+			return l.Days[i].Task < l.Days[j].Task
+		})
+
+		daySecs := make(map[string]int64, 0)
+		for _, d := range l.Days {
+			daySecs[d.Date] += d.Seconds
+		}
+		for date, secs := range daySecs {
+			if secs > int64(workHours)*3600 {
+				l.Overloads = append(l.Overloads, date)
+			}
+		}
+		sort.Strings(l.Overloads)
+
+		ans = append(ans, *l)
+	}
+
+	return ans, nil
+}
+
+func getResourceAbsences(r *specs.Resource) []ResourceAbsence {
+	ans := []ResourceAbsence{}
+	for _, h := range r.Holidays {
+		ans = append(ans, ResourceAbsence{Type: ABSENCE_HOLIDAYS, Period: *h.Period})
+	}
+	for _, s := range r.Sick {
+		ans = append(ans, ResourceAbsence{Type: ABSENCE_SICK, Period: *s.Period})
+	}
+	for _, u := range r.Unemployed {
+		ans = append(ans, ResourceAbsence{Type: ABSENCE_UNEMPLOYED, Period: *u.Period})
+	}
+	return ans
+}
+
+// GetBars returns the bars of the consecutive work days of the
+// resource on the same task.
+func (l *ResourceLoad) GetBars() ([]ResourceBar, error) {
+	ans := []ResourceBar{}
+	// Index of the last bar of the task
+	mLast := make(map[string]int, 0)
+
+	for _, d := range l.Days {
+		key := fmt.Sprintf("%s|%v", d.Task, d.Planned)
+		if idx, ok := mLast[key]; ok {
+			next, err := time.GetNextWorkDay(ans[idx].End)
+			if err != nil {
+				return nil, err
+			}
+			if d.Date <= next {
+				ans[idx].End = d.Date
+				ans[idx].Seconds += d.Seconds
+				continue
+			}
+		}
+
+		ans = append(ans, ResourceBar{
+			Task:     d.Task,
+			Activity: d.Activity,
+			Start:    d.Date,
+			End:      d.Date,
+			Seconds:  d.Seconds,
+			Planned:  d.Planned,
+		})
+		mLast[key] = len(ans) - 1
+	}
+
+	return ans, nil
+}
+
+// GetAbsencesInRange returns the absences of the resource clipped
+// to the period from-to.
+func (l *ResourceLoad) GetAbsencesInRange(from, to string) []ResourceAbsence {
+	ans := []ResourceAbsence{}
+	for _, a := range l.Absences {
+		start := a.Period.StartPeriod
+		end := a.Period.EndPeriod
+		if end == "" {
+			end = to
+		}
+		if end < from || start > to {
+			continue
+		}
+		if start < from {
+			start = from
+		}
+		if end > to {
+			end = to
+		}
+		ans = append(ans, ResourceAbsence{
+			Type:   a.Type,
+			Period: specs.Period{StartPeriod: start, EndPeriod: end},
+		})
+	}
+	return ans
+}
+
+// GetLoadsPeriod returns the first and the last day of the timesheets
+// of the resources.
+func GetLoadsPeriod(loads []ResourceLoad) (string, string) {
+	from := ""
+	to := ""
+	for _, l := range loads {
+		for _, d := range l.Days {
+			if from == "" || d.Date < from {
+				from = d.Date
+			}
+			if to == "" || d.Date > to {
+				to = d.Date
+			}
+		}
+	}
+	return from, to
+}
+
+// Return the index of the activities of the timesheets used
+// for the colors of the bars.
+func getActivitiesIndex(loads []ResourceLoad) map[string]int {
+	names := []string{}
+	for _, l := range loads {
+		for _, d := range l.Days {
+			names = append(names, d.Activity)
+		}
+	}
+	sort.Strings(names)
+
+	ans := make(map[string]int, 0)
+	for _, n := range names {
+		if _, ok := ans[n]; !ok {
+			ans[n] = len(ans)
+		}
+	}
+	return ans
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt_test
+
+import (
+	"strings"
+
+	. "github.com/geaaru/time-master/pkg/gantt"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Gantt by resource Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	schedule := &specs.ScenarioSchedule{
+		Scenario: &specs.Scenario{
+			Name:    "Plan",
+			NowTime: "2026-09-07",
+			Overrides: &specs.ScenarioOverrides{
+				Resources: []specs.ScenarioResourceOverride{
+					{User: "mrossi", AvailableTo: "2026-09-09"},
+				},
+			},
+		},
+		Schedule: []specs.TaskScheduled{
+			newTaskScheduled("ACT1.analysis", "Analysis", "2026-09-03", "2026-09-08", 50),
+			newTaskScheduled("ACT2.dev", "Development", "2026-09-07", "2026-09-07", 0),
+		},
+	}
+	schedule.Schedule[0].Timesheets = []specs.ResourceTimesheet{
+		*specs.NewResourceTimesheet("geaaru", "2026-09-03", "ACT1.analysis", "4h"),
+		*specs.NewResourceTimesheet("geaaru", "2026-09-04", "ACT1.analysis", "8h"),
+		*specs.NewResourceTimesheet("geaaru", "2026-09-07", "ACT1.analysis", "6h"),
+		*specs.NewResourceTimesheet("geaaru", "2026-09-07", "ACT1.analysis", "2h"),
+		*specs.NewResourceTimesheet("mrossi", "2026-09-08", "ACT1.analysis", "8h"),
+	}
+	schedule.Schedule[1].Timesheets = []specs.ResourceTimesheet{
+		*specs.NewResourceTimesheet("geaaru", "2026-09-07", "ACT2.dev", "2h"),
+	}
+
+	resources := []specs.Resource{
+		{
+			User: "geaaru",
+			Name: "Daniele",
+			Holidays: []specs.ResourceHolidays{
+				{Period: &specs.Period{StartPeriod: "2026-09-08", EndPeriod: "2026-09-20"}},
+			},
+		},
+		{User: "mrossi", Name: "Mario"},
+		{User: "lbianchi", Name: "Luca"},
+	}
+
+	opts := ProducerOpts{ByResource: true, Resources: resources}
+
+	Context("Load", func() {
+
+		It("Days, bars, absences and overloads", func() {
+			loads, err := GetResourcesLoad(schedule, opts, 8)
+			Expect(err).Should(BeNil())
+			Expect(len(loads)).To(Equal(3))
+
+			geaaru := loads[0]
+			Expect(geaaru.Name).To(Equal("Daniele"))
+			Expect(geaaru.Days).To(Equal([]ResourceDay{
+				{Date: "2026-09-03", Task: "ACT1.analysis", Activity: "ACT1", Seconds: 4 * 3600},
+				{Date: "2026-09-04", Task: "ACT1.analysis", Activity: "ACT1", Seconds: 8 * 3600},
+				{Date: "2026-09-07", Task: "ACT1.analysis", Activity: "ACT1", Seconds: 8 * 3600, Planned: true},
+				{Date: "2026-09-07", Task: "ACT2.dev", Activity: "ACT2", Seconds: 2 * 3600, Planned: true},
+			}))
+			Expect(geaaru.Overloads).To(Equal([]string{"2026-09-07"}))
+
+			bars, err := geaaru.GetBars()
+			Expect(err).Should(BeNil())
+			Expect(bars).To(Equal([]ResourceBar{
+				{Task: "ACT1.analysis", Activity: "ACT1", Start: "2026-09-03", End: "2026-09-04", Seconds: 12 * 3600},
+				{Task: "ACT1.analysis", Activity: "ACT1", Start: "2026-09-07", End: "2026-09-07", Seconds: 8 * 3600, Planned: true},
+				{Task: "ACT2.dev", Activity: "ACT2", Start: "2026-09-07", End: "2026-09-07", Seconds: 2 * 3600, Planned: true},
+			}))
+
+			Expect(geaaru.GetAbsencesInRange("2026-09-03", "2026-09-10")).To(Equal([]ResourceAbsence{
+				{Type: ABSENCE_HOLIDAYS, Period: specs.Period{StartPeriod: "2026-09-08", EndPeriod: "2026-09-10"}},
+			}))
+
+			// The absence of the scenario override
+			Expect(loads[1].GetAbsencesInRange("2026-09-03", "2026-09-12")).To(Equal([]ResourceAbsence{
+				{Type: ABSENCE_UNEMPLOYED, Period: specs.Period{StartPeriod: "2026-09-10", EndPeriod: "2026-09-12"}},
+			}))
+
+			Expect(loads[2].User).To(Equal("lbianchi"))
+			Expect(len(loads[2].Days)).To(Equal(0))
+		})
+	})
+
+	Context("Producers", func() {
+
+		It("Frappe rejects the resources", func() {
+			producer := NewFrappeGanttProducer(config)
+			_, err := producer.GetTasks(schedule, opts)
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("SVG rows", func() {
+			producer := NewSvgGanttProducer(config)
+			data, err := producer.Build(schedule, opts)
+			Expect(err).Should(BeNil())
+
+			svg := string(data)
+			Expect(svg).To(ContainSubstring(`class="section">Daniele</text>`))
+			Expect(svg).To(ContainSubstring(`class="section">Luca</text>`))
+			Expect(svg).To(ContainSubstring(`class="absence-holidays"`))
+			Expect(svg).To(ContainSubstring(`class="absence-unemployed"`))
+			Expect(strings.Count(svg, `class="overload"`)).To(Equal(1))
+			// The time over capacity is not stacked.
+			Expect(strings.Count(svg, `class="load-planned"`)).To(Equal(2))
+		})
+	})
+})
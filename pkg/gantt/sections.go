@@ -0,0 +1,125 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+)
+
+// GanttSection contains the scheduled tasks of an activity.
+type GanttSection struct {
+	Name  string
+	Tasks []*specs.TaskScheduled
+}
+
+var invalidIdChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+var plainReplacer = strings.NewReplacer("\n", " ")
+
+// GetGanttSections groups the scheduled tasks by activity. The tasks
+// are sorted by start time (or end time) and the sections are sorted
+// by the first task. The tasks without a period are skipped.
+func GetGanttSections(s *specs.ScenarioSchedule, opts ProducerOpts) []GanttSection {
+	ans := []GanttSection{}
+	tasks := []*specs.TaskScheduled{}
+
+	for idx := range s.Schedule {
+		ts := &s.Schedule[idx]
+		if ts.Period == nil || ts.Period.StartPeriod == "" {
+			continue
+		}
+		tasks = append(tasks, ts)
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].Period.StartPeriod, tasks[j].Period.StartPeriod
+		if opts.OrderByEndTime {
+			a, b = getEndPeriod(tasks[i]), getEndPeriod(tasks[j])
+		}
+		if a == b {
+			return tasks[i].Task.Milestone == "" && tasks[j].Task.Milestone != ""
+		}
+		return a < b
+	})
+
+	mSections := make(map[string]int, 0)
+	for _, ts := range tasks {
+		activity := GetTaskActivity(ts)
+		idx, ok := mSections[activity]
+		if !ok {
+			idx = len(ans)
+			mSections[activity] = idx
+			ans = append(ans, GanttSection{
+				Name:  activity,
+				Tasks: []*specs.TaskScheduled{},
+			})
+		}
+		ans[idx].Tasks = append(ans[idx].Tasks, ts)
+	}
+
+	return ans
+}
+
+// GetTaskActivity returns the name of the activity of the task.
+func GetTaskActivity(ts *specs.TaskScheduled) string {
+	if ts.Activity != nil {
+		return ts.Activity.Name
+	}
+	return strings.Split(ts.Task.Name, ".")[0]
+}
+
+// GetTaskProgress returns the progress (0-100) of the task.
+func GetTaskProgress(ts *specs.TaskScheduled) float64 {
+	if ts.Task.Completed {
+		return 100
+	}
+	return ts.Progress
+}
+
+// Return the end date of the task or the start date if the
+// end date is not available.
+func getEndPeriod(ts *specs.TaskScheduled) string {
+	if ts.Period.EndPeriod == "" {
+		return ts.Period.StartPeriod
+	}
+	return ts.Period.EndPeriod
+}
+
+// Return the name of the task without the chars not supported
+// by the text formats.
+func getTaskLabel(ts *specs.TaskScheduled, opts ProducerOpts, replacer *strings.Replacer) string {
+	ans := ts.Task.Description
+	if ans == "" {
+		ans = ts.Task.Name
+	}
+	if opts.ShowActivityOnTasks || ts.Task.Milestone != "" {
+		ans = GetTaskActivity(ts) + " - " + ans
+	}
+	return strings.TrimSpace(replacer.Replace(ans))
+}
+
+// Return an identifier of the task valid for the text formats.
+func getTaskId(name string) string {
+	return invalidIdChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,537 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	gotime "time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	time "github.com/geaaru/time-master/pkg/time"
+)
+
+const (
+	svgLabelWidth   = 280
+	svgDayWidth     = 16
+	svgHeaderHeight = 44
+	svgRowHeight    = 24
+	svgBarHeight    = 14
+)
+
+// SvgGanttProducer produces a static gantt in SVG format without
+// javascript. Useful for PDFs and emails.
+type SvgGanttProducer struct {
+	*DefaultGanttProducer
+}
+
+type svgRow struct {
+	Section string
+	Task    *specs.TaskScheduled
+	Start   gotime.Time
+	End     gotime.Time
+}
+
+// svgCanvas contains the layout of the gantt.
+type svgCanvas struct {
+	buf   bytes.Buffer
+	Start gotime.Time
+	End   gotime.Time
+	Width int
+	Rows  int
+}
+
+func NewSvgGanttProducer(config *specs.TimeMasterConfig) *SvgGanttProducer {
+	return &SvgGanttProducer{
+		DefaultGanttProducer: newDefaultGanttProducer(config),
+	}
+}
+
+func svgEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// Return the x of the start of the day.
+func (c *svgCanvas) getX(t gotime.Time) int {
+	days := int(t.Sub(c.Start).Hours() / 24)
+	return svgLabelWidth + days*svgDayWidth
+}
+
+// Return the y of the top of the row.
+func (c *svgCanvas) getY(row int) int {
+	return svgHeaderHeight + row*svgRowHeight
+}
+
+func (c *svgCanvas) getHeight() int {
+	return c.getY(c.Rows) + 1
+}
+
+func (c *svgCanvas) printf(format string, args ...interface{}) {
+	c.buf.WriteString(fmt.Sprintf(format, args...))
+}
+
+func newSvgCanvas(start, end gotime.Time, rows int) *svgCanvas {
+	// Start from the monday of the first week.
+	start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	end = end.AddDate(0, 0, 7)
+
+	ans := &svgCanvas{
+		Start: start,
+		End:   end,
+		Rows:  rows,
+	}
+	ans.Width = ans.getX(end) + 1
+	return ans
+}
+
+// Draw the header, the weekends, the holidays and the ticks of
+// weeks and months.
+func (c *svgCanvas) drawGrid(holidays []specs.Period) error {
+	height := c.getHeight()
+
+	c.printf(`<rect x="0" y="0" width="%d" height="%d" class="header"/>`+"\n",
+		c.Width, svgHeaderHeight)
+
+	holidayDays := make(map[string]bool, 0)
+	for _, p := range holidays {
+		start, err := time.ParseTimestamp(p.StartPeriod, true)
+		if err != nil {
+			return err
+		}
+		end := start
+		if p.EndPeriod != "" {
+			end, err = time.ParseTimestamp(p.EndPeriod, true)
+			if err != nil {
+				return err
+			}
+		}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			holidayDays[d.Format("2006-01-02")] = true
+		}
+	}
+
+	for d := c.Start; d.Before(c.End); d = d.AddDate(0, 0, 1) {
+		x := c.getX(d)
+
+		if holidayDays[d.Format("2006-01-02")] {
+			c.printf(`<rect x="%d" y="%d" width="%d" height="%d" class="holiday"/>`+"\n",
+				x, svgHeaderHeight, svgDayWidth, height-svgHeaderHeight)
+		} else if d.Weekday() == gotime.Saturday || d.Weekday() == gotime.Sunday {
+			c.printf(`<rect x="%d" y="%d" width="%d" height="%d" class="weekend"/>`+"\n",
+				x, svgHeaderHeight, svgDayWidth, height-svgHeaderHeight)
+		}
+
+		if d.Day() == 1 {
+			c.printf(`<line x1="%d" y1="0" x2="%d" y2="%d" class="month-tick"/>`+"\n",
+				x, x, height)
+		}
+		// The label of the first month is drawn only if there is enough space.
+		if d.Day() == 1 || (d.Equal(c.Start) && d.AddDate(0, 0, 7).Month() == d.Month()) {
+			c.printf(`<text x="%d" y="16" class="month">%s</text>`+"\n",
+				x+4, d.Format("Jan 2006"))
+		}
+
+		if d.Weekday() == gotime.Monday {
+			c.printf(`<line x1="%d" y1="22" x2="%d" y2="%d" class="week-tick"/>`+"\n",
+				x, x, height)
+			c.printf(`<text x="%d" y="38" class="week">%s</text>`+"\n",
+				x+3, d.Format("02/01"))
+		}
+	}
+
+	c.printf(`<line x1="%d" y1="0" x2="%d" y2="%d" class="separator"/>`+"\n",
+		svgLabelWidth, svgLabelWidth, height)
+	c.printf(`<line x1="0" y1="%d" x2="%d" y2="%d" class="separator"/>`+"\n",
+		svgHeaderHeight, c.Width, svgHeaderHeight)
+
+	return nil
+}
+
+// Draw the line of the now date.
+func (c *svgCanvas) drawNow(now string) error {
+	if now == "" {
+		return nil
+	}
+
+	nowTime, err := time.ParseTimestamp(now, true)
+	if err != nil {
+		return err
+	}
+	if nowTime.Before(c.Start) || !nowTime.Before(c.End) {
+		return nil
+	}
+
+	x := c.getX(nowTime)
+	c.printf(`<line x1="%d" y1="%d" x2="%d" y2="%d" class="now"/>`+"\n",
+		x, svgHeaderHeight, x, c.getHeight())
+	c.printf(`<text x="%d" y="%d" class="now-label">now</text>`+"\n",
+		x+3, svgHeaderHeight+10)
+
+	return nil
+}
+
+func (c *svgCanvas) writeSvg(title string) []byte {
+	var ans bytes.Buffer
+
+	ans.WriteString(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		c.Width, c.getHeight(), c.Width, c.getHeight()))
+	if title != "" {
+		ans.WriteString("<title>" + svgEscape(title) + "</title>\n")
+	}
+	ans.WriteString(`<defs>
+<marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M 0 0 L 10 5 L 0 10 z" fill="#555"/></marker>
+<style>
+text { font-family: sans-serif; font-size: 11px; fill: #333; }
+.header { fill: #f5f5f5; }
+.section-row { fill: #e8eef5; }
+.section { font-weight: bold; }
+.weekend { fill: #f0f0f0; }
+.holiday { fill: #fde2e2; }
+.month-tick { stroke: #999; stroke-width: 1; }
+.week-tick { stroke: #ddd; stroke-width: 1; }
+.separator { stroke: #999; stroke-width: 1; }
+.month { font-weight: bold; }
+.week { font-size: 10px; fill: #666; }
+.bar { fill: #9fc1e4; }
+.bar-underestimated { fill: #ef9a9a; }
+.bar-done { fill: #b9dcb0; }
+.progress { fill: #3a78b5; }
+.milestone { fill: #e0a100; stroke: #8a6300; stroke-width: 1; }
+.dependency { fill: none; stroke: #555; stroke-width: 1; }
+.now { stroke: #d32f2f; stroke-width: 1.5; stroke-dasharray: 4 2; }
+.now-label { fill: #d32f2f; font-size: 10px; }
+.absence-holidays { fill: #e57373; fill-opacity: 0.35; }
+.absence-sick { fill: #fbc02d; fill-opacity: 0.35; }
+.absence-unemployed { fill: #9e9e9e; fill-opacity: 0.35; }
+.load-planned { fill-opacity: 0.45; }
+.overload { fill: none; stroke: #d32f2f; stroke-width: 2; }
+</style>
+</defs>
+<rect x="0" y="0" width="100%" height="100%" fill="#fff"/>
+`)
+	ans.Write(c.buf.Bytes())
+	ans.WriteString("</svg>\n")
+
+	return ans.Bytes()
+}
+
+func (s *SvgGanttProducer) getRows(schedule *specs.ScenarioSchedule, opts ProducerOpts) ([]svgRow, error) {
+	ans := []svgRow{}
+
+	for _, section := range GetGanttSections(schedule, opts) {
+		ans = append(ans, svgRow{Section: section.Name})
+
+		for _, ts := range section.Tasks {
+			start, err := time.ParseTimestamp(ts.Period.StartPeriod, true)
+			if err != nil {
+				s.Logger.Error("Error on on parse start date of task ", ts.Task.Name)
+				return nil, err
+			}
+			end, err := time.ParseTimestamp(getEndPeriod(ts), true)
+			if err != nil {
+				s.Logger.Error("Error on on parse end date of task ", ts.Task.Name)
+				return nil, err
+			}
+
+			ans = append(ans, svgRow{Task: ts, Start: start, End: end})
+		}
+	}
+
+	return ans, nil
+}
+
+func (s *SvgGanttProducer) Build(schedule *specs.ScenarioSchedule, opts ProducerOpts) ([]byte, error) {
+	if opts.ByResource {
+		return s.buildByResource(schedule, opts)
+	}
+
+	rows, err := s.getRows(schedule, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var start, end gotime.Time
+	for _, r := range rows {
+		if r.Task == nil {
+			continue
+		}
+		if start.IsZero() || r.Start.Before(start) {
+			start = r.Start
+		}
+		if end.IsZero() || r.End.After(end) {
+			end = r.End
+		}
+	}
+	if start.IsZero() {
+		start = gotime.Now().UTC().Truncate(24 * gotime.Hour)
+		end = start
+	}
+
+	c := newSvgCanvas(start, end, len(rows))
+
+	// Draw the background of the sections.
+	for idx, r := range rows {
+		if r.Task == nil {
+			c.printf(`<rect x="0" y="%d" width="%d" height="%d" class="section-row"/>`+"\n",
+				c.getY(idx), c.Width, svgRowHeight)
+		}
+	}
+
+	if err := c.drawGrid(opts.Holidays); err != nil {
+		return nil, err
+	}
+
+	// Position of the bars for the dependencies.
+	type barPos struct {
+		Start, End, Y int
+	}
+	bars := make(map[string]barPos, 0)
+
+	for idx, r := range rows {
+		y := c.getY(idx)
+		textY := y + svgRowHeight/2 + 4
+
+		if r.Task == nil {
+			c.printf(`<text x="6" y="%d" class="section">%s</text>`+"\n",
+				textY, svgEscape(r.Section))
+			continue
+		}
+
+		ts := r.Task
+		label := getTaskLabel(ts, opts, plainReplacer)
+		c.printf(`<text x="16" y="%d">%s</text>`+"\n", textY, svgEscape(truncateLabel(label, 42)))
+
+		x1 := c.getX(r.Start)
+		// The end date is inclusive.
+		x2 := c.getX(r.End.AddDate(0, 0, 1))
+		barY := y + (svgRowHeight-svgBarHeight)/2
+		middleY := y + svgRowHeight/2
+
+		if ts.Task.Milestone != "" {
+			mx := x2 - svgDayWidth/2
+			c.printf(`<polygon points="%d,%d %d,%d %d,%d %d,%d" class="milestone"><title>%s</title></polygon>`+"\n",
+				mx, middleY-7, mx+7, middleY, mx, middleY+7, mx-7, middleY,
+				svgEscape(label+" "+getEndPeriod(ts)))
+			bars[ts.Task.Name] = barPos{Start: mx - 7, End: mx + 7, Y: middleY}
+			continue
+		}
+
+		progress := GetTaskProgress(ts)
+		class := "bar"
+		if ts.Underestimated {
+			class = "bar-underestimated"
+		} else if progress >= 100 {
+			class = "bar-done"
+		}
+
+		c.printf(`<rect x="%d" y="%d" width="%d" height="%d" rx="2" class="%s"><title>%s</title></rect>`+"\n",
+			x1, barY, x2-x1, svgBarHeight, class,
+			svgEscape(fmt.Sprintf("%s %s - %s (%d%%)", label, ts.Period.StartPeriod,
+				getEndPeriod(ts), int(progress))))
+		if progress > 0 && progress < 100 {
+			c.printf(`<rect x="%d" y="%d" width="%d" height="%d" rx="2" class="progress"/>`+"\n",
+				x1, barY+svgBarHeight-4, int(float64(x2-x1)*progress/100), 4)
+		}
+
+		bars[ts.Task.Name] = barPos{Start: x1, End: x2, Y: middleY}
+	}
+
+	for _, r := range rows {
+		if r.Task == nil {
+			continue
+		}
+		to := bars[r.Task.Task.Name]
+		for _, dep := range r.Task.Task.Depends {
+			from, ok := bars[dep]
+			if !ok {
+				continue
+			}
+			c.printf(`<path d="M %d %d h 6 V %d H %d" class="dependency" marker-end="url(#arrow)"/>`+"\n",
+				from.End, from.Y, to.Y, to.Start)
+		}
+	}
+
+	now := ""
+	title := ""
+	if schedule.Scenario != nil {
+		now = schedule.Scenario.NowTime
+		title = schedule.Scenario.Name
+	}
+	if err := c.drawNow(now); err != nil {
+		return nil, err
+	}
+
+	return c.writeSvg(title), nil
+}
+
+// Colors of the activities on the gantt by resource.
+var svgActivityColors = []string{
+	"#4e79a7", "#f28e2b", "#59a14f", "#b07aa1", "#edc948",
+	"#76b7b2", "#ff9da7", "#9c755f", "#e15759", "#bab0ac",
+}
+
+// Produce the gantt with a row for every resource. Every day contains
+// the time of the tasks stacked with the height proportional to the
+// work hours and coloured by activity.
+func (s *SvgGanttProducer) buildByResource(schedule *specs.ScenarioSchedule, opts ProducerOpts) ([]byte, error) {
+	workHours := s.Config.GetWork().WorkHours
+	if workHours <= 0 {
+		return nil, errors.New("Invalid work hours")
+	}
+	loads, err := GetResourcesLoad(schedule, opts, workHours)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := GetLoadsPeriod(loads)
+	if from == "" {
+		from = gotime.Now().Format("2006-01-02")
+		to = from
+	}
+	start, err := time.ParseTimestamp(from, true)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.ParseTimestamp(to, true)
+	if err != nil {
+		return nil, err
+	}
+
+	activities := getActivitiesIndex(loads)
+	names := make([]string, len(activities))
+	for a, idx := range activities {
+		names[idx] = a
+	}
+
+	// A row for every resource and a row for the legend.
+	c := newSvgCanvas(start, end, len(loads)+1)
+	if err := c.drawGrid(opts.Holidays); err != nil {
+		return nil, err
+	}
+
+	innerHeight := svgRowHeight - 4
+	daySecs := int64(workHours) * 3600
+
+	for idx, l := range loads {
+		y := c.getY(idx)
+		name := l.Name
+		if name == "" {
+			name = l.User
+		}
+		c.printf(`<text x="6" y="%d" class="section">%s</text>`+"\n",
+			y+svgRowHeight/2+4, svgEscape(truncateLabel(name, 44)))
+		c.printf(`<line x1="0" y1="%d" x2="%d" y2="%d" class="week-tick"/>`+"\n",
+			y+svgRowHeight, c.Width, y+svgRowHeight)
+
+		for _, a := range l.GetAbsencesInRange(c.Start.Format("2006-01-02"),
+			c.End.AddDate(0, 0, -1).Format("2006-01-02")) {
+			aStart, err := time.ParseTimestamp(a.Period.StartPeriod, true)
+			if err != nil {
+				return nil, err
+			}
+			aEnd, err := time.ParseTimestamp(a.Period.EndPeriod, true)
+			if err != nil {
+				return nil, err
+			}
+			x1 := c.getX(aStart)
+			x2 := c.getX(aEnd.AddDate(0, 0, 1))
+			c.printf(`<rect x="%d" y="%d" width="%d" height="%d" class="absence-%s"><title>%s</title></rect>`+"\n",
+				x1, y, x2-x1, svgRowHeight, a.Type,
+				svgEscape(fmt.Sprintf("%s %s - %s", a.Type, a.Period.StartPeriod, a.Period.EndPeriod)))
+		}
+
+		// Stack the tasks of the day from the bottom of the row.
+		mOffset := make(map[string]int, 0)
+		for _, d := range l.Days {
+			dTime, err := time.ParseTimestamp(d.Date, true)
+			if err != nil {
+				return nil, err
+			}
+
+			h := int(d.Seconds * int64(innerHeight) / daySecs)
+			if h < 1 {
+				h = 1
+			}
+			offset := mOffset[d.Date]
+			if offset+h > innerHeight {
+				h = innerHeight - offset
+			}
+			mOffset[d.Date] = offset + h
+			if h <= 0 {
+				continue
+			}
+
+			class := ""
+			if d.Planned {
+				class = ` class="load-planned"`
+			}
+			hours, _ := time.Seconds2Duration(d.Seconds)
+			c.printf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s"%s><title>%s</title></rect>`+"\n",
+				c.getX(dTime)+1, y+svgRowHeight-2-offset-h, svgDayWidth-2, h,
+				svgActivityColors[activities[d.Activity]%len(svgActivityColors)], class,
+				svgEscape(fmt.Sprintf("%s %s %s", d.Task, d.Date, hours)))
+		}
+
+		for _, d := range l.Overloads {
+			dTime, err := time.ParseTimestamp(d, true)
+			if err != nil {
+				return nil, err
+			}
+			c.printf(`<rect x="%d" y="%d" width="%d" height="%d" class="overload"><title>%s</title></rect>`+"\n",
+				c.getX(dTime)+1, y+1, svgDayWidth-2, svgRowHeight-2, svgEscape("Over capacity "+d))
+		}
+	}
+
+	// Legend of the activities
+	y := c.getY(len(loads))
+	x := 6
+	for idx, a := range names {
+		c.printf(`<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`+"\n",
+			x, y+7, svgActivityColors[idx%len(svgActivityColors)])
+		c.printf(`<text x="%d" y="%d">%s</text>`+"\n", x+14, y+16, svgEscape(a))
+		x += 24 + 7*len([]rune(a))
+	}
+
+	now := ""
+	title := ""
+	if schedule.Scenario != nil {
+		now = schedule.Scenario.NowTime
+		title = schedule.Scenario.Name
+	}
+	if err := c.drawNow(now); err != nil {
+		return nil, err
+	}
+
+	return c.writeSvg(title), nil
+}
+
+// Truncate the label to the max number of chars.
+func truncateLabel(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[0:max-1]) + "…"
+}
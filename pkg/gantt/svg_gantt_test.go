@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	. "github.com/geaaru/time-master/pkg/gantt"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SVG Gantt Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+
+	schedule := &specs.ScenarioSchedule{
+		Scenario: &specs.Scenario{Name: "Plan & Co", NowTime: "2026-09-09"},
+		Schedule: []specs.TaskScheduled{
+			newTaskScheduled("ACT1.analysis", "Analysis", "2026-09-01", "2026-09-04", 100),
+			newTaskScheduled("ACT2.dev", "Development <core>", "2026-09-07", "2026-09-11", 50),
+			newTaskScheduled("ACT2.release", "Release", "2026-09-14", "2026-09-14", 0),
+		},
+	}
+	schedule.Schedule[1].Task.Depends = []string{"ACT1.analysis"}
+	schedule.Schedule[2].Task.Milestone = "2026-09-14"
+	schedule.Schedule[2].Task.Depends = []string{"ACT2.dev"}
+
+	Context("Build", func() {
+
+		It("Rows, markers and dependencies", func() {
+			producer, err := NewProducer(config, "svg")
+			Expect(err).Should(BeNil())
+
+			data, err := producer.Build(schedule, ProducerOpts{
+				Holidays: []specs.Period{{StartPeriod: "2026-09-10", EndPeriod: "2026-09-10"}},
+			})
+			Expect(err).Should(BeNil())
+
+			// Check that the document is a valid XML.
+			decoder := xml.NewDecoder(bytes.NewReader(data))
+			for {
+				_, err := decoder.Token()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).Should(BeNil())
+			}
+
+			svg := string(data)
+			Expect(svg).To(ContainSubstring("<title>Plan &amp; Co</title>"))
+			Expect(svg).To(ContainSubstring(`class="section">ACT1</text>`))
+			Expect(svg).To(ContainSubstring(`class="section">ACT2</text>`))
+			Expect(svg).To(ContainSubstring("Development &lt;core&gt;"))
+			Expect(svg).To(ContainSubstring(`class="bar-done"`))
+			Expect(svg).To(ContainSubstring(`class="progress"`))
+			Expect(svg).To(ContainSubstring(`class="milestone"`))
+			Expect(svg).To(ContainSubstring(`class="now"`))
+			Expect(strings.Count(svg, `class="dependency"`)).To(Equal(2))
+			Expect(strings.Count(svg, `class="holiday"`)).To(Equal(1))
+			// Three weekends between 2026-08-31 and 2026-09-20
+			Expect(strings.Count(svg, `class="weekend"`)).To(Equal(6))
+		})
+	})
+})
@@ -0,0 +1,121 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gantt_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/gantt"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Text Gantt Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+
+	schedule := &specs.ScenarioSchedule{
+		Scenario: &specs.Scenario{Name: "Plan"},
+		Schedule: []specs.TaskScheduled{
+			newTaskScheduled("ACT2.dev", "Development: core", "2026-09-07", "2026-09-11", 40),
+			newTaskScheduled("ACT1.analysis", "Analysis", "2026-09-01", "2026-09-04", 100),
+			newTaskScheduled("ACT2.release", "Release", "2026-09-14", "2026-09-14", 0),
+		},
+	}
+	schedule.Schedule[0].Task.Depends = []string{"ACT1.analysis", "ACT0.missing"}
+	schedule.Schedule[2].Task.Milestone = "2026-09-14"
+
+	Context("Mermaid", func() {
+
+		It("Sections, markers and dependencies", func() {
+			producer, err := NewProducer(config, "mermaid")
+			Expect(err).Should(BeNil())
+
+			data, err := producer.Build(schedule, ProducerOpts{})
+			Expect(err).Should(BeNil())
+			Expect(string(data)).To(Equal(`gantt
+    title Plan
+    dateFormat YYYY-MM-DD
+    axisFormat %d/%m
+    excludes weekends
+
+    section ACT1
+    Analysis :done, ACT1_analysis, 2026-09-01, 2026-09-05
+
+    section ACT2
+    Development  core :active, ACT2_dev, 2026-09-07, 2026-09-12
+    ACT2 - Release :milestone, ACT2_release, 2026-09-14, 0d
+`))
+		})
+
+		It("Dependencies only with the same start", func() {
+			deps := &specs.ScenarioSchedule{
+				Schedule: []specs.TaskScheduled{
+					newTaskScheduled("ACT1.analysis", "Analysis", "2026-09-01", "2026-09-02", 0),
+					newTaskScheduled("ACT1.design", "Design", "2026-09-03", "2026-09-03", 0),
+					newTaskScheduled("ACT1.dev", "Development", "2026-09-07", "2026-09-08", 0),
+				},
+			}
+			deps.Schedule[1].Task.Depends = []string{"ACT1.analysis"}
+			deps.Schedule[2].Task.Depends = []string{"ACT1.analysis"}
+
+			producer, err := NewProducer(config, "mermaid")
+			Expect(err).Should(BeNil())
+
+			data, err := producer.Build(deps, ProducerOpts{ShowWeekends: true})
+			Expect(err).Should(BeNil())
+			Expect(string(data)).To(ContainSubstring(`
+    Analysis :ACT1_analysis, 2026-09-01, 2026-09-03
+    Design :ACT1_design, after ACT1_analysis, 2026-09-04
+    Development :ACT1_dev, 2026-09-07, 2026-09-09
+`))
+		})
+	})
+
+	Context("PlantUML", func() {
+
+		It("Sections, markers and dependencies", func() {
+			producer, err := NewProducer(config, "plantuml")
+			Expect(err).Should(BeNil())
+
+			data, err := producer.Build(schedule, ProducerOpts{ShowWeekends: true})
+			Expect(err).Should(BeNil())
+			Expect(string(data)).To(Equal(`@startgantt
+title Plan
+Project starts 2026-09-01
+
+-- ACT1 --
+[Analysis] as [ACT1_analysis] starts 2026-09-01
+[ACT1_analysis] ends 2026-09-04
+[ACT1_analysis] is 100% completed
+
+-- ACT2 --
+[Development: core] as [ACT2_dev] starts 2026-09-07
+[ACT2_dev] ends 2026-09-11
+[ACT2_dev] is 40% completed
+[ACT2_dev] is colored in LightSkyBlue
+[ACT2 - Release] as [ACT2_release] happens 2026-09-14
+
+[ACT1_analysis] -> [ACT2_dev]
+@endgantt
+`))
+		})
+	})
+})
@@ -0,0 +1,825 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calendar contains the events of an iCalendar file.
+type Calendar struct {
+	Name   string
+	Events []*Event
+}
+
+type Attendee struct {
+	Email string
+	Name  string
+	// Participation status (ACCEPTED, DECLINED, TENTATIVE, NEEDS-ACTION).
+	PartStat string
+}
+
+// Event is a VEVENT of the calendar. The recurrence rule supports the
+// parts FREQ, INTERVAL, COUNT, UNTIL, WKST, BYDAY, BYMONTHDAY, BYMONTH
+// and BYSETPOS (only with FREQ=MONTHLY or FREQ=YEARLY). An event with
+// a rule not supported has the reason in Unsupported and hasn't
+// occurrences. An event with RecurrenceId overrides a single
+// occurrence of the recurring event with the same Uid.
+type Event struct {
+	Uid         string
+	Summary     string
+	Description string
+	Status      string
+	Categories  []string
+	Attendees   []Attendee
+	Organizer   *Attendee
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	RRule       *RRule
+	ExDates     []time.Time
+	// Original start of the occurrence overridden by the event.
+	RecurrenceId *time.Time
+	// Creation time of the event (DTSTAMP) used by Marshal.
+	Stamp time.Time
+	// Reason of the recurrence rule not supported.
+	Unsupported string
+}
+
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	WeekStart  time.Weekday
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+}
+
+// WeekdayNum is a day of the BYDAY part with the optional ordinal
+// of the day in the month or in the year (for example 2TU or -1FR).
+type WeekdayNum struct {
+	Ordinal int
+	Day     time.Weekday
+}
+
+// Occurrence is a single instance of an event.
+type Occurrence struct {
+	Start time.Time
+	End   time.Time
+}
+
+type property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Max number of occurrences generated for an event.
+const maxOccurrences = 10000
+
+var weekdayNumRegex = regexp.MustCompile(`^([+-]?\d{1,2})?([A-Z]{2})$`)
+
+var durationRegex = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// Unfold the lines of the content.
+func unfoldLines(data string) []string {
+	ans := []string{}
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(ans) > 0 {
+			ans[len(ans)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		ans = append(ans, line)
+	}
+	return ans
+}
+
+func parseProperty(line string) (*property, error) {
+	// The value starts after the first colon outside of quotes.
+	inQuote := false
+	sep := -1
+	for idx, c := range line {
+		if c == '"' {
+			inQuote = !inQuote
+		} else if c == ':' && !inQuote {
+			sep = idx
+			break
+		}
+	}
+	if sep < 0 {
+		return nil, errors.New("Invalid line " + line)
+	}
+
+	ans := &property{
+		Params: make(map[string]string, 0),
+		Value:  line[sep+1:],
+	}
+
+	parts := strings.Split(line[0:sep], ";")
+	ans.Name = strings.ToUpper(parts[0])
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 {
+			ans.Params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], "\"")
+		}
+	}
+
+	return ans, nil
+}
+
+func unescapeText(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return r.Replace(s)
+}
+
+// Split a list of values separated by commas not escaped.
+func splitText(s string) []string {
+	ans := []string{}
+	cur := ""
+	for idx := 0; idx < len(s); idx++ {
+		if s[idx] == '\\' && idx+1 < len(s) {
+			cur += s[idx : idx+2]
+			idx++
+			continue
+		}
+		if s[idx] == ',' {
+			ans = append(ans, unescapeText(strings.TrimSpace(cur)))
+			cur = ""
+			continue
+		}
+		cur += string(s[idx])
+	}
+	if strings.TrimSpace(cur) != "" {
+		ans = append(ans, unescapeText(strings.TrimSpace(cur)))
+	}
+	return ans
+}
+
+// ParseDateTime parses a DATE or DATE-TIME value. The second value is
+// true if the value is a date.
+func ParseDateTime(value string, params map[string]string) (time.Time, bool, error) {
+	loc := time.Local
+	if tzid, ok := params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// ParseDuration parses a duration value (ex. P1D, PT1H30M).
+func ParseDuration(value string) (time.Duration, error) {
+	m := durationRegex.FindStringSubmatch(value)
+	if m == nil {
+		return 0, errors.New("Invalid duration " + value)
+	}
+
+	mult := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	ans := time.Duration(0)
+	for idx, v := range m[2:] {
+		if v == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(v)
+		ans += time.Duration(n) * mult[idx]
+	}
+	if m[1] == "-" {
+		ans = -ans
+	}
+	return ans, nil
+}
+
+func parseAttendee(p *property) Attendee {
+	email := p.Value
+	if strings.HasPrefix(strings.ToLower(email), "mailto:") {
+		email = email[7:]
+	}
+	return Attendee{
+		Email:    email,
+		Name:     p.Params["CN"],
+		PartStat: strings.ToUpper(p.Params["PARTSTAT"]),
+	}
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Parse the comma separated integers of a part of the rule. The
+// values must be not zero and between -max and max.
+func parseRuleInts(part, value string, max int) ([]int, error) {
+	ans := []int{}
+	for _, v := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(strings.TrimPrefix(v, "+"))
+		if err != nil || i == 0 || i > max || i < -max {
+			return nil, errors.New(fmt.Sprintf("Invalid %s value %s", part, v))
+		}
+		ans = append(ans, i)
+	}
+	return ans, nil
+}
+
+// ParseRRule parses the recurrence rule of an event.
+func ParseRRule(value string, loc *time.Location) (*RRule, error) {
+	ans := &RRule{Interval: 1, WeekStart: time.Monday}
+	var err error
+
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.New("Invalid rule " + value)
+		}
+
+		switch strings.ToUpper(kv[0]) {
+		case "FREQ":
+			ans.Freq = strings.ToUpper(kv[1])
+			switch ans.Freq {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+			default:
+				return nil, errors.New("Unsupported frequency " + kv[1])
+			}
+		case "INTERVAL":
+			i, err := strconv.Atoi(kv[1])
+			if err != nil || i <= 0 {
+				return nil, errors.New("Invalid interval " + kv[1])
+			}
+			ans.Interval = i
+		case "COUNT":
+			c, err := strconv.Atoi(kv[1])
+			if err != nil {
+				return nil, errors.New("Invalid count " + kv[1])
+			}
+			ans.Count = c
+		case "UNTIL":
+			t, _, err := ParseDateTime(kv[1], map[string]string{})
+			if err != nil {
+				return nil, errors.New("Invalid until " + kv[1])
+			}
+			if len(kv[1]) == 8 {
+				// The until date is inclusive.
+				t = time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, loc)
+			}
+			ans.Until = &t
+		case "WKST":
+			wd, ok := weekdays[strings.ToUpper(kv[1])]
+			if !ok {
+				return nil, errors.New("Invalid week start " + kv[1])
+			}
+			ans.WeekStart = wd
+		case "BYDAY":
+			for _, d := range strings.Split(kv[1], ",") {
+				m := weekdayNumRegex.FindStringSubmatch(strings.ToUpper(d))
+				if m == nil {
+					return nil, errors.New("Invalid day " + d)
+				}
+				wd, ok := weekdays[m[2]]
+				if !ok {
+					return nil, errors.New("Invalid day " + d)
+				}
+				n := WeekdayNum{Day: wd}
+				if m[1] != "" {
+					n.Ordinal, _ = strconv.Atoi(strings.TrimPrefix(m[1], "+"))
+					if n.Ordinal == 0 || n.Ordinal > 53 || n.Ordinal < -53 {
+						return nil, errors.New("Invalid day " + d)
+					}
+				}
+				ans.ByDay = append(ans.ByDay, n)
+			}
+		case "BYMONTHDAY":
+			ans.ByMonthDay, err = parseRuleInts("BYMONTHDAY", kv[1], 31)
+		case "BYMONTH":
+			ans.ByMonth, err = parseRuleInts("BYMONTH", kv[1], 12)
+			for _, m := range ans.ByMonth {
+				if m < 0 {
+					return nil, errors.New("Invalid BYMONTH value " + kv[1])
+				}
+			}
+		case "BYSETPOS":
+			ans.BySetPos, err = parseRuleInts("BYSETPOS", kv[1], 366)
+		default:
+			return nil, errors.New("Unsupported part " + kv[0])
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ans.Freq == "" {
+		return nil, errors.New("Missing frequency on rule " + value)
+	}
+
+	if ans.Freq == "DAILY" || ans.Freq == "WEEKLY" {
+		for _, d := range ans.ByDay {
+			if d.Ordinal != 0 {
+				return nil, errors.New("Unsupported ordinal day with frequency " + ans.Freq)
+			}
+		}
+		if len(ans.BySetPos) > 0 {
+			return nil, errors.New("Unsupported BYSETPOS with frequency " + ans.Freq)
+		}
+		if ans.Freq == "WEEKLY" && len(ans.ByMonthDay) > 0 {
+			return nil, errors.New("Unsupported BYMONTHDAY with frequency WEEKLY")
+		}
+	}
+
+	return ans, nil
+}
+
+// Parse returns the calendar of the iCalendar content.
+func Parse(data []byte) (*Calendar, error) {
+	ans := &Calendar{
+		Events: []*Event{},
+	}
+
+	var event *Event
+	var durationValue string
+	hasEnd := false
+
+	for _, line := range unfoldLines(string(data)) {
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Name == "BEGIN" && strings.ToUpper(p.Value) == "VEVENT" {
+			event = &Event{
+				Categories: []string{},
+				Attendees:  []Attendee{},
+				ExDates:    []time.Time{},
+			}
+			durationValue = ""
+			hasEnd = false
+			continue
+		}
+
+		if p.Name == "END" && strings.ToUpper(p.Value) == "VEVENT" {
+			if event == nil {
+				return nil, errors.New("Unexpected END:VEVENT")
+			}
+			if event.Start.IsZero() {
+				return nil, errors.New("Event " + event.Uid + " without DTSTART")
+			}
+
+			if !hasEnd {
+				if durationValue != "" {
+					d, err := ParseDuration(durationValue)
+					if err != nil {
+						return nil, err
+					}
+					event.End = event.Start.Add(d)
+				} else if event.AllDay {
+					event.End = event.Start.AddDate(0, 0, 1)
+				} else {
+					event.End = event.Start
+				}
+			}
+
+			ans.Events = append(ans.Events, event)
+			event = nil
+			continue
+		}
+
+		if event == nil {
+			if p.Name == "X-WR-CALNAME" {
+				ans.Name = unescapeText(p.Value)
+			}
+			continue
+		}
+
+		switch p.Name {
+		case "UID":
+			event.Uid = p.Value
+		case "SUMMARY":
+			event.Summary = unescapeText(p.Value)
+		case "DESCRIPTION":
+			event.Description = unescapeText(p.Value)
+		case "STATUS":
+			event.Status = strings.ToUpper(p.Value)
+		case "CATEGORIES":
+			event.Categories = append(event.Categories, splitText(p.Value)...)
+		case "ATTENDEE":
+			event.Attendees = append(event.Attendees, parseAttendee(p))
+		case "ORGANIZER":
+			a := parseAttendee(p)
+			event.Organizer = &a
+		case "DTSTART":
+			event.Start, event.AllDay, err = ParseDateTime(p.Value, p.Params)
+		case "DTEND":
+			event.End, _, err = ParseDateTime(p.Value, p.Params)
+			hasEnd = true
+		case "DURATION":
+			durationValue = p.Value
+		case "RRULE":
+			loc := time.Local
+			if !event.Start.IsZero() {
+				loc = event.Start.Location()
+			}
+			event.RRule, err = ParseRRule(p.Value, loc)
+			if err != nil {
+				// The event is skipped without fail the whole file.
+				event.Unsupported = err.Error()
+				event.RRule = nil
+				err = nil
+			}
+		case "RECURRENCE-ID":
+			var t time.Time
+			t, _, err = ParseDateTime(p.Value, p.Params)
+			event.RecurrenceId = &t
+		case "EXDATE":
+			for _, v := range strings.Split(p.Value, ",") {
+				t, _, e := ParseDateTime(v, p.Params)
+				if e != nil {
+					err = e
+					break
+				}
+				event.ExDates = append(event.ExDates, t)
+			}
+		}
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid property %s: %s", p.Name, err.Error()))
+		}
+	}
+
+	ans.resolveOverrides()
+
+	return ans, nil
+}
+
+// Exclude from the recurring events the occurrences overridden by
+// other events. The override inherits the fields not defined from
+// the recurring event.
+func (c *Calendar) resolveOverrides() {
+	masters := make(map[string]*Event, 0)
+	for _, e := range c.Events {
+		if e.RecurrenceId == nil && e.RRule != nil && e.Uid != "" {
+			masters[e.Uid] = e
+		}
+	}
+
+	for _, e := range c.Events {
+		if e.RecurrenceId == nil {
+			continue
+		}
+		master, ok := masters[e.Uid]
+		if !ok {
+			continue
+		}
+
+		master.ExDates = append(master.ExDates, *e.RecurrenceId)
+
+		if e.Summary == "" {
+			e.Summary = master.Summary
+		}
+		if e.Description == "" {
+			e.Description = master.Description
+		}
+		if len(e.Categories) == 0 {
+			e.Categories = master.Categories
+		}
+		if e.Organizer == nil {
+			e.Organizer = master.Organizer
+		}
+		if len(e.Attendees) == 0 {
+			e.Attendees = master.Attendees
+		}
+	}
+}
+
+func (e *Event) isExcluded(t time.Time) bool {
+	for _, ex := range e.ExDates {
+		if e.AllDay {
+			if ex.Format("20060102") == t.Format("20060102") {
+				return true
+			}
+		} else if ex.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Event) hasDay(wd time.Weekday) bool {
+	for _, d := range e.RRule.ByDay {
+		if d.Day == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Event) hasMonth(m time.Month) bool {
+	if len(e.RRule.ByMonth) == 0 {
+		return true
+	}
+	for _, v := range e.RRule.ByMonth {
+		if v == int(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return true if the date matches the BYMONTHDAY part.
+func (e *Event) hasMonthDay(t time.Time) bool {
+	if len(e.RRule.ByMonthDay) == 0 {
+		return true
+	}
+	days := daysIn(t.Year(), t.Month())
+	for _, v := range e.RRule.ByMonthDay {
+		if v == t.Day() || (v < 0 && days+v+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return true if the day of the scope matches the BYDAY part. The
+// ordinals count the days in the scope of n days.
+func (e *Event) hasDayNum(t time.Time, day, n int) bool {
+	if len(e.RRule.ByDay) == 0 {
+		return true
+	}
+	for _, d := range e.RRule.ByDay {
+		if d.Day != t.Weekday() {
+			continue
+		}
+		if d.Ordinal == 0 ||
+			(d.Ordinal > 0 && (day-1)/7+1 == d.Ordinal) ||
+			(d.Ordinal < 0 && (n-day)/7+1 == -d.Ordinal) {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Return the first day of the week of the date. The date is converted
+// in UTC to avoid errors with the daylight saving time.
+func weekStart(t time.Time, wkst time.Weekday) time.Time {
+	ans := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return ans.AddDate(0, 0, -((int(ans.Weekday()) - int(wkst) + 7) % 7))
+}
+
+// Return the sorted dates of the month (or of the year with month 0)
+// that match the parts of the rule with the time of the start.
+func (e *Event) expandPeriod(year int, month time.Month) []time.Time {
+	r := e.RRule
+	h, m, sec := e.Start.Clock()
+	newDate := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, h, m, sec, 0, e.Start.Location())
+	}
+
+	ans := []time.Time{}
+	if month == 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) > 0 {
+		// The ordinals of the days count the days of the year.
+		n := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		for day := 1; day <= n; day++ {
+			t := newDate(time.January, day)
+			if e.hasDayNum(t, day, n) {
+				ans = append(ans, t)
+			}
+		}
+	} else {
+		months := []time.Month{month}
+		if month == 0 {
+			months = []time.Month{}
+			for mm := time.January; mm <= time.December; mm++ {
+				if len(r.ByMonth) > 0 || len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 {
+					if e.hasMonth(mm) {
+						months = append(months, mm)
+					}
+				} else if mm == e.Start.Month() {
+					months = append(months, mm)
+				}
+			}
+		} else if !e.hasMonth(month) {
+			return ans
+		}
+
+		for _, mm := range months {
+			n := daysIn(year, mm)
+			for day := 1; day <= n; day++ {
+				t := newDate(mm, day)
+				if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 && day != e.Start.Day() {
+					continue
+				}
+				if e.hasMonthDay(t) && e.hasDayNum(t, day, n) {
+					ans = append(ans, t)
+				}
+			}
+		}
+	}
+
+	if len(r.BySetPos) == 0 {
+		return ans
+	}
+
+	set := []time.Time{}
+	for i, t := range ans {
+		for _, pos := range r.BySetPos {
+			if pos == i+1 || len(ans)+pos == i {
+				set = append(set, t)
+				break
+			}
+		}
+	}
+	return set
+}
+
+func (e *Event) newOccurrence(start time.Time, duration time.Duration) Occurrence {
+	if e.AllDay {
+		// Use the days to avoid errors with the daylight saving time.
+		days := int(math.Round(duration.Hours() / 24))
+		return Occurrence{Start: start, End: start.AddDate(0, 0, days)}
+	}
+	return Occurrence{Start: start, End: start.Add(duration)}
+}
+
+// Occurrences returns the occurrences of the event that start
+// before the limit date.
+func (e *Event) Occurrences(limit time.Time) []Occurrence {
+	ans := []Occurrence{}
+	duration := e.End.Sub(e.Start)
+
+	if e.Unsupported != "" {
+		return ans
+	}
+
+	if e.RRule == nil {
+		if e.Start.Before(limit) {
+			ans = append(ans, Occurrence{Start: e.Start, End: e.End})
+		}
+		return ans
+	}
+
+	r := e.RRule
+	until := limit
+	if r.Until != nil && r.Until.Before(until) {
+		until = r.Until.Add(time.Second)
+	}
+
+	count := 0
+	for n := 0; n < maxOccurrences; n++ {
+		var start time.Time
+
+		switch r.Freq {
+		case "MONTHLY", "YEARLY":
+			return e.periodOccurrences(until, duration)
+		case "WEEKLY":
+			if len(r.ByDay) > 0 {
+				// Check every day of the weeks of the interval.
+				start = e.Start.AddDate(0, 0, n)
+				// The weeks of the interval start on the WKST day.
+				week := int(weekStart(start, r.WeekStart).Sub(
+					weekStart(e.Start, r.WeekStart)).Hours()/24) / 7
+				if week%r.Interval != 0 || !e.hasDay(start.Weekday()) || !e.hasMonth(start.Month()) {
+					if !start.Before(until) {
+						return ans
+					}
+					continue
+				}
+			} else {
+				start = e.Start.AddDate(0, 0, 7*n*r.Interval)
+				if !e.hasMonth(start.Month()) {
+					if !start.Before(until) {
+						return ans
+					}
+					continue
+				}
+			}
+		case "DAILY":
+			start = e.Start.AddDate(0, 0, n*r.Interval)
+			if (len(r.ByDay) > 0 && !e.hasDay(start.Weekday())) ||
+				!e.hasMonth(start.Month()) || !e.hasMonthDay(start) {
+				if !start.Before(until) {
+					return ans
+				}
+				continue
+			}
+		}
+
+		if !start.Before(until) {
+			break
+		}
+
+		count++
+		if r.Count > 0 && count > r.Count {
+			break
+		}
+
+		if e.isExcluded(start) {
+			continue
+		}
+
+		ans = append(ans, e.newOccurrence(start, duration))
+	}
+
+	return ans
+}
+
+// Return the occurrences of the monthly and yearly rules expanding
+// the days of every period of the interval.
+func (e *Event) periodOccurrences(until time.Time, duration time.Duration) []Occurrence {
+	ans := []Occurrence{}
+	r := e.RRule
+
+	count := 0
+	for n := 0; n < maxOccurrences; n++ {
+		var dates []time.Time
+		if r.Freq == "MONTHLY" {
+			first := time.Date(e.Start.Year(), e.Start.Month()+time.Month(n*r.Interval), 1,
+				0, 0, 0, 0, time.UTC)
+			if !first.Before(until) {
+				break
+			}
+			dates = e.expandPeriod(first.Year(), first.Month())
+		} else {
+			year := e.Start.Year() + n*r.Interval
+			if year > until.Year() {
+				break
+			}
+			dates = e.expandPeriod(year, 0)
+		}
+
+		for _, start := range dates {
+			if start.Before(e.Start) {
+				continue
+			}
+			if !start.Before(until) {
+				return ans
+			}
+
+			count++
+			if r.Count > 0 && count > r.Count {
+				return ans
+			}
+
+			if e.isExcluded(start) {
+				continue
+			}
+
+			ans = append(ans, e.newOccurrence(start, duration))
+		}
+	}
+
+	return ans
+}
+
+// GetDays returns the first and the last day (inclusive) of the
+// occurrence in the format YYYY-MM-DD.
+func (o *Occurrence) GetDays() (string, string) {
+	end := o.End
+	if end.After(o.Start) && end.Hour() == 0 && end.Minute() == 0 && end.Second() == 0 {
+		// The end is exclusive.
+		end = end.Add(-time.Second)
+	}
+	return o.Start.Format("2006-01-02"), end.Format("2006-01-02")
+}
@@ -0,0 +1,324 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package ical_test
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	. "github.com/geaaru/time-master/pkg/ical"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func getDays(occurrences []Occurrence) [][]string {
+	ans := [][]string{}
+	for _, o := range occurrences {
+		start, end := o.GetDays()
+		ans = append(ans, []string{start, end})
+	}
+	return ans
+}
+
+var _ = Describe("iCalendar Test", func() {
+
+	limit := time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local)
+
+	Context("Parse", func() {
+
+		It("All day and folded lines", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\r\n" +
+				"X-WR-CALNAME:HR Calendar\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"UID:1\r\n" +
+				"SUMMARY:Summer\r\n  holidays\r\n" +
+				"CATEGORIES:Holiday,Paid\\, full\r\n" +
+				"DTSTART;VALUE=DATE:20260810\r\n" +
+				"DTEND;VALUE=DATE:20260815\r\n" +
+				"ATTENDEE;CN=\"Rossi, Mario\";ROLE=REQ-PARTICIPANT;PARTSTAT=accepted:mailto:m@x.it\r\n" +
+				"STATUS:CONFIRMED\r\n" +
+				"END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(cal.Name).To(Equal("HR Calendar"))
+			Expect(len(cal.Events)).To(Equal(1))
+
+			e := cal.Events[0]
+			Expect(e.Summary).To(Equal("Summer holidays"))
+			Expect(e.Categories).To(Equal([]string{"Holiday", "Paid, full"}))
+			Expect(e.Attendees).To(Equal([]Attendee{{Email: "m@x.it", Name: "Rossi, Mario", PartStat: "ACCEPTED"}}))
+			Expect(e.Status).To(Equal("CONFIRMED"))
+			Expect(e.AllDay).To(Equal(true))
+			Expect(getDays(e.Occurrences(limit))).To(Equal([][]string{
+				{"2026-08-10", "2026-08-14"},
+			}))
+		})
+
+		It("Timed event with duration", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;TZID=Europe/Rome:20260902T090000\n" +
+				"DURATION:PT8H\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(cal.Events[0].End.Sub(cal.Events[0].Start)).To(Equal(8 * time.Hour))
+			Expect(getDays(cal.Events[0].Occurrences(limit))).To(Equal([][]string{
+				{"2026-09-02", "2026-09-02"},
+			}))
+		})
+	})
+
+	Context("Recurrence rules", func() {
+
+		It("Weekly by day with exdate", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260907\n" +
+				"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;UNTIL=20261002\n" +
+				"EXDATE;VALUE=DATE:20260921\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(getDays(cal.Events[0].Occurrences(limit))).To(Equal([][]string{
+				{"2026-09-07", "2026-09-07"},
+				{"2026-09-11", "2026-09-11"},
+				{"2026-09-25", "2026-09-25"},
+			}))
+		})
+
+		It("Weekly interval anchored at the week of dtstart", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260902\n" +
+				"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=4\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(getDays(cal.Events[0].Occurrences(limit))).To(Equal([][]string{
+				{"2026-09-02", "2026-09-02"},
+				{"2026-09-14", "2026-09-14"},
+				{"2026-09-16", "2026-09-16"},
+				{"2026-09-28", "2026-09-28"},
+			}))
+		})
+
+		It("Yearly multi-day with count", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20251224\n" +
+				"DTEND;VALUE=DATE:20251227\n" +
+				"RRULE:FREQ=YEARLY;COUNT=5\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(getDays(cal.Events[0].Occurrences(limit))).To(Equal([][]string{
+				{"2025-12-24", "2025-12-26"},
+				{"2026-12-24", "2026-12-26"},
+			}))
+		})
+
+		It("Override of an occurrence", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"UID:weekly\n" +
+				"SUMMARY:Weekly\n" +
+				"DTSTART;VALUE=DATE:20260907\n" +
+				"RRULE:FREQ=WEEKLY;COUNT=3\n" +
+				"STATUS:CONFIRMED\n" +
+				"END:VEVENT\n" +
+				"BEGIN:VEVENT\n" +
+				"UID:weekly\n" +
+				"RECURRENCE-ID;VALUE=DATE:20260914\n" +
+				"DTSTART;VALUE=DATE:20260916\n" +
+				"STATUS:CANCELLED\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(len(cal.Events)).To(Equal(2))
+			Expect(getDays(cal.Events[0].Occurrences(limit))).To(Equal([][]string{
+				{"2026-09-07", "2026-09-07"},
+				{"2026-09-21", "2026-09-21"},
+			}))
+
+			override := cal.Events[1]
+			Expect(override.RecurrenceId.Format("2006-01-02")).To(Equal("2026-09-14"))
+			Expect(override.Summary).To(Equal("Weekly"))
+			Expect(override.Status).To(Equal("CANCELLED"))
+			Expect(getDays(override.Occurrences(limit))).To(Equal([][]string{
+				{"2026-09-16", "2026-09-16"},
+			}))
+		})
+
+		It("Monthly by ordinal days", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260908\n" +
+				"RRULE:FREQ=MONTHLY;COUNT=4;BYDAY=2TU,-1FR\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(cal.Events[0].Unsupported).To(Equal(""))
+			Expect(getDays(cal.Events[0].Occurrences(limit))).To(Equal([][]string{
+				{"2026-09-08", "2026-09-08"},
+				{"2026-09-25", "2026-09-25"},
+				{"2026-10-13", "2026-10-13"},
+				{"2026-10-30", "2026-10-30"},
+			}))
+		})
+
+		It("Monthly last working day with BYSETPOS", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260930\n" +
+				"RRULE:FREQ=MONTHLY;UNTIL=20261231;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(getDays(cal.Events[0].Occurrences(limit))).To(Equal([][]string{
+				{"2026-09-30", "2026-09-30"},
+				{"2026-10-30", "2026-10-30"},
+				{"2026-11-30", "2026-11-30"},
+				{"2026-12-31", "2026-12-31"},
+			}))
+		})
+
+		It("Yearly by month, month day and day", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20251127\n" +
+				"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH\n" +
+				"END:VEVENT\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260331\n" +
+				"RRULE:FREQ=YEARLY;BYMONTH=3,6,9,12;BYMONTHDAY=-1\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			Expect(err).Should(BeNil())
+			Expect(getDays(cal.Events[0].Occurrences(limit))).To(Equal([][]string{
+				{"2025-11-27", "2025-11-27"},
+				{"2026-11-26", "2026-11-26"},
+			}))
+			Expect(getDays(cal.Events[1].Occurrences(limit))).To(Equal([][]string{
+				{"2026-03-31", "2026-03-31"},
+				{"2026-06-30", "2026-06-30"},
+				{"2026-09-30", "2026-09-30"},
+				{"2026-12-31", "2026-12-31"},
+			}))
+		})
+
+		It("Unsupported rules", func() {
+			cal, err := Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260907\n" +
+				"RRULE:FREQ=HOURLY\n" +
+				"END:VEVENT\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260907\n" +
+				"RRULE:FREQ=YEARLY;BYWEEKNO=20\n" +
+				"END:VEVENT\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260907\n" +
+				"RRULE:FREQ=WEEKLY;BYDAY=2MO\n" +
+				"END:VEVENT\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20260908\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+
+			// The events with the rules not supported don't fail the file.
+			Expect(err).Should(BeNil())
+			Expect(len(cal.Events)).To(Equal(4))
+			Expect(cal.Events[0].Unsupported).To(Equal("Unsupported frequency HOURLY"))
+			Expect(cal.Events[1].Unsupported).To(Equal("Unsupported part BYWEEKNO"))
+			Expect(cal.Events[2].Unsupported).To(Equal("Unsupported ordinal day with frequency WEEKLY"))
+			for _, e := range cal.Events[0:3] {
+				Expect(e.Occurrences(limit)).To(BeEmpty())
+			}
+			Expect(getDays(cal.Events[3].Occurrences(limit))).To(Equal([][]string{
+				{"2026-09-08", "2026-09-08"},
+			}))
+		})
+	})
+
+	Context("Marshal", func() {
+
+		It("Round trip with folding and escaping", func() {
+			stamp := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+			cal := &Calendar{
+				Name: "Time Master - Daniele",
+				Events: []*Event{
+					{
+						Uid:         "abc@time-master",
+						Summary:     "CLIENT1 / ACT1: Analysis, design; review",
+						Description: "Task: ACT1.analysis\nLong description " + strings.Repeat("è", 60),
+						Categories:  []string{"ACT1", "CLIENT, Inc"},
+						Start:       time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+						End:         time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC),
+						AllDay:      true,
+						Attendees:   []Attendee{{Name: "Rondina, Daniele", Email: "geaaru@example.com"}},
+						Stamp:       stamp,
+					},
+					{
+						Uid:   "def@time-master",
+						Start: time.Date(2026, 9, 2, 9, 0, 0, 0, time.UTC),
+						End:   time.Date(2026, 9, 2, 10, 30, 0, 0, time.UTC),
+						Stamp: stamp,
+					},
+				},
+			}
+
+			data := cal.Marshal()
+			for _, line := range strings.Split(strings.TrimSuffix(string(data), "\r\n"), "\r\n") {
+				Expect(len(line) <= 75).To(Equal(true))
+				Expect(utf8.ValidString(line)).To(Equal(true))
+			}
+			Expect(string(data)).To(ContainSubstring("DTSTART;VALUE=DATE:20260901\r\n"))
+			Expect(string(data)).To(ContainSubstring("DTSTAMP:20260901T080000Z\r\n"))
+			Expect(string(data)).To(ContainSubstring("DTEND:20260902T103000Z\r\n"))
+
+			parsed, err := Parse(data)
+			Expect(err).Should(BeNil())
+			Expect(parsed.Name).To(Equal(cal.Name))
+			Expect(len(parsed.Events)).To(Equal(2))
+
+			e := parsed.Events[0]
+			Expect(e.Uid).To(Equal("abc@time-master"))
+			Expect(e.Summary).To(Equal(cal.Events[0].Summary))
+			Expect(e.Description).To(Equal(cal.Events[0].Description))
+			Expect(e.Categories).To(Equal([]string{"ACT1", "CLIENT, Inc"}))
+			Expect(e.Attendees).To(Equal(cal.Events[0].Attendees))
+			Expect(e.AllDay).To(Equal(true))
+			Expect(e.End.Format("2006-01-02")).To(Equal("2026-09-05"))
+			Expect(parsed.Events[1].End.Equal(cal.Events[1].End)).To(Equal(true))
+		})
+	})
+})
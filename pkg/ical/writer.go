@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	PRODID = "-//geaaru//Time Master//EN"
+
+	// Max length in octets of the lines of the file.
+	maxLineLength = 75
+)
+
+// Marshal returns the calendar in the iCalendar format. The recurrence
+// rules and the excluded dates of the events are not written.
+func (c *Calendar) Marshal() []byte {
+	var buf bytes.Buffer
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+PRODID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		e.marshal(&buf)
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+func (e *Event) marshal(buf *bytes.Buffer) {
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	writeLine(buf, "BEGIN:VEVENT")
+	writeLine(buf, "UID:"+e.Uid)
+	writeLine(buf, "DTSTAMP:"+formatDateTime(stamp))
+	if e.AllDay {
+		writeLine(buf, "DTSTART;VALUE=DATE:"+e.Start.Format("20060102"))
+		writeLine(buf, "DTEND;VALUE=DATE:"+e.End.Format("20060102"))
+	} else {
+		writeLine(buf, "DTSTART:"+formatDateTime(e.Start))
+		writeLine(buf, "DTEND:"+formatDateTime(e.End))
+	}
+	if e.Summary != "" {
+		writeLine(buf, "SUMMARY:"+escapeText(e.Summary))
+	}
+	if e.Description != "" {
+		writeLine(buf, "DESCRIPTION:"+escapeText(e.Description))
+	}
+	if e.Status != "" {
+		writeLine(buf, "STATUS:"+e.Status)
+	}
+	if len(e.Categories) > 0 {
+		categories := []string{}
+		for _, c := range e.Categories {
+			categories = append(categories, escapeText(c))
+		}
+		writeLine(buf, "CATEGORIES:"+strings.Join(categories, ","))
+	}
+	if e.Organizer != nil {
+		writeLine(buf, "ORGANIZER"+e.Organizer.marshal())
+	}
+	for _, a := range e.Attendees {
+		writeLine(buf, "ATTENDEE"+a.marshal())
+	}
+	writeLine(buf, "END:VEVENT")
+}
+
+// Return the parameters and the value of the attendee property.
+func (a *Attendee) marshal() string {
+	ans := ""
+	if a.Name != "" {
+		ans += ";CN=" + quoteParam(a.Name)
+	}
+	if a.PartStat != "" {
+		ans += ";PARTSTAT=" + a.PartStat
+	}
+	return ans + ":mailto:" + a.Email
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+func quoteParam(s string) string {
+	s = strings.ReplaceAll(s, `"`, "'")
+	if strings.ContainsAny(s, ";:,") {
+		return fmt.Sprintf(`"%s"`, s)
+	}
+	return s
+}
+
+// Write the line folded to the max length of 75 octets without
+// split the UTF-8 characters.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		idx := limit
+		for idx > 0 && !utf8.RuneStart(line[idx]) {
+			idx--
+		}
+		buf.WriteString(line[0:idx] + "\r\n ")
+		line = line[idx:]
+		// The space of the continuation line is part of the length.
+		limit = maxLineLength - 1
+	}
+	buf.WriteString(line + "\r\n")
+}
@@ -0,0 +1,507 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	formatter "github.com/geaaru/time-master/pkg/formatter"
+	ical "github.com/geaaru/time-master/pkg/ical"
+	log "github.com/geaaru/time-master/pkg/logger"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ABSENCE_HOLIDAYS   = "holidays"
+	ABSENCE_SICK       = "sick"
+	ABSENCE_UNEMPLOYED = "unemployed"
+
+	ABSENCE_FIELD_SUMMARY  = "summary"
+	ABSENCE_FIELD_CATEGORY = "category"
+
+	REPORT_EVENT = "event"
+	// Events with a recurrence rule not supported.
+	REPORT_RULE = "rule"
+)
+
+// TmAbsencesMapper maps the events of a calendar to the
+// absences of the resources.
+type TmAbsencesMapper struct {
+	// Map attendees (email or name) or calendar names to users.
+	Users []TmAbsencesUser `json:"users,omitempty" yaml:"users,omitempty"`
+	// Rules applied in order to classify the events.
+	Rules []TmAbsencesRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// Type of the events without rules matched. If empty the
+	// events are ignored.
+	DefaultType string `json:"default_type,omitempty" yaml:"default_type,omitempty"`
+}
+
+type TmAbsencesUser struct {
+	Source string `json:"source" yaml:"source"`
+	User   string `json:"user" yaml:"user"`
+}
+
+type TmAbsencesRule struct {
+	// Field to check: summary (default) or category
+	Field string `json:"field,omitempty" yaml:"field,omitempty"`
+	Match string `json:"match" yaml:"match"`
+	// Type of the absence: holidays, sick or unemployed
+	Type string `json:"type" yaml:"type"`
+
+	regex *regexp.Regexp
+}
+
+// TmAbsence is a period of absence of a user. The dates are inclusive.
+type TmAbsence struct {
+	User        string
+	Type        string
+	StartPeriod string
+	EndPeriod   string
+	Summary     string
+}
+
+type TmAbsencesImporter struct {
+	Logger    *log.TmLogger
+	Config    *specs.TimeMasterConfig
+	Mapper    *TmAbsencesMapper
+	Resources []specs.Resource
+	Report    *TmImportReport
+	// Period of the absences to import (YYYY-MM-DD)
+	From string
+	To   string
+	// Import the events with a time as absences of the whole days.
+	// Without it only the all-day events are imported.
+	TimedEvents bool
+}
+
+func TmAbsencesMapperFromYaml(data []byte) (*TmAbsencesMapper, error) {
+	ans := &TmAbsencesMapper{}
+	if err := yamlv2.Unmarshal(data, ans); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func NewTmAbsencesImporter(config *specs.TimeMasterConfig, resources []specs.Resource) *TmAbsencesImporter {
+	return &TmAbsencesImporter{
+		Logger:    log.NewTmLogger(config),
+		Config:    config,
+		Mapper:    &TmAbsencesMapper{},
+		Resources: resources,
+		Report:    NewTmImportReport(),
+	}
+}
+
+func isValidAbsenceType(t string) bool {
+	return t == ABSENCE_HOLIDAYS || t == ABSENCE_SICK || t == ABSENCE_UNEMPLOYED
+}
+
+func (i *TmAbsencesImporter) ImportMapper(mapper *TmAbsencesMapper) error {
+	if mapper.DefaultType != "" && !isValidAbsenceType(mapper.DefaultType) {
+		return errors.New("Invalid default type " + mapper.DefaultType)
+	}
+
+	for idx := range mapper.Rules {
+		r := &mapper.Rules[idx]
+		if r.Field == "" {
+			r.Field = ABSENCE_FIELD_SUMMARY
+		}
+		if r.Field != ABSENCE_FIELD_SUMMARY && r.Field != ABSENCE_FIELD_CATEGORY {
+			return errors.New("Invalid field " + r.Field + " for the rule " + r.Match)
+		}
+		if !isValidAbsenceType(r.Type) {
+			return errors.New("Invalid type " + r.Type + " for the rule " + r.Match)
+		}
+
+		regex, err := regexp.Compile(r.Match)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid regex %s: %s", r.Match, err.Error()))
+		}
+		r.regex = regex
+	}
+
+	i.Mapper = mapper
+	return nil
+}
+
+func (i *TmAbsencesImporter) SetPeriod(from, to string) {
+	i.From = from
+	i.To = to
+}
+
+// GetEventType returns the type of absence of the event or an empty
+// string if the event must be ignored.
+func (i *TmAbsencesImporter) GetEventType(e *ical.Event) string {
+	for _, r := range i.Mapper.Rules {
+		if r.Field == ABSENCE_FIELD_CATEGORY {
+			for _, c := range e.Categories {
+				if r.regex.MatchString(c) {
+					return r.Type
+				}
+			}
+		} else if r.regex.MatchString(e.Summary) {
+			return r.Type
+		}
+	}
+
+	return i.Mapper.DefaultType
+}
+
+// Return the user of the mapper or of the resources.
+func (i *TmAbsencesImporter) getUser(source string) string {
+	if source == "" {
+		return ""
+	}
+
+	for _, u := range i.Mapper.Users {
+		if strings.EqualFold(u.Source, source) {
+			return u.User
+		}
+	}
+
+	for _, r := range i.Resources {
+		if r.User == source || strings.EqualFold(r.Name, source) {
+			return r.User
+		}
+		for _, email := range r.Email {
+			if strings.EqualFold(email, source) {
+				return r.User
+			}
+		}
+	}
+
+	return ""
+}
+
+// GetEventUsers returns the users of the event from the attendees
+// or, without attendees, from the name of the calendar.
+func (i *TmAbsencesImporter) GetEventUsers(e *ical.Event, calendarName string) ([]string, []string) {
+	users := []string{}
+	unmapped := []string{}
+
+	add := func(u string) {
+		for _, v := range users {
+			if v == u {
+				return
+			}
+		}
+		users = append(users, u)
+	}
+
+	for _, a := range e.Attendees {
+		if u := i.getUser(a.Email); u != "" {
+			add(u)
+		} else if u := i.getUser(a.Name); u != "" {
+			add(u)
+		} else if a.Email != "" {
+			unmapped = append(unmapped, a.Email)
+		} else {
+			unmapped = append(unmapped, a.Name)
+		}
+	}
+
+	if len(e.Attendees) == 0 {
+		if u := i.getUser(calendarName); u != "" {
+			add(u)
+		} else {
+			unmapped = append(unmapped, calendarName)
+		}
+	}
+
+	return users, unmapped
+}
+
+// GetAbsences returns the absences of the calendar.
+func (i *TmAbsencesImporter) GetAbsences(cal *ical.Calendar) ([]TmAbsence, error) {
+	ans := []TmAbsence{}
+
+	// Without end date the recurring events are expanded for one year.
+	limit := time.Now().AddDate(1, 0, 0)
+	if i.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", i.To, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		limit = to.AddDate(0, 0, 1)
+	}
+
+	for _, e := range cal.Events {
+		if e.Status == "CANCELLED" {
+			i.Logger.Debug("Skipping cancelled event " + e.Summary)
+			continue
+		}
+		if e.Unsupported != "" {
+			i.Logger.Warning(fmt.Sprintf("Skipping event %s: %s", e.Summary, e.Unsupported))
+			i.Report.AddIgnored(REPORT_RULE, e.Summary, 0)
+			continue
+		}
+		if !e.AllDay && !i.TimedEvents {
+			i.Logger.Debug("Skipping timed event " + e.Summary)
+			i.Report.AddIgnored(REPORT_EVENT, e.Summary, 0)
+			continue
+		}
+
+		t := i.GetEventType(e)
+		if t == "" {
+			i.Logger.Debug("Ignoring event " + e.Summary)
+			i.Report.AddIgnored(REPORT_EVENT, e.Summary, 0)
+			continue
+		}
+
+		users, unmapped := i.GetEventUsers(e, cal.Name)
+		for _, u := range unmapped {
+			i.Report.AddUnmapped(REPORT_USER, u, 0)
+		}
+
+		for _, o := range e.Occurrences(limit) {
+			start, end := o.GetDays()
+			if i.From != "" && end < i.From {
+				continue
+			}
+
+			for _, u := range users {
+				ans = append(ans, TmAbsence{
+					User:        u,
+					Type:        t,
+					StartPeriod: start,
+					EndPeriod:   end,
+					Summary:     e.Summary,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(ans, func(x, y int) bool {
+		if ans[x].User != ans[y].User {
+			return ans[x].User < ans[y].User
+		}
+		return ans[x].StartPeriod < ans[y].StartPeriod
+	})
+
+	return ans, nil
+}
+
+func getResourcePeriods(r *specs.Resource, t string) []*specs.Period {
+	ans := []*specs.Period{}
+	switch t {
+	case ABSENCE_HOLIDAYS:
+		for _, h := range r.Holidays {
+			ans = append(ans, h.Period)
+		}
+	case ABSENCE_SICK:
+		for _, s := range r.Sick {
+			ans = append(ans, s.Period)
+		}
+	case ABSENCE_UNEMPLOYED:
+		for _, u := range r.Unemployed {
+			ans = append(ans, u.Period)
+		}
+	}
+	return ans
+}
+
+// GetUncoveredAbsences returns the parts of the absence not contained
+// in the periods of the same type of the resource.
+func GetUncoveredAbsences(r *specs.Resource, a *TmAbsence) []TmAbsence {
+	ans := []TmAbsence{}
+
+	isCovered := func(day string) bool {
+		for _, p := range getResourcePeriods(r, a.Type) {
+			if p == nil {
+				continue
+			}
+			end := p.EndPeriod
+			if end == "" {
+				end = p.StartPeriod
+			}
+			if p.StartPeriod <= day && day <= end {
+				return true
+			}
+		}
+		return false
+	}
+
+	start, err := time.Parse("2006-01-02", a.StartPeriod)
+	if err != nil {
+		return []TmAbsence{*a}
+	}
+
+	var part *TmAbsence
+	for t := start; t.Format("2006-01-02") <= a.EndPeriod; t = t.AddDate(0, 0, 1) {
+		day := t.Format("2006-01-02")
+		if isCovered(day) {
+			part = nil
+			continue
+		}
+		if part == nil {
+			ans = append(ans, *a)
+			part = &ans[len(ans)-1]
+			part.StartPeriod = day
+		}
+		part.EndPeriod = day
+	}
+
+	return ans
+}
+
+// IsAbsencePresent returns true if the absence is already contained
+// in the periods of the same type of the resource.
+func IsAbsencePresent(r *specs.Resource, a *TmAbsence) bool {
+	return len(GetUncoveredAbsences(r, a)) == 0
+}
+
+func newAbsenceNode(a *TmAbsence) *yaml.Node {
+	str := func(v string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	}
+	date := func(v string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v,
+			Style: yaml.DoubleQuotedStyle}
+	}
+
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			str("period"),
+			{
+				Kind: yaml.MappingNode,
+				Content: []*yaml.Node{
+					str("start_period"), date(a.StartPeriod),
+					str("end_period"), date(a.EndPeriod),
+				},
+			},
+		},
+	}
+}
+
+// Add the absence to the sequence of its type of the resource document.
+func addAbsenceNode(root *yaml.Node, a *TmAbsence) {
+	var seq *yaml.Node
+	for k := 0; k+1 < len(root.Content); k += 2 {
+		if root.Content[k].Value == a.Type {
+			seq = root.Content[k+1]
+			break
+		}
+	}
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		if seq == nil {
+			seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			root.Content = append(root.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: a.Type}, seq)
+		} else {
+			// POST: empty value or [] to convert in a block sequence.
+			seq.Kind = yaml.SequenceNode
+			seq.Tag = "!!seq"
+			seq.Value = ""
+		}
+	}
+	seq.Style = 0
+	seq.Content = append(seq.Content, newAbsenceNode(a))
+}
+
+// Add the absence to the copy of the resource without change the
+// slices of the original resource.
+func addResourceAbsence(r *specs.Resource, a *TmAbsence) {
+	p := &specs.Period{StartPeriod: a.StartPeriod, EndPeriod: a.EndPeriod}
+	switch a.Type {
+	case ABSENCE_HOLIDAYS:
+		r.Holidays = append(append([]specs.ResourceHolidays{}, r.Holidays...),
+			specs.ResourceHolidays{Period: p})
+	case ABSENCE_SICK:
+		r.Sick = append(append([]specs.ResourceSick{}, r.Sick...),
+			specs.ResourceSick{Period: p})
+	case ABSENCE_UNEMPLOYED:
+		r.Unemployed = append(append([]specs.ResourceUnemployed{}, r.Unemployed...),
+			specs.ResourceUnemployed{Period: p})
+	}
+}
+
+// MergeResourceFile adds the days of the absences not present to the
+// file of the resource. It returns the original content, the new
+// content and the absences added.
+func (i *TmAbsencesImporter) MergeResourceFile(r *specs.Resource, absences []TmAbsence) ([]byte, []byte, []TmAbsence, error) {
+	added := []TmAbsence{}
+
+	if r.File == "" {
+		return nil, nil, nil, errors.New("No file for the resource " + r.User)
+	}
+
+	original, err := ioutil.ReadFile(r.File)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(original, doc); err != nil {
+		return nil, nil, nil, errors.New("Error on parse file " + r.File + ": " + err.Error())
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, nil, errors.New("Invalid resource file " + r.File)
+	}
+	root := doc.Content[0]
+
+	// Copy of the resource used to check the absences already added.
+	resource := *r
+
+	for idx := range absences {
+		if absences[idx].User != r.User {
+			continue
+		}
+
+		// Only the days not already present are added.
+		for _, part := range GetUncoveredAbsences(&resource, &absences[idx]) {
+			a := part
+			addAbsenceNode(root, &a)
+			addResourceAbsence(&resource, &a)
+			added = append(added, a)
+		}
+	}
+
+	if len(added) == 0 {
+		return original, original, added, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, nil, nil, err
+	}
+	encoder.Close()
+
+	// The content is written in the canonical format of tm fmt so the
+	// untouched parts of a formatted file aren't changed.
+	content, err := formatter.NewTmFormatter(i.Config).Format(buf.Bytes(), formatter.FILE_RESOURCE)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return original, content, added, nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	ical "github.com/geaaru/time-master/pkg/ical"
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Absences Importer Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+
+	calendar := []byte("BEGIN:VCALENDAR\n" +
+		"X-WR-CALNAME:Mario Rossi\n" +
+		"BEGIN:VEVENT\n" +
+		"SUMMARY:Vacation\n" +
+		"CATEGORIES:Holiday\n" +
+		"DTSTART;VALUE=DATE:20260810\n" +
+		"DTEND;VALUE=DATE:20260815\n" +
+		"ATTENDEE;CN=Daniele:mailto:geaaru@example.com\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"SUMMARY:Sick leave\n" +
+		"DTSTART;VALUE=DATE:20260902\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"SUMMARY:Team lunch\n" +
+		"DTSTART:20260903T120000\n" +
+		"DTEND:20260903T140000\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n")
+
+	Context("Merge absences", func() {
+
+		It("Classify and merge without duplicates", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-absences")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			file1 := filepath.Join(tmpDir, "geaaru.yml")
+			err = ioutil.WriteFile(file1, []byte(
+				"user: geaaru\n"+
+					"name: Daniele Rondina\n"+
+					"email: [geaaru@example.com]\n"+
+					"holidays:\n"+
+					"  - period:\n"+
+					"      start_period: \"2026-08-10\"\n"+
+					"      end_period: \"2026-08-14\"\n"), 0644)
+			Expect(err).Should(BeNil())
+			file2 := filepath.Join(tmpDir, "mrossi.yml")
+			err = ioutil.WriteFile(file2, []byte(
+				"# Mario\n"+
+					"user: mrossi\n"+
+					"name: Mario Rossi\n"), 0644)
+			Expect(err).Should(BeNil())
+
+			r1 := specs.NewResource("geaaru", "Daniele Rondina")
+			r1.Email = []string{"geaaru@example.com"}
+			r1.File = file1
+			r1.AddHoliday(specs.ResourceHolidays{
+				Period: &specs.Period{StartPeriod: "2026-08-10", EndPeriod: "2026-08-14"},
+			})
+			r2 := specs.NewResource("mrossi", "Mario Rossi")
+			r2.File = file2
+
+			imp := NewTmAbsencesImporter(config, []specs.Resource{*r1, *r2})
+			err = imp.ImportMapper(&TmAbsencesMapper{
+				Rules: []TmAbsencesRule{
+					{Field: "category", Match: "^Holiday$", Type: ABSENCE_HOLIDAYS},
+					{Match: "(?i)sick", Type: ABSENCE_SICK},
+				},
+			})
+			Expect(err).Should(BeNil())
+
+			cal, err := ical.Parse(calendar)
+			Expect(err).Should(BeNil())
+
+			absences, err := imp.GetAbsences(cal)
+			Expect(err).Should(BeNil())
+			Expect(absences).To(Equal([]TmAbsence{
+				{User: "geaaru", Type: ABSENCE_HOLIDAYS, StartPeriod: "2026-08-10",
+					EndPeriod: "2026-08-14", Summary: "Vacation"},
+				{User: "mrossi", Type: ABSENCE_SICK, StartPeriod: "2026-09-02",
+					EndPeriod: "2026-09-02", Summary: "Sick leave"},
+			}))
+			Expect(imp.Report.Ignored[0].Value).To(Equal("Team lunch"))
+
+			_, _, added, err := imp.MergeResourceFile(r1, absences)
+			Expect(err).Should(BeNil())
+			Expect(len(added)).To(Equal(0))
+
+			_, content, added, err := imp.MergeResourceFile(r2, absences)
+			Expect(err).Should(BeNil())
+			Expect(len(added)).To(Equal(1))
+			Expect(string(content)).To(Equal(
+				"# Mario\n" +
+					"user: mrossi\n" +
+					"name: Mario Rossi\n" +
+					"sick:\n" +
+					"  - period:\n" +
+					"      start_period: \"2026-09-02\"\n" +
+					"      end_period: \"2026-09-02\"\n"))
+
+			r, err := specs.ResourceFromYaml(content, file2)
+			Expect(err).Should(BeNil())
+			Expect(r.Validate()).Should(BeNil())
+			Expect(r.Sick[0].Period.StartPeriod).To(Equal("2026-09-02"))
+		})
+
+		It("Timed events", func() {
+			imp := NewTmAbsencesImporter(config, []specs.Resource{*specs.NewResource("mrossi", "Mario Rossi")})
+			err := imp.ImportMapper(&TmAbsencesMapper{DefaultType: ABSENCE_HOLIDAYS})
+			Expect(err).Should(BeNil())
+
+			cal, err := ical.Parse(calendar)
+			Expect(err).Should(BeNil())
+
+			absences, err := imp.GetAbsences(cal)
+			Expect(err).Should(BeNil())
+			Expect(len(absences)).To(Equal(1))
+			Expect(absences[0].Summary).To(Equal("Sick leave"))
+			Expect(imp.Report.Ignored[0].Value).To(Equal("Team lunch"))
+
+			imp.TimedEvents = true
+			absences, err = imp.GetAbsences(cal)
+			Expect(err).Should(BeNil())
+			Expect(len(absences)).To(Equal(2))
+			Expect([]string{absences[1].Summary, absences[1].StartPeriod, absences[1].EndPeriod}).To(
+				Equal([]string{"Team lunch", "2026-09-03", "2026-09-03"}))
+		})
+
+		It("Add only the days not present", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-absences")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			file := filepath.Join(tmpDir, "geaaru.yml")
+			err = ioutil.WriteFile(file, []byte(
+				"user: geaaru\n"+
+					"name: Daniele Rondina\n"+
+					"holidays:\n"+
+					"  - period:\n"+
+					"      start_period: \"2026-08-12\"\n"+
+					"      end_period: \"2026-08-13\"\n"), 0644)
+			Expect(err).Should(BeNil())
+
+			r := specs.NewResource("geaaru", "Daniele Rondina")
+			r.File = file
+			r.AddHoliday(specs.ResourceHolidays{
+				Period: &specs.Period{StartPeriod: "2026-08-12", EndPeriod: "2026-08-13"},
+			})
+
+			imp := NewTmAbsencesImporter(config, []specs.Resource{*r})
+			_, _, added, err := imp.MergeResourceFile(r, []TmAbsence{
+				{User: "geaaru", Type: ABSENCE_HOLIDAYS, StartPeriod: "2026-08-10",
+					EndPeriod: "2026-08-14", Summary: "Vacation"},
+				{User: "geaaru", Type: ABSENCE_HOLIDAYS, StartPeriod: "2026-08-14",
+					EndPeriod: "2026-08-17", Summary: "Trip"},
+			})
+			Expect(err).Should(BeNil())
+			Expect(added).To(Equal([]TmAbsence{
+				{User: "geaaru", Type: ABSENCE_HOLIDAYS, StartPeriod: "2026-08-10",
+					EndPeriod: "2026-08-11", Summary: "Vacation"},
+				{User: "geaaru", Type: ABSENCE_HOLIDAYS, StartPeriod: "2026-08-14",
+					EndPeriod: "2026-08-14", Summary: "Vacation"},
+				{User: "geaaru", Type: ABSENCE_HOLIDAYS, StartPeriod: "2026-08-15",
+					EndPeriod: "2026-08-17", Summary: "Trip"},
+			}))
+		})
+	})
+})
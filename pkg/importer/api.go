@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/geaaru/time-master/pkg/logger"
+)
+
+// TmApiClient is a simple client of the REST API used by the importers.
+// The requests that reach the rate limit are retried with backoff.
+type TmApiClient struct {
+	Client     *http.Client
+	Logger     *log.TmLogger
+	MaxRetries int
+	// Wait time of the first retry when the server doesn't return
+	// the Retry-After header. It's doubled on every retry.
+	RetryWait time.Duration
+	// Callback used to set the authentication headers.
+	Auth func(*http.Request)
+}
+
+// TmApiError is the error of a request completed with a status
+// not successful.
+type TmApiError struct {
+	Url        string
+	StatusCode int
+	Body       string
+}
+
+func (e *TmApiError) Error() string {
+	return fmt.Sprintf("Request %s failed with status %d: %s",
+		e.Url, e.StatusCode, e.Body)
+}
+
+func NewTmApiClient(logger *log.TmLogger, maxRetries int) *TmApiClient {
+	return &TmApiClient{
+		Client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+		Logger:     logger,
+		MaxRetries: maxRetries,
+		RetryWait:  time.Second,
+	}
+}
+
+func (c *TmApiClient) getRetryWait(resp *http.Response, retry int) time.Duration {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	return c.RetryWait * time.Duration(1<<uint(retry))
+}
+
+// GetJson executes a GET request and decodes the JSON response on
+// target. It returns the headers of the response.
+func (c *TmApiClient) GetJson(url string, target interface{}) (http.Header, error) {
+	retry := 0
+
+	for {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if c.Auth != nil {
+			c.Auth(req)
+		}
+
+		c.Logger.Debug("GET " + url)
+		resp, err := c.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode == http.StatusServiceUnavailable {
+			if retry >= c.MaxRetries {
+				return nil, errors.New(fmt.Sprintf(
+					"Rate limit reached for %s after %d retries", url, retry))
+			}
+
+			wait := c.getRetryWait(resp, retry)
+			c.Logger.Info(fmt.Sprintf("Rate limit reached. Waiting %s before retry.", wait))
+			time.Sleep(wait)
+			retry++
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, &TmApiError{
+				Url:        url,
+				StatusCode: resp.StatusCode,
+				Body:       string(data),
+			}
+		}
+
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, errors.New("Invalid response of " + url + ": " + err.Error())
+		}
+
+		return resp.Header, nil
+	}
+}
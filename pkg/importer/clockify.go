@@ -0,0 +1,197 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	"github.com/geaaru/time-master/pkg/tools"
+)
+
+const (
+	CLOCKIFY_SOURCE = "clockify"
+)
+
+// TmClockifyImporter imports the detailed report of Clockify
+// exported in CSV or JSON format.
+type TmClockifyImporter struct {
+	*TmTrackingImporter
+}
+
+type clockifyReport struct {
+	TimeEntries []clockifyEntry `json:"timeentries"`
+}
+
+type clockifyEntry struct {
+	Id          string `json:"_id"`
+	UserName    string `json:"userName"`
+	ClientName  string `json:"clientName"`
+	ProjectName string `json:"projectName"`
+	TaskName    string `json:"taskName"`
+	Description string `json:"description"`
+	Tags        []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+	TimeInterval struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"timeInterval"`
+}
+
+func NewTmClockifyImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string, opts ImportOpts) *TmClockifyImporter {
+	return &TmClockifyImporter{
+		TmTrackingImporter: NewTmTrackingImporter(config, tmDir, filePrefix, CLOCKIFY_SOURCE, opts),
+	}
+}
+
+func (i *TmClockifyImporter) LoadTimesheets(file string) error {
+	if !tools.Exists(file) {
+		return errors.New("File " + file + " not present")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var entries []TmTrackingEntry
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		entries, err = i.parseJson(data)
+	} else {
+		entries, err = i.parseCsv(data)
+	}
+	if err != nil {
+		return err
+	}
+
+	return i.ConvertEntries(entries)
+}
+
+func (i *TmClockifyImporter) parseJson(data []byte) ([]TmTrackingEntry, error) {
+	ans := []TmTrackingEntry{}
+	clockifyEntries := []clockifyEntry{}
+
+	// The report contains the entries in the timeentries field.
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		report := clockifyReport{}
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, err
+		}
+		clockifyEntries = report.TimeEntries
+	} else if err := json.Unmarshal(data, &clockifyEntries); err != nil {
+		return nil, err
+	}
+
+	for _, e := range clockifyEntries {
+		start, err := time.Parse(time.RFC3339, e.TimeInterval.Start)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid start of the entry %s: %s",
+				e.Id, err.Error()))
+		}
+		end, err := time.Parse(time.RFC3339, e.TimeInterval.End)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid end of the entry %s: %s",
+				e.Id, err.Error()))
+		}
+
+		tags := []string{}
+		for _, t := range e.Tags {
+			tags = append(tags, t.Name)
+		}
+
+		// The entries are split at the local midnight.
+		ans = append(ans, TmTrackingEntry{
+			Id:          e.Id,
+			User:        e.UserName,
+			Client:      e.ClientName,
+			Project:     e.ProjectName,
+			Task:        e.TaskName,
+			Description: e.Description,
+			Tags:        tags,
+			Start:       start.In(time.Local),
+			End:         end.In(time.Local),
+		})
+	}
+
+	return ans, nil
+}
+
+func (i *TmClockifyImporter) parseCsv(data []byte) ([]TmTrackingEntry, error) {
+	ans := []TmTrackingEntry{}
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	var indexes map[string]int
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if indexes == nil {
+			indexes = getCsvHeaderIndexes(row)
+			for _, c := range []string{"user", "start date", "start time", "end date", "end time"} {
+				if _, ok := indexes[c]; !ok {
+					return nil, errors.New("Missing the column " + c + " on the Clockify export")
+				}
+			}
+			continue
+		}
+
+		// The default format is the US format of Clockify.
+		start, err := i.Mapper.ParseDateTime(
+			getCsvValue(row, indexes, "Start Date"), getCsvValue(row, indexes, "Start Time"),
+			"01/02/2006", "03:04:05 PM")
+		if err != nil {
+			return nil, err
+		}
+		end, err := i.Mapper.ParseDateTime(
+			getCsvValue(row, indexes, "End Date"), getCsvValue(row, indexes, "End Time"),
+			"01/02/2006", "03:04:05 PM")
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, TmTrackingEntry{
+			User:        getCsvValue(row, indexes, "User"),
+			Client:      getCsvValue(row, indexes, "Client"),
+			Project:     getCsvValue(row, indexes, "Project"),
+			Task:        getCsvValue(row, indexes, "Task"),
+			Description: getCsvValue(row, indexes, "Description"),
+			Tags:        splitCsvTags(getCsvValue(row, indexes, "Tags")),
+			Start:       start,
+			End:         end,
+		})
+	}
+
+	return ans, nil
+}
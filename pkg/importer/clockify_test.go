@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clockify Importer Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "tm-clockify")
+		Expect(err).Should(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	Context("CSV export", func() {
+
+		It("US date format", func() {
+			file := filepath.Join(tmpDir, "clockify.csv")
+			err := ioutil.WriteFile(file, []byte(
+				"\ufeffProject,Client,Description,Task,User,Group,Email,Tags,Billable,Start Date,Start Time,End Date,End Time,Duration (h),Duration (decimal)\n"+
+					"Website,ACME,Login,Backend,Mario Rossi,,m@x.it,,Yes,09/01/2026,11:00:00 PM,09/02/2026,02:00:00 AM,03:00:00,3.00\n"),
+				0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmClockifyImporter(config, tmpDir, "", ImportOpts{})
+			Expect(imp.ImportMapper(&TmTrackingMapper{
+				Rules: []TmMappingRule{
+					{Field: "task", Match: `^(\w+)$`, Task: "ACT.$1"},
+				},
+			})).Should(BeNil())
+			Expect(imp.LoadTimesheets(file)).Should(BeNil())
+
+			ts := (*imp.GetTimesheets())[0].Timesheets
+			Expect(len(ts)).To(Equal(2))
+			Expect([]string{ts[0].Period.StartPeriod, ts[0].Task, ts[0].Duration}).To(
+				Equal([]string{"2026-09-01", "ACT.Backend", "1h"}))
+			Expect([]string{ts[1].Period.StartPeriod, ts[1].Task, ts[1].Duration}).To(
+				Equal([]string{"2026-09-02", "ACT.Backend", "2h"}))
+			Expect(ts[0].Source).To(Equal(CLOCKIFY_SOURCE))
+		})
+	})
+
+	Context("JSON export", func() {
+
+		It("Detailed report", func() {
+			file := filepath.Join(tmpDir, "clockify.json")
+			err := ioutil.WriteFile(file, []byte(`{"timeentries":[
+				{"_id":"5f1","userName":"Mario Rossi","projectName":"Website","taskName":"",
+				 "description":"Review","tags":[{"name":"review"}],
+				 "timeInterval":{"start":"2026-09-03T14:00:00+02:00","end":"2026-09-03T16:30:00+02:00"}}]}`),
+				0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmClockifyImporter(config, tmpDir, "", ImportOpts{})
+			Expect(imp.ImportMapper(&TmTrackingMapper{
+				Resources: []TmTrackingResource{{Source: "Mario Rossi", Name: "mrossi"}},
+			})).Should(BeNil())
+			Expect(imp.LoadTimesheets(file)).Should(BeNil())
+
+			rt := specs.NewResourceTimesheet("mrossi", "2026-09-03", "Website", "2h30m")
+			rt.Note = "Review"
+			rt.Source = CLOCKIFY_SOURCE
+			rt.ExternalId = "5f1"
+
+			Expect((*imp.GetTimesheets())[0].Timesheets).To(Equal([]specs.ResourceTimesheet{*rt}))
+			Expect(imp.GetReport().GetUnmapped(REPORT_PROJECT)).To(Equal([]string{"Website"}))
+
+			data, err := imp.MapperSkeleton()
+			Expect(err).Should(BeNil())
+			Expect(string(data)).To(ContainSubstring("match: ^Website$"))
+		})
+
+		It("UTC entries over the local midnight", func() {
+			local := time.Local
+			time.Local = time.FixedZone("CEST", 2*3600)
+			defer func() { time.Local = local }()
+
+			file := filepath.Join(tmpDir, "clockify.json")
+			err := ioutil.WriteFile(file, []byte(`[
+				{"_id":"5f2","userName":"Mario Rossi","projectName":"Website","taskName":"",
+				 "description":"Deploy","tags":[],
+				 "timeInterval":{"start":"2026-09-03T21:00:00Z","end":"2026-09-03T22:45:00Z"}}]`),
+				0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmClockifyImporter(config, tmpDir, "", ImportOpts{})
+			Expect(imp.ImportMapper(&TmTrackingMapper{
+				Resources: []TmTrackingResource{{Source: "Mario Rossi", Name: "mrossi"}},
+			})).Should(BeNil())
+			Expect(imp.LoadTimesheets(file)).Should(BeNil())
+
+			ts := (*imp.GetTimesheets())[0].Timesheets
+			Expect(len(ts)).To(Equal(2))
+			Expect([]string{ts[0].Period.StartPeriod, ts[0].Duration}).To(
+				Equal([]string{"2026-09-03", "1h"}))
+			Expect([]string{ts[1].Period.StartPeriod, ts[1].Duration}).To(
+				Equal([]string{"2026-09-04", "45m"}))
+		})
+	})
+})
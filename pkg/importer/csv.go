@@ -0,0 +1,472 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+	"github.com/geaaru/time-master/pkg/tools"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	CSV_COLUMN_DATE     = "date"
+	CSV_COLUMN_USER     = "user"
+	CSV_COLUMN_DURATION = "duration"
+	CSV_COLUMN_TASK     = "task"
+	CSV_COLUMN_NOTE     = "note"
+	CSV_COLUMN_ID       = "id"
+
+	CSV_SOURCE = "csv"
+
+	// Supported duration formats
+	CSV_DURATION_TM      = "duration"
+	CSV_DURATION_HOURS   = "hours"
+	CSV_DURATION_MINUTES = "minutes"
+	CSV_DURATION_SECONDS = "seconds"
+	CSV_DURATION_HHMM    = "hh:mm"
+	CSV_DURATION_HHMMSS  = "hh:mm:ss"
+)
+
+type TmCsvImporter struct {
+	*DefaultImporter
+	Mapper          *TmCsvMapper
+	ResourceMapping map[string]string
+	Rules           *TmRuleEngine
+	ignoredRegexes  []*regexp.Regexp
+}
+
+// TmCsvMapper describes the layout of the CSV/TSV file and how
+// the rows are mapped to the timesheets.
+type TmCsvMapper struct {
+	// The delimiter of the fields. Default ",". Use "tab" or "\t" for TSV.
+	Delimiter string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+	// Number of rows to skip before the header or the data.
+	SkipRows int `json:"skip_rows,omitempty" yaml:"skip_rows,omitempty"`
+	// Define if the first row contains the headers. Default true.
+	Header *bool `json:"header,omitempty" yaml:"header,omitempty"`
+
+	// The columns are defined with the name of the header or
+	// with the position (starting from 1).
+	Columns TmCsvColumns `json:"columns" yaml:"columns"`
+
+	// Go layout of the date. Default 2006-01-02.
+	DateFormat string `json:"date_format,omitempty" yaml:"date_format,omitempty"`
+	// Format of the duration: duration (default) | hours | minutes |
+	// seconds | hh:mm | hh:mm:ss
+	DurationFormat   string `json:"duration_format,omitempty" yaml:"duration_format,omitempty"`
+	DecimalSeparator string `json:"decimal_separator,omitempty" yaml:"decimal_separator,omitempty"`
+
+	Resources []TmCsvResource `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Tasks     []TmMappingRule `json:"tasks,omitempty" yaml:"tasks,omitempty"`
+	// Regexes of the tasks/issues to ignore.
+	Ignored []string `json:"ignored,omitempty" yaml:"ignored,omitempty"`
+}
+
+type TmCsvColumns struct {
+	Date     string `json:"date" yaml:"date"`
+	User     string `json:"user" yaml:"user"`
+	Duration string `json:"duration" yaml:"duration"`
+	Task     string `json:"task" yaml:"task"`
+	Note     string `json:"note,omitempty" yaml:"note,omitempty"`
+	// Column with the unique id of the entry used on merge.
+	Id string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+type TmCsvResource struct {
+	Source string `json:"source" yaml:"source"`
+	Name   string `json:"name" yaml:"name"`
+}
+
+type TmCsvRow struct {
+	Id       string
+	Date     string
+	User     string
+	Duration string
+	Task     string
+	Note     string
+}
+
+func TmCsvMapperFromYaml(data []byte) (*TmCsvMapper, error) {
+	ans := &TmCsvMapper{}
+	if err := yaml.Unmarshal(data, ans); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func NewTmCsvImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string, opts ImportOpts) *TmCsvImporter {
+	return &TmCsvImporter{
+		DefaultImporter: NewDefaultImporter(config, tmDir, filePrefix, opts),
+		Mapper:          &TmCsvMapper{},
+		ResourceMapping: make(map[string]string, 0),
+		Rules:           NewTmRuleEngine(),
+		ignoredRegexes:  []*regexp.Regexp{},
+	}
+}
+
+func (i *TmCsvImporter) ImportMapper(mapper *TmCsvMapper) error {
+	i.Mapper = mapper
+
+	for _, r := range mapper.Resources {
+		i.ResourceMapping[r.Source] = r.Name
+	}
+
+	err := i.Rules.AddRules(mapper.Tasks, CSV_COLUMN_TASK)
+	if err != nil {
+		return err
+	}
+
+	for _, ignored := range mapper.Ignored {
+		r, err := regexp.Compile(ignored)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid regex %s: %s", ignored, err.Error()))
+		}
+		i.ignoredRegexes = append(i.ignoredRegexes, r)
+	}
+
+	return nil
+}
+
+func (m *TmCsvMapper) GetDelimiter() rune {
+	switch m.Delimiter {
+	case "":
+		return ','
+	case "tab", "\\t":
+		return '\t'
+	default:
+		return []rune(m.Delimiter)[0]
+	}
+}
+
+func (m *TmCsvMapper) HasHeader() bool {
+	return m.Header == nil || *m.Header
+}
+
+// Resolve the index of the column from the header name or the position.
+func (m *TmCsvMapper) getColumnIndex(column, value string, header []string) (int, error) {
+	for idx, h := range header {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), value) {
+			return idx, nil
+		}
+	}
+
+	pos, err := strconv.Atoi(value)
+	if err == nil && pos > 0 {
+		return pos - 1, nil
+	}
+
+	if len(header) > 0 {
+		return -1, errors.New(fmt.Sprintf(
+			"Column %s (%s) not found. Headers found: %s",
+			column, value, strings.Join(header, ", ")))
+	}
+
+	return -1, errors.New(fmt.Sprintf(
+		"Invalid position %s for the column %s", value, column))
+}
+
+func (m *TmCsvMapper) GetColumnsIndexes(header []string) (map[string]int, error) {
+	ans := make(map[string]int, 0)
+
+	columns := map[string]string{
+		CSV_COLUMN_DATE:     m.Columns.Date,
+		CSV_COLUMN_USER:     m.Columns.User,
+		CSV_COLUMN_DURATION: m.Columns.Duration,
+		CSV_COLUMN_TASK:     m.Columns.Task,
+		CSV_COLUMN_NOTE:     m.Columns.Note,
+		CSV_COLUMN_ID:       m.Columns.Id,
+	}
+
+	for column, value := range columns {
+		if value == "" {
+			if column == CSV_COLUMN_NOTE || column == CSV_COLUMN_ID {
+				continue
+			}
+			return nil, errors.New("Missing definition of the column " + column)
+		}
+
+		idx, err := m.getColumnIndex(column, value, header)
+		if err != nil {
+			return nil, err
+		}
+		ans[column] = idx
+	}
+
+	return ans, nil
+}
+
+func (i *TmCsvImporter) LoadTimesheets(csvFile string) error {
+	if !tools.Exists(csvFile) {
+		return errors.New("File " + csvFile + " not present")
+	}
+
+	data, err := ioutil.ReadFile(csvFile)
+	if err != nil {
+		return err
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.Comma = i.Mapper.GetDelimiter()
+	reader.FieldsPerRecord = -1
+	if reader.Comma == '\t' {
+		reader.LazyQuotes = true
+	}
+
+	rowNum := 0
+	var columns map[string]int
+	rts := []*specs.ResourceTimesheet{}
+	idGenerator := NewExternalIdGenerator()
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rowNum++
+
+		if rowNum <= i.Mapper.SkipRows {
+			continue
+		}
+
+		if columns == nil {
+			if i.Mapper.HasHeader() {
+				columns, err = i.Mapper.GetColumnsIndexes(row)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			columns, err = i.Mapper.GetColumnsIndexes([]string{})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(row) == 1 && strings.TrimSpace(row[0]) == "" {
+			// Skip empty rows
+			continue
+		}
+
+		csvRow := TmCsvRow{}
+		for column, idx := range columns {
+			if idx >= len(row) {
+				return errors.New(fmt.Sprintf(
+					"Row %d without the column %s", rowNum, column))
+			}
+			value := strings.TrimSpace(row[idx])
+			switch column {
+			case CSV_COLUMN_DATE:
+				csvRow.Date = value
+			case CSV_COLUMN_USER:
+				csvRow.User = value
+			case CSV_COLUMN_DURATION:
+				csvRow.Duration = value
+			case CSV_COLUMN_TASK:
+				csvRow.Task = value
+			case CSV_COLUMN_NOTE:
+				csvRow.Note = strings.ReplaceAll(strings.ReplaceAll(value, "\n", " "), "\r", "")
+			case CSV_COLUMN_ID:
+				csvRow.Id = value
+			}
+		}
+
+		if csvRow.Id == "" {
+			// The note is excluded so the id doesn't change when the
+			// description is edited on source.
+			csvRow.Id = idGenerator.Get(csvRow.Date, csvRow.User, csvRow.Task)
+		}
+
+		if i.IsTask2Ignore(csvRow.Task) {
+			i.Logger.Debug("Ignoring row ", rowNum, " of the task ", csvRow.Task)
+			secs, _ := i.Mapper.ParseDuration(csvRow.Duration, i.Config.GetWork().WorkHours)
+			i.Report.AddIgnored(REPORT_TASK, csvRow.Task, secs)
+			continue
+		}
+
+		rt, err := i.convertRow2ResourceTimesheet(&csvRow)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Error on parse row %d: %s", rowNum, err.Error()))
+		}
+
+		if rt != nil {
+			rts = append(rts, rt)
+		}
+
+		i.Logger.Debug("Parse row ", row)
+	}
+
+	i.AddResourceTimesheets(rts)
+
+	return nil
+}
+
+func (i *TmCsvImporter) IsTask2Ignore(task string) bool {
+	for _, r := range i.ignoredRegexes {
+		if r.MatchString(task) {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *TmCsvImporter) GetMappedUser(user string) (ans string) {
+	if u, ok := i.ResourceMapping[user]; ok {
+		ans = u
+	} else {
+		ans = user
+	}
+	return
+}
+
+func (i *TmCsvImporter) getRuleFields(row *TmCsvRow) map[string]string {
+	return map[string]string{
+		CSV_COLUMN_TASK: row.Task,
+		CSV_COLUMN_USER: row.User,
+		CSV_COLUMN_NOTE: row.Note,
+	}
+}
+
+func (i *TmCsvImporter) GetMappedTask(row *TmCsvRow) string {
+	task, ok := i.Rules.GetTask(i.getRuleFields(row))
+	if ok {
+		return task
+	}
+	return row.Task
+}
+
+// MapperSkeleton returns the mapper with the users and the tasks without
+// mapping. The rules match the exact value of the task column.
+func (i *TmCsvImporter) MapperSkeleton() ([]byte, error) {
+	mapper := *i.Mapper
+	mapper.Resources = append([]TmCsvResource{}, i.Mapper.Resources...)
+	mapper.Tasks = append([]TmMappingRule{}, i.Mapper.Tasks...)
+
+	for _, u := range i.Report.GetUnmapped(REPORT_USER) {
+		mapper.Resources = append(mapper.Resources, TmCsvResource{Source: u})
+	}
+	for _, t := range i.Report.GetUnmapped(REPORT_TASK) {
+		mapper.Tasks = append(mapper.Tasks, TmMappingRule{
+			Match: "^" + regexp.QuoteMeta(t) + "$",
+		})
+	}
+
+	return yaml.Marshal(mapper)
+}
+
+// ParseDuration returns the seconds of the duration value.
+func (m *TmCsvMapper) ParseDuration(value string, workHours int) (int64, error) {
+	if m.DecimalSeparator != "" && m.DecimalSeparator != "." {
+		value = strings.ReplaceAll(value, m.DecimalSeparator, ".")
+	}
+
+	switch m.DurationFormat {
+	case "", CSV_DURATION_TM:
+		return tmtime.ParseDuration(value, workHours)
+	case CSV_DURATION_HOURS, CSV_DURATION_MINUTES, CSV_DURATION_SECONDS:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return -1, err
+		}
+		switch m.DurationFormat {
+		case CSV_DURATION_HOURS:
+			v = v * 3600
+		case CSV_DURATION_MINUTES:
+			v = v * 60
+		}
+		return int64(v + 0.5), nil
+	case CSV_DURATION_HHMM, CSV_DURATION_HHMMSS:
+		parts := strings.Split(value, ":")
+		if (m.DurationFormat == CSV_DURATION_HHMM && len(parts) != 2) ||
+			(m.DurationFormat == CSV_DURATION_HHMMSS && len(parts) != 3) {
+			return -1, errors.New("Invalid duration " + value)
+		}
+		ans := int64(0)
+		mult := []int64{3600, 60, 1}
+		for idx, p := range parts {
+			v, err := strconv.ParseInt(p, 10, 64)
+			if err != nil {
+				return -1, errors.New("Invalid duration " + value)
+			}
+			ans += v * mult[idx]
+		}
+		return ans, nil
+	}
+
+	return -1, errors.New("Invalid duration format " + m.DurationFormat)
+}
+
+func (i *TmCsvImporter) convertRow2ResourceTimesheet(row *TmCsvRow) (*specs.ResourceTimesheet, error) {
+	layout := i.Mapper.DateFormat
+	if layout == "" {
+		layout = "2006-01-02"
+	}
+
+	d, err := time.Parse(layout, row.Date)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid date %s: %s", row.Date, err.Error()))
+	}
+
+	secs, err := i.Mapper.ParseDuration(row.Duration, i.Config.GetWork().WorkHours)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid duration %s: %s", row.Duration, err.Error()))
+	}
+
+	if secs <= 0 {
+		i.Logger.Debug(fmt.Sprintf("Skipping entry of %s with duration %s.",
+			row.Date, row.Duration))
+		return nil, nil
+	}
+
+	duration, err := tmtime.Seconds2CanonicalDuration(secs, i.Config.GetWork().WorkHours, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := i.ResourceMapping[row.User]; !ok {
+		i.Report.AddUnmapped(REPORT_USER, row.User, secs)
+	}
+	if _, ok := i.Rules.GetTask(i.getRuleFields(row)); !ok {
+		i.Report.AddUnmapped(REPORT_TASK, row.Task, secs)
+	}
+
+	ans := specs.NewResourceTimesheet(i.GetMappedUser(row.User),
+		d.Format("2006-01-02"), i.GetMappedTask(row), duration)
+	ans.Note = row.Note
+	ans.Source = CSV_SOURCE
+	ans.ExternalId = row.Id
+
+	return ans, nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CSV Importer Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	Context("Parse duration", func() {
+
+		It("hh:mm", func() {
+			m := &TmCsvMapper{DurationFormat: "hh:mm"}
+			secs, err := m.ParseDuration("01:30", 8)
+			Expect(err).Should(BeNil())
+			Expect(secs).To(Equal(int64(5400)))
+		})
+
+		It("hours with comma", func() {
+			m := &TmCsvMapper{DurationFormat: "hours", DecimalSeparator: ","}
+			secs, err := m.ParseDuration("1,25", 8)
+			Expect(err).Should(BeNil())
+			Expect(secs).To(Equal(int64(4500)))
+		})
+
+		It("time-master duration", func() {
+			m := &TmCsvMapper{}
+			secs, err := m.ParseDuration("1d", 8)
+			Expect(err).Should(BeNil())
+			Expect(secs).To(Equal(int64(28800)))
+		})
+	})
+
+	Context("Load CSV", func() {
+
+		It("External ids without the note", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-csv")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			mapper := &TmCsvMapper{
+				Columns: TmCsvColumns{
+					Date:     "Date",
+					User:     "User",
+					Duration: "Duration",
+					Task:     "Task",
+					Note:     "Note",
+				},
+			}
+
+			getIds := func(note string) []string {
+				file := filepath.Join(tmpDir, "report.csv")
+				err := ioutil.WriteFile(file, []byte(
+					"Date,User,Duration,Task,Note\n"+
+						"2026-09-03,mrossi,2h,ACT.dev,"+note+"\n"+
+						"2026-09-03,mrossi,1h,ACT.dev,"+note+"\n"), 0644)
+				Expect(err).Should(BeNil())
+
+				imp := NewTmCsvImporter(config, tmpDir, "", ImportOpts{})
+				Expect(imp.ImportMapper(mapper)).Should(BeNil())
+				Expect(imp.LoadTimesheets(file)).Should(BeNil())
+
+				ans := []string{}
+				for _, rt := range (*imp.GetTimesheets())[0].Timesheets {
+					ans = append(ans, rt.ExternalId)
+				}
+				return ans
+			}
+
+			ids := getIds("Review")
+			Expect(len(ids)).To(Equal(2))
+			Expect(ids[0]).ToNot(Equal(ids[1]))
+			Expect(getIds("Review of the API")).To(Equal(ids))
+		})
+	})
+
+	Context("Load TSV", func() {
+
+		It("Split for user", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-csv")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			file := filepath.Join(tmpDir, "report.tsv")
+			err = ioutil.WriteFile(file, []byte(
+				"Day\tWho\tHours\tIssue\n"+
+					"03/09/2026\tMario\t7.5\tPRJ-12\n"+
+					"04/09/2026\tLuigi\t2\tPRJ-13\n"+
+					"04/09/2026\tLuigi\t1\tINTERNAL\n"), 0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmCsvImporter(config, tmpDir, "", ImportOpts{SplitResource: true})
+			err = imp.ImportMapper(&TmCsvMapper{
+				Delimiter: "tab",
+				Columns: TmCsvColumns{
+					Date:     "Day",
+					User:     "2",
+					Duration: "Hours",
+					Task:     "Issue",
+				},
+				DateFormat:     "02/01/2006",
+				DurationFormat: "hours",
+				Resources: []TmCsvResource{
+					{Source: "Mario", Name: "mrossi"},
+				},
+				Tasks: []TmMappingRule{
+					{Match: `^PRJ-(\d+)$`, Task: "ACT.ticket$1"},
+				},
+				Ignored: []string{"^INTERNAL$"},
+			})
+			Expect(err).Should(BeNil())
+
+			err = imp.LoadTimesheets(file)
+			Expect(err).Should(BeNil())
+
+			// The external ids are generated from the row fields.
+			agendas := *imp.GetTimesheets()
+			for idx := range agendas {
+				for tidx := range agendas[idx].Timesheets {
+					Expect(agendas[idx].Timesheets[tidx].Source).To(Equal(CSV_SOURCE))
+					Expect(agendas[idx].Timesheets[tidx].ExternalId).ToNot(BeEmpty())
+					agendas[idx].Timesheets[tidx].Source = ""
+					agendas[idx].Timesheets[tidx].ExternalId = ""
+				}
+			}
+
+			Expect(agendas).To(Equal([]specs.AgendaTimesheets{
+				{
+					File: "mrossi",
+					Name: "mrossi",
+					Timesheets: []specs.ResourceTimesheet{
+						*specs.NewResourceTimesheet("mrossi", "2026-09-03", "ACT.ticket12", "7h30m"),
+					},
+				},
+				{
+					File: "Luigi",
+					Name: "Luigi",
+					Timesheets: []specs.ResourceTimesheet{
+						*specs.NewResourceTimesheet("Luigi", "2026-09-04", "ACT.ticket13", "2h"),
+					},
+				},
+			}))
+		})
+	})
+})
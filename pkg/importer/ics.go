@@ -0,0 +1,374 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	ical "github.com/geaaru/time-master/pkg/ical"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+	"github.com/geaaru/time-master/pkg/tools"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	ICS_SOURCE = "ics"
+
+	ICS_FIELD_SUMMARY   = "summary"
+	ICS_FIELD_ORGANIZER = "organizer"
+	ICS_FIELD_CATEGORY  = "category"
+)
+
+// TmIcsMapper maps the meetings of an iCalendar file to the timesheets.
+type TmIcsMapper struct {
+	// Mapping between the emails (or the names) of the attendees
+	// and the users.
+	Users []TmIcsUser `json:"users,omitempty" yaml:"users,omitempty"`
+	// User of the events without attendees (the owner of the calendar).
+	Owner string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	// Rules applied in order. Supported fields: summary (default),
+	// organizer, category.
+	Rules []TmMappingRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// Regexes of the summaries of the events to ignore.
+	Ignored []string `json:"ignored,omitempty" yaml:"ignored,omitempty"`
+}
+
+type TmIcsUser struct {
+	Source string `json:"source" yaml:"source"`
+	User   string `json:"user" yaml:"user"`
+}
+
+// TmIcsImporter imports the accepted meetings of an iCalendar file.
+// The all-day events, the cancelled events and the events declined
+// by the attendees are skipped.
+type TmIcsImporter struct {
+	*DefaultImporter
+	Mapper         *TmIcsMapper
+	Rules          *TmRuleEngine
+	From           string
+	To             string
+	ignoredRegexes []*regexp.Regexp
+}
+
+func TmIcsMapperFromYaml(data []byte) (*TmIcsMapper, error) {
+	ans := &TmIcsMapper{}
+	if err := yaml.Unmarshal(data, ans); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func NewTmIcsImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string, opts ImportOpts) *TmIcsImporter {
+	return &TmIcsImporter{
+		DefaultImporter: NewDefaultImporter(config, tmDir, filePrefix, opts),
+		Mapper:          &TmIcsMapper{},
+		Rules:           NewTmRuleEngine(),
+		ignoredRegexes:  []*regexp.Regexp{},
+	}
+}
+
+func (i *TmIcsImporter) ImportMapper(mapper *TmIcsMapper) error {
+	i.Mapper = mapper
+
+	for _, ignored := range mapper.Ignored {
+		r, err := regexp.Compile(ignored)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid regex %s: %s", ignored, err.Error()))
+		}
+		i.ignoredRegexes = append(i.ignoredRegexes, r)
+	}
+
+	return i.Rules.AddRules(mapper.Rules, ICS_FIELD_SUMMARY,
+		ICS_FIELD_SUMMARY, ICS_FIELD_ORGANIZER, ICS_FIELD_CATEGORY)
+}
+
+// SetPeriod defines the dates (YYYY-MM-DD) of the meetings to import.
+// Without the end date the meetings until now are imported.
+func (i *TmIcsImporter) SetPeriod(from, to string) {
+	i.From = from
+	i.To = to
+}
+
+func (i *TmIcsImporter) isEvent2Ignore(summary string) bool {
+	for _, r := range i.ignoredRegexes {
+		if r.MatchString(summary) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the user of the mapper of the attendee.
+func (i *TmIcsImporter) getUser(a *ical.Attendee) string {
+	for _, u := range i.Mapper.Users {
+		if (a.Email != "" && strings.EqualFold(u.Source, a.Email)) ||
+			(a.Name != "" && strings.EqualFold(u.Source, a.Name)) {
+			return u.User
+		}
+	}
+	return ""
+}
+
+// GetEventUsers returns the users that have accepted the event. The
+// organizer accepts implicitly the event. The second value is false
+// if no attendee of the event is mapped.
+func (i *TmIcsImporter) GetEventUsers(e *ical.Event) ([]string, bool) {
+	users := []string{}
+	mapped := false
+
+	add := func(u string) {
+		for _, v := range users {
+			if v == u {
+				return
+			}
+		}
+		users = append(users, u)
+	}
+
+	declined := make(map[string]bool, 0)
+	for idx := range e.Attendees {
+		a := &e.Attendees[idx]
+		u := i.getUser(a)
+		if u == "" {
+			continue
+		}
+		mapped = true
+
+		switch a.PartStat {
+		case "ACCEPTED":
+			add(u)
+		case "DECLINED":
+			declined[u] = true
+			i.Logger.Debug(fmt.Sprintf("Event %s declined by %s.", e.Summary, u))
+		default:
+			i.Logger.Debug(fmt.Sprintf("Event %s not accepted by %s.", e.Summary, u))
+		}
+	}
+
+	if e.Organizer != nil {
+		if u := i.getUser(e.Organizer); u != "" {
+			mapped = true
+			if !declined[u] {
+				add(u)
+			}
+		}
+	}
+
+	if len(e.Attendees) == 0 && !mapped && i.Mapper.Owner != "" {
+		mapped = true
+		add(i.Mapper.Owner)
+	}
+
+	return users, mapped
+}
+
+// GetMappedTask returns the task of the event and if a rule is matched.
+// Without rules the summary is used.
+func (i *TmIcsImporter) GetMappedTask(e *ical.Event) (string, bool) {
+	organizer := []string{}
+	if e.Organizer != nil {
+		organizer = append(organizer, e.Organizer.Email, e.Organizer.Name)
+	}
+
+	task, ok := i.Rules.GetTaskFromValues(map[string][]string{
+		ICS_FIELD_SUMMARY:   []string{e.Summary},
+		ICS_FIELD_ORGANIZER: organizer,
+		ICS_FIELD_CATEGORY:  e.Categories,
+	})
+	if ok {
+		return task, true
+	}
+
+	return e.Summary, false
+}
+
+// Return the limit of the occurrences of the recurring events.
+func (i *TmIcsImporter) getLimit() (time.Time, error) {
+	if i.To == "" {
+		return time.Now(), nil
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", i.To, time.Local)
+	if err != nil {
+		return to, err
+	}
+	return to.AddDate(0, 0, 1), nil
+}
+
+// ConvertCalendar converts the meetings of the calendar to the
+// agendas of the importer.
+func (i *TmIcsImporter) ConvertCalendar(cal *ical.Calendar) error {
+	rts := []*specs.ResourceTimesheet{}
+	idGenerator := NewExternalIdGenerator()
+
+	limit, err := i.getLimit()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range cal.Events {
+		if e.AllDay {
+			i.Logger.Debug("Skipping all-day event " + e.Summary)
+			continue
+		}
+		if e.Status == "CANCELLED" {
+			i.Logger.Debug("Skipping cancelled event " + e.Summary)
+			continue
+		}
+		if e.Unsupported != "" {
+			i.Logger.Warning(fmt.Sprintf("Skipping event %s: %s", e.Summary, e.Unsupported))
+			i.Report.AddIgnored(REPORT_RULE, e.Summary, 0)
+			continue
+		}
+
+		occurrences := []ical.Occurrence{}
+		secs := int64(0)
+		for _, o := range e.Occurrences(limit) {
+			// Without the end date only the meetings already ended are imported.
+			if (i.To == "" && o.End.After(limit)) || !o.Start.Before(o.End) {
+				continue
+			}
+			if i.From != "" && o.End.Format("2006-01-02") < i.From {
+				continue
+			}
+			occurrences = append(occurrences, o)
+			secs += int64(o.End.Sub(o.Start).Seconds())
+		}
+		if len(occurrences) == 0 {
+			continue
+		}
+
+		if i.isEvent2Ignore(e.Summary) {
+			i.Logger.Debug("Ignoring event " + e.Summary)
+			i.Report.AddIgnored(REPORT_EVENT, e.Summary, secs)
+			continue
+		}
+
+		users, mapped := i.GetEventUsers(e)
+		if !mapped {
+			source := cal.Name
+			if e.Organizer != nil {
+				source = e.Organizer.Email
+			}
+			i.Report.AddUnmapped(REPORT_USER, source, secs)
+			continue
+		}
+		if len(users) == 0 {
+			continue
+		}
+
+		task, ok := i.GetMappedTask(e)
+		if !ok {
+			i.Report.AddUnmapped(REPORT_EVENT, task, secs*int64(len(users)))
+		}
+
+		uid := e.Uid
+		if uid == "" {
+			uid = idGenerator.Get(e.Summary, e.Start.Format(time.RFC3339))
+		}
+
+		for _, o := range occurrences {
+			// The override of an occurrence keeps the id of the original
+			// occurrence so the merge replaces the moved meeting.
+			occurrenceId := o.Start
+			if e.RecurrenceId != nil {
+				occurrenceId = *e.RecurrenceId
+			}
+
+			start := o.Start.In(time.Local)
+			end := o.End.In(time.Local)
+			periods := tmtime.SplitDays(start, end)
+
+			for _, p := range periods {
+				date := p[0].Format("2006-01-02")
+				if (i.From != "" && date < i.From) || (i.To != "" && date > i.To) {
+					continue
+				}
+
+				duration, err := tmtime.Seconds2CanonicalDuration(
+					int64(p[1].Sub(p[0]).Seconds()), i.Config.GetWork().WorkHours, false)
+				if err != nil {
+					return err
+				}
+
+				for _, u := range users {
+					rt := specs.NewResourceTimesheet(u, date, task, duration)
+					rt.Note = e.Summary
+					rt.Source = ICS_SOURCE
+					rt.ExternalId = fmt.Sprintf("%s@%s@%s", uid,
+						occurrenceId.UTC().Format("20060102T150405Z"), u)
+					if len(periods) > 1 {
+						rt.ExternalId += "@" + date
+					}
+					rts = append(rts, rt)
+				}
+			}
+		}
+	}
+
+	i.AddResourceTimesheets(rts)
+
+	return nil
+}
+
+func (i *TmIcsImporter) LoadTimesheets(file string) error {
+	if !tools.Exists(file) {
+		return errors.New("File " + file + " not present")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	cal, err := ical.Parse(data)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error on parse file %s: %s", file, err.Error()))
+	}
+
+	return i.ConvertCalendar(cal)
+}
+
+// MapperSkeleton returns the mapper with the users and the events
+// without mapping.
+func (i *TmIcsImporter) MapperSkeleton() ([]byte, error) {
+	mapper := *i.Mapper
+	mapper.Users = append([]TmIcsUser{}, i.Mapper.Users...)
+	mapper.Rules = append([]TmMappingRule{}, i.Mapper.Rules...)
+
+	for _, u := range i.Report.GetUnmapped(REPORT_USER) {
+		mapper.Users = append(mapper.Users, TmIcsUser{Source: u})
+	}
+	for _, s := range i.Report.GetUnmapped(REPORT_EVENT) {
+		mapper.Rules = append(mapper.Rules, TmMappingRule{
+			Field: ICS_FIELD_SUMMARY,
+			Match: "^" + regexp.QuoteMeta(s) + "$",
+		})
+	}
+
+	return yaml.Marshal(mapper)
+}
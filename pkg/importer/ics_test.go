@@ -0,0 +1,225 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	ical "github.com/geaaru/time-master/pkg/ical"
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ICS Importer Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+
+	calendar := []byte("BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:standup\n" +
+		"SUMMARY:Daily standup\n" +
+		"DTSTART:20260901T093000\n" +
+		"DTEND:20260901T094500\n" +
+		"RRULE:FREQ=DAILY;COUNT=2\n" +
+		"ORGANIZER;CN=Daniele:mailto:geaaru@example.com\n" +
+		"ATTENDEE;PARTSTAT=ACCEPTED:mailto:geaaru@example.com\n" +
+		"ATTENDEE;PARTSTAT=DECLINED:mailto:mario.rossi@example.com\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:release\n" +
+		"SUMMARY:Release night\n" +
+		"DTSTART:20260903T230000\n" +
+		"DTEND:20260904T010000\n" +
+		"ORGANIZER:mailto:pm@example.com\n" +
+		"ATTENDEE;PARTSTAT=ACCEPTED:mailto:mario.rossi@example.com\n" +
+		"ATTENDEE;PARTSTAT=TENTATIVE:mailto:geaaru@example.com\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:offsite\n" +
+		"SUMMARY:Offsite\n" +
+		"DTSTART;VALUE=DATE:20260905\n" +
+		"ATTENDEE;PARTSTAT=ACCEPTED:mailto:geaaru@example.com\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:customer\n" +
+		"SUMMARY:Customer call\n" +
+		"DTSTART:20260907T100000\n" +
+		"DTEND:20260907T110000\n" +
+		"ORGANIZER:mailto:customer@example.com\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n")
+
+	Context("Convert calendar", func() {
+
+		It("Accepted meetings", func() {
+			imp := NewTmIcsImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.ImportMapper(&TmIcsMapper{
+				Users: []TmIcsUser{
+					{Source: "geaaru@example.com", User: "geaaru"},
+					{Source: "mario.rossi@example.com", User: "mrossi"},
+				},
+				Rules: []TmMappingRule{
+					{Match: "(?i)standup", Task: "ACT.meetings"},
+				},
+			})
+			Expect(err).Should(BeNil())
+			imp.SetPeriod("2026-09-01", "2026-09-30")
+
+			cal, err := ical.Parse(calendar)
+			Expect(err).Should(BeNil())
+			err = imp.ConvertCalendar(cal)
+			Expect(err).Should(BeNil())
+
+			agendas := *imp.GetTimesheets()
+			Expect(len(agendas)).To(Equal(1))
+
+			rts := agendas[0].Timesheets
+			Expect(len(rts)).To(Equal(4))
+
+			Expect(rts[0].User).To(Equal("geaaru"))
+			Expect(rts[0].Period.StartPeriod).To(Equal("2026-09-01"))
+			Expect(rts[0].Task).To(Equal("ACT.meetings"))
+			Expect(rts[0].Duration).To(Equal("15m"))
+			Expect(rts[0].Source).To(Equal(ICS_SOURCE))
+			Expect(rts[1].Period.StartPeriod).To(Equal("2026-09-02"))
+			Expect(rts[1].ExternalId).ToNot(Equal(rts[0].ExternalId))
+
+			Expect(rts[2].User).To(Equal("mrossi"))
+			Expect(rts[2].Task).To(Equal("Release night"))
+			Expect(rts[2].Period.StartPeriod).To(Equal("2026-09-03"))
+			Expect(rts[2].Duration).To(Equal("1h"))
+			Expect(rts[3].Period.StartPeriod).To(Equal("2026-09-04"))
+			Expect(rts[3].Duration).To(Equal("1h"))
+
+			report := imp.GetReport()
+			Expect(report.GetUnmapped(REPORT_EVENT)).To(Equal([]string{"Release night"}))
+			Expect(report.GetUnmapped(REPORT_USER)).To(Equal([]string{"customer@example.com"}))
+		})
+
+		It("Moved and declined occurrences", func() {
+			imp := NewTmIcsImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.ImportMapper(&TmIcsMapper{
+				Users: []TmIcsUser{
+					{Source: "geaaru@example.com", User: "geaaru"},
+					{Source: "mario.rossi@example.com", User: "mrossi"},
+				},
+			})
+			Expect(err).Should(BeNil())
+			imp.SetPeriod("2026-09-01", "2026-09-30")
+
+			cal, err := ical.Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"UID:standup\n" +
+				"SUMMARY:Daily standup\n" +
+				"DTSTART:20260901T093000\n" +
+				"DTEND:20260901T094500\n" +
+				"RRULE:FREQ=DAILY;COUNT=3\n" +
+				"ATTENDEE;PARTSTAT=ACCEPTED:mailto:geaaru@example.com\n" +
+				"ATTENDEE;PARTSTAT=ACCEPTED:mailto:mario.rossi@example.com\n" +
+				"END:VEVENT\n" +
+				"BEGIN:VEVENT\n" +
+				"UID:standup\n" +
+				"RECURRENCE-ID:20260902T093000\n" +
+				"DTSTART:20260902T110000\n" +
+				"DTEND:20260902T113000\n" +
+				"END:VEVENT\n" +
+				"BEGIN:VEVENT\n" +
+				"UID:standup\n" +
+				"RECURRENCE-ID:20260903T093000\n" +
+				"SUMMARY:Daily standup\n" +
+				"DTSTART:20260903T093000\n" +
+				"DTEND:20260903T094500\n" +
+				"ATTENDEE;PARTSTAT=ACCEPTED:mailto:geaaru@example.com\n" +
+				"ATTENDEE;PARTSTAT=DECLINED:mailto:mario.rossi@example.com\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+			Expect(err).Should(BeNil())
+			err = imp.ConvertCalendar(cal)
+			Expect(err).Should(BeNil())
+
+			rts := (*imp.GetTimesheets())[0].Timesheets
+			Expect(len(rts)).To(Equal(5))
+
+			entries := []string{}
+			for _, rt := range rts {
+				entries = append(entries, rt.Period.StartPeriod+" "+rt.User+" "+rt.Duration)
+			}
+			Expect(entries).To(Equal([]string{
+				"2026-09-01 geaaru 15m",
+				"2026-09-01 mrossi 15m",
+				"2026-09-02 geaaru 30m",
+				"2026-09-02 mrossi 30m",
+				"2026-09-03 geaaru 15m",
+			}))
+			Expect(rts[2].Task).To(Equal("Daily standup"))
+			Expect(rts[2].ExternalId).To(ContainSubstring("standup@" +
+				cal.Events[1].RecurrenceId.UTC().Format("20060102T150405Z")))
+		})
+
+		It("Unsupported recurrence rule", func() {
+			imp := NewTmIcsImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.ImportMapper(&TmIcsMapper{
+				Users: []TmIcsUser{{Source: "geaaru@example.com", User: "geaaru"}},
+			})
+			Expect(err).Should(BeNil())
+			imp.SetPeriod("2026-09-01", "2026-09-30")
+
+			cal, err := ical.Parse([]byte("BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"UID:review\n" +
+				"SUMMARY:Review\n" +
+				"DTSTART:20260901T100000\n" +
+				"DTEND:20260901T110000\n" +
+				"RRULE:FREQ=WEEKLY;BYDAY=1TU\n" +
+				"ATTENDEE;PARTSTAT=ACCEPTED:mailto:geaaru@example.com\n" +
+				"END:VEVENT\n" +
+				"BEGIN:VEVENT\n" +
+				"UID:planning\n" +
+				"SUMMARY:Planning\n" +
+				"DTSTART:20260901T090000\n" +
+				"DTEND:20260901T093000\n" +
+				"RRULE:FREQ=MONTHLY;COUNT=2;BYDAY=1TU\n" +
+				"ATTENDEE;PARTSTAT=ACCEPTED:mailto:geaaru@example.com\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"))
+			Expect(err).Should(BeNil())
+			Expect(imp.ConvertCalendar(cal)).Should(BeNil())
+
+			rts := (*imp.GetTimesheets())[0].Timesheets
+			Expect(len(rts)).To(Equal(1))
+			Expect([]string{rts[0].Period.StartPeriod, rts[0].Task, rts[0].Duration}).To(
+				Equal([]string{"2026-09-01", "Planning", "30m"}))
+
+			report := imp.GetReport()
+			Expect(len(report.Ignored)).To(Equal(1))
+			Expect([]string{report.Ignored[0].Kind, report.Ignored[0].Value}).To(
+				Equal([]string{REPORT_RULE, "Review"}))
+		})
+
+		It("Invalid field", func() {
+			imp := NewTmIcsImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.ImportMapper(&TmIcsMapper{
+				Rules: []TmMappingRule{{Field: "project", Match: "x", Task: "ACT.x"}},
+			})
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+})
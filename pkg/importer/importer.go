@@ -20,11 +20,14 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package importer
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/geaaru/time-master/pkg/logger"
 	specs "github.com/geaaru/time-master/pkg/specs"
@@ -34,11 +37,22 @@ import (
 
 type ImportOpts struct {
 	SplitResource bool
+
+	// Merge the timesheets with the existing files
+	Merge bool
+	// Remove the entries of the same source not available in the
+	// imported data of the same period.
+	RemoveMissing bool
+	// Show changes without write the files
+	DryRun bool
 }
 
 type TimeMasterImporter interface {
 	LoadTimesheets(string) error
 	WriteTimesheets() error
+	MergeTimesheets() (*MergeSummary, error)
+	GetReport() *TmImportReport
+	MapperSkeleton() ([]byte, error)
 	GetTimesheets() *[]specs.AgendaTimesheets
 	AddTimesheet(*specs.AgendaTimesheets)
 }
@@ -50,6 +64,7 @@ type DefaultImporter struct {
 	FilePrefix   string
 	Opts         ImportOpts
 	Timesheets   []specs.AgendaTimesheets
+	Report       *TmImportReport
 }
 
 func NewDefaultImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string, opts ImportOpts) *DefaultImporter {
@@ -60,6 +75,7 @@ func NewDefaultImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string
 		FilePrefix:   filePrefix,
 		Opts:         opts,
 		Timesheets:   []specs.AgendaTimesheets{},
+		Report:       NewTmImportReport(),
 	}
 
 	// Initialize logging
@@ -74,6 +90,38 @@ func NewDefaultImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string
 	return ans
 }
 
+// StableExternalId returns the id of an entry without an id available
+// on source. The id is the hash of the fields, so only the fields that
+// identify the entry and that aren't edited on source must be used.
+func StableExternalId(fields ...string) string {
+	h := sha1.Sum([]byte(strings.Join(fields, "|")))
+	return hex.EncodeToString(h[:])[0:16]
+}
+
+// ExternalIdGenerator creates the ids of the entries without an
+// id available on source. The id is the hash of the fields of the entry
+// with a counter for the entries with the same fields.
+type ExternalIdGenerator struct {
+	counters map[string]int
+}
+
+func NewExternalIdGenerator() *ExternalIdGenerator {
+	return &ExternalIdGenerator{
+		counters: make(map[string]int, 0),
+	}
+}
+
+func (g *ExternalIdGenerator) Get(fields ...string) string {
+	ans := StableExternalId(fields...)
+
+	g.counters[ans]++
+	if g.counters[ans] > 1 {
+		ans = fmt.Sprintf("%s-%d", ans, g.counters[ans])
+	}
+
+	return ans
+}
+
 func (i *DefaultImporter) GetTimesheets() *[]specs.AgendaTimesheets {
 	return &i.Timesheets
 }
@@ -82,7 +130,45 @@ func (i *DefaultImporter) AddTimesheet(t *specs.AgendaTimesheets) {
 	i.Timesheets = append(i.Timesheets, *t)
 }
 
+// AddResourceTimesheets adds the timesheets to the agenda of the user or
+// to a single agenda if the split for user is disabled.
+func (i *DefaultImporter) AddResourceTimesheets(rts []*specs.ResourceTimesheet) {
+	if i.Opts.SplitResource {
+		users := []string{}
+		mAgenda := make(map[string]*specs.AgendaTimesheets, 0)
+
+		for _, rt := range rts {
+			agenda, ok := mAgenda[rt.User]
+			if !ok {
+				agenda = &specs.AgendaTimesheets{
+					File: rt.User,
+					Name: rt.User,
+				}
+				mAgenda[rt.User] = agenda
+				users = append(users, rt.User)
+			}
+			agenda.AddResourceTimesheet(rt)
+		}
+
+		for _, u := range users {
+			i.AddTimesheet(mAgenda[u])
+		}
+
+	} else {
+		agenda := specs.AgendaTimesheets{}
+		for _, rt := range rts {
+			agenda.AddResourceTimesheet(rt)
+		}
+		i.AddTimesheet(&agenda)
+	}
+}
+
 func (i *DefaultImporter) WriteTimesheets() error {
+	if i.Opts.Merge {
+		_, err := i.MergeTimesheets()
+		return err
+	}
+
 	// Ensure that timesheetDir is an absolute path to avoid errors with path.Jon
 	tmDir, err := filepath.Abs(i.TimesheetDir)
 	if err != nil {
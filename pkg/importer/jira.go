@@ -28,6 +28,7 @@ import (
 	"strings"
 
 	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
 	"github.com/geaaru/time-master/pkg/tools"
 
 	"gopkg.in/yaml.v2"
@@ -38,16 +39,36 @@ type TmJiraImporter struct {
 	ResourceMapping map[string]string
 	IssueTaskMap    map[string]string
 	IgnoredIssueMap map[string]bool
-	Before202009    bool
-	Before202401    bool
-	Before202402    bool
-	Before202408    bool
+	ProjectTaskMap  map[string]string
+	Rules           *TmRuleEngine
+	// Aliases of the CSV columns
+	ColumnAliases map[string][]string
 }
 
 type TmJiraMapper struct {
 	Resources     []TmJiraResource `json:"resources" yaml:"resources"`
 	Issues        []TmJiraIssue    `json:"issues" yaml:"issues"`
 	IgnoredIssues []string         `json:"ignored_issues,omitempty" yaml:"ignored_issues,omitempty"`
+	// Rules applied in order when the issue is not mapped. Supported
+	// fields: issue (default), summary, description, project, epic.
+	Rules []TmMappingRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// Default task of the issues of a project not mapped.
+	Projects []TmJiraProject `json:"projects,omitempty" yaml:"projects,omitempty"`
+	Columns  *TmJiraColumns  `json:"columns,omitempty" yaml:"columns,omitempty"`
+}
+
+// TmJiraColumns contains the additional names of the columns
+// of the CSV file exported from Jira.
+type TmJiraColumns struct {
+	Issue       []string `json:"issue,omitempty" yaml:"issue,omitempty"`
+	Hours       []string `json:"hours,omitempty" yaml:"hours,omitempty"`
+	Date        []string `json:"date,omitempty" yaml:"date,omitempty"`
+	User        []string `json:"user,omitempty" yaml:"user,omitempty"`
+	Description []string `json:"description,omitempty" yaml:"description,omitempty"`
+	WorklogId   []string `json:"worklog_id,omitempty" yaml:"worklog_id,omitempty"`
+	Summary     []string `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Project     []string `json:"project,omitempty" yaml:"project,omitempty"`
+	Epic        []string `json:"epic,omitempty" yaml:"epic,omitempty"`
 }
 
 type TmJiraIssue struct {
@@ -55,17 +76,28 @@ type TmJiraIssue struct {
 	TaskName  string `json:"task" yaml:"task"`
 }
 
+type TmJiraProject struct {
+	Project  string `json:"project" yaml:"project"`
+	TaskName string `json:"task" yaml:"task"`
+}
+
 type TmJiraResource struct {
 	JiraName string `json:"jira_name" yaml:"jira_name"`
 	Name     string `json:"name" yaml:"name"`
 }
 
 type TmJiraCsvRow struct {
+	Id       string
 	Issue    string
+	Summary  string
+	Project  string
+	Epic     string
 	Descr    string
 	Date     string
 	WorkTime string
 	User     string
+	// Seconds of the worklog. If set it's used instead of the hours.
+	Seconds int64
 }
 
 func TmJiraMapperFromYaml(data []byte) (*TmJiraMapper, error) {
@@ -76,29 +108,100 @@ func TmJiraMapperFromYaml(data []byte) (*TmJiraMapper, error) {
 	return ans, nil
 }
 
+const (
+	JIRA_COLUMN_ISSUE       = "issue"
+	JIRA_COLUMN_HOURS       = "hours"
+	JIRA_COLUMN_DATE        = "date"
+	JIRA_COLUMN_USER        = "user"
+	JIRA_COLUMN_DESCRIPTION = "description"
+	JIRA_COLUMN_WORKLOG_ID  = "worklog_id"
+	JIRA_COLUMN_SUMMARY     = "summary"
+	JIRA_COLUMN_PROJECT     = "project"
+	JIRA_COLUMN_EPIC        = "epic"
+
+	JIRA_SOURCE = "jira"
+)
+
+// Columns names used by the Jira/Tempo exports.
+func getJiraDefaultColumnAliases() map[string][]string {
+	return map[string][]string{
+		JIRA_COLUMN_ISSUE:       []string{"Issue Key", "Key"},
+		JIRA_COLUMN_HOURS:       []string{"Hours", "Time Spent (h)", "Logged Hours"},
+		JIRA_COLUMN_DATE:        []string{"Work date", "Date", "Started"},
+		JIRA_COLUMN_USER:        []string{"Full name", "Author", "User", "Worker"},
+		JIRA_COLUMN_DESCRIPTION: []string{"Work Description", "Worklog Description", "Comment"},
+		JIRA_COLUMN_WORKLOG_ID:  []string{"Worklog Id", "Tempo Worklog ID", "Jira Worklog ID"},
+		JIRA_COLUMN_SUMMARY:     []string{"Issue summary", "Summary"},
+		JIRA_COLUMN_PROJECT:     []string{"Project Key"},
+		JIRA_COLUMN_EPIC:        []string{"Epic", "Epic Link"},
+	}
+}
+
 func NewTmJiraImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string, opts ImportOpts) *TmJiraImporter {
 	return &TmJiraImporter{
 		DefaultImporter: NewDefaultImporter(config, tmDir, filePrefix, opts),
 		ResourceMapping: make(map[string]string, 0),
 		IssueTaskMap:    make(map[string]string, 0),
 		IgnoredIssueMap: make(map[string]bool, 0),
-		Before202009:    false,
+		ProjectTaskMap:  make(map[string]string, 0),
+		Rules:           NewTmRuleEngine(),
+		ColumnAliases:   getJiraDefaultColumnAliases(),
 	}
 }
 
-func (i *TmJiraImporter) SetBefore202009() {
-	i.Before202009 = true
+// AddColumnAliases add aliases of a column. The aliases added
+// are checked before the default aliases.
+func (i *TmJiraImporter) AddColumnAliases(column string, aliases []string) {
+	if len(aliases) > 0 {
+		i.ColumnAliases[column] = append(append([]string{}, aliases...),
+			i.ColumnAliases[column]...)
+	}
 }
 
-func (i *TmJiraImporter) SetBefore202401() {
-	i.Before202401 = true
-}
+// DetectColumns returns the index of the columns from the header of the CSV.
+func (i *TmJiraImporter) DetectColumns(header []string) (map[string]int, error) {
+	ans := make(map[string]int, 0)
+
+	normalize := func(s string) string {
+		return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(s, "\ufeff")))
+	}
+
+	headerMap := make(map[string]int, len(header))
+	for idx, h := range header {
+		if _, ok := headerMap[normalize(h)]; !ok {
+			headerMap[normalize(h)] = idx
+		}
+	}
+
+	for _, column := range []string{
+		JIRA_COLUMN_ISSUE, JIRA_COLUMN_HOURS, JIRA_COLUMN_DATE,
+		JIRA_COLUMN_USER, JIRA_COLUMN_DESCRIPTION, JIRA_COLUMN_WORKLOG_ID,
+		JIRA_COLUMN_SUMMARY, JIRA_COLUMN_PROJECT, JIRA_COLUMN_EPIC,
+	} {
+		for _, alias := range i.ColumnAliases[column] {
+			if idx, ok := headerMap[normalize(alias)]; ok {
+				ans[column] = idx
+				break
+			}
+		}
+
+		// Only issue, hours, date and user are mandatory.
+		if _, ok := ans[column]; !ok && (column == JIRA_COLUMN_ISSUE ||
+			column == JIRA_COLUMN_HOURS || column == JIRA_COLUMN_DATE ||
+			column == JIRA_COLUMN_USER) {
+			return nil, errors.New(fmt.Sprintf(
+				"Unable to detect the column %s (aliases: %s) from the CSV header. Headers found: %s",
+				column,
+				strings.Join(i.ColumnAliases[column], ", "),
+				strings.Join(header, ", "),
+			))
+		}
+	}
 
-func (i *TmJiraImporter) SetBefore202408() {
-	i.Before202408 = true
+	return ans, nil
 }
 
-func (i *TmJiraImporter) ImportMapper(mapper *TmJiraMapper) {
+func (i *TmJiraImporter) ImportMapper(mapper *TmJiraMapper) error {
 	if len(mapper.Resources) > 0 {
 		for _, r := range mapper.Resources {
 			i.ResourceMapping[r.JiraName] = r.Name
@@ -116,6 +219,26 @@ func (i *TmJiraImporter) ImportMapper(mapper *TmJiraMapper) {
 			i.IgnoredIssueMap[issue] = true
 		}
 	}
+
+	if mapper.Columns != nil {
+		i.AddColumnAliases(JIRA_COLUMN_ISSUE, mapper.Columns.Issue)
+		i.AddColumnAliases(JIRA_COLUMN_HOURS, mapper.Columns.Hours)
+		i.AddColumnAliases(JIRA_COLUMN_DATE, mapper.Columns.Date)
+		i.AddColumnAliases(JIRA_COLUMN_USER, mapper.Columns.User)
+		i.AddColumnAliases(JIRA_COLUMN_DESCRIPTION, mapper.Columns.Description)
+		i.AddColumnAliases(JIRA_COLUMN_WORKLOG_ID, mapper.Columns.WorklogId)
+		i.AddColumnAliases(JIRA_COLUMN_SUMMARY, mapper.Columns.Summary)
+		i.AddColumnAliases(JIRA_COLUMN_PROJECT, mapper.Columns.Project)
+		i.AddColumnAliases(JIRA_COLUMN_EPIC, mapper.Columns.Epic)
+	}
+
+	for _, p := range mapper.Projects {
+		i.ProjectTaskMap[p.Project] = p.TaskName
+	}
+
+	return i.Rules.AddRules(mapper.Rules, JIRA_COLUMN_ISSUE,
+		JIRA_COLUMN_ISSUE, JIRA_COLUMN_SUMMARY, JIRA_COLUMN_DESCRIPTION,
+		JIRA_COLUMN_PROJECT, JIRA_COLUMN_EPIC)
 }
 
 func (i *TmJiraImporter) LoadTimesheets(csvFile string) error {
@@ -131,6 +254,9 @@ func (i *TmJiraImporter) LoadTimesheets(csvFile string) error {
 	reader := csv.NewReader(strings.NewReader(string(data)))
 	rowNum := 0
 	jiraRows := []TmJiraCsvRow{}
+	// Index of the rows without worklog id.
+	generatedIds := make(map[string]int, 0)
+	var columns map[string]int
 
 	for {
 		row, err := reader.Read()
@@ -143,39 +269,71 @@ func (i *TmJiraImporter) LoadTimesheets(csvFile string) error {
 		rowNum++
 
 		if rowNum == 1 {
-			// Skip header
+			columns, err = i.DetectColumns(row)
+			if err != nil {
+				return err
+			}
+			i.Logger.Debug(fmt.Sprintf("Detected columns: %v", columns))
 			continue
 		}
 
-		dateIdx := 4
-		descIdx := 32
-		userIdx := 6
-		if i.Before202009 {
-			userIdx = 5
-			dateIdx = 3
-			descIdx = 22
-		} else if i.Before202401 {
-			userIdx = 5
-			dateIdx = 3
-			descIdx = 23
-		} else if i.Before202402 {
-			userIdx = 5
-			dateIdx = 3
-			descIdx = 30
-		} else if i.Before202408 {
-			userIdx = 5
-			dateIdx = 3
-			descIdx = 31
+		descr := ""
+		if descIdx, ok := columns[JIRA_COLUMN_DESCRIPTION]; ok {
+			descr = strings.TrimSpace(strings.ReplaceAll(
+				strings.ReplaceAll(row[descIdx], "\n", ""), "\r", ""))
+		}
+
+		id := ""
+		if idIdx, ok := columns[JIRA_COLUMN_WORKLOG_ID]; ok {
+			id = strings.TrimSpace(row[idIdx])
+		}
+
+		summary := ""
+		if sIdx, ok := columns[JIRA_COLUMN_SUMMARY]; ok {
+			summary = strings.TrimSpace(row[sIdx])
+		}
+
+		issue := row[columns[JIRA_COLUMN_ISSUE]]
+		project := ""
+		if pIdx, ok := columns[JIRA_COLUMN_PROJECT]; ok {
+			project = strings.TrimSpace(row[pIdx])
+		} else {
+			project = GetJiraProjectFromIssue(issue)
+		}
+
+		epic := ""
+		if eIdx, ok := columns[JIRA_COLUMN_EPIC]; ok {
+			epic = strings.TrimSpace(row[eIdx])
 		}
 
 		jiraRows = append(jiraRows, TmJiraCsvRow{
-			Issue:    row[0],
-			Descr:    strings.TrimSpace(strings.ReplaceAll(strings.ReplaceAll(row[descIdx], "\n", ""), "\r", "")),
-			Date:     row[dateIdx],
-			WorkTime: row[2],
-			User:     row[userIdx],
+			Id:       id,
+			Issue:    issue,
+			Summary:  summary,
+			Project:  project,
+			Epic:     epic,
+			Descr:    descr,
+			Date:     row[columns[JIRA_COLUMN_DATE]],
+			WorkTime: row[columns[JIRA_COLUMN_HOURS]],
+			User:     row[columns[JIRA_COLUMN_USER]],
 		})
 
+		if id == "" {
+			// Without the worklog id the rows of the same issue, user and
+			// date (with the start time when available) are summed.
+			last := &jiraRows[len(jiraRows)-1]
+			last.Id = StableExternalId(last.Issue, last.User, last.Date)
+			if idx, ok := generatedIds[last.Id]; ok {
+				err = jiraRows[idx].AddRow(last, i.Config.GetWork().WorkHours)
+				if err != nil {
+					return err
+				}
+				jiraRows = jiraRows[:len(jiraRows)-1]
+			} else {
+				generatedIds[last.Id] = len(jiraRows) - 1
+			}
+		}
+
 		i.Logger.Debug("Parse row ", row)
 	}
 
@@ -191,8 +349,7 @@ func (i *TmJiraImporter) convertRows2Agenda(rows *[]TmJiraCsvRow) error {
 
 		for _, row := range *rows {
 
-			if i.IsIssue2Ignore(row.Issue) {
-				i.Logger.Debug("Ignoring issue " + row.Issue)
+			if i.isRow2Ignore(&row) {
 				continue
 			}
 
@@ -224,6 +381,9 @@ func (i *TmJiraImporter) convertRows2Agenda(rows *[]TmJiraCsvRow) error {
 
 		agenda := specs.AgendaTimesheets{}
 		for _, row := range *rows {
+			if i.isRow2Ignore(&row) {
+				continue
+			}
 			agenda.AddResourceTimesheet(i.convertRow2ResourceTimesheet(&row))
 		}
 
@@ -240,6 +400,43 @@ func (i *TmJiraImporter) IsIssue2Ignore(issue string) bool {
 	return false
 }
 
+func (i *TmJiraImporter) isRow2Ignore(row *TmJiraCsvRow) bool {
+	if !i.IsIssue2Ignore(row.Issue) {
+		return false
+	}
+
+	i.Logger.Debug("Ignoring issue " + row.Issue)
+	secs, _ := tmtime.ParseDuration(row.GetDuration(), i.Config.GetWork().WorkHours)
+	i.Report.AddIgnored(REPORT_ISSUE, row.Issue, secs)
+	return true
+}
+
+// AddRow adds the time of the row r to the row.
+func (row *TmJiraCsvRow) AddRow(r *TmJiraCsvRow, workHours int) error {
+	secs, err := tmtime.ParseDuration(row.GetDuration(), workHours)
+	if err != nil {
+		return err
+	}
+	rSecs, err := tmtime.ParseDuration(r.GetDuration(), workHours)
+	if err != nil {
+		return err
+	}
+	row.Seconds = secs + rSecs
+	if row.Descr == "" {
+		row.Descr = r.Descr
+	}
+	return nil
+}
+
+func (row *TmJiraCsvRow) GetDuration() string {
+	if row.Seconds > 0 {
+		d, _ := tmtime.Seconds2CanonicalDuration(row.Seconds, 8, false)
+		return d
+	}
+	// To check. It seems that jira return time in hours.
+	return fmt.Sprintf("%sh", strings.ReplaceAll(row.WorkTime, ",", "."))
+}
+
 func (i *TmJiraImporter) GetMappedUser(user string) (ans string) {
 	if u, ok := i.ResourceMapping[user]; ok {
 		ans = u
@@ -249,18 +446,59 @@ func (i *TmJiraImporter) GetMappedUser(user string) (ans string) {
 	return
 }
 
-func (i *TmJiraImporter) GetMappedTask(descr, issue string) (ans string) {
+// GetJiraProjectFromIssue returns the project key of the issue (PRJ of PRJ-123).
+func GetJiraProjectFromIssue(issue string) string {
+	if idx := strings.LastIndex(issue, "-"); idx > 0 {
+		return issue[0:idx]
+	}
+	return ""
+}
+
+// GetMappedTask returns the task of the row and if a mapping is been
+// found. The exact issue mapping is checked before the rules and the
+// default task of the project.
+func (i *TmJiraImporter) GetMappedTask(row *TmJiraCsvRow) (string, bool) {
 	// Check if there is an issue mapping
-	if task, ok := i.IssueTaskMap[issue]; ok {
-		ans = task
-	} else {
-		if descr != "" {
-			ans = descr
-		} else {
-			ans = issue
-		}
+	if task, ok := i.IssueTaskMap[row.Issue]; ok {
+		return task, true
 	}
-	return
+
+	if task, ok := i.Rules.GetTask(map[string]string{
+		JIRA_COLUMN_ISSUE:       row.Issue,
+		JIRA_COLUMN_SUMMARY:     row.Summary,
+		JIRA_COLUMN_DESCRIPTION: row.Descr,
+		JIRA_COLUMN_PROJECT:     row.Project,
+		JIRA_COLUMN_EPIC:        row.Epic,
+	}); ok {
+		return task, true
+	}
+
+	if task, ok := i.ProjectTaskMap[row.Project]; ok && row.Project != "" {
+		return task, true
+	}
+
+	if row.Descr != "" {
+		return row.Descr, false
+	}
+	return row.Issue, false
+}
+
+// MapperSkeleton returns the mapper with the users and the issues
+// without mapping. The names of the tasks and users must be filled.
+func (i *TmJiraImporter) MapperSkeleton() ([]byte, error) {
+	mapper := &TmJiraMapper{
+		Resources: []TmJiraResource{},
+		Issues:    []TmJiraIssue{},
+	}
+
+	for _, u := range i.Report.GetUnmapped(REPORT_USER) {
+		mapper.Resources = append(mapper.Resources, TmJiraResource{JiraName: u})
+	}
+	for _, issue := range i.Report.GetUnmapped(REPORT_ISSUE) {
+		mapper.Issues = append(mapper.Issues, TmJiraIssue{JiraIssue: issue})
+	}
+
+	return yaml.Marshal(mapper)
 }
 
 func (i *TmJiraImporter) convertRow2ResourceTimesheet(row *TmJiraCsvRow) *specs.ResourceTimesheet {
@@ -268,10 +506,21 @@ func (i *TmJiraImporter) convertRow2ResourceTimesheet(row *TmJiraCsvRow) *specs.
 		Period: &specs.Period{
 			StartPeriod: row.Date,
 		},
-		User: i.GetMappedUser(row.User),
-		Task: i.GetMappedTask(row.Descr, row.Issue),
-		// To check. It seems that jira return time in hours.
-		Duration: fmt.Sprintf("%sh", row.WorkTime),
+		User:       i.GetMappedUser(row.User),
+		Duration:   row.GetDuration(),
+		Source:     JIRA_SOURCE,
+		ExternalId: row.Id,
+	}
+
+	task, mapped := i.GetMappedTask(row)
+	ans.Task = task
+
+	secs := i.getTimesheetSeconds(ans)
+	if _, ok := i.ResourceMapping[row.User]; !ok {
+		i.Report.AddUnmapped(REPORT_USER, row.User, secs)
+	}
+	if !mapped {
+		i.Report.AddUnmapped(REPORT_ISSUE, row.Issue, secs)
 	}
 
 	return ans
@@ -0,0 +1,302 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+)
+
+// TmJiraApiImporter retrieves the worklogs of the issues of a JQL
+// query through the Jira REST API. The rows are converted with the
+// same mapper of the CSV importer.
+type TmJiraApiImporter struct {
+	*TmJiraImporter
+	Client   *TmApiClient
+	Url      string
+	PageSize int
+	// Jira Cloud searches the issues with the endpoint search/jql of
+	// the API v3. Jira Server and Data Center with search of the v2.
+	Cloud bool
+	// Period of the worklogs to import (YYYY-MM-DD)
+	From string
+	To   string
+}
+
+type jiraSearchResponse struct {
+	StartAt    int         `json:"startAt"`
+	MaxResults int         `json:"maxResults"`
+	Total      int         `json:"total"`
+	Issues     []jiraIssue `json:"issues"`
+	// Paging of the search/jql endpoint of Jira Cloud.
+	NextPageToken string `json:"nextPageToken"`
+	IsLast        bool   `json:"isLast"`
+}
+
+type jiraIssue struct {
+	Id     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string `json:"summary"`
+		Project struct {
+			Key string `json:"key"`
+		} `json:"project"`
+	} `json:"fields"`
+}
+
+type jiraWorklogResponse struct {
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Worklogs   []jiraWorklog `json:"worklogs"`
+}
+
+type jiraWorklog struct {
+	Id     string `json:"id"`
+	Author struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"author"`
+	Comment          string `json:"comment"`
+	Started          string `json:"started"`
+	TimeSpentSeconds int64  `json:"timeSpentSeconds"`
+}
+
+func NewTmJiraApiImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string, opts ImportOpts) *TmJiraApiImporter {
+	jiraImporter := NewTmJiraImporter(config, tmDir, filePrefix, opts)
+	jiraConfig := config.GetJira()
+
+	ans := &TmJiraApiImporter{
+		TmJiraImporter: jiraImporter,
+		Client:         NewTmApiClient(jiraImporter.Logger, jiraConfig.MaxRetries),
+		Url:            strings.TrimSuffix(jiraConfig.Url, "/"),
+		PageSize:       jiraConfig.PageSize,
+	}
+
+	if ans.PageSize <= 0 {
+		ans.PageSize = 50
+	}
+
+	switch jiraConfig.Deployment {
+	case "cloud":
+		ans.Cloud = true
+	case "":
+		if u, err := url.Parse(ans.Url); err == nil {
+			ans.Cloud = strings.HasSuffix(u.Hostname(), ".atlassian.net")
+		}
+	}
+
+	user := jiraConfig.User
+	token := jiraConfig.Token
+	ans.Client.Auth = func(req *http.Request) {
+		if token == "" {
+			return
+		}
+		if user != "" {
+			req.SetBasicAuth(user, token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return ans
+}
+
+func (i *TmJiraApiImporter) SetPeriod(from, to string) {
+	i.From = from
+	i.To = to
+}
+
+// Return the JQL with the filter of the worklogs period.
+func (i *TmJiraApiImporter) getJql(jql string) string {
+	filters := []string{}
+	if jql != "" {
+		filters = append(filters, "("+jql+")")
+	}
+	if i.From != "" {
+		filters = append(filters, fmt.Sprintf("worklogDate >= \"%s\"", i.From))
+	}
+	if i.To != "" {
+		filters = append(filters, fmt.Sprintf("worklogDate <= \"%s\"", i.To))
+	}
+	return strings.Join(filters, " AND ")
+}
+
+// Retrieve the issues of the JQL with the endpoint search/jql of Jira
+// Cloud. The pages are retrieved with the token of the next page.
+func (i *TmJiraApiImporter) searchCloudIssues(jql string) ([]jiraIssue, error) {
+	ans := []jiraIssue{}
+	token := ""
+
+	for {
+		params := url.Values{}
+		params.Set("jql", jql)
+		params.Set("fields", "summary,project")
+		params.Set("maxResults", fmt.Sprintf("%d", i.PageSize))
+		if token != "" {
+			params.Set("nextPageToken", token)
+		}
+
+		resp := jiraSearchResponse{}
+		_, err := i.Client.GetJson(i.Url+"/rest/api/3/search/jql?"+params.Encode(), &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, resp.Issues...)
+
+		if resp.IsLast || resp.NextPageToken == "" || len(resp.Issues) == 0 {
+			break
+		}
+		token = resp.NextPageToken
+	}
+
+	return ans, nil
+}
+
+func (i *TmJiraApiImporter) searchIssues(jql string) ([]jiraIssue, error) {
+	if i.Cloud {
+		return i.searchCloudIssues(jql)
+	}
+
+	ans := []jiraIssue{}
+	startAt := 0
+
+	for {
+		params := url.Values{}
+		params.Set("jql", jql)
+		params.Set("fields", "summary,project")
+		params.Set("startAt", fmt.Sprintf("%d", startAt))
+		params.Set("maxResults", fmt.Sprintf("%d", i.PageSize))
+
+		resp := jiraSearchResponse{}
+		_, err := i.Client.GetJson(i.Url+"/rest/api/2/search?"+params.Encode(), &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, resp.Issues...)
+		startAt += len(resp.Issues)
+
+		if len(resp.Issues) == 0 || startAt >= resp.Total {
+			break
+		}
+	}
+
+	return ans, nil
+}
+
+func (i *TmJiraApiImporter) getWorklogs(issue string) ([]jiraWorklog, error) {
+	ans := []jiraWorklog{}
+	startAt := 0
+
+	for {
+		u := fmt.Sprintf("%s/rest/api/2/issue/%s/worklog?startAt=%d&maxResults=%d",
+			i.Url, url.PathEscape(issue), startAt, i.PageSize)
+
+		resp := jiraWorklogResponse{}
+		_, err := i.Client.GetJson(u, &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, resp.Worklogs...)
+		startAt += len(resp.Worklogs)
+
+		if len(resp.Worklogs) == 0 || startAt >= resp.Total {
+			break
+		}
+	}
+
+	return ans, nil
+}
+
+// LoadTimesheets retrieves the worklogs of the issues of the JQL query
+// in the configured period.
+func (i *TmJiraApiImporter) LoadTimesheets(jql string) error {
+	if i.Url == "" {
+		return errors.New("Jira url not configured")
+	}
+	switch i.Config.GetJira().Deployment {
+	case "", "cloud", "server":
+	default:
+		return errors.New("Invalid Jira deployment " + i.Config.GetJira().Deployment)
+	}
+
+	issues, err := i.searchIssues(i.getJql(jql))
+	if err != nil {
+		return err
+	}
+
+	i.Logger.Debug(fmt.Sprintf("Found %d issues.", len(issues)))
+
+	rows := []TmJiraCsvRow{}
+	for _, issue := range issues {
+		project := issue.Fields.Project.Key
+		if project == "" {
+			project = GetJiraProjectFromIssue(issue.Key)
+		}
+
+		worklogs, err := i.getWorklogs(issue.Key)
+		if err != nil {
+			return err
+		}
+
+		for _, w := range worklogs {
+			if len(w.Started) < 10 {
+				return errors.New(fmt.Sprintf("Invalid start date %s of the worklog %s",
+					w.Started, w.Id))
+			}
+
+			if w.TimeSpentSeconds <= 0 {
+				continue
+			}
+
+			date := w.Started[0:10]
+			if (i.From != "" && date < i.From) || (i.To != "" && date > i.To) {
+				continue
+			}
+
+			user := w.Author.DisplayName
+			if user == "" {
+				user = w.Author.Name
+			}
+
+			rows = append(rows, TmJiraCsvRow{
+				Id:      w.Id,
+				Issue:   issue.Key,
+				Summary: issue.Fields.Summary,
+				Project: project,
+				Descr: strings.TrimSpace(strings.ReplaceAll(
+					strings.ReplaceAll(w.Comment, "\n", " "), "\r", "")),
+				Date:    date,
+				User:    user,
+				Seconds: w.TimeSpentSeconds,
+			})
+		}
+	}
+
+	return i.convertRows2Agenda(&rows)
+}
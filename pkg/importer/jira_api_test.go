@@ -0,0 +1,191 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Jira API Importer Test", func() {
+
+	var server *httptest.Server
+	var searchRequests, rateLimited int
+	var jql, auth string
+
+	BeforeEach(func() {
+		searchRequests = 0
+		rateLimited = 0
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+			searchRequests++
+			jql = r.URL.Query().Get("jql")
+			auth = r.Header.Get("Authorization")
+
+			// Simulate the rate limit on the first request.
+			if searchRequests == 1 {
+				rateLimited++
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			if r.URL.Query().Get("startAt") == "0" {
+				fmt.Fprint(w, `{"startAt":0,"maxResults":1,"total":2,"issues":[
+					{"id":"10","key":"PRJ-1","fields":{"summary":"Login","project":{"key":"PRJ"}}}]}`)
+			} else {
+				fmt.Fprint(w, `{"startAt":1,"maxResults":1,"total":2,"issues":[
+					{"id":"11","key":"PRJ-2","fields":{"summary":"[backend] API","project":{"key":"PRJ"}}}]}`)
+			}
+		})
+		mux.HandleFunc("/rest/api/3/search/jql", func(w http.ResponseWriter, r *http.Request) {
+			searchRequests++
+			jql = r.URL.Query().Get("jql")
+
+			if r.URL.Query().Get("nextPageToken") == "" {
+				fmt.Fprint(w, `{"nextPageToken":"page2","isLast":false,"issues":[
+					{"id":"10","key":"PRJ-1","fields":{"summary":"Login","project":{"key":"PRJ"}}}]}`)
+			} else {
+				fmt.Fprint(w, `{"isLast":true,"issues":[
+					{"id":"11","key":"PRJ-2","fields":{"summary":"[backend] API","project":{"key":"PRJ"}}}]}`)
+			}
+		})
+		mux.HandleFunc("/rest/api/2/issue/PRJ-1/worklog", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"startAt":0,"maxResults":1,"total":2,"worklogs":[
+				{"id":"100","author":{"displayName":"Daniele Rondina"},"comment":"Analysis",
+				 "started":"2026-09-01T09:00:00.000+0200","timeSpentSeconds":5400},
+				{"id":"101","author":{"displayName":"Daniele Rondina"},
+				 "started":"2026-08-31T09:00:00.000+0200","timeSpentSeconds":3600}]}`)
+		})
+		mux.HandleFunc("/rest/api/2/issue/PRJ-2/worklog", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"startAt":0,"maxResults":1,"total":1,"worklogs":[
+				{"id":"200","author":{"displayName":"Daniele Rondina"},
+				 "started":"2026-09-02T14:00:00.000+0200","timeSpentSeconds":7200}]}`)
+		})
+
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("Load worklogs", func() {
+
+		It("With paging and rate limit", func() {
+			config := specs.NewTimeMasterConfig(nil)
+			config.GetJira().Url = server.URL
+			config.GetJira().Token = "secret"
+			config.GetJira().PageSize = 1
+			config.GetJira().MaxRetries = 2
+
+			imp := NewTmJiraApiImporter(config, "/tmp", "", ImportOpts{})
+			imp.Client.RetryWait = time.Millisecond
+			imp.SetPeriod("2026-09-01", "2026-09-30")
+			err := imp.ImportMapper(&TmJiraMapper{
+				Resources: []TmJiraResource{{JiraName: "Daniele Rondina", Name: "geaaru"}},
+				Issues:    []TmJiraIssue{{JiraIssue: "PRJ-1", TaskName: "ACT.login"}},
+				Rules: []TmMappingRule{
+					{Field: "summary", Match: `^\[(\w+)\]`, Task: "ACT.$1"},
+				},
+			})
+			Expect(err).Should(BeNil())
+
+			err = imp.LoadTimesheets("project = PRJ")
+			Expect(err).Should(BeNil())
+
+			Expect(rateLimited).To(Equal(1))
+			Expect(searchRequests).To(Equal(3))
+			Expect(auth).To(Equal("Bearer secret"))
+			Expect(jql).To(Equal(
+				`(project = PRJ) AND worklogDate >= "2026-09-01" AND worklogDate <= "2026-09-30"`))
+
+			rt1 := specs.NewResourceTimesheet("geaaru", "2026-09-01", "ACT.login", "1h30m")
+			rt1.Source = JIRA_SOURCE
+			rt1.ExternalId = "100"
+			rt2 := specs.NewResourceTimesheet("geaaru", "2026-09-02", "ACT.backend", "2h")
+			rt2.Source = JIRA_SOURCE
+			rt2.ExternalId = "200"
+
+			Expect(len(*imp.GetTimesheets())).To(Equal(1))
+			Expect((*imp.GetTimesheets())[0].Timesheets).To(Equal([]specs.ResourceTimesheet{
+				*rt1, *rt2,
+			}))
+			Expect(imp.GetReport().IsEmpty()).To(Equal(true))
+		})
+
+		It("Jira Cloud search with the next page token", func() {
+			config := specs.NewTimeMasterConfig(nil)
+			config.GetJira().Url = server.URL
+			config.GetJira().Deployment = "cloud"
+			config.GetJira().PageSize = 1
+
+			imp := NewTmJiraApiImporter(config, "/tmp", "", ImportOpts{})
+			Expect(imp.Cloud).To(Equal(true))
+			err := imp.ImportMapper(&TmJiraMapper{
+				Resources: []TmJiraResource{{JiraName: "Daniele Rondina", Name: "geaaru"}},
+			})
+			Expect(err).Should(BeNil())
+
+			err = imp.LoadTimesheets("project = PRJ")
+			Expect(err).Should(BeNil())
+			Expect(searchRequests).To(Equal(2))
+			Expect(jql).To(Equal("(project = PRJ)"))
+
+			ids := []string{}
+			for _, rt := range (*imp.GetTimesheets())[0].Timesheets {
+				ids = append(ids, rt.ExternalId)
+			}
+			Expect(ids).To(Equal([]string{"100", "101", "200"}))
+		})
+
+		It("Jira deployment", func() {
+			config := specs.NewTimeMasterConfig(nil)
+			config.GetJira().Url = "https://myorg.atlassian.net/"
+			Expect(NewTmJiraApiImporter(config, "/tmp", "", ImportOpts{}).Cloud).To(Equal(true))
+
+			config.GetJira().Deployment = "server"
+			Expect(NewTmJiraApiImporter(config, "/tmp", "", ImportOpts{}).Cloud).To(Equal(false))
+
+			config.GetJira().Deployment = "datacenter"
+			err := NewTmJiraApiImporter(config, "/tmp", "", ImportOpts{}).LoadTimesheets("project = PRJ")
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("Rate limit retries exhausted", func() {
+			config := specs.NewTimeMasterConfig(nil)
+			config.GetJira().Url = server.URL
+
+			imp := NewTmJiraApiImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.LoadTimesheets("project = PRJ")
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("Rate limit"))
+		})
+	})
+})
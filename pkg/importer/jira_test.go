@@ -0,0 +1,246 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Jira Importer Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+
+	Context("Detect columns", func() {
+
+		It("Header of 2020", func() {
+			imp := NewTmJiraImporter(config, "/tmp", "", ImportOpts{})
+			columns, err := imp.DetectColumns([]string{
+				"Issue Key", "Issue summary", "Hours", "Work date", "User Account ID",
+				"Full name", "Team", "Work Description",
+			})
+
+			Expect(err).Should(BeNil())
+			Expect(columns).To(Equal(map[string]int{
+				JIRA_COLUMN_ISSUE:       0,
+				JIRA_COLUMN_SUMMARY:     1,
+				JIRA_COLUMN_HOURS:       2,
+				JIRA_COLUMN_DATE:        3,
+				JIRA_COLUMN_USER:        5,
+				JIRA_COLUMN_DESCRIPTION: 7,
+			}))
+		})
+
+		It("Aliases from mapper", func() {
+			imp := NewTmJiraImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.ImportMapper(&TmJiraMapper{
+				Columns: &TmJiraColumns{
+					User: []string{"Logged by"},
+				},
+			})
+			Expect(err).Should(BeNil())
+
+			columns, err := imp.DetectColumns([]string{
+				"\ufeffIssue key", "Logged By", "Hours", "Date",
+			})
+
+			Expect(err).Should(BeNil())
+			Expect(columns[JIRA_COLUMN_ISSUE]).To(Equal(0))
+			Expect(columns[JIRA_COLUMN_USER]).To(Equal(1))
+			_, ok := columns[JIRA_COLUMN_DESCRIPTION]
+			Expect(ok).To(Equal(false))
+		})
+
+		It("Unknown layout", func() {
+			imp := NewTmJiraImporter(config, "/tmp", "", ImportOpts{})
+			_, err := imp.DetectColumns([]string{"Key", "Hours", "Foo"})
+
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("Headers found: Key, Hours, Foo"))
+		})
+	})
+
+	Context("Mapping rules", func() {
+
+		It("Issue, summary and project", func() {
+			imp := NewTmJiraImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.ImportMapper(&TmJiraMapper{
+				Issues: []TmJiraIssue{{JiraIssue: "PRJ-1", TaskName: "ACT.briefing"}},
+				Rules: []TmMappingRule{
+					{Match: `^PRJ-(\d+)$`, Task: "ACT.ticket$1"},
+					{Field: "summary", Match: `^\[(?P<epic>\w+)\]`, Task: "ACT.${epic}"},
+				},
+				Projects: []TmJiraProject{{Project: "OPS", TaskName: "ACT.operations"}},
+			})
+			Expect(err).Should(BeNil())
+
+			task, ok := imp.GetMappedTask(&TmJiraCsvRow{Issue: "PRJ-1"})
+			Expect(ok).To(Equal(true))
+			Expect(task).To(Equal("ACT.briefing"))
+
+			task, ok = imp.GetMappedTask(&TmJiraCsvRow{Issue: "PRJ-12"})
+			Expect(ok).To(Equal(true))
+			Expect(task).To(Equal("ACT.ticket12"))
+
+			task, ok = imp.GetMappedTask(&TmJiraCsvRow{Issue: "DEV-3", Summary: "[backend] Login"})
+			Expect(ok).To(Equal(true))
+			Expect(task).To(Equal("ACT.backend"))
+
+			task, ok = imp.GetMappedTask(&TmJiraCsvRow{
+				Issue: "OPS-3", Project: GetJiraProjectFromIssue("OPS-3")})
+			Expect(ok).To(Equal(true))
+			Expect(task).To(Equal("ACT.operations"))
+
+			task, ok = imp.GetMappedTask(&TmJiraCsvRow{Issue: "FOO-1", Descr: "Meeting"})
+			Expect(ok).To(Equal(false))
+			Expect(task).To(Equal("Meeting"))
+		})
+
+		It("Epic", func() {
+			imp := NewTmJiraImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.ImportMapper(&TmJiraMapper{
+				Issues: []TmJiraIssue{{JiraIssue: "PRJ-1", TaskName: "ACT.briefing"}},
+				Rules: []TmMappingRule{
+					{Field: "epic", Match: `^PRJ-100$`, Task: "ACT.backend"},
+				},
+			})
+			Expect(err).Should(BeNil())
+
+			columns, err := imp.DetectColumns([]string{
+				"Issue Key", "Hours", "Work date", "Full name", "Epic Link",
+			})
+			Expect(err).Should(BeNil())
+			Expect(columns[JIRA_COLUMN_EPIC]).To(Equal(4))
+
+			task, ok := imp.GetMappedTask(&TmJiraCsvRow{Issue: "PRJ-1", Epic: "PRJ-100"})
+			Expect(ok).To(Equal(true))
+			Expect(task).To(Equal("ACT.briefing"))
+
+			task, ok = imp.GetMappedTask(&TmJiraCsvRow{Issue: "PRJ-2", Epic: "PRJ-100"})
+			Expect(ok).To(Equal(true))
+			Expect(task).To(Equal("ACT.backend"))
+
+			_, ok = imp.GetMappedTask(&TmJiraCsvRow{Issue: "PRJ-3", Epic: "PRJ-200"})
+			Expect(ok).To(Equal(false))
+		})
+
+		It("Invalid field", func() {
+			imp := NewTmJiraImporter(config, "/tmp", "", ImportOpts{})
+			err := imp.ImportMapper(&TmJiraMapper{
+				Rules: []TmMappingRule{{Field: "tag", Match: "x", Task: "ACT.x"}},
+			})
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	Context("Re-import without worklog id", func() {
+
+		It("Edited description", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-jira")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			load := func(content string) *specs.AgendaTimesheets {
+				file := filepath.Join(tmpDir, "report.csv")
+				err := ioutil.WriteFile(file, []byte(content), 0644)
+				Expect(err).Should(BeNil())
+
+				imp := NewTmJiraImporter(config, tmpDir, "", ImportOpts{})
+				err = imp.LoadTimesheets(file)
+				Expect(err).Should(BeNil())
+				return &(*imp.GetTimesheets())[0]
+			}
+
+			current := load("Issue Key,Hours,Work date,Full name,Work Description\n" +
+				"PRJ-1,2,2026-09-01 09:00,geaaru,Analysis\n" +
+				"PRJ-1,1,2026-09-01 09:00,geaaru,\n" +
+				"PRJ-1,1,2026-09-01 14:00,geaaru,Analysis\n")
+			Expect(len(current.Timesheets)).To(Equal(2))
+			Expect(current.Timesheets[0].Duration).To(Equal("3h"))
+
+			imported := load("Issue Key,Hours,Work date,Full name,Work Description\n" +
+				"PRJ-1,1,2026-09-01 14:00,geaaru,Analysis\n" +
+				"PRJ-1,2,2026-09-01 09:00,geaaru,Analysis of the login\n" +
+				"PRJ-1,1,2026-09-01 09:00,geaaru,\n")
+			Expect(current.Timesheets[0].ExternalId).To(Equal(imported.Timesheets[1].ExternalId))
+			Expect(current.Timesheets[1].ExternalId).To(Equal(imported.Timesheets[0].ExternalId))
+
+			summary := MergeFileSummary{}
+			MergeAgenda(current, imported, false, &summary)
+			Expect(summary.Added).To(Equal(0))
+			Expect(summary.Updated).To(Equal(1))
+			Expect(summary.Unchanged).To(Equal(1))
+			Expect(len(current.Timesheets)).To(Equal(2))
+			Expect(current.Timesheets[0].Task).To(Equal("Analysis of the login"))
+		})
+	})
+
+	Context("Unmapped report", func() {
+
+		It("Users, issues and ignored issues", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-jira")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			file := filepath.Join(tmpDir, "report.csv")
+			err = ioutil.WriteFile(file, []byte(
+				"Issue Key,Hours,Work date,Full name\n"+
+					"PRJ-1,2,2026-09-01,Daniele Rondina\n"+
+					"PRJ-2,1.5,2026-09-01,Mario Rossi\n"+
+					"PRJ-2,0.5,2026-09-02,Mario Rossi\n"+
+					"INT-1,3,2026-09-02,Mario Rossi\n"), 0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmJiraImporter(config, tmpDir, "", ImportOpts{})
+			err = imp.ImportMapper(&TmJiraMapper{
+				Resources:     []TmJiraResource{{JiraName: "Daniele Rondina", Name: "geaaru"}},
+				Issues:        []TmJiraIssue{{JiraIssue: "PRJ-1", TaskName: "ACT.task1"}},
+				IgnoredIssues: []string{"INT-1"},
+			})
+			Expect(err).Should(BeNil())
+
+			err = imp.LoadTimesheets(file)
+			Expect(err).Should(BeNil())
+
+			report := imp.GetReport()
+			Expect(report.GetUnmapped(REPORT_USER)).To(Equal([]string{"Mario Rossi"}))
+			Expect(report.GetUnmapped(REPORT_ISSUE)).To(Equal([]string{"PRJ-2"}))
+			Expect(report.Unmapped[1].Entries).To(Equal(2))
+			Expect(report.Unmapped[1].GetHours()).To(Equal("2.00"))
+			Expect(len(report.Ignored)).To(Equal(1))
+			Expect(report.Ignored[0].Value).To(Equal("INT-1"))
+			Expect(report.Ignored[0].Seconds).To(Equal(int64(3 * 3600)))
+
+			data, err := imp.MapperSkeleton()
+			Expect(err).Should(BeNil())
+			mapper, err := TmJiraMapperFromYaml(data)
+			Expect(err).Should(BeNil())
+			Expect(mapper.Resources).To(Equal([]TmJiraResource{{JiraName: "Mario Rossi"}}))
+			Expect(mapper.Issues).To(Equal([]TmJiraIssue{{JiraIssue: "PRJ-2"}}))
+		})
+	})
+})
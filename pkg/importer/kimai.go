@@ -38,14 +38,20 @@ type TmKimaiImporter struct {
 	*DefaultImporter
 	ResourceMapping  map[string]string
 	ActivityTaskMap  map[string]string
+	LabelTaskMap     map[string]string
 	IgnoredLabelsMap map[string]bool
+	Rules            *TmRuleEngine
 }
 
 type TmKimaiMapper struct {
 	Resources  []TmKimaiResource `json:"resources" yaml:"resources"`
 	Activities []TmKimaiActivity `json:"activities" yaml:"activities"`
+	Labels     []TmKimaiLabel    `json:"labels,omitempty" yaml:"labels,omitempty"`
 
 	IgnoredLabels []string `json:"ignored_labels,omitempty" yaml:"ignored_labels,omitempty"`
+	// Rules applied in order when the activity or the label is not mapped.
+	// Supported fields: activity (default), project, tag, description.
+	Rules []TmMappingRule `json:"rules,omitempty" yaml:"rules,omitempty"`
 }
 
 type TmKimaiResource struct {
@@ -58,7 +64,22 @@ type TmKimaiActivity struct {
 	Task     string `json:"task" yaml:"task"`
 }
 
+type TmKimaiLabel struct {
+	Label string `json:"label" yaml:"label"`
+	Task  string `json:"task" yaml:"task"`
+}
+
+const (
+	KIMAI_SOURCE = "kimai"
+
+	KIMAI_FIELD_ACTIVITY    = "activity"
+	KIMAI_FIELD_PROJECT     = "project"
+	KIMAI_FIELD_TAG         = "tag"
+	KIMAI_FIELD_DESCRIPTION = "description"
+)
+
 type TmKimaiCsvRow struct {
+	Id       string
 	Date     string
 	WorkTime string
 	User     string
@@ -81,11 +102,13 @@ func NewTmKimaiImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string
 		DefaultImporter:  NewDefaultImporter(config, tmDir, filePrefix, opts),
 		ResourceMapping:  make(map[string]string, 0),
 		ActivityTaskMap:  make(map[string]string, 0),
+		LabelTaskMap:     make(map[string]string, 0),
 		IgnoredLabelsMap: make(map[string]bool, 0),
+		Rules:            NewTmRuleEngine(),
 	}
 }
 
-func (i *TmKimaiImporter) ImportMapper(mapper *TmKimaiMapper) {
+func (i *TmKimaiImporter) ImportMapper(mapper *TmKimaiMapper) error {
 	if len(mapper.Resources) > 0 {
 		for _, r := range mapper.Resources {
 			i.ResourceMapping[r.KimaiName] = r.Name
@@ -98,11 +121,21 @@ func (i *TmKimaiImporter) ImportMapper(mapper *TmKimaiMapper) {
 		}
 	}
 
+	if len(mapper.Labels) > 0 {
+		for _, label := range mapper.Labels {
+			i.LabelTaskMap[label.Label] = label.Task
+		}
+	}
+
 	if len(mapper.IgnoredLabels) > 0 {
 		for _, label := range mapper.IgnoredLabels {
 			i.IgnoredLabelsMap[label] = true
 		}
 	}
+
+	return i.Rules.AddRules(mapper.Rules, KIMAI_FIELD_ACTIVITY,
+		KIMAI_FIELD_ACTIVITY, KIMAI_FIELD_PROJECT, KIMAI_FIELD_TAG,
+		KIMAI_FIELD_DESCRIPTION)
 }
 
 func (i *TmKimaiImporter) LoadTimesheets(csvFile string) error {
@@ -118,6 +151,8 @@ func (i *TmKimaiImporter) LoadTimesheets(csvFile string) error {
 	reader := csv.NewReader(strings.NewReader(string(data)))
 	rowNum := 0
 	kimaiRows := []TmKimaiCsvRow{}
+	// Index of the rows of every id.
+	generatedIds := make(map[string]int, 0)
 
 	for {
 		row, err := reader.Read()
@@ -145,12 +180,45 @@ func (i *TmKimaiImporter) LoadTimesheets(csvFile string) error {
 			Tags:     strings.Split(row[17], ","),
 		})
 
+		// The CSV export doesn't contain the id of the entry. The rows
+		// with the same user, start, project and activity are summed.
+		last := &kimaiRows[len(kimaiRows)-1]
+		last.Id = StableExternalId(last.User, last.Date, last.Project, last.Activity)
+		if idx, ok := generatedIds[last.Id]; ok {
+			kimaiRows[idx].AddRow(last)
+			kimaiRows = kimaiRows[:len(kimaiRows)-1]
+		} else {
+			generatedIds[last.Id] = len(kimaiRows) - 1
+		}
+
 		i.Logger.Debug("Parse row ", row)
 	}
 
 	return i.convertRows2Agenda(&kimaiRows)
 }
 
+// Return the seconds of the work time in the format HH:MM:SS. An
+// invalid work time is considered of one hour.
+func (row *TmKimaiCsvRow) GetSeconds() int64 {
+	parts := strings.Split(row.WorkTime, ":")
+	if len(parts) == 3 {
+		d, err := time.ParseDuration(fmt.Sprintf("%sh%sm%ss", parts[0], parts[1], parts[2]))
+		if err == nil {
+			return int64(d.Seconds())
+		}
+	}
+	return 3600
+}
+
+// AddRow adds the time of the row r to the row.
+func (row *TmKimaiCsvRow) AddRow(r *TmKimaiCsvRow) {
+	secs := row.GetSeconds() + r.GetSeconds()
+	row.WorkTime = fmt.Sprintf("%02d:%02d:%02d", secs/3600, (secs%3600)/60, secs%60)
+	if row.Descr == "" {
+		row.Descr = r.Descr
+	}
+}
+
 func (i *TmKimaiImporter) convertRows2Agenda(rows *[]TmKimaiCsvRow) error {
 
 	if i.Opts.SplitResource {
@@ -223,16 +291,59 @@ func (i *TmKimaiImporter) GetMappedTask(activity string) (ans string) {
 	return
 }
 
+// MapperSkeleton returns the mapper with the users, the activities and
+// the labels without mapping. The names of the tasks and users must be filled.
+func (i *TmKimaiImporter) MapperSkeleton() ([]byte, error) {
+	mapper := &TmKimaiMapper{
+		Resources:  []TmKimaiResource{},
+		Activities: []TmKimaiActivity{},
+		Labels:     []TmKimaiLabel{},
+	}
+
+	for _, u := range i.Report.GetUnmapped(REPORT_USER) {
+		mapper.Resources = append(mapper.Resources, TmKimaiResource{KimaiName: u})
+	}
+	for _, a := range i.Report.GetUnmapped(REPORT_ACTIVITY) {
+		mapper.Activities = append(mapper.Activities, TmKimaiActivity{Activity: a})
+	}
+	for _, l := range i.Report.GetUnmapped(REPORT_LABEL) {
+		mapper.Labels = append(mapper.Labels, TmKimaiLabel{Label: l})
+	}
+
+	return yaml.Marshal(mapper)
+}
+
+// getExactMappedTask returns the task of the label (if present) or of the
+// activity and if a mapping is been found. Without mapping the label
+// or the activity is returned.
+func (i *TmKimaiImporter) getExactMappedTask(activity, label string) (string, bool) {
+	if label != "" {
+		if task, ok := i.LabelTaskMap[label]; ok {
+			return task, true
+		}
+		return label, false
+	}
+
+	if task, ok := i.ActivityTaskMap[activity]; ok {
+		return task, true
+	}
+	return activity, false
+}
+
 func (i *TmKimaiImporter) convertRow2ResourceTimesheet(row *TmKimaiCsvRow) *specs.ResourceTimesheet {
 	ans := &specs.ResourceTimesheet{
 		Period: &specs.Period{
 			StartPeriod: row.Date,
 		},
-		User: i.GetMappedUser(row.User),
+		User:       i.GetMappedUser(row.User),
+		Source:     KIMAI_SOURCE,
+		ExternalId: row.Id,
 	}
 
 	// Check if there is mapping.
-	task := i.GetMappedTask(strings.TrimSpace(row.Activity))
+	activity := strings.TrimSpace(row.Activity)
+	label := ""
+	tags := []string{}
 
 	if len(row.Tags) > 0 {
 		// Get the first label not ignored.
@@ -240,13 +351,28 @@ func (i *TmKimaiImporter) convertRow2ResourceTimesheet(row *TmKimaiCsvRow) *spec
 			if i.IsLabel2Ignore(strings.TrimSpace(row.Tags[idx])) {
 				continue
 			}
-			label := strings.TrimSpace(row.Tags[idx])
-			if label != "" {
-				task = label
+			if l := strings.TrimSpace(row.Tags[idx]); l != "" {
+				label = l
+				tags = append(tags, l)
 			}
 		}
 	}
 
+	// The exact mapping of the label or of the activity is checked
+	// before the rules.
+	task, mapped := i.getExactMappedTask(activity, label)
+	if !mapped {
+		if ruleTask, ok := i.Rules.GetTaskFromValues(map[string][]string{
+			KIMAI_FIELD_ACTIVITY:    []string{activity},
+			KIMAI_FIELD_PROJECT:     []string{strings.TrimSpace(row.Project)},
+			KIMAI_FIELD_TAG:         tags,
+			KIMAI_FIELD_DESCRIPTION: []string{row.Descr},
+		}); ok {
+			task = ruleTask
+			mapped = true
+		}
+	}
+
 	ans.Task = task
 
 	// Convert duration HH:MM:SS in hours.
@@ -265,5 +391,17 @@ func (i *TmKimaiImporter) convertRow2ResourceTimesheet(row *TmKimaiCsvRow) *spec
 		}
 	}
 
+	secs := i.getTimesheetSeconds(ans)
+	if _, ok := i.ResourceMapping[row.User]; !ok {
+		i.Report.AddUnmapped(REPORT_USER, row.User, secs)
+	}
+	if !mapped {
+		if label != "" {
+			i.Report.AddUnmapped(REPORT_LABEL, label, secs)
+		} else {
+			i.Report.AddUnmapped(REPORT_ACTIVITY, activity, secs)
+		}
+	}
+
 	return ans
 }
@@ -0,0 +1,245 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+)
+
+// TmKimaiApiImporter retrieves the timesheets of a period through
+// the Kimai REST API. The rows are converted with the same mapper
+// of the CSV importer.
+type TmKimaiApiImporter struct {
+	*TmKimaiImporter
+	Client   *TmApiClient
+	Url      string
+	PageSize int
+	// Period of the timesheets to import (YYYY-MM-DD)
+	From string
+	To   string
+	// Username or alias of the users to import. If empty the
+	// timesheets of all users are imported.
+	Users []string
+}
+
+type kimaiUser struct {
+	Id       int    `json:"id"`
+	Username string `json:"username"`
+	Alias    string `json:"alias"`
+}
+
+type kimaiTimesheet struct {
+	Id          int       `json:"id"`
+	Begin       string    `json:"begin"`
+	Duration    int64     `json:"duration"`
+	Description string    `json:"description"`
+	User        kimaiUser `json:"user"`
+	Activity    struct {
+		Name string `json:"name"`
+	} `json:"activity"`
+	Project struct {
+		Name string `json:"name"`
+	} `json:"project"`
+	Tags []string `json:"tags"`
+}
+
+func NewTmKimaiApiImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string, opts ImportOpts) *TmKimaiApiImporter {
+	kimaiImporter := NewTmKimaiImporter(config, tmDir, filePrefix, opts)
+	kimaiConfig := config.GetKimai()
+
+	ans := &TmKimaiApiImporter{
+		TmKimaiImporter: kimaiImporter,
+		Client:          NewTmApiClient(kimaiImporter.Logger, kimaiConfig.MaxRetries),
+		Url:             strings.TrimSuffix(kimaiConfig.Url, "/"),
+		PageSize:        kimaiConfig.PageSize,
+		Users:           []string{},
+	}
+
+	if ans.PageSize <= 0 {
+		ans.PageSize = 100
+	}
+
+	user := kimaiConfig.User
+	token := kimaiConfig.Token
+	ans.Client.Auth = func(req *http.Request) {
+		if token == "" {
+			return
+		}
+		if user != "" {
+			req.Header.Set("X-AUTH-USER", user)
+			req.Header.Set("X-AUTH-TOKEN", token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return ans
+}
+
+func (i *TmKimaiApiImporter) SetPeriod(from, to string) {
+	i.From = from
+	i.To = to
+}
+
+func (i *TmKimaiApiImporter) SetUsers(users []string) {
+	i.Users = users
+}
+
+// Return the ids of the configured users. The list of the users
+// requires the permission view_user: without it only the current
+// user is available.
+func (i *TmKimaiApiImporter) getUsersIds() ([]string, error) {
+	users := []kimaiUser{}
+	_, err := i.Client.GetJson(i.Url+"/api/users?visible=3", &users)
+	if apiErr, ok := err.(*TmApiError); ok && apiErr.StatusCode == http.StatusForbidden {
+		i.Logger.Debug("List of the users not permitted. Using the current user.")
+		me := kimaiUser{}
+		_, err = i.Client.GetJson(i.Url+"/api/users/me", &me)
+		users = []kimaiUser{me}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ans := []string{}
+	for _, name := range i.Users {
+		found := false
+		for _, u := range users {
+			if u.Username == name || (u.Alias != "" && u.Alias == name) {
+				ans = append(ans, strconv.Itoa(u.Id))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("User " + name + " not found on Kimai")
+		}
+	}
+
+	return ans, nil
+}
+
+func (i *TmKimaiApiImporter) getTimesheets(userId string) ([]kimaiTimesheet, error) {
+	ans := []kimaiTimesheet{}
+	page := 1
+
+	for {
+		params := url.Values{}
+		params.Set("user", userId)
+		params.Set("full", "true")
+		params.Set("page", strconv.Itoa(page))
+		params.Set("size", strconv.Itoa(i.PageSize))
+		if i.From != "" {
+			params.Set("begin", i.From+"T00:00:00")
+		}
+		if i.To != "" {
+			params.Set("end", i.To+"T23:59:59")
+		}
+
+		timesheets := []kimaiTimesheet{}
+		headers, err := i.Client.GetJson(i.Url+"/api/timesheets?"+params.Encode(), &timesheets)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, timesheets...)
+
+		totalPages, err := strconv.Atoi(headers.Get("X-Total-Pages"))
+		if err != nil || page >= totalPages || len(timesheets) == 0 {
+			break
+		}
+		page++
+	}
+
+	return ans, nil
+}
+
+func (i *TmKimaiApiImporter) convertTimesheet2Row(t *kimaiTimesheet) (*TmKimaiCsvRow, error) {
+	// Begin is in the format 2026-09-01T09:00:00+0200
+	if len(t.Begin) < 16 {
+		return nil, errors.New(fmt.Sprintf("Invalid begin %s of the timesheet %d",
+			t.Begin, t.Id))
+	}
+
+	user := t.User.Alias
+	if user == "" {
+		user = t.User.Username
+	}
+
+	return &TmKimaiCsvRow{
+		Id:   strconv.Itoa(t.Id),
+		Date: fmt.Sprintf("%s %s", t.Begin[0:10], t.Begin[11:16]),
+		WorkTime: fmt.Sprintf("%02d:%02d:%02d",
+			t.Duration/3600, (t.Duration%3600)/60, t.Duration%60),
+		User:     user,
+		Project:  t.Project.Name,
+		Activity: t.Activity.Name,
+		Descr: strings.TrimSpace(strings.ReplaceAll(
+			strings.ReplaceAll(t.Description, "\n", ""), "\r", "")),
+		Tags: t.Tags,
+	}, nil
+}
+
+// LoadTimesheets retrieves the timesheets of the configured users
+// and period. The source argument is not used.
+func (i *TmKimaiApiImporter) LoadTimesheets(source string) error {
+	if i.Url == "" {
+		return errors.New("Kimai url not configured")
+	}
+
+	usersIds := []string{"all"}
+	if len(i.Users) > 0 {
+		ids, err := i.getUsersIds()
+		if err != nil {
+			return err
+		}
+		usersIds = ids
+	}
+
+	rows := []TmKimaiCsvRow{}
+	for _, userId := range usersIds {
+		timesheets, err := i.getTimesheets(userId)
+		if err != nil {
+			return err
+		}
+
+		for idx := range timesheets {
+			// Skip running timesheets
+			if timesheets[idx].Duration <= 0 {
+				continue
+			}
+
+			row, err := i.convertTimesheet2Row(&timesheets[idx])
+			if err != nil {
+				return err
+			}
+			rows = append(rows, *row)
+		}
+	}
+
+	return i.convertRows2Agenda(&rows)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Kimai API Importer Test", func() {
+
+	var server *httptest.Server
+	var queries []string
+	var authUser, authToken string
+
+	BeforeEach(func() {
+		queries = []string{}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
+			// Only the admin could list the users.
+			if r.Header.Get("X-AUTH-USER") != "admin" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"code":403,"message":"Access denied."}`)
+				return
+			}
+			fmt.Fprint(w, `[{"id":1,"username":"geaaru","alias":"Daniele"},
+				{"id":2,"username":"mrossi","alias":""}]`)
+		})
+		mux.HandleFunc("/api/users/me", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id":2,"username":"mrossi","alias":""}`)
+		})
+		mux.HandleFunc("/api/timesheets", func(w http.ResponseWriter, r *http.Request) {
+			authUser = r.Header.Get("X-AUTH-USER")
+			authToken = r.Header.Get("X-AUTH-TOKEN")
+			q := r.URL.Query()
+			queries = append(queries, fmt.Sprintf("%s/%s/%s/%s",
+				q.Get("user"), q.Get("page"), q.Get("begin"), q.Get("end")))
+
+			w.Header().Set("X-Total-Pages", "2")
+			if q.Get("page") == "1" {
+				fmt.Fprint(w, `[{"id":10,"begin":"2026-09-01T09:00:00+0200","duration":5400,
+					"description":"Analysis","user":{"id":1,"username":"geaaru","alias":"Daniele"},
+					"activity":{"name":"Development"},"project":{"name":"Customer"},
+					"tags":["billable","briefing"]}]`)
+			} else {
+				fmt.Fprint(w, `[{"id":11,"begin":"2026-09-02T14:00:00+0200","duration":3600,
+					"description":"","user":{"id":1,"username":"geaaru","alias":"Daniele"},
+					"activity":{"name":"Support"},"project":{"name":"Customer"},"tags":[]},
+					{"id":12,"begin":"2026-09-02T16:00:00+0200","duration":0,
+					"user":{"id":1,"username":"geaaru"},"activity":{"name":"Support"},
+					"project":{"name":"Customer"},"tags":[]}]`)
+			}
+		})
+
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("Load timesheets", func() {
+
+		It("Users and mapper", func() {
+			config := specs.NewTimeMasterConfig(nil)
+			config.GetKimai().Url = server.URL
+			config.GetKimai().User = "admin"
+			config.GetKimai().Token = "secret"
+
+			imp := NewTmKimaiApiImporter(config, "/tmp", "", ImportOpts{})
+			imp.SetPeriod("2026-09-01", "2026-09-30")
+			imp.SetUsers([]string{"Daniele"})
+			err := imp.ImportMapper(&TmKimaiMapper{
+				Resources:     []TmKimaiResource{{KimaiName: "Daniele", Name: "geaaru"}},
+				Activities:    []TmKimaiActivity{{Activity: "Support", Task: "CUSTOMER.support"}},
+				Labels:        []TmKimaiLabel{{Label: "briefing", Task: "CUSTOMER.briefing"}},
+				IgnoredLabels: []string{"billable"},
+			})
+			Expect(err).Should(BeNil())
+
+			err = imp.LoadTimesheets("")
+			Expect(err).Should(BeNil())
+
+			Expect(authUser).To(Equal("admin"))
+			Expect(authToken).To(Equal("secret"))
+			Expect(queries).To(Equal([]string{
+				"1/1/2026-09-01T00:00:00/2026-09-30T23:59:59",
+				"1/2/2026-09-01T00:00:00/2026-09-30T23:59:59",
+			}))
+
+			rt1 := specs.NewResourceTimesheet("geaaru", "2026-09-01 09:00", "CUSTOMER.briefing", "1.5h")
+			rt1.Source = KIMAI_SOURCE
+			rt1.ExternalId = "10"
+			rt2 := specs.NewResourceTimesheet("geaaru", "2026-09-02 14:00", "CUSTOMER.support", "1.0h")
+			rt2.Source = KIMAI_SOURCE
+			rt2.ExternalId = "11"
+
+			Expect(len(*imp.GetTimesheets())).To(Equal(1))
+			Expect((*imp.GetTimesheets())[0].Timesheets).To(Equal([]specs.ResourceTimesheet{
+				*rt1, *rt2,
+			}))
+			Expect(imp.GetReport().IsEmpty()).To(Equal(true))
+		})
+
+		It("Current user without the permission to list the users", func() {
+			config := specs.NewTimeMasterConfig(nil)
+			config.GetKimai().Url = server.URL
+			config.GetKimai().User = "mrossi"
+			config.GetKimai().Token = "secret"
+
+			imp := NewTmKimaiApiImporter(config, "/tmp", "", ImportOpts{})
+			imp.SetPeriod("2026-09-01", "2026-09-30")
+			imp.SetUsers([]string{"mrossi"})
+			err := imp.LoadTimesheets("")
+			Expect(err).Should(BeNil())
+			Expect(queries).To(Equal([]string{
+				"2/1/2026-09-01T00:00:00/2026-09-30T23:59:59",
+				"2/2/2026-09-01T00:00:00/2026-09-30T23:59:59",
+			}))
+
+			imp.SetUsers([]string{"geaaru"})
+			err = imp.LoadTimesheets("")
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("Unknown user", func() {
+			config := specs.NewTimeMasterConfig(nil)
+			config.GetKimai().Url = server.URL
+
+			imp := NewTmKimaiApiImporter(config, "/tmp", "", ImportOpts{})
+			imp.SetUsers([]string{"foo"})
+			err := imp.LoadTimesheets("")
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+})
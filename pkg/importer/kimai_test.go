@@ -0,0 +1,168 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Create a row of the Kimai CSV export.
+func kimaiRow(date, duration, user, project, activity, tags string) string {
+	fields := make([]string, 18)
+	fields[0] = date
+	fields[1] = "09:00"
+	fields[3] = duration
+	fields[10] = user
+	fields[13] = project
+	fields[14] = activity
+	fields[17] = tags
+	return strings.Join(fields, ",") + "\n"
+}
+
+var _ = Describe("Kimai Importer Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+
+	Context("Mapping rules", func() {
+
+		It("Project, activity and tags", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-kimai")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			file := filepath.Join(tmpDir, "export.csv")
+			err = ioutil.WriteFile(file, []byte(
+				strings.Repeat("h,", 17)+"h\n"+
+					kimaiRow("2026-09-01", "02:00:00", "geaaru", "Internal", "Meeting", "")+
+					kimaiRow("2026-09-01", "01:30:00", "geaaru", "Customer", "Development", `"billable,TCK-42"`)+
+					kimaiRow("2026-09-02", "01:00:00", "geaaru", "Customer", "Support", "")+
+					kimaiRow("2026-09-02", "01:00:00", "geaaru", "Other", "Support", "")),
+				0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmKimaiImporter(config, tmpDir, "", ImportOpts{})
+			err = imp.ImportMapper(&TmKimaiMapper{
+				Resources:     []TmKimaiResource{{KimaiName: "geaaru", Name: "geaaru"}},
+				IgnoredLabels: []string{"billable"},
+				Rules: []TmMappingRule{
+					{Field: "tag", Match: `^TCK-(\d+)$`, Task: "CUSTOMER.ticket$1"},
+					{Field: "project", Match: `^Internal$`, Task: "INTERNAL.meetings"},
+					{Match: `^Support$`, Task: "CUSTOMER.support"},
+				},
+			})
+			Expect(err).Should(BeNil())
+
+			err = imp.LoadTimesheets(file)
+			Expect(err).Should(BeNil())
+
+			tasks := []string{}
+			for _, rt := range (*imp.GetTimesheets())[0].Timesheets {
+				tasks = append(tasks, rt.Task)
+			}
+			Expect(tasks).To(Equal([]string{
+				"INTERNAL.meetings", "CUSTOMER.ticket42", "CUSTOMER.support", "CUSTOMER.support",
+			}))
+			Expect(imp.GetReport().IsEmpty()).To(Equal(true))
+		})
+
+		It("Exact mapping before the rules", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-kimai")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			file := filepath.Join(tmpDir, "export.csv")
+			err = ioutil.WriteFile(file, []byte(
+				strings.Repeat("h,", 17)+"h\n"+
+					kimaiRow("2026-09-01", "01:00:00", "geaaru", "Customer", "Support", "")+
+					kimaiRow("2026-09-02", "01:00:00", "geaaru", "Customer", "Support", "TCK-1")+
+					kimaiRow("2026-09-03", "01:00:00", "geaaru", "Customer", "Development", "")),
+				0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmKimaiImporter(config, tmpDir, "", ImportOpts{})
+			err = imp.ImportMapper(&TmKimaiMapper{
+				Resources:  []TmKimaiResource{{KimaiName: "geaaru", Name: "geaaru"}},
+				Activities: []TmKimaiActivity{{Activity: "Support", Task: "CUSTOMER.helpdesk"}},
+				Labels:     []TmKimaiLabel{{Label: "TCK-1", Task: "CUSTOMER.onboarding"}},
+				Rules: []TmMappingRule{
+					{Field: "project", Match: `^Customer$`, Task: "CUSTOMER.generic"},
+				},
+			})
+			Expect(err).Should(BeNil())
+
+			err = imp.LoadTimesheets(file)
+			Expect(err).Should(BeNil())
+
+			tasks := []string{}
+			for _, rt := range (*imp.GetTimesheets())[0].Timesheets {
+				tasks = append(tasks, rt.Task)
+			}
+			Expect(tasks).To(Equal([]string{
+				"CUSTOMER.helpdesk", "CUSTOMER.onboarding", "CUSTOMER.generic",
+			}))
+			Expect(imp.GetReport().IsEmpty()).To(Equal(true))
+		})
+	})
+
+	Context("Re-import", func() {
+
+		It("Stable ids and duplicated rows", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-kimai")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			load := func(rows ...string) []specs.ResourceTimesheet {
+				file := filepath.Join(tmpDir, "export.csv")
+				err := ioutil.WriteFile(file, []byte(
+					strings.Repeat("h,", 17)+"h\n"+strings.Join(rows, "")), 0644)
+				Expect(err).Should(BeNil())
+
+				imp := NewTmKimaiImporter(config, tmpDir, "", ImportOpts{})
+				err = imp.LoadTimesheets(file)
+				Expect(err).Should(BeNil())
+				return (*imp.GetTimesheets())[0].Timesheets
+			}
+
+			first := load(
+				kimaiRow("2026-09-01", "01:00:00", "geaaru", "Customer", "Support", ""),
+				kimaiRow("2026-09-01", "00:30:00", "geaaru", "Customer", "Support", ""),
+				kimaiRow("2026-09-02", "01:00:00", "geaaru", "Customer", "Support", ""),
+			)
+			Expect(len(first)).To(Equal(2))
+			Expect(first[0].Duration).To(Equal("1.5h"))
+
+			second := load(
+				kimaiRow("2026-09-02", "01:00:00", "geaaru", "Customer", "Support", ""),
+				kimaiRow("2026-09-01", "00:30:00", "geaaru", "Customer", "Support", ""),
+			)
+			Expect(second[0].ExternalId).To(Equal(first[1].ExternalId))
+			Expect(second[1].ExternalId).To(Equal(first[0].ExternalId))
+		})
+	})
+})
@@ -0,0 +1,216 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	"github.com/geaaru/time-master/pkg/tools"
+
+	"gopkg.in/yaml.v2"
+)
+
+type MergeSummary struct {
+	Files []MergeFileSummary
+}
+
+type MergeFileSummary struct {
+	File      string
+	Created   bool
+	Added     int
+	Updated   int
+	Removed   int
+	Unchanged int
+	Diff      string
+}
+
+func (s *MergeFileSummary) HasChanges() bool {
+	return s.Added > 0 || s.Updated > 0 || s.Removed > 0
+}
+
+func (s *MergeFileSummary) String() string {
+	return fmt.Sprintf("%s: %d added, %d updated, %d removed, %d unchanged",
+		s.File, s.Added, s.Updated, s.Removed, s.Unchanged)
+}
+
+func getMergeKey(rt *specs.ResourceTimesheet) string {
+	if rt.ExternalId == "" {
+		return ""
+	}
+	return rt.Source + "/" + rt.ExternalId
+}
+
+func isSameTimesheet(a, b *specs.ResourceTimesheet) bool {
+	return a.Period.StartPeriod == b.Period.StartPeriod &&
+		a.User == b.User && a.Task == b.Task &&
+		a.Duration == b.Duration && a.Note == b.Note
+}
+
+// MergeAgenda merges the imported agenda with the existing agenda. The
+// entries are identified by source and external id. The entries without
+// external id are maintained.
+func MergeAgenda(current, imported *specs.AgendaTimesheets, removeMissing bool, summary *MergeFileSummary) {
+	importedMap := make(map[string]*specs.ResourceTimesheet, 0)
+	sources := make(map[string]bool, 0)
+	minDate := ""
+	maxDate := ""
+
+	for idx := range imported.Timesheets {
+		rt := &imported.Timesheets[idx]
+		if key := getMergeKey(rt); key != "" {
+			importedMap[key] = rt
+		}
+		sources[rt.Source] = true
+
+		d := rt.Period.StartPeriod
+		if minDate == "" || d < minDate {
+			minDate = d
+		}
+		if maxDate == "" || d > maxDate {
+			maxDate = d
+		}
+	}
+
+	timesheets := []specs.ResourceTimesheet{}
+	merged := make(map[string]bool, 0)
+
+	for idx := range current.Timesheets {
+		rt := current.Timesheets[idx]
+		key := getMergeKey(&rt)
+
+		if key == "" {
+			timesheets = append(timesheets, rt)
+			continue
+		}
+
+		if irt, ok := importedMap[key]; ok {
+			merged[key] = true
+			if isSameTimesheet(&rt, irt) {
+				summary.Unchanged++
+			} else {
+				summary.Updated++
+			}
+			timesheets = append(timesheets, *irt)
+			continue
+		}
+
+		// Remove only the entries of the same source in the
+		// period of the imported data.
+		if removeMissing && sources[rt.Source] &&
+			rt.Period.StartPeriod >= minDate && rt.Period.StartPeriod <= maxDate {
+			summary.Removed++
+			continue
+		}
+
+		timesheets = append(timesheets, rt)
+	}
+
+	for idx := range imported.Timesheets {
+		rt := imported.Timesheets[idx]
+		key := getMergeKey(&rt)
+		if key != "" && merged[key] {
+			continue
+		}
+		summary.Added++
+		timesheets = append(timesheets, rt)
+	}
+
+	current.Timesheets = timesheets
+}
+
+// MergeTimesheets merges the imported timesheets with the existing
+// files and writes the files if the dry run is disabled.
+func (i *DefaultImporter) MergeTimesheets() (*MergeSummary, error) {
+	ans := &MergeSummary{
+		Files: []MergeFileSummary{},
+	}
+
+	tmDir, err := filepath.Abs(i.TimesheetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range i.Timesheets {
+		imported := &i.Timesheets[idx]
+
+		if i.FilePrefix == "" && imported.File == "" {
+			return nil, errors.New("Both file prefix and agenda file are empty")
+		}
+
+		tmFile := filepath.Join(tmDir, fmt.Sprintf("%s%s.yml", i.FilePrefix, imported.File))
+		summary := MergeFileSummary{
+			File: tmFile,
+		}
+
+		var original []byte
+		var current *specs.AgendaTimesheets
+
+		if tools.Exists(tmFile) {
+			original, err = ioutil.ReadFile(tmFile)
+			if err != nil {
+				return nil, err
+			}
+
+			current, err = specs.AgengaTimesheetFromYaml(original, tmFile)
+			if err != nil {
+				return nil, errors.New("Error on parse file " + tmFile + ": " + err.Error())
+			}
+		} else {
+			summary.Created = true
+			current = &specs.AgendaTimesheets{
+				Name:       imported.Name,
+				Timesheets: []specs.ResourceTimesheet{},
+			}
+		}
+
+		MergeAgenda(current, imported, i.Opts.RemoveMissing, &summary)
+
+		data, err := yaml.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Diff = tools.UnifiedDiff(filepath.Join("a", tmFile), filepath.Join("b", tmFile),
+			string(original), string(data))
+
+		if !i.Opts.DryRun && summary.HasChanges() {
+			err = os.MkdirAll(filepath.Dir(tmFile), os.ModePerm)
+			if err != nil {
+				return nil, err
+			}
+
+			err = ioutil.WriteFile(tmFile, data, 0644)
+			if err != nil {
+				return nil, err
+			}
+
+			i.Logger.Info(fmt.Sprintf(">>> [timesheet] Updated file %s :check_mark:", tmFile))
+		}
+
+		ans.Files = append(ans.Files, summary)
+	}
+
+	return ans, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newImportedTimesheet(id, date, task, duration string) specs.ResourceTimesheet {
+	rt := specs.NewResourceTimesheet("geaaru", date, task, duration)
+	rt.Source = "jira"
+	rt.ExternalId = id
+	return *rt
+}
+
+var _ = Describe("Merge Test", func() {
+
+	Context("Merge agenda", func() {
+
+		var current *specs.AgendaTimesheets
+
+		BeforeEach(func() {
+			current = &specs.AgendaTimesheets{
+				Name: "geaaru",
+				Timesheets: []specs.ResourceTimesheet{
+					*specs.NewResourceTimesheet("geaaru", "2026-09-01", "ACT.manual", "1h"),
+					newImportedTimesheet("1", "2026-09-01", "ACT.task1", "2h"),
+					newImportedTimesheet("2", "2026-09-02", "ACT.task1", "3h"),
+					newImportedTimesheet("3", "2026-09-03", "ACT.task1", "1h"),
+				},
+			}
+		})
+
+		It("Add and update", func() {
+			imported := &specs.AgendaTimesheets{
+				Timesheets: []specs.ResourceTimesheet{
+					newImportedTimesheet("1", "2026-09-01", "ACT.task1", "2h"),
+					newImportedTimesheet("2", "2026-09-02", "ACT.task1", "4h"),
+					newImportedTimesheet("4", "2026-09-04", "ACT.task2", "1h"),
+				},
+			}
+			summary := MergeFileSummary{}
+
+			MergeAgenda(current, imported, false, &summary)
+
+			Expect(summary.Added).To(Equal(1))
+			Expect(summary.Updated).To(Equal(1))
+			Expect(summary.Unchanged).To(Equal(1))
+			Expect(summary.Removed).To(Equal(0))
+			Expect(len(current.Timesheets)).To(Equal(5))
+			Expect(current.Timesheets[0].Task).To(Equal("ACT.manual"))
+			Expect(current.Timesheets[2].Duration).To(Equal("4h"))
+		})
+
+		It("Re-import is idempotent", func() {
+			imported := &specs.AgendaTimesheets{
+				Timesheets: []specs.ResourceTimesheet{
+					newImportedTimesheet("1", "2026-09-01", "ACT.task1", "2h"),
+					newImportedTimesheet("2", "2026-09-02", "ACT.task1", "3h"),
+				},
+			}
+			summary := MergeFileSummary{}
+
+			MergeAgenda(current, imported, false, &summary)
+
+			Expect(summary.HasChanges()).To(BeFalse())
+			Expect(len(current.Timesheets)).To(Equal(4))
+		})
+
+		It("Remove missing", func() {
+			imported := &specs.AgendaTimesheets{
+				Timesheets: []specs.ResourceTimesheet{
+					newImportedTimesheet("1", "2026-09-01", "ACT.task1", "2h"),
+					newImportedTimesheet("3", "2026-09-02", "ACT.task1", "1h"),
+				},
+			}
+			summary := MergeFileSummary{}
+
+			MergeAgenda(current, imported, true, &summary)
+
+			// The entry 2 is removed. The entry 3 is moved on 2026-09-02.
+			Expect(summary.Removed).To(Equal(1))
+			Expect(summary.Updated).To(Equal(1))
+			Expect(len(current.Timesheets)).To(Equal(3))
+			Expect(current.Timesheets[0].Task).To(Equal("ACT.manual"))
+		})
+	})
+})
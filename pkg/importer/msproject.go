@@ -0,0 +1,416 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	formatter "github.com/geaaru/time-master/pkg/formatter"
+	log "github.com/geaaru/time-master/pkg/logger"
+	msproject "github.com/geaaru/time-master/pkg/msproject"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type TmMsProjectMapper struct {
+	Resources []TmMsProjectResource `json:"resources" yaml:"resources"`
+}
+
+type TmMsProjectResource struct {
+	MsProjectName string `json:"msproject_name" yaml:"msproject_name"`
+	Name          string `json:"name" yaml:"name"`
+}
+
+// TmMsProjectImporter converts the tasks of a MS Project XML (MSPDI)
+// file to time-master activities.
+type TmMsProjectImporter struct {
+	Logger *log.TmLogger
+	Config *specs.TimeMasterConfig
+
+	// Outline level of the tasks converted to activities. With 0 all
+	// the tasks of the project are added to a single activity.
+	ActivityLevel int
+	// Name of the activity used when ActivityLevel is 0.
+	ActivityName string
+
+	ResourceMapping map[string]string
+	Report          *TmImportReport
+
+	resources []specs.Resource
+}
+
+// Node of the outline of the MSPDI tasks.
+type msProjectNode struct {
+	Task     *msproject.Task
+	Name     string
+	FullName string
+	Children []*msProjectNode
+}
+
+var (
+	msProjectActivityRegex = regexp.MustCompile(`[^A-Z0-9_-]+`)
+	msProjectTaskRegex     = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+func TmMsProjectMapperFromYaml(data []byte) (*TmMsProjectMapper, error) {
+	ans := &TmMsProjectMapper{}
+	if err := yaml.Unmarshal(data, ans); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func NewTmMsProjectImporter(config *specs.TimeMasterConfig, resources []specs.Resource) *TmMsProjectImporter {
+	return &TmMsProjectImporter{
+		Logger:          log.NewTmLogger(config),
+		Config:          config,
+		ActivityLevel:   0,
+		ResourceMapping: make(map[string]string, 0),
+		Report:          NewTmImportReport(),
+		resources:       resources,
+	}
+}
+
+func (i *TmMsProjectImporter) ImportMapper(mapper *TmMsProjectMapper) error {
+	for _, r := range mapper.Resources {
+		if r.MsProjectName == "" {
+			return errors.New("Invalid resource mapping without msproject_name")
+		}
+		i.ResourceMapping[r.MsProjectName] = r.Name
+	}
+	return nil
+}
+
+func (i *TmMsProjectImporter) GetReport() *TmImportReport {
+	return i.Report
+}
+
+// GetMappedUser returns the user of the MS Project resource and if
+// the user is been found. The mapper is checked before the name, the
+// email and the user of the resources.
+func (i *TmMsProjectImporter) GetMappedUser(name string) (string, bool) {
+	if u, ok := i.ResourceMapping[name]; ok && u != "" {
+		return u, true
+	}
+
+	for _, r := range i.resources {
+		if strings.EqualFold(r.Name, name) || r.User == name {
+			return r.User, true
+		}
+		for _, e := range r.Email {
+			if strings.EqualFold(e, name) {
+				return r.User, true
+			}
+		}
+	}
+
+	return name, false
+}
+
+// GetActivityName returns a valid activity name from the MS Project name.
+func GetActivityName(name string) string {
+	ans := msProjectActivityRegex.ReplaceAllString(strings.ToUpper(name), "-")
+	return strings.Trim(ans, "-")
+}
+
+// GetTaskName returns a valid task name from the MS Project name.
+func GetTaskName(name string) string {
+	ans := msProjectTaskRegex.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(ans, "-")
+}
+
+func getUniqueName(name string, names map[string]bool) string {
+	ans := name
+	for n := 2; names[ans]; n++ {
+		ans = fmt.Sprintf("%s-%d", name, n)
+	}
+	names[ans] = true
+	return ans
+}
+
+// Return the outline of the tasks. The project summary task (UID 0)
+// and the null tasks are skipped.
+func getMsProjectOutline(p *msproject.Project) []*msProjectNode {
+	roots := []*msProjectNode{}
+	stack := []*msProjectNode{}
+
+	for idx := range p.Tasks {
+		t := &p.Tasks[idx]
+		if t.UID == 0 || t.OutlineLevel == 0 || t.IsNull == 1 {
+			continue
+		}
+
+		node := &msProjectNode{Task: t, Children: []*msProjectNode{}}
+		for len(stack) > 0 && stack[len(stack)-1].Task.OutlineLevel >= t.OutlineLevel {
+			stack = stack[0 : len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, node)
+	}
+
+	return roots
+}
+
+// GetActivities converts the tasks of the project to activities.
+func (i *TmMsProjectImporter) GetActivities(p *msproject.Project) ([]specs.Activity, error) {
+	if i.Config.GetWork().WorkHours <= 0 {
+		return nil, errors.New("Invalid work hours")
+	}
+
+	ans := []specs.Activity{}
+	roots := getMsProjectOutline(p)
+	uids := make(map[int]string, 0)
+
+	activityNodes := []*msProjectNode{}
+	if i.ActivityLevel <= 0 {
+		name := i.ActivityName
+		if name == "" {
+			name = GetActivityName(p.Name)
+		}
+		if name == "" {
+			return nil, errors.New("Missing activity name")
+		}
+		activityNodes = append(activityNodes, &msProjectNode{
+			Task:     &msproject.Task{Name: p.Title},
+			Name:     name,
+			Children: roots,
+		})
+	} else {
+		var walk func(nodes []*msProjectNode, level int)
+		walk = func(nodes []*msProjectNode, level int) {
+			for _, n := range nodes {
+				if level == i.ActivityLevel {
+					activityNodes = append(activityNodes, n)
+				} else if len(n.Children) > 0 {
+					walk(n.Children, level+1)
+				} else {
+					i.Logger.Warning(fmt.Sprintf(
+						"Task %s above the activity level ignored.", n.Task.Name))
+					i.Report.AddIgnored(REPORT_TASK, n.Task.Name, 0)
+				}
+			}
+		}
+		walk(roots, 1)
+	}
+
+	// Assign the names of the activities and the tasks before the
+	// conversion to resolve the dependencies.
+	activityNames := make(map[string]bool, 0)
+	var setNames func(nodes []*msProjectNode, prefix string)
+	setNames = func(nodes []*msProjectNode, prefix string) {
+		names := make(map[string]bool, 0)
+		for _, n := range nodes {
+			name := GetTaskName(n.Task.Name)
+			if name == "" {
+				name = fmt.Sprintf("task%d", n.Task.ID)
+			}
+			n.Name = getUniqueName(name, names)
+			n.FullName = prefix + "." + n.Name
+			uids[n.Task.UID] = n.FullName
+			setNames(n.Children, n.FullName)
+		}
+	}
+
+	for _, a := range activityNodes {
+		if a.Name == "" {
+			a.Name = GetActivityName(a.Task.Name)
+			if a.Name == "" {
+				a.Name = fmt.Sprintf("ACTIVITY%d", a.Task.ID)
+			}
+		}
+		a.Name = getUniqueName(a.Name, activityNames)
+		setNames(a.Children, a.Name)
+	}
+
+	for _, a := range activityNodes {
+		if len(a.Children) == 0 {
+			i.Logger.Warning(fmt.Sprintf("Activity %s without tasks ignored.", a.Name))
+			continue
+		}
+
+		descr := a.Task.Name
+		if descr == "" {
+			descr = a.Name
+		}
+		activity := specs.NewActivity(a.Name, descr)
+		activity.Note = a.Task.Notes
+
+		for _, n := range a.Children {
+			t, err := i.getTask(p, n, uids)
+			if err != nil {
+				return nil, err
+			}
+			activity.AddTask(t)
+		}
+
+		ans = append(ans, *activity)
+	}
+
+	return ans, nil
+}
+
+func (i *TmMsProjectImporter) getTask(p *msproject.Project, n *msProjectNode, uids map[int]string) (*specs.Task, error) {
+	minutesPerDay := p.MinutesPerDay
+	if minutesPerDay == 0 {
+		minutesPerDay = i.Config.GetWork().WorkHours * 60
+	}
+
+	ans := &specs.Task{
+		Name:              n.Name,
+		Description:       n.Task.Name,
+		Note:              n.Task.Notes,
+		Completed:         n.Task.PercentComplete >= 100,
+		AllocatedResource: []string{},
+		Tasks:             []specs.Task{},
+		Depends:           []string{},
+	}
+
+	for _, l := range n.Task.PredecessorLink {
+		if d, ok := uids[l.PredecessorUID]; ok {
+			ans.Depends = append(ans.Depends, d)
+		} else {
+			i.Logger.Warning(fmt.Sprintf("Predecessor %d of the task %s not available ignored.",
+				l.PredecessorUID, n.FullName))
+		}
+	}
+
+	users := make(map[string]bool, 0)
+	for _, a := range p.GetTaskAssignments(n.Task.UID) {
+		r := p.GetResourceByUID(a.ResourceUID)
+		if r == nil || r.Name == "" {
+			continue
+		}
+		user, ok := i.GetMappedUser(r.Name)
+		if !ok {
+			secs, _ := msproject.ParseDuration(a.Work, minutesPerDay)
+			i.Report.AddUnmapped(REPORT_USER, r.Name, secs)
+		}
+		if !users[user] {
+			users[user] = true
+			ans.AllocatedResource = append(ans.AllocatedResource, user)
+		}
+	}
+
+	if n.Task.Milestone == 1 {
+		if len(n.Task.Start) >= 10 {
+			ans.Milestone = n.Task.Start[0:10]
+		}
+		return ans, nil
+	}
+
+	if len(n.Children) == 0 && n.Task.Summary == 0 {
+		secs, err := msproject.ParseDuration(n.Task.Work, minutesPerDay)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid work %s of the task %s: %s",
+				n.Task.Work, n.FullName, err.Error()))
+		}
+		if secs == 0 {
+			secs, err = msproject.ParseDuration(n.Task.Duration, minutesPerDay)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("Invalid duration %s of the task %s: %s",
+					n.Task.Duration, n.FullName, err.Error()))
+			}
+		}
+		if secs > 0 {
+			effort, err := tmtime.Seconds2CanonicalDuration(secs, i.Config.GetWork().WorkHours, true)
+			if err != nil {
+				return nil, err
+			}
+			ans.Effort = effort
+		}
+	}
+
+	for _, c := range n.Children {
+		t, err := i.getTask(p, c, uids)
+		if err != nil {
+			return nil, err
+		}
+		ans.Tasks = append(ans.Tasks, *t)
+	}
+
+	return ans, nil
+}
+
+// GetActivityFile returns the path of the file of the activity under dir.
+func GetActivityFile(a *specs.Activity, dir string) string {
+	return filepath.Join(dir, strings.ToLower(a.Name)+".yml")
+}
+
+// WriteActivity writes the activity in the canonical format under
+// the directory and returns the path of the file.
+func (i *TmMsProjectImporter) WriteActivity(a *specs.Activity, dir string, force bool) (string, error) {
+	file := GetActivityFile(a, dir)
+	if _, err := os.Stat(file); err == nil && !force {
+		return "", errors.New("File " + file + " already present")
+	}
+
+	data, err := i.MarshalActivity(a)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return "", err
+	}
+
+	i.Logger.Info(fmt.Sprintf(">>> [activity] Created file %s :check_mark:", file))
+
+	return file, nil
+}
+
+// MarshalActivity returns the YAML of the activity in the canonical format.
+func (i *TmMsProjectImporter) MarshalActivity(a *specs.Activity) ([]byte, error) {
+	data, err := yaml.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return formatter.NewTmFormatter(i.Config).Format(data, formatter.FILE_ACTIVITY)
+}
+
+// MapperSkeleton returns the mapper with the resources without mapping.
+// The names of the users must be filled.
+func (i *TmMsProjectImporter) MapperSkeleton() ([]byte, error) {
+	mapper := &TmMsProjectMapper{
+		Resources: []TmMsProjectResource{},
+	}
+
+	for _, u := range i.Report.GetUnmapped(REPORT_USER) {
+		mapper.Resources = append(mapper.Resources, TmMsProjectResource{MsProjectName: u})
+	}
+
+	return yaml.Marshal(mapper)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/geaaru/time-master/pkg/importer"
+	msproject "github.com/geaaru/time-master/pkg/msproject"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MS Project Importer Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Project xmlns="http://schemas.microsoft.com/project">
+  <Name>Portal</Name>
+  <MinutesPerDay>480</MinutesPerDay>
+  <Tasks>
+    <Task><UID>0</UID><ID>0</ID><Name>Portal</Name><OutlineLevel>0</OutlineLevel><Summary>1</Summary></Task>
+    <Task><UID>1</UID><ID>1</ID><Name>Phase 1</Name><OutlineLevel>1</OutlineLevel><Summary>1</Summary></Task>
+    <Task><UID>2</UID><ID>2</ID><Name>Analysis</Name><OutlineLevel>2</OutlineLevel>
+      <Duration>PT16H0M0S</Duration><PercentComplete>100</PercentComplete><Notes>Kick-off</Notes></Task>
+    <Task><UID>3</UID><ID>3</ID><Name>Development</Name><OutlineLevel>2</OutlineLevel>
+      <Work>PT24H0M0S</Work>
+      <PredecessorLink><PredecessorUID>2</PredecessorUID><Type>1</Type></PredecessorLink></Task>
+    <Task><UID>4</UID><ID>4</ID><Name>Development</Name><OutlineLevel>2</OutlineLevel>
+      <Work>PT4H0M0S</Work></Task>
+    <Task><UID>5</UID><ID>5</ID><Name>Phase 2</Name><OutlineLevel>1</OutlineLevel><Summary>1</Summary></Task>
+    <Task><UID>6</UID><ID>6</ID><Name>Go live!</Name><OutlineLevel>2</OutlineLevel>
+      <Start>2026-10-01T08:00:00</Start><Milestone>1</Milestone>
+      <PredecessorLink><PredecessorUID>3</PredecessorUID><Type>1</Type></PredecessorLink></Task>
+  </Tasks>
+  <Resources>
+    <Resource><UID>1</UID><ID>1</ID><Name>Daniele Rondina</Name></Resource>
+    <Resource><UID>2</UID><ID>2</ID><Name>Mario Rossi</Name></Resource>
+    <Resource><UID>3</UID><ID>3</ID><Name>Luigi</Name></Resource>
+  </Resources>
+  <Assignments>
+    <Assignment><UID>1</UID><TaskUID>2</TaskUID><ResourceUID>1</ResourceUID><Work>PT16H0M0S</Work></Assignment>
+    <Assignment><UID>2</UID><TaskUID>3</TaskUID><ResourceUID>2</ResourceUID><Work>PT12H0M0S</Work></Assignment>
+    <Assignment><UID>3</UID><TaskUID>3</TaskUID><ResourceUID>3</ResourceUID><Work>PT12H0M0S</Work></Assignment>
+  </Assignments>
+</Project>`)
+
+	resources := []specs.Resource{{User: "geaaru", Name: "Daniele Rondina"}}
+
+	Context("Names", func() {
+
+		It("Activity and task", func() {
+			Expect(GetActivityName("Phase 1: design")).To(Equal("PHASE-1-DESIGN"))
+			Expect(GetTaskName("Go live!")).To(Equal("go-live"))
+			Expect(GetTaskName("Test_env.v2")).To(Equal("test_env-v2"))
+		})
+	})
+
+	Context("Activities", func() {
+
+		It("Single activity", func() {
+			p, err := msproject.Parse(data)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmMsProjectImporter(config, resources)
+			err = imp.ImportMapper(&TmMsProjectMapper{
+				Resources: []TmMsProjectResource{{MsProjectName: "Luigi", Name: "luigi"}},
+			})
+			Expect(err).Should(BeNil())
+
+			activities, err := imp.GetActivities(p)
+			Expect(err).Should(BeNil())
+			Expect(len(activities)).To(Equal(1))
+
+			a := activities[0]
+			Expect(a.Name).To(Equal("PORTAL"))
+			Expect(len(a.Tasks)).To(Equal(2))
+			Expect(a.Tasks[0].Name).To(Equal("phase-1"))
+			Expect(a.Tasks[0].Effort).To(Equal(""))
+
+			tasks := a.Tasks[0].Tasks
+			Expect(len(tasks)).To(Equal(3))
+			Expect(tasks[0].Name).To(Equal("analysis"))
+			Expect(tasks[0].Effort).To(Equal("2d"))
+			Expect(tasks[0].Completed).To(Equal(true))
+			Expect(tasks[0].Note).To(Equal("Kick-off"))
+			Expect(tasks[0].AllocatedResource).To(Equal([]string{"geaaru"}))
+
+			Expect(tasks[1].Name).To(Equal("development"))
+			Expect(tasks[1].Effort).To(Equal("3d"))
+			Expect(tasks[1].Depends).To(Equal([]string{"PORTAL.phase-1.analysis"}))
+			Expect(tasks[1].AllocatedResource).To(Equal([]string{"Mario Rossi", "luigi"}))
+			Expect(tasks[2].Name).To(Equal("development-2"))
+			Expect(tasks[2].Effort).To(Equal("4h"))
+
+			golive := a.Tasks[1].Tasks[0]
+			Expect(golive.Name).To(Equal("go-live"))
+			Expect(golive.Description).To(Equal("Go live!"))
+			Expect(golive.Milestone).To(Equal("2026-10-01"))
+			Expect(golive.Depends).To(Equal([]string{"PORTAL.phase-1.development"}))
+
+			Expect(imp.GetReport().GetUnmapped(REPORT_USER)).To(Equal([]string{"Mario Rossi"}))
+
+			skeleton, err := imp.MapperSkeleton()
+			Expect(err).Should(BeNil())
+			mapper, err := TmMsProjectMapperFromYaml(skeleton)
+			Expect(err).Should(BeNil())
+			Expect(mapper.Resources).To(Equal([]TmMsProjectResource{{MsProjectName: "Mario Rossi"}}))
+		})
+
+		It("Activities from level", func() {
+			p, err := msproject.Parse(data)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmMsProjectImporter(config, resources)
+			imp.ActivityLevel = 1
+
+			activities, err := imp.GetActivities(p)
+			Expect(err).Should(BeNil())
+			Expect(len(activities)).To(Equal(2))
+			Expect(activities[0].Name).To(Equal("PHASE-1"))
+			Expect(activities[0].Description).To(Equal("Phase 1"))
+			Expect(len(activities[0].Tasks)).To(Equal(3))
+			Expect(activities[1].Name).To(Equal("PHASE-2"))
+			Expect(activities[1].Tasks[0].Depends).To(Equal([]string{"PHASE-1.development"}))
+		})
+
+		It("Write activity", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-msproject")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			p, err := msproject.Parse(data)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmMsProjectImporter(config, resources)
+			imp.ActivityName = "PLAN"
+			activities, err := imp.GetActivities(p)
+			Expect(err).Should(BeNil())
+
+			file, err := imp.WriteActivity(&activities[0], tmpDir, false)
+			Expect(err).Should(BeNil())
+			Expect(file).To(Equal(filepath.Join(tmpDir, "plan.yml")))
+
+			content, err := ioutil.ReadFile(file)
+			Expect(err).Should(BeNil())
+			a, err := specs.ActivityFromYaml(content, file)
+			Expect(err).Should(BeNil())
+			Expect(a.Name).To(Equal("PLAN"))
+			Expect(a.Tasks[0].Tasks[1].Effort).To(Equal("3d"))
+			Expect(string(content)).NotTo(ContainSubstring("null"))
+
+			_, err = imp.WriteActivity(&activities[0], tmpDir, false)
+			Expect(err).ShouldNot(BeNil())
+			_, err = imp.WriteActivity(&activities[0], tmpDir, true)
+			Expect(err).Should(BeNil())
+		})
+	})
+})
@@ -0,0 +1,122 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"fmt"
+	"sort"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+)
+
+const (
+	REPORT_USER     = "user"
+	REPORT_ISSUE    = "issue"
+	REPORT_ACTIVITY = "activity"
+	REPORT_LABEL    = "label"
+	REPORT_TASK     = "task"
+)
+
+// TmImportReportEntry contains the number of entries and the time
+// of a value without mapping or ignored.
+type TmImportReportEntry struct {
+	Kind    string
+	Value   string
+	Entries int
+	Seconds int64
+}
+
+// TmImportReport collects the values of the imported data without
+// a mapping and the ignored values.
+type TmImportReport struct {
+	Unmapped []*TmImportReportEntry
+	Ignored  []*TmImportReportEntry
+
+	unmappedMap map[string]*TmImportReportEntry
+	ignoredMap  map[string]*TmImportReportEntry
+}
+
+func NewTmImportReport() *TmImportReport {
+	return &TmImportReport{
+		Unmapped:    []*TmImportReportEntry{},
+		Ignored:     []*TmImportReportEntry{},
+		unmappedMap: make(map[string]*TmImportReportEntry, 0),
+		ignoredMap:  make(map[string]*TmImportReportEntry, 0),
+	}
+}
+
+func addReportEntry(list *[]*TmImportReportEntry, m map[string]*TmImportReportEntry,
+	kind, value string, secs int64) {
+	key := kind + "/" + value
+	e, ok := m[key]
+	if !ok {
+		e = &TmImportReportEntry{
+			Kind:  kind,
+			Value: value,
+		}
+		m[key] = e
+		*list = append(*list, e)
+	}
+	e.Entries++
+	e.Seconds += secs
+}
+
+func (r *TmImportReport) AddUnmapped(kind, value string, secs int64) {
+	addReportEntry(&r.Unmapped, r.unmappedMap, kind, value, secs)
+}
+
+func (r *TmImportReport) AddIgnored(kind, value string, secs int64) {
+	addReportEntry(&r.Ignored, r.ignoredMap, kind, value, secs)
+}
+
+func (r *TmImportReport) IsEmpty() bool {
+	return len(r.Unmapped) == 0 && len(r.Ignored) == 0
+}
+
+// GetUnmapped returns the sorted values without mapping of a kind.
+func (r *TmImportReport) GetUnmapped(kind string) []string {
+	ans := []string{}
+	for _, e := range r.Unmapped {
+		if e.Kind == kind {
+			ans = append(ans, e.Value)
+		}
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+func (e *TmImportReportEntry) GetHours() string {
+	return fmt.Sprintf("%.2f", float64(e.Seconds)/3600)
+}
+
+// Return the seconds of the timesheet or 0 if the duration is not valid.
+func (i *DefaultImporter) getTimesheetSeconds(rt *specs.ResourceTimesheet) int64 {
+	secs, err := tmtime.ParseDuration(rt.Duration, i.Config.GetWork().WorkHours)
+	if err != nil {
+		i.Logger.Debug(fmt.Sprintf("Invalid duration %s: %s", rt.Duration, err.Error()))
+		return 0
+	}
+	return secs
+}
+
+func (i *DefaultImporter) GetReport() *TmImportReport {
+	return i.Report
+}
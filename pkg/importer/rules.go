@@ -0,0 +1,121 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TmMappingRule maps a field of the imported entries to a task
+// through a regular expression. The task could contain references to
+// the capture groups of the regex ($1, ${name}).
+type TmMappingRule struct {
+	Field string `json:"field,omitempty" yaml:"field,omitempty"`
+	Match string `json:"match" yaml:"match"`
+	Task  string `json:"task" yaml:"task"`
+
+	regex *regexp.Regexp
+}
+
+// TmRuleEngine applies the rules in order. The first rule
+// that matches the value of the field defines the task.
+type TmRuleEngine struct {
+	Rules []TmMappingRule
+}
+
+func NewTmRuleEngine() *TmRuleEngine {
+	return &TmRuleEngine{
+		Rules: []TmMappingRule{},
+	}
+}
+
+// AddRules compiles and adds the rules. The rules without field use the
+// default field. If fields are passed the field of the rules is validated.
+func (e *TmRuleEngine) AddRules(rules []TmMappingRule, defaultField string, fields ...string) error {
+	for _, r := range rules {
+		if r.Field == "" {
+			r.Field = defaultField
+		}
+
+		if len(fields) > 0 {
+			valid := false
+			for _, f := range fields {
+				if f == r.Field {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return errors.New(fmt.Sprintf("Invalid field %s for the rule %s. Supported fields: %s",
+					r.Field, r.Match, strings.Join(fields, ", ")))
+			}
+		}
+
+		regex, err := regexp.Compile(r.Match)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid regex %s: %s", r.Match, err.Error()))
+		}
+		r.regex = regex
+
+		e.Rules = append(e.Rules, r)
+	}
+
+	return nil
+}
+
+// GetTask returns the task of the first rule matched. The fields
+// map contains the values of the entry to check.
+func (e *TmRuleEngine) GetTask(fields map[string]string) (string, bool) {
+	values := make(map[string][]string, len(fields))
+	for k, v := range fields {
+		values[k] = []string{v}
+	}
+	return e.GetTaskFromValues(values)
+}
+
+// GetTaskFromValues is like GetTask but a field could have multiple
+// values (for example the tags of an entry). A rule matches if one of
+// the values of the field matches.
+func (e *TmRuleEngine) GetTaskFromValues(fields map[string][]string) (string, bool) {
+	for _, r := range e.Rules {
+		values, ok := fields[r.Field]
+		if !ok {
+			continue
+		}
+
+		for _, value := range values {
+			match := r.regex.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+
+			return string(r.regex.ExpandString([]byte{}, r.Task, value, match)), true
+		}
+	}
+
+	return "", false
+}
+
+func (e *TmRuleEngine) HasRules() bool {
+	return len(e.Rules) > 0
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	"github.com/geaaru/time-master/pkg/tools"
+)
+
+const (
+	TOGGL_SOURCE = "toggl"
+)
+
+// TmTogglImporter imports the detailed report of Toggl Track
+// exported in CSV or JSON format.
+type TmTogglImporter struct {
+	*TmTrackingImporter
+}
+
+type togglReport struct {
+	Data []togglEntry `json:"data"`
+}
+
+type togglEntry struct {
+	Id          int64    `json:"id"`
+	User        string   `json:"user"`
+	Client      string   `json:"client"`
+	Project     string   `json:"project"`
+	Task        string   `json:"task"`
+	Description string   `json:"description"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	Tags        []string `json:"tags"`
+}
+
+func NewTmTogglImporter(config *specs.TimeMasterConfig, tmDir, filePrefix string, opts ImportOpts) *TmTogglImporter {
+	return &TmTogglImporter{
+		TmTrackingImporter: NewTmTrackingImporter(config, tmDir, filePrefix, TOGGL_SOURCE, opts),
+	}
+}
+
+func (i *TmTogglImporter) LoadTimesheets(file string) error {
+	if !tools.Exists(file) {
+		return errors.New("File " + file + " not present")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var entries []TmTrackingEntry
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		entries, err = i.parseJson(data)
+	} else {
+		entries, err = i.parseCsv(data)
+	}
+	if err != nil {
+		return err
+	}
+
+	return i.ConvertEntries(entries)
+}
+
+func (i *TmTogglImporter) parseJson(data []byte) ([]TmTrackingEntry, error) {
+	ans := []TmTrackingEntry{}
+	togglEntries := []togglEntry{}
+
+	// The report contains the entries in the data field.
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		report := togglReport{}
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, err
+		}
+		togglEntries = report.Data
+	} else if err := json.Unmarshal(data, &togglEntries); err != nil {
+		return nil, err
+	}
+
+	for _, e := range togglEntries {
+		start, err := time.Parse(time.RFC3339, e.Start)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid start of the entry %d: %s",
+				e.Id, err.Error()))
+		}
+		end, err := time.Parse(time.RFC3339, e.End)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid end of the entry %d: %s",
+				e.Id, err.Error()))
+		}
+
+		// The entries are split at the local midnight.
+		ans = append(ans, TmTrackingEntry{
+			Id:          strconv.FormatInt(e.Id, 10),
+			User:        e.User,
+			Client:      e.Client,
+			Project:     e.Project,
+			Task:        e.Task,
+			Description: e.Description,
+			Tags:        e.Tags,
+			Start:       start.In(time.Local),
+			End:         end.In(time.Local),
+		})
+	}
+
+	return ans, nil
+}
+
+func (i *TmTogglImporter) parseCsv(data []byte) ([]TmTrackingEntry, error) {
+	ans := []TmTrackingEntry{}
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	var indexes map[string]int
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if indexes == nil {
+			indexes = getCsvHeaderIndexes(row)
+			for _, c := range []string{"user", "start date", "start time", "end date", "end time"} {
+				if _, ok := indexes[c]; !ok {
+					return nil, errors.New("Missing the column " + c + " on the Toggl export")
+				}
+			}
+			continue
+		}
+
+		start, err := i.Mapper.ParseDateTime(
+			getCsvValue(row, indexes, "Start date"), getCsvValue(row, indexes, "Start time"),
+			"2006-01-02", "15:04:05")
+		if err != nil {
+			return nil, err
+		}
+		end, err := i.Mapper.ParseDateTime(
+			getCsvValue(row, indexes, "End date"), getCsvValue(row, indexes, "End time"),
+			"2006-01-02", "15:04:05")
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, TmTrackingEntry{
+			User:        getCsvValue(row, indexes, "User"),
+			Client:      getCsvValue(row, indexes, "Client"),
+			Project:     getCsvValue(row, indexes, "Project"),
+			Task:        getCsvValue(row, indexes, "Task"),
+			Description: getCsvValue(row, indexes, "Description"),
+			Tags:        splitCsvTags(getCsvValue(row, indexes, "Tags")),
+			Start:       start,
+			End:         end,
+		})
+	}
+
+	return ans, nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/geaaru/time-master/pkg/importer"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Toggl Importer Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "tm-toggl")
+		Expect(err).Should(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	mapper := &TmTrackingMapper{
+		Resources: []TmTrackingResource{{Source: "Mario Rossi", Name: "mrossi"}},
+		Rules: []TmMappingRule{
+			{Field: "tag", Match: `^TCK-(\d+)$`, Task: "ACT.ticket$1"},
+			{Match: `^Website$`, Task: "ACT.website"},
+		},
+		Ignored: []string{"^Personal$"},
+	}
+
+	Context("CSV export", func() {
+
+		It("Split entries over midnight", func() {
+			file := filepath.Join(tmpDir, "toggl.csv")
+			err := ioutil.WriteFile(file, []byte(
+				"User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\n"+
+					"Mario Rossi,m@x.it,ACME,Website,,Deploy,Yes,2026-09-01,22:30:00,2026-09-02,01:00:00,02:30:00,\n"+
+					"Mario Rossi,m@x.it,ACME,Support,,Fix,Yes,2026-09-02,09:00:00,2026-09-02,10:15:00,01:15:00,\"billable, TCK-7\"\n"+
+					"Mario Rossi,m@x.it,,Personal,,Gym,No,2026-09-02,12:00:00,2026-09-02,13:00:00,01:00:00,\n"),
+				0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmTogglImporter(config, tmpDir, "", ImportOpts{SplitResource: true})
+			Expect(imp.ImportMapper(mapper)).Should(BeNil())
+			Expect(imp.LoadTimesheets(file)).Should(BeNil())
+
+			agendas := *imp.GetTimesheets()
+			Expect(len(agendas)).To(Equal(1))
+			Expect(agendas[0].Name).To(Equal("mrossi"))
+
+			ts := agendas[0].Timesheets
+			Expect(len(ts)).To(Equal(3))
+			Expect([]string{ts[0].Period.StartPeriod, ts[0].Task, ts[0].Duration}).To(
+				Equal([]string{"2026-09-01", "ACT.website", "1h30m"}))
+			Expect([]string{ts[1].Period.StartPeriod, ts[1].Task, ts[1].Duration}).To(
+				Equal([]string{"2026-09-02", "ACT.website", "1h"}))
+			Expect([]string{ts[2].Period.StartPeriod, ts[2].Task, ts[2].Duration}).To(
+				Equal([]string{"2026-09-02", "ACT.ticket7", "1h15m"}))
+
+			// The parts of the splitted entry have different ids.
+			Expect(ts[0].Source).To(Equal(TOGGL_SOURCE))
+			Expect(ts[0].ExternalId).ToNot(Equal(ts[1].ExternalId))
+
+			Expect(len(imp.GetReport().Ignored)).To(Equal(1))
+			Expect(imp.GetReport().Ignored[0].Seconds).To(Equal(int64(3600)))
+		})
+
+		It("External ids without the description", func() {
+			getId := func(description string) string {
+				file := filepath.Join(tmpDir, "toggl.csv")
+				err := ioutil.WriteFile(file, []byte(
+					"User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\n"+
+						"Mario Rossi,m@x.it,ACME,Website,,"+description+",Yes,2026-09-02,09:00:00,2026-09-02,10:00:00,01:00:00,\n"),
+					0644)
+				Expect(err).Should(BeNil())
+
+				imp := NewTmTogglImporter(config, tmpDir, "", ImportOpts{})
+				Expect(imp.ImportMapper(mapper)).Should(BeNil())
+				Expect(imp.LoadTimesheets(file)).Should(BeNil())
+				return (*imp.GetTimesheets())[0].Timesheets[0].ExternalId
+			}
+
+			Expect(getId("Deploy")).To(Equal(getId("Deploy of the release")))
+		})
+	})
+
+	Context("JSON export", func() {
+
+		It("Detailed report", func() {
+			file := filepath.Join(tmpDir, "toggl.json")
+			err := ioutil.WriteFile(file, []byte(`{"data":[
+				{"id":42,"user":"Luigi","project":"Website","description":"Meeting",
+				 "start":"2026-09-03T09:00:00+02:00","end":"2026-09-03T09:45:00+02:00","tags":[]}]}`),
+				0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmTogglImporter(config, tmpDir, "", ImportOpts{})
+			Expect(imp.ImportMapper(mapper)).Should(BeNil())
+			Expect(imp.LoadTimesheets(file)).Should(BeNil())
+
+			rt := specs.NewResourceTimesheet("Luigi", "2026-09-03", "ACT.website", "45m")
+			rt.Note = "Meeting"
+			rt.Source = TOGGL_SOURCE
+			rt.ExternalId = "42"
+
+			Expect((*imp.GetTimesheets())[0].Timesheets).To(Equal([]specs.ResourceTimesheet{*rt}))
+			Expect(imp.GetReport().GetUnmapped(REPORT_USER)).To(Equal([]string{"Luigi"}))
+		})
+
+		It("UTC entries over the local midnight", func() {
+			local := time.Local
+			time.Local = time.FixedZone("CEST", 2*3600)
+			defer func() { time.Local = local }()
+
+			file := filepath.Join(tmpDir, "toggl.json")
+			err := ioutil.WriteFile(file, []byte(`[
+				{"id":43,"user":"Mario Rossi","project":"Website","description":"Deploy",
+				 "start":"2026-09-03T21:30:00Z","end":"2026-09-03T23:00:00Z","tags":[]}]`),
+				0644)
+			Expect(err).Should(BeNil())
+
+			imp := NewTmTogglImporter(config, tmpDir, "", ImportOpts{})
+			Expect(imp.ImportMapper(mapper)).Should(BeNil())
+			Expect(imp.LoadTimesheets(file)).Should(BeNil())
+
+			ts := (*imp.GetTimesheets())[0].Timesheets
+			Expect(len(ts)).To(Equal(2))
+			Expect([]string{ts[0].Period.StartPeriod, ts[0].Duration}).To(
+				Equal([]string{"2026-09-03", "30m"}))
+			Expect([]string{ts[1].Period.StartPeriod, ts[1].Duration}).To(
+				Equal([]string{"2026-09-04", "1h"}))
+		})
+	})
+})
@@ -0,0 +1,314 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	TRACKING_FIELD_USER        = "user"
+	TRACKING_FIELD_PROJECT     = "project"
+	TRACKING_FIELD_CLIENT      = "client"
+	TRACKING_FIELD_TASK        = "task"
+	TRACKING_FIELD_DESCRIPTION = "description"
+	TRACKING_FIELD_TAG         = "tag"
+
+	REPORT_PROJECT = "project"
+)
+
+// TmTrackingEntry is a time entry of a time tracking tool
+// (Toggl, Clockify).
+type TmTrackingEntry struct {
+	Id          string
+	User        string
+	Client      string
+	Project     string
+	Task        string
+	Description string
+	Tags        []string
+	Start       time.Time
+	End         time.Time
+}
+
+// TmTrackingMapper maps the entries of the time tracking tools
+// to the timesheets.
+type TmTrackingMapper struct {
+	Resources []TmTrackingResource `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// Rules applied in order. Supported fields: project (default),
+	// user, client, task, description, tag.
+	Rules []TmMappingRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// Regexes of the projects to ignore.
+	Ignored []string `json:"ignored,omitempty" yaml:"ignored,omitempty"`
+
+	// Go layout of the date and time columns of the CSV export.
+	DateFormat string `json:"date_format,omitempty" yaml:"date_format,omitempty"`
+	TimeFormat string `json:"time_format,omitempty" yaml:"time_format,omitempty"`
+}
+
+type TmTrackingResource struct {
+	Source string `json:"source" yaml:"source"`
+	Name   string `json:"name" yaml:"name"`
+}
+
+// TmTrackingImporter contains the logic shared by the importers of
+// the time tracking tools.
+type TmTrackingImporter struct {
+	*DefaultImporter
+	Mapper          *TmTrackingMapper
+	ResourceMapping map[string]string
+	Rules           *TmRuleEngine
+	Source          string
+	ignoredRegexes  []*regexp.Regexp
+}
+
+func TmTrackingMapperFromYaml(data []byte) (*TmTrackingMapper, error) {
+	ans := &TmTrackingMapper{}
+	if err := yaml.Unmarshal(data, ans); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func NewTmTrackingImporter(config *specs.TimeMasterConfig, tmDir, filePrefix, source string, opts ImportOpts) *TmTrackingImporter {
+	return &TmTrackingImporter{
+		DefaultImporter: NewDefaultImporter(config, tmDir, filePrefix, opts),
+		Mapper:          &TmTrackingMapper{},
+		ResourceMapping: make(map[string]string, 0),
+		Rules:           NewTmRuleEngine(),
+		Source:          source,
+		ignoredRegexes:  []*regexp.Regexp{},
+	}
+}
+
+func (i *TmTrackingImporter) ImportMapper(mapper *TmTrackingMapper) error {
+	i.Mapper = mapper
+
+	for _, r := range mapper.Resources {
+		i.ResourceMapping[r.Source] = r.Name
+	}
+
+	for _, ignored := range mapper.Ignored {
+		r, err := regexp.Compile(ignored)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid regex %s: %s", ignored, err.Error()))
+		}
+		i.ignoredRegexes = append(i.ignoredRegexes, r)
+	}
+
+	return i.Rules.AddRules(mapper.Rules, TRACKING_FIELD_PROJECT,
+		TRACKING_FIELD_PROJECT, TRACKING_FIELD_USER, TRACKING_FIELD_CLIENT,
+		TRACKING_FIELD_TASK, TRACKING_FIELD_DESCRIPTION, TRACKING_FIELD_TAG)
+}
+
+func (i *TmTrackingImporter) IsProject2Ignore(project string) bool {
+	for _, r := range i.ignoredRegexes {
+		if r.MatchString(project) {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *TmTrackingImporter) GetMappedUser(user string) (ans string) {
+	if u, ok := i.ResourceMapping[user]; ok {
+		ans = u
+	} else {
+		ans = user
+	}
+	return
+}
+
+// GetMappedTask returns the task of the entry and if a rule is matched.
+// Without rules the task, the project or the description is used.
+func (i *TmTrackingImporter) GetMappedTask(e *TmTrackingEntry) (string, bool) {
+	task, ok := i.Rules.GetTaskFromValues(map[string][]string{
+		TRACKING_FIELD_PROJECT:     []string{e.Project},
+		TRACKING_FIELD_USER:        []string{e.User},
+		TRACKING_FIELD_CLIENT:      []string{e.Client},
+		TRACKING_FIELD_TASK:        []string{e.Task},
+		TRACKING_FIELD_DESCRIPTION: []string{e.Description},
+		TRACKING_FIELD_TAG:         e.Tags,
+	})
+	if ok {
+		return task, true
+	}
+
+	if e.Task != "" {
+		return e.Task, false
+	} else if e.Project != "" {
+		return e.Project, false
+	}
+	return e.Description, false
+}
+
+func (i *TmTrackingImporter) convertEntry2ResourceTimesheets(e *TmTrackingEntry) ([]*specs.ResourceTimesheet, error) {
+	ans := []*specs.ResourceTimesheet{}
+
+	if !e.Start.Before(e.End) {
+		i.Logger.Debug(fmt.Sprintf("Skipping entry %s without duration.", e.Id))
+		return ans, nil
+	}
+
+	if i.IsProject2Ignore(e.Project) {
+		i.Logger.Debug("Ignoring entry of the project " + e.Project)
+		i.Report.AddIgnored(REPORT_PROJECT, e.Project, int64(e.End.Sub(e.Start).Seconds()))
+		return ans, nil
+	}
+
+	task, mapped := i.GetMappedTask(e)
+	secs := int64(e.End.Sub(e.Start).Seconds())
+	if _, ok := i.ResourceMapping[e.User]; !ok {
+		i.Report.AddUnmapped(REPORT_USER, e.User, secs)
+	}
+	if !mapped {
+		i.Report.AddUnmapped(REPORT_PROJECT, task, secs)
+	}
+
+	periods := tmtime.SplitDays(e.Start, e.End)
+	for _, p := range periods {
+		daySecs := int64(p[1].Sub(p[0]).Seconds())
+		if daySecs <= 0 {
+			continue
+		}
+
+		duration, err := tmtime.Seconds2CanonicalDuration(daySecs,
+			i.Config.GetWork().WorkHours, false)
+		if err != nil {
+			return nil, err
+		}
+
+		date := p[0].Format("2006-01-02")
+		rt := specs.NewResourceTimesheet(i.GetMappedUser(e.User), date, task, duration)
+		rt.Note = e.Description
+		rt.Source = i.Source
+		rt.ExternalId = e.Id
+		if len(periods) > 1 {
+			rt.ExternalId = e.Id + "@" + date
+		}
+
+		ans = append(ans, rt)
+	}
+
+	return ans, nil
+}
+
+// ConvertEntries converts the entries to the agendas of the importer.
+func (i *TmTrackingImporter) ConvertEntries(entries []TmTrackingEntry) error {
+	rts := []*specs.ResourceTimesheet{}
+	idGenerator := NewExternalIdGenerator()
+
+	for idx := range entries {
+		e := &entries[idx]
+		if e.Id == "" {
+			// The description is excluded so the id doesn't change when
+			// it is edited on source.
+			e.Id = idGenerator.Get(e.User, e.Start.Format(time.RFC3339),
+				e.Project, e.Task)
+		}
+
+		erts, err := i.convertEntry2ResourceTimesheets(e)
+		if err != nil {
+			return err
+		}
+		rts = append(rts, erts...)
+	}
+
+	i.AddResourceTimesheets(rts)
+
+	return nil
+}
+
+// MapperSkeleton returns the mapper with the users and the projects
+// without mapping.
+func (i *TmTrackingImporter) MapperSkeleton() ([]byte, error) {
+	mapper := *i.Mapper
+	mapper.Resources = append([]TmTrackingResource{}, i.Mapper.Resources...)
+	mapper.Rules = append([]TmMappingRule{}, i.Mapper.Rules...)
+
+	for _, u := range i.Report.GetUnmapped(REPORT_USER) {
+		mapper.Resources = append(mapper.Resources, TmTrackingResource{Source: u})
+	}
+	for _, p := range i.Report.GetUnmapped(REPORT_PROJECT) {
+		mapper.Rules = append(mapper.Rules, TmMappingRule{
+			Field: TRACKING_FIELD_PROJECT,
+			Match: "^" + regexp.QuoteMeta(p) + "$",
+		})
+	}
+
+	return yaml.Marshal(mapper)
+}
+
+// Parse the date and time of the CSV exports.
+func (m *TmTrackingMapper) ParseDateTime(d, t, defaultDate, defaultTime string) (time.Time, error) {
+	dateLayout := m.DateFormat
+	if dateLayout == "" {
+		dateLayout = defaultDate
+	}
+	timeLayout := m.TimeFormat
+	if timeLayout == "" {
+		timeLayout = defaultTime
+	}
+
+	return time.ParseInLocation(dateLayout+" "+timeLayout,
+		strings.TrimSpace(d)+" "+strings.TrimSpace(t), time.Local)
+}
+
+// Return the index of the columns of the CSV header.
+func getCsvHeaderIndexes(header []string) map[string]int {
+	ans := make(map[string]int, len(header))
+	for idx, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if _, ok := ans[h]; !ok {
+			ans[h] = idx
+		}
+	}
+	return ans
+}
+
+// Return the value of the first column available.
+func getCsvValue(row []string, indexes map[string]int, columns ...string) string {
+	for _, c := range columns {
+		if idx, ok := indexes[strings.ToLower(c)]; ok && idx < len(row) {
+			return strings.TrimSpace(row[idx])
+		}
+	}
+	return ""
+}
+
+// Split the tags of the CSV exports.
+func splitCsvTags(tags string) []string {
+	ans := []string{}
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			ans = append(ans, t)
+		}
+	}
+	return ans
+}
@@ -0,0 +1,333 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+)
+
+const (
+	INVOICE_BY_TASK     = "task"
+	INVOICE_BY_RESOURCE = "resource"
+
+	INVOICE_DATE_LAYOUT = "2006-01-02"
+)
+
+type Invoice struct {
+	Number   string `json:"number" yaml:"number"`
+	Year     int    `json:"year" yaml:"year"`
+	Sequence int    `json:"sequence" yaml:"sequence"`
+	Date     string `json:"date" yaml:"date"`
+	Month    string `json:"month" yaml:"month"`
+
+	Client            string `json:"client" yaml:"client"`
+	ClientDescription string `json:"client_description,omitempty" yaml:"client_description,omitempty"`
+	Issuer            string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Currency          string `json:"currency,omitempty" yaml:"currency,omitempty"`
+	GroupBy           string `json:"group_by" yaml:"group_by"`
+
+	Items    []InvoiceItem `json:"items" yaml:"items"`
+	Subtotal float64       `json:"subtotal" yaml:"subtotal"`
+	VatPerc  float64       `json:"vat_perc,omitempty" yaml:"vat_perc,omitempty"`
+	Vat      float64       `json:"vat,omitempty" yaml:"vat,omitempty"`
+	Total    float64       `json:"total" yaml:"total"`
+
+	// Timesheets billed by the invoice.
+	Entries []InvoiceEntry `json:"entries" yaml:"entries"`
+
+	File string `json:"-" yaml:"-"`
+}
+
+// InvoiceItem is a line of the invoice with the time billed for a task
+// or for a resource of an activity.
+type InvoiceItem struct {
+	Activity    string  `json:"activity" yaml:"activity"`
+	Name        string  `json:"name" yaml:"name"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Seconds     int64   `json:"seconds" yaml:"seconds"`
+	Days        float64 `json:"days" yaml:"days"`
+	DailyRate   float64 `json:"daily_rate" yaml:"daily_rate"`
+	Amount      float64 `json:"amount" yaml:"amount"`
+}
+
+// InvoiceEntry contains the time billed for a user on a task in a
+// specific day. Seconds is the worked time and BilledSeconds the time
+// after the rounding.
+type InvoiceEntry struct {
+	Date          string `json:"date" yaml:"date"`
+	User          string `json:"user" yaml:"user"`
+	Task          string `json:"task" yaml:"task"`
+	Seconds       int64  `json:"seconds" yaml:"seconds"`
+	BilledSeconds int64  `json:"billed_seconds" yaml:"billed_seconds"`
+}
+
+// TmInvoiceBuilder collects the timesheets of the time and material
+// activities of a client and creates the invoice of a month.
+type TmInvoiceBuilder struct {
+	Config *specs.TimeMasterConfig
+
+	GroupBy      string
+	Date         string
+	VatPerc      float64
+	Rounding     string
+	RoundingMode string
+
+	// Map user -> name used for the items by resource.
+	Resources map[string]string
+
+	issued map[string]*InvoiceEntry
+}
+
+func NewTmInvoiceBuilder(config *specs.TimeMasterConfig) *TmInvoiceBuilder {
+	return &TmInvoiceBuilder{
+		Config:       config,
+		GroupBy:      INVOICE_BY_TASK,
+		Date:         time.Now().Format(INVOICE_DATE_LAYOUT),
+		VatPerc:      config.GetInvoice().Vat,
+		Rounding:     config.GetInvoice().Rounding,
+		RoundingMode: config.GetInvoice().RoundingMode,
+		Resources:    make(map[string]string, 0),
+		issued:       make(map[string]*InvoiceEntry, 0),
+	}
+}
+
+func (e *InvoiceEntry) GetKey() string {
+	return fmt.Sprintf("%s|%s|%s", e.Date, e.User, e.Task)
+}
+
+// SetIssued registers the entries of the invoices already issued. The
+// time already billed is excluded from the new invoices.
+func (b *TmInvoiceBuilder) SetIssued(invoices []*Invoice) {
+	b.issued = make(map[string]*InvoiceEntry, 0)
+	for _, inv := range invoices {
+		for _, e := range inv.Entries {
+			if val, ok := b.issued[e.GetKey()]; ok {
+				val.Seconds += e.Seconds
+				val.BilledSeconds += e.BilledSeconds
+			} else {
+				entry := e
+				b.issued[e.GetKey()] = &entry
+			}
+		}
+	}
+}
+
+func (b *TmInvoiceBuilder) Build(client *specs.Client, month string, agendas []specs.AgendaTimesheets) (*Invoice, error) {
+	var unit int64
+	var err error
+
+	if _, err = time.Parse("2006-01", month); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid month %s: %s", month, err.Error()))
+	}
+	if b.GroupBy != INVOICE_BY_TASK && b.GroupBy != INVOICE_BY_RESOURCE {
+		return nil, errors.New("Invalid group by " + b.GroupBy)
+	}
+
+	workHours := b.Config.GetWork().WorkHours
+	workDaySec := int64(workHours) * 60 * 60
+	if workDaySec <= 0 {
+		return nil, errors.New("Invalid work hours")
+	}
+
+	if b.Rounding != "" {
+		unit, err = tmtime.ParseDuration(b.Rounding, workHours)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid rounding %s: %s", b.Rounding, err.Error()))
+		}
+	}
+
+	activities := make(map[string]*specs.Activity, 0)
+	for idx := range client.Activities {
+		a := &client.Activities[idx]
+		if a.IsTimeAndMaterial() {
+			activities[a.Name] = a
+		}
+	}
+
+	// Aggregate the worked time of every day, user and task.
+	worked := make(map[string]*InvoiceEntry, 0)
+	// The activity of the entries.
+	workedActivity := make(map[string]string, 0)
+	keys := []string{}
+	for _, agenda := range agendas {
+		for _, rt := range agenda.Timesheets {
+			if rt.Period == nil {
+				continue
+			}
+			if _, ok := activities[rt.ResolveActivityByName()]; !ok {
+				continue
+			}
+
+			m, err := rt.GetMonth(true)
+			if err != nil {
+				return nil, err
+			}
+			if m != month {
+				continue
+			}
+
+			date, _ := rt.GetDate(true)
+			secs, err := rt.GetSeconds(workHours)
+			if err != nil {
+				return nil, err
+			}
+
+			entry := &InvoiceEntry{
+				Date: date,
+				User: rt.User,
+				Task: rt.Task,
+			}
+			if val, ok := worked[entry.GetKey()]; ok {
+				entry = val
+			} else {
+				worked[entry.GetKey()] = entry
+				workedActivity[entry.GetKey()] = rt.ResolveActivityByName()
+				keys = append(keys, entry.GetKey())
+			}
+			entry.Seconds += secs
+		}
+	}
+
+	sort.Strings(keys)
+
+	ans := &Invoice{
+		Date:              b.Date,
+		Month:             month,
+		Client:            client.Name,
+		ClientDescription: client.Description,
+		Issuer:            b.Config.GetInvoice().Issuer,
+		Currency:          b.Config.GetWork().Currency,
+		GroupBy:           b.GroupBy,
+		VatPerc:           b.VatPerc,
+		Items:             []InvoiceItem{},
+		Entries:           []InvoiceEntry{},
+	}
+
+	items := make(map[string]*InvoiceItem, 0)
+	itemKeys := []string{}
+
+	for _, key := range keys {
+		entry := worked[key]
+
+		billed, err := tmtime.RoundSeconds(entry.Seconds, unit, b.RoundingMode)
+		if err != nil {
+			return nil, err
+		}
+
+		// Exclude the time already billed.
+		if prev, ok := b.issued[key]; ok {
+			entry.Seconds -= prev.Seconds
+			billed -= prev.BilledSeconds
+		}
+		if billed <= 0 {
+			continue
+		}
+		entry.BilledSeconds = billed
+		ans.Entries = append(ans.Entries, *entry)
+
+		activity := activities[workedActivity[key]]
+		if activity.GetTimeAndMaterialDailyOffer() <= 0 {
+			return nil, errors.New(fmt.Sprintf(
+				"Activity %s is time and material without daily offer", activity.Name))
+		}
+
+		name := entry.Task
+		if b.GroupBy == INVOICE_BY_RESOURCE {
+			name = entry.User
+		}
+
+		itemKey := activity.Name + "|" + name
+		item, ok := items[itemKey]
+		if !ok {
+			item = &InvoiceItem{
+				Activity:  activity.Name,
+				Name:      name,
+				DailyRate: activity.GetTimeAndMaterialDailyOffer(),
+			}
+			if b.GroupBy == INVOICE_BY_RESOURCE {
+				item.Description = b.Resources[entry.User]
+			} else if t, err := activity.GetTaskByFullName(entry.Task); err == nil {
+				item.Description = t.Description
+			}
+			items[itemKey] = item
+			itemKeys = append(itemKeys, itemKey)
+		}
+		item.Seconds += billed
+	}
+
+	if len(ans.Entries) == 0 {
+		return nil, errors.New(fmt.Sprintf(
+			"No billable timesheets for client %s on month %s", client.Name, month))
+	}
+
+	sort.Strings(itemKeys)
+	for _, k := range itemKeys {
+		item := items[k]
+		item.Days = float64(item.Seconds) / float64(workDaySec)
+		item.Amount = roundAmount(item.DailyRate * item.Days)
+		ans.Items = append(ans.Items, *item)
+		ans.Subtotal += item.Amount
+	}
+
+	ans.Subtotal = roundAmount(ans.Subtotal)
+	ans.Vat = roundAmount(ans.Subtotal * ans.VatPerc / 100)
+	ans.Total = roundAmount(ans.Subtotal + ans.Vat)
+
+	return ans, nil
+}
+
+// SetNumber assigns to the invoice the next number of the year
+// of the invoice date.
+func (i *Invoice) SetNumber(issued []*Invoice, prefix string) error {
+	date, err := time.Parse(INVOICE_DATE_LAYOUT, i.Date)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Invalid invoice date %s: %s", i.Date, err.Error()))
+	}
+
+	i.Year = date.Year()
+	i.Sequence = 1
+	for _, inv := range issued {
+		if inv.Year == i.Year && inv.Sequence >= i.Sequence {
+			i.Sequence = inv.Sequence + 1
+		}
+	}
+	i.Number = fmt.Sprintf("%s%d-%04d", prefix, i.Year, i.Sequence)
+
+	return nil
+}
+
+func (i *Invoice) GetBilledSeconds() int64 {
+	var ans int64 = 0
+	for _, e := range i.Entries {
+		ans += e.BilledSeconds
+	}
+	return ans
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,245 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package invoice_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/geaaru/time-master/pkg/invoice"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Invoice Test", func() {
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+	config.GetWork().Currency = "€"
+
+	client := &specs.Client{
+		Name:        "CLIENT1",
+		Description: "Client 1",
+		Activities: []specs.Activity{
+			{
+				Name:         "ACT1",
+				TimeMaterial: true,
+				TMDailyOffer: 400,
+				Tasks: []specs.Task{
+					{Name: "dev", Description: "Development"},
+					{Name: "test", Description: "Testing"},
+				},
+			},
+			{
+				Name:  "ACT2",
+				Offer: 10000,
+				Tasks: []specs.Task{{Name: "dev"}},
+			},
+		},
+	}
+
+	agendas := []specs.AgendaTimesheets{
+		{
+			Timesheets: []specs.ResourceTimesheet{
+				*specs.NewResourceTimesheet("geaaru", "2026-09-01", "ACT1.dev", "3h50m"),
+				*specs.NewResourceTimesheet("geaaru", "2026-09-01", "ACT1.dev", "2h"),
+				*specs.NewResourceTimesheet("user2", "2026-09-02", "ACT1.test", "4h"),
+				*specs.NewResourceTimesheet("geaaru", "2026-09-02", "ACT1.test", "1h10m"),
+				// Activity not time and material
+				*specs.NewResourceTimesheet("geaaru", "2026-09-03", "ACT2.dev", "8h"),
+				// Other month
+				*specs.NewResourceTimesheet("geaaru", "2026-10-01", "ACT1.dev", "8h"),
+			},
+		},
+	}
+
+	Context("Build", func() {
+
+		It("By task", func() {
+			b := NewTmInvoiceBuilder(config)
+			b.Date = "2026-10-01"
+			b.VatPerc = 22
+
+			inv, err := b.Build(client, "2026-09", agendas)
+			Expect(err).Should(BeNil())
+			Expect(inv.Client).To(Equal("CLIENT1"))
+			Expect(len(inv.Entries)).To(Equal(3))
+			Expect(inv.Entries[0].Seconds).To(Equal(int64(5*3600 + 50*60)))
+			Expect(len(inv.Items)).To(Equal(2))
+
+			Expect(inv.Items[0].Name).To(Equal("ACT1.dev"))
+			Expect(inv.Items[0].Description).To(Equal("Development"))
+			Expect(inv.Items[0].Seconds).To(Equal(int64(5*3600 + 50*60)))
+			Expect(inv.Items[0].Amount).To(Equal(291.67))
+			Expect(inv.Items[1].Name).To(Equal("ACT1.test"))
+			Expect(inv.Items[1].Amount).To(Equal(258.33))
+
+			Expect(inv.Subtotal).To(Equal(550.0))
+			Expect(inv.Vat).To(Equal(121.0))
+			Expect(inv.Total).To(Equal(671.0))
+		})
+
+		It("By resource with rounding", func() {
+			b := NewTmInvoiceBuilder(config)
+			b.GroupBy = INVOICE_BY_RESOURCE
+			b.Rounding = "30m"
+			b.RoundingMode = "up"
+			b.Resources["geaaru"] = "Daniele"
+
+			inv, err := b.Build(client, "2026-09", agendas)
+			Expect(err).Should(BeNil())
+			Expect(len(inv.Items)).To(Equal(2))
+
+			Expect(inv.Items[0].Name).To(Equal("geaaru"))
+			Expect(inv.Items[0].Description).To(Equal("Daniele"))
+			Expect(inv.Items[0].Seconds).To(Equal(int64(7*3600 + 30*60)))
+			Expect(inv.Items[0].Days).To(Equal(0.9375))
+			Expect(inv.Items[0].Amount).To(Equal(375.0))
+			Expect(inv.Items[1].Name).To(Equal("user2"))
+			Expect(inv.Items[1].Amount).To(Equal(200.0))
+			Expect(inv.Vat).To(Equal(0.0))
+		})
+
+		It("Exclude issued time", func() {
+			b := NewTmInvoiceBuilder(config)
+			b.Rounding = "1h"
+
+			issued, err := b.Build(client, "2026-09", agendas[:1])
+			Expect(err).Should(BeNil())
+
+			updated := []specs.AgendaTimesheets{
+				agendas[0],
+				{
+					Timesheets: []specs.ResourceTimesheet{
+						*specs.NewResourceTimesheet("geaaru", "2026-09-02", "ACT1.test", "1h"),
+					},
+				},
+			}
+
+			b.SetIssued([]*Invoice{issued})
+			inv, err := b.Build(client, "2026-09", updated)
+			Expect(err).Should(BeNil())
+			Expect(len(inv.Entries)).To(Equal(1))
+			Expect(inv.Entries[0].Seconds).To(Equal(int64(3600)))
+			Expect(inv.Entries[0].BilledSeconds).To(Equal(int64(3600)))
+
+			b.SetIssued([]*Invoice{issued, inv})
+			_, err = b.Build(client, "2026-09", updated)
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("Errors", func() {
+			b := NewTmInvoiceBuilder(config)
+			_, err := b.Build(client, "2026/09", agendas)
+			Expect(err).ShouldNot(BeNil())
+
+			_, err = b.Build(client, "2026-08", agendas)
+			Expect(err).ShouldNot(BeNil())
+
+			c := &specs.Client{
+				Name:       "CLIENT2",
+				Activities: []specs.Activity{{Name: "ACT1", TimeMaterial: true}},
+			}
+			_, err = b.Build(c, "2026-09", agendas)
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	Context("Numbers and registry", func() {
+
+		It("SetNumber", func() {
+			issued := []*Invoice{
+				{Year: 2025, Sequence: 7},
+				{Year: 2026, Sequence: 1},
+				{Year: 2026, Sequence: 2},
+			}
+
+			inv := &Invoice{Date: "2026-10-01"}
+			Expect(inv.SetNumber(issued, "INV-")).Should(BeNil())
+			Expect(inv.Number).To(Equal("INV-2026-0003"))
+
+			inv = &Invoice{Date: "2027-01-10"}
+			Expect(inv.SetNumber(issued, "")).Should(BeNil())
+			Expect(inv.Number).To(Equal("2027-0001"))
+
+			inv = &Invoice{Date: "10/01/2026"}
+			Expect(inv.SetNumber(issued, "")).ShouldNot(BeNil())
+		})
+
+		It("Write and load", func() {
+			tmpDir, err := ioutil.TempDir("", "tm-invoice")
+			Expect(err).Should(BeNil())
+			defer os.RemoveAll(tmpDir)
+
+			r := NewTmInvoiceRegistry(config)
+			r.Dir = tmpDir
+
+			Expect(r.Lock()).Should(BeNil())
+			defer r.Unlock()
+
+			b := NewTmInvoiceBuilder(config)
+			b.Date = "2026-10-01"
+			inv, err := b.Build(client, "2026-09", agendas)
+			Expect(err).Should(BeNil())
+
+			issued, err := r.Load()
+			Expect(err).Should(BeNil())
+			Expect(len(issued)).To(Equal(0))
+
+			Expect(inv.SetNumber(issued, "")).Should(BeNil())
+			Expect(r.Write(inv)).Should(BeNil())
+			Expect(r.Write(inv)).ShouldNot(BeNil())
+
+			issued, err = r.Load()
+			Expect(err).Should(BeNil())
+			Expect(len(issued)).To(Equal(1))
+			Expect(issued[0].Number).To(Equal("2026-0001"))
+			Expect(issued[0].Total).To(Equal(inv.Total))
+			Expect(issued[0].Entries).To(Equal(inv.Entries))
+		})
+	})
+
+	Context("Render", func() {
+
+		b := NewTmInvoiceBuilder(config)
+		b.Date = "2026-10-01"
+		b.VatPerc = 22
+		inv, _ := b.Build(client, "2026-09", agendas)
+		inv.SetNumber([]*Invoice{}, "INV-")
+
+		It("Markdown", func() {
+			md := inv.Markdown()
+			Expect(md).To(ContainSubstring("# Invoice INV-2026-0001"))
+			Expect(md).To(ContainSubstring("| ACT1 | ACT1.dev | Development | 5h50m | 0.73 | 400.00 € | 291.67 € |"))
+			Expect(md).To(ContainSubstring("**VAT 22%:** 121.00 €"))
+			Expect(md).To(ContainSubstring("**Total:** 671.00 €"))
+		})
+
+		It("Html", func() {
+			data, err := inv.Html()
+			Expect(err).Should(BeNil())
+			Expect(string(data)).To(ContainSubstring("<title>Invoice INV-2026-0001</title>"))
+			Expect(string(data)).To(ContainSubstring("<td>Testing</td>"))
+			Expect(string(data)).To(ContainSubstring("671.00 €"))
+		})
+	})
+})
@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package invoice
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	"github.com/geaaru/time-master/pkg/tools"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TmInvoiceRegistry stores the issued invoices as YAML files of a
+// directory. The lock avoids that concurrent invocations assign the
+// same number.
+type TmInvoiceRegistry struct {
+	Dir string
+
+	lock *tools.FileLock
+}
+
+func NewTmInvoiceRegistry(config *specs.TimeMasterConfig) *TmInvoiceRegistry {
+	return &TmInvoiceRegistry{
+		Dir: config.GetInvoice().Dir,
+	}
+}
+
+func InvoiceFromYaml(data []byte, file string) (*Invoice, error) {
+	ans := &Invoice{}
+	if err := yaml.Unmarshal(data, ans); err != nil {
+		return nil, err
+	}
+	ans.File = file
+
+	return ans, nil
+}
+
+func (r *TmInvoiceRegistry) Lock() error {
+	if r.lock != nil {
+		return errors.New("Invoices registry already locked")
+	}
+
+	err := os.MkdirAll(r.Dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	r.lock, err = tools.LockFile(filepath.Join(r.Dir, ".lock"))
+	return err
+}
+
+func (r *TmInvoiceRegistry) Unlock() error {
+	if r.lock == nil {
+		return nil
+	}
+
+	err := r.lock.Unlock()
+	r.lock = nil
+
+	return err
+}
+
+// Load returns the issued invoices sorted by number.
+func (r *TmInvoiceRegistry) Load() ([]*Invoice, error) {
+	var regexConfs = regexp.MustCompile(`.yml$|.yaml$`)
+	ans := []*Invoice{}
+
+	if !tools.Exists(r.Dir) {
+		return ans, nil
+	}
+
+	files, err := ioutil.ReadDir(r.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !regexConfs.MatchString(file.Name()) {
+			continue
+		}
+
+		f := filepath.Join(r.Dir, file.Name())
+		content, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		inv, err := InvoiceFromYaml(content, f)
+		if err != nil {
+			return nil, errors.New("Error on parse file " + f + ": " + err.Error())
+		}
+
+		ans = append(ans, inv)
+	}
+
+	sort.Slice(ans, func(i, j int) bool {
+		if ans[i].Year != ans[j].Year {
+			return ans[i].Year < ans[j].Year
+		}
+		return ans[i].Sequence < ans[j].Sequence
+	})
+
+	return ans, nil
+}
+
+// Write stores the invoice in the registry. An invoice with the
+// same number is never overwritten.
+func (r *TmInvoiceRegistry) Write(inv *Invoice) error {
+	if inv.Number == "" {
+		return errors.New("Invoice without number")
+	}
+
+	err := os.MkdirAll(r.Dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	name := strings.ReplaceAll(inv.Number, string(os.PathSeparator), "_")
+	f := filepath.Join(r.Dir, name+".yaml")
+	if tools.Exists(f) {
+		return errors.New("Invoice " + inv.Number + " already issued")
+	}
+
+	data, err := yaml.Marshal(inv)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(f, data, 0644)
+	if err != nil {
+		return err
+	}
+	inv.File = f
+
+	return nil
+}
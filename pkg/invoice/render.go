@@ -0,0 +1,155 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package invoice
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	tmtime "github.com/geaaru/time-master/pkg/time"
+)
+
+//go:embed assets/invoice.html
+var assets embed.FS
+
+// Values of an item formatted for the documents.
+type invoiceRow struct {
+	Activity    string
+	Name        string
+	Description string
+	Hours       string
+	Days        string
+	DailyRate   string
+	Amount      string
+}
+
+type invoicePage struct {
+	Invoice    *Invoice
+	NameHeader string
+	Items      []invoiceRow
+	Subtotal   string
+	VatPerc    string
+	Vat        string
+	Total      string
+}
+
+// FormatAmount returns the amount with two decimals and the currency
+// of the invoice.
+func (i *Invoice) FormatAmount(v float64) string {
+	ans := fmt.Sprintf("%.2f", v)
+	if i.Currency != "" {
+		ans += " " + i.Currency
+	}
+	return ans
+}
+
+func (i *Invoice) newPage() *invoicePage {
+	ans := &invoicePage{
+		Invoice:    i,
+		NameHeader: "Task",
+		Items:      []invoiceRow{},
+		Subtotal:   i.FormatAmount(i.Subtotal),
+		Total:      i.FormatAmount(i.Total),
+	}
+	if i.GroupBy == INVOICE_BY_RESOURCE {
+		ans.NameHeader = "Resource"
+	}
+	if i.Vat != 0 {
+		ans.VatPerc = strconv.FormatFloat(i.VatPerc, 'f', -1, 64)
+		ans.Vat = i.FormatAmount(i.Vat)
+	}
+
+	for _, item := range i.Items {
+		hours, _ := tmtime.Seconds2Duration(item.Seconds)
+		ans.Items = append(ans.Items, invoiceRow{
+			Activity:    item.Activity,
+			Name:        item.Name,
+			Description: item.Description,
+			Hours:       hours,
+			Days:        fmt.Sprintf("%.2f", item.Days),
+			DailyRate:   i.FormatAmount(item.DailyRate),
+			Amount:      i.FormatAmount(item.Amount),
+		})
+	}
+
+	return ans
+}
+
+func (i *Invoice) Markdown() string {
+	var b strings.Builder
+
+	page := i.newPage()
+
+	b.WriteString(fmt.Sprintf("# Invoice %s\n\n", i.Number))
+	if i.Issuer != "" {
+		for _, line := range strings.Split(strings.TrimSpace(i.Issuer), "\n") {
+			b.WriteString(line + "  \n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(fmt.Sprintf("**Date:** %s  \n", i.Date))
+	b.WriteString(fmt.Sprintf("**Period:** %s  \n", i.Month))
+	b.WriteString(fmt.Sprintf("**Client:** %s  \n", i.Client))
+	if i.ClientDescription != "" {
+		b.WriteString(i.ClientDescription + "  \n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(fmt.Sprintf("| Activity | %s | Description | Hours | Days | Daily Rate | Amount |\n",
+		page.NameHeader))
+	b.WriteString("|---|---|---|---:|---:|---:|---:|\n")
+	for _, row := range page.Items {
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s |\n",
+			row.Activity, row.Name, strings.ReplaceAll(row.Description, "|", "\\|"),
+			row.Hours, row.Days, row.DailyRate, row.Amount))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(fmt.Sprintf("**Subtotal:** %s  \n", page.Subtotal))
+	if page.Vat != "" {
+		b.WriteString(fmt.Sprintf("**VAT %s%%:** %s  \n", page.VatPerc, page.Vat))
+	}
+	b.WriteString(fmt.Sprintf("**Total:** %s\n", page.Total))
+
+	return b.String()
+}
+
+func (i *Invoice) Html() ([]byte, error) {
+	data, err := assets.ReadFile("assets/invoice.html")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("invoice").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, i.newPage()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
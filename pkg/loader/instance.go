@@ -143,6 +143,17 @@ func (i *TimeMasterInstance) GetAllTaskMap() map[string]specs.Task {
 	return ans
 }
 
+// GetActivityClientMap returns the map with the client of every activity.
+func (i *TimeMasterInstance) GetActivityClientMap() map[string]string {
+	ans := make(map[string]string, 0)
+	for _, client := range i.Clients {
+		for _, activity := range *client.GetActivities() {
+			ans[activity.Name] = client.Name
+		}
+	}
+	return ans
+}
+
 func (i *TimeMasterInstance) GetActivityByName(aName string) (*specs.Activity, *specs.Client, error) {
 
 	for idx := range i.Clients {
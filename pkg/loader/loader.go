@@ -20,11 +20,13 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package loader
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	specs "github.com/geaaru/time-master/pkg/specs"
 	"github.com/geaaru/time-master/pkg/tools"
@@ -56,6 +58,59 @@ func (i *TimeMasterInstance) Load() error {
 		i.LoadScenarioDir(dir)
 	}
 
+	return i.resolveScenarios()
+}
+
+// Merge the scenarios with the parent scenarios defined in extends.
+func (i *TimeMasterInstance) resolveScenarios() error {
+	resolved := make(map[string]*specs.Scenario, 0)
+
+	var resolve func(s *specs.Scenario, visited []string) (*specs.Scenario, error)
+	resolve = func(s *specs.Scenario, visited []string) (*specs.Scenario, error) {
+		if r, ok := resolved[s.Name]; ok {
+			return r, nil
+		}
+
+		if s.Extends == "" {
+			resolved[s.Name] = s
+			return s, nil
+		}
+
+		for _, v := range visited {
+			if v == s.Name {
+				return nil, errors.New(fmt.Sprintf(
+					"Found cycle on extends of the scenario %s: %s",
+					s.Name, strings.Join(append(visited, s.Name), " -> ")))
+			}
+		}
+
+		parent, err := i.GetScenarioByName(s.Extends)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf(
+				"Scenario %s extends a scenario not present: %s", s.Name, s.Extends))
+		}
+
+		rparent, err := resolve(parent, append(visited, s.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		ans := s.Extend(rparent)
+		resolved[s.Name] = ans
+
+		return ans, nil
+	}
+
+	scenarios := []specs.Scenario{}
+	for idx := range i.Scenarios {
+		s, err := resolve(&i.Scenarios[idx], []string{})
+		if err != nil {
+			return err
+		}
+		scenarios = append(scenarios, *s)
+	}
+	i.Scenarios = scenarios
+
 	return nil
 }
 
@@ -40,14 +40,34 @@ func (i *TimeMasterInstance) CalculateTimesheetsCostAndRevenue(sName string) err
 
 		for idx_t, rt := range agenda.Timesheets {
 
-			cost, err := scenario.GetResourceCost4Date(rt.Period.StartPeriod, rt.User)
+			activityName := rt.ResolveActivityByName()
+			activity, _, err := i.GetActivityByName(activityName)
 			if err != nil {
 				return err
 			}
 
-			rate, err := scenario.GetResourceRate4Date(rt.Period.StartPeriod, rt.User)
-			if err != nil {
-				return err
+			// Check if the scenario overrides cost and rate of the task
+			// or of the activity.
+			costOverride := scenario.GetDailyCostOverride(rt.Task, activityName)
+			rateOverride := scenario.GetDailyRateOverride(rt.Task, activityName)
+
+			var cost, rate float64
+			if costOverride != nil {
+				cost = *costOverride
+			} else {
+				cost, err = scenario.GetResourceCost4Date(rt.Period.StartPeriod, rt.User)
+				if err != nil {
+					return err
+				}
+			}
+
+			if rateOverride != nil {
+				rate = *rateOverride
+			} else {
+				rate, err = scenario.GetResourceRate4Date(rt.Period.StartPeriod, rt.User)
+				if err != nil {
+					return err
+				}
 			}
 
 			workDaySec, _ := tmtime.ParseDuration("1d", i.Config.GetWork().WorkHours)
@@ -56,15 +76,10 @@ func (i *TimeMasterInstance) CalculateTimesheetsCostAndRevenue(sName string) err
 				return err
 			}
 
-			activityName := rt.ResolveActivityByName()
-			activity, _, err := i.GetActivityByName(activityName)
-			if err != nil {
-				return err
-			}
 			costRt := (cost / float64(workDaySec)) * float64(secs)
 
 			var revenueRt float64
-			if activity.IsTimeAndMaterial() {
+			if activity.IsTimeAndMaterial() && rateOverride == nil {
 				revenueRt = (activity.GetTimeAndMaterialDailyOffer() / float64(workDaySec)) * float64(secs)
 			} else {
 				revenueRt = (rate / float64(workDaySec)) * float64(secs)
@@ -170,5 +170,32 @@ func (i *TimeMasterInstance) Validate(ignoreError bool) error {
 		}
 	}
 
+	// Validate scenarios overrides.
+	for _, s := range i.Scenarios {
+		overrides := s.GetOverrides()
+		errMsgs := []string{}
+
+		for _, to := range overrides.Tasks {
+			if ok := tasksMap[to.Name]; !ok {
+				errMsgs = append(errMsgs, fmt.Sprintf(
+					"Invalid task %s on overrides of the scenario %s", to.Name, s.Name))
+			}
+		}
+
+		for _, ao := range overrides.Activities {
+			if ok := activitiesMap[ao.Name]; !ok {
+				errMsgs = append(errMsgs, fmt.Sprintf(
+					"Invalid activity %s on overrides of the scenario %s", ao.Name, s.Name))
+			}
+		}
+
+		for _, errMsg := range errMsgs {
+			if !ignoreError {
+				return errors.New(errMsg)
+			}
+			i.Logger.Warning(errMsg)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,256 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package msproject
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	NAMESPACE = "http://schemas.microsoft.com/project"
+
+	// Layout of the dates of the MSPDI files.
+	DATE_LAYOUT = "2006-01-02T15:04:05"
+
+	// Types of the predecessor links.
+	LINK_FF = 0
+	LINK_FS = 1
+	LINK_SF = 2
+	LINK_SS = 3
+
+	// Format of the durations in hours.
+	DURATION_FORMAT_HOURS = 5
+	// Format of the durations in days.
+	DURATION_FORMAT_DAYS = 7
+
+	RESOURCE_TYPE_WORK = 1
+)
+
+var durationRegex = regexp.MustCompile(
+	`^-?P(?:(\d+(?:\.\d+)?)Y)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// Project is the root element of a MS Project XML (MSPDI) file. Only
+// the elements used by time-master are available. The order of the
+// fields follows the order of the elements of the MSPDI schema.
+type Project struct {
+	XMLName           xml.Name `xml:"Project"`
+	Xmlns             string   `xml:"xmlns,attr,omitempty"`
+	SaveVersion       int      `xml:"SaveVersion,omitempty"`
+	Name              string   `xml:"Name,omitempty"`
+	Title             string   `xml:"Title,omitempty"`
+	Company           string   `xml:"Company,omitempty"`
+	CreationDate      string   `xml:"CreationDate,omitempty"`
+	ScheduleFromStart int      `xml:"ScheduleFromStart"`
+	StartDate         string   `xml:"StartDate,omitempty"`
+	FinishDate        string   `xml:"FinishDate,omitempty"`
+	CalendarUID       int      `xml:"CalendarUID,omitempty"`
+	DefaultStartTime  string   `xml:"DefaultStartTime,omitempty"`
+	DefaultFinishTime string   `xml:"DefaultFinishTime,omitempty"`
+	MinutesPerDay     int      `xml:"MinutesPerDay,omitempty"`
+	MinutesPerWeek    int      `xml:"MinutesPerWeek,omitempty"`
+	DaysPerMonth      int      `xml:"DaysPerMonth,omitempty"`
+	CurrentDate       string   `xml:"CurrentDate,omitempty"`
+
+	Calendars   []Calendar   `xml:"Calendars>Calendar"`
+	Tasks       []Task       `xml:"Tasks>Task"`
+	Resources   []Resource   `xml:"Resources>Resource"`
+	Assignments []Assignment `xml:"Assignments>Assignment"`
+}
+
+type Calendar struct {
+	UID            int       `xml:"UID"`
+	Name           string    `xml:"Name"`
+	IsBaseCalendar int       `xml:"IsBaseCalendar"`
+	WeekDays       []WeekDay `xml:"WeekDays>WeekDay"`
+}
+
+type WeekDay struct {
+	// Day of the week: 1 Sunday ... 7 Saturday.
+	DayType      int           `xml:"DayType"`
+	DayWorking   int           `xml:"DayWorking"`
+	WorkingTimes []WorkingTime `xml:"WorkingTimes>WorkingTime,omitempty"`
+}
+
+type WorkingTime struct {
+	FromTime string `xml:"FromTime"`
+	ToTime   string `xml:"ToTime"`
+}
+
+type Task struct {
+	UID             int               `xml:"UID"`
+	ID              int               `xml:"ID"`
+	Name            string            `xml:"Name,omitempty"`
+	Type            int               `xml:"Type"`
+	IsNull          int               `xml:"IsNull"`
+	WBS             string            `xml:"WBS,omitempty"`
+	OutlineNumber   string            `xml:"OutlineNumber,omitempty"`
+	OutlineLevel    int               `xml:"OutlineLevel"`
+	Priority        int               `xml:"Priority,omitempty"`
+	Start           string            `xml:"Start,omitempty"`
+	Finish          string            `xml:"Finish,omitempty"`
+	Duration        string            `xml:"Duration,omitempty"`
+	DurationFormat  int               `xml:"DurationFormat,omitempty"`
+	Work            string            `xml:"Work,omitempty"`
+	Milestone       int               `xml:"Milestone"`
+	Summary         int               `xml:"Summary"`
+	PercentComplete int               `xml:"PercentComplete"`
+	ActualStart     string            `xml:"ActualStart,omitempty"`
+	ActualFinish    string            `xml:"ActualFinish,omitempty"`
+	ActualWork      string            `xml:"ActualWork,omitempty"`
+	RemainingWork   string            `xml:"RemainingWork,omitempty"`
+	Notes           string            `xml:"Notes,omitempty"`
+	PredecessorLink []PredecessorLink `xml:"PredecessorLink"`
+}
+
+type PredecessorLink struct {
+	PredecessorUID int `xml:"PredecessorUID"`
+	Type           int `xml:"Type"`
+}
+
+type Resource struct {
+	UID          int    `xml:"UID"`
+	ID           int    `xml:"ID"`
+	Name         string `xml:"Name,omitempty"`
+	Type         int    `xml:"Type"`
+	IsNull       int    `xml:"IsNull"`
+	Initials     string `xml:"Initials,omitempty"`
+	EmailAddress string `xml:"EmailAddress,omitempty"`
+}
+
+type Assignment struct {
+	UID                 int     `xml:"UID"`
+	TaskUID             int     `xml:"TaskUID"`
+	ResourceUID         int     `xml:"ResourceUID"`
+	PercentWorkComplete int     `xml:"PercentWorkComplete"`
+	ActualWork          string  `xml:"ActualWork,omitempty"`
+	Finish              string  `xml:"Finish,omitempty"`
+	RemainingWork       string  `xml:"RemainingWork,omitempty"`
+	Start               string  `xml:"Start,omitempty"`
+	Units               float64 `xml:"Units"`
+	Work                string  `xml:"Work,omitempty"`
+}
+
+// Parse reads a MSPDI file.
+func Parse(data []byte) (*Project, error) {
+	ans := &Project{}
+	if err := xml.Unmarshal(data, ans); err != nil {
+		return nil, err
+	}
+	if ans.XMLName.Local != "Project" {
+		return nil, errors.New("Invalid MS Project XML file: root element " + ans.XMLName.Local)
+	}
+	return ans, nil
+}
+
+// Marshal returns the MSPDI file of the project.
+func (p *Project) Marshal() ([]byte, error) {
+	p.Xmlns = NAMESPACE
+	data, err := xml.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"), data...), nil
+}
+
+// GetTaskByUID returns the task with the UID or nil.
+func (p *Project) GetTaskByUID(uid int) *Task {
+	for idx := range p.Tasks {
+		if p.Tasks[idx].UID == uid {
+			return &p.Tasks[idx]
+		}
+	}
+	return nil
+}
+
+// GetResourceByUID returns the resource with the UID or nil.
+func (p *Project) GetResourceByUID(uid int) *Resource {
+	for idx := range p.Resources {
+		if p.Resources[idx].UID == uid {
+			return &p.Resources[idx]
+		}
+	}
+	return nil
+}
+
+// GetTaskAssignments returns the assignments of the task.
+func (p *Project) GetTaskAssignments(uid int) []Assignment {
+	ans := []Assignment{}
+	for _, a := range p.Assignments {
+		if a.TaskUID == uid {
+			ans = append(ans, a)
+		}
+	}
+	return ans
+}
+
+// FormatDuration returns the duration of the seconds in the
+// format used by MS Project (PT8H30M0S).
+func FormatDuration(secs int64) string {
+	return fmt.Sprintf("PT%dH%dM%dS", secs/3600, (secs%3600)/60, secs%60)
+}
+
+// ParseDuration returns the seconds of a duration. The days are
+// converted with the minutes per day of the project.
+func ParseDuration(d string, minutesPerDay int) (int64, error) {
+	if d == "" {
+		return 0, nil
+	}
+
+	m := durationRegex.FindStringSubmatch(d)
+	if m == nil {
+		return 0, errors.New("Invalid duration " + d)
+	}
+
+	if minutesPerDay <= 0 {
+		minutesPerDay = 480
+	}
+	day := float64(minutesPerDay) * 60
+	// Years and months as 52 weeks and 4 weeks of 5 days.
+	factors := []float64{260 * day, 20 * day, day, 3600, 60, 1}
+
+	var ans float64
+	for idx, f := range factors {
+		if m[idx+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(m[idx+1], 64)
+		if err != nil {
+			return 0, err
+		}
+		ans += v * f
+	}
+
+	return int64(ans), nil
+}
+
+// FormatDate returns the date in the format of MSPDI.
+func FormatDate(t time.Time) string {
+	return t.Format(DATE_LAYOUT)
+}
+
+// ParseDate parses a date of MSPDI.
+func ParseDate(d string) (time.Time, error) {
+	return time.Parse(DATE_LAYOUT, d)
+}
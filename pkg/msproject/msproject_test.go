@@ -0,0 +1,108 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package msproject_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/msproject"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MS Project Test", func() {
+
+	Context("Durations", func() {
+
+		It("Format", func() {
+			Expect(FormatDuration(int64(8*3600 + 30*60))).To(Equal("PT8H30M0S"))
+			Expect(FormatDuration(int64(0))).To(Equal("PT0H0M0S"))
+		})
+
+		It("Parse hours", func() {
+			secs, err := ParseDuration("PT8H30M0S", 480)
+			Expect(err).Should(BeNil())
+			Expect(secs).To(Equal(int64(8*3600 + 30*60)))
+		})
+
+		It("Parse days", func() {
+			secs, err := ParseDuration("P2DT4H", 420)
+			Expect(err).Should(BeNil())
+			Expect(secs).To(Equal(int64(2*7*3600 + 4*3600)))
+		})
+
+		It("Parse decimal", func() {
+			secs, err := ParseDuration("PT1.5H", 480)
+			Expect(err).Should(BeNil())
+			Expect(secs).To(Equal(int64(5400)))
+		})
+
+		It("Invalid", func() {
+			_, err := ParseDuration("8h", 480)
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	Context("Marshal", func() {
+
+		It("Round trip", func() {
+			p := &Project{
+				Name:              "Test",
+				ScheduleFromStart: 1,
+				Tasks: []Task{
+					{UID: 1, ID: 1, Name: "Phase 1", OutlineLevel: 1, Summary: 1},
+					{
+						UID: 2, ID: 2, Name: "Analysis & design", OutlineLevel: 2,
+						Start: "2026-09-01T08:00:00", Work: "PT16H0M0S",
+					},
+					{
+						UID: 3, ID: 3, Name: "Development", OutlineLevel: 2,
+						PredecessorLink: []PredecessorLink{
+							{PredecessorUID: 2, Type: LINK_FS},
+						},
+					},
+				},
+				Resources: []Resource{{UID: 1, ID: 1, Name: "Daniele", Type: RESOURCE_TYPE_WORK}},
+				Assignments: []Assignment{
+					{UID: 1, TaskUID: 2, ResourceUID: 1, Units: 1, Work: "PT16H0M0S"},
+				},
+			}
+
+			data, err := p.Marshal()
+			Expect(err).Should(BeNil())
+			Expect(string(data)).To(ContainSubstring(`<Project xmlns="http://schemas.microsoft.com/project">`))
+			Expect(string(data)).To(ContainSubstring("<Name>Analysis &amp; design</Name>"))
+
+			p2, err := Parse(data)
+			Expect(err).Should(BeNil())
+			Expect(len(p2.Tasks)).To(Equal(3))
+			Expect(p2.GetTaskByUID(3).PredecessorLink).To(Equal([]PredecessorLink{
+				{PredecessorUID: 2, Type: LINK_FS},
+			}))
+			Expect(p2.GetResourceByUID(1).Name).To(Equal("Daniele"))
+			Expect(p2.GetTaskAssignments(2)).To(Equal(p.Assignments))
+			Expect(p2.GetTaskByUID(4)).To(BeNil())
+		})
+
+		It("Invalid root", func() {
+			_, err := Parse([]byte("<Foo></Foo>"))
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,634 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	loader "github.com/geaaru/time-master/pkg/loader"
+	log "github.com/geaaru/time-master/pkg/logger"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tools "github.com/geaaru/time-master/pkg/tools"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FILE_CLIENT    = "client"
+	FILE_ACTIVITY  = "activity"
+	FILE_TIMESHEET = "timesheet"
+	FILE_SCENARIO  = "scenario"
+	FILE_MAPPER    = "mapper"
+
+	tmpSuffix    = ".tm-tmp"
+	backupSuffix = ".tm-bak"
+)
+
+// TmRefactor rewrites the references of tasks and activities over
+// all the files of the workspace. The files are modified only in memory
+// until Apply() is called.
+type TmRefactor struct {
+	Logger   *log.TmLogger
+	Instance *loader.TimeMasterInstance
+	Files    []*RefactorFile
+}
+
+type RefactorFile struct {
+	Path     string
+	Type     string
+	Original string
+	Content  string
+}
+
+func NewTmRefactor(tm *loader.TimeMasterInstance, mapperFiles []string) (*TmRefactor, error) {
+	ans := &TmRefactor{
+		Logger:   tm.Logger,
+		Instance: tm,
+		Files:    []*RefactorFile{},
+	}
+
+	for _, c := range *tm.GetClients() {
+		err := ans.addFile(c.File, FILE_CLIENT)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range *c.GetActivities() {
+			if a.File != "" {
+				err = ans.addFile(a.File, FILE_ACTIVITY)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, agenda := range *tm.GetTimesheets() {
+		err := ans.addFile(agenda.File, FILE_TIMESHEET)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range *tm.GetScenarios() {
+		err := ans.addFile(s.File, FILE_SCENARIO)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range mapperFiles {
+		err := ans.addFile(f, FILE_MAPPER)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ans, nil
+}
+
+func (r *TmRefactor) addFile(f, t string) error {
+	if f == "" {
+		return nil
+	}
+
+	for _, rf := range r.Files {
+		if rf.Path == f {
+			return nil
+		}
+	}
+
+	content, err := ioutil.ReadFile(f)
+	if err != nil {
+		return err
+	}
+
+	r.Files = append(r.Files, &RefactorFile{
+		Path:     f,
+		Type:     t,
+		Original: string(content),
+		Content:  string(content),
+	})
+
+	return nil
+}
+
+func (r *TmRefactor) GetChangedFiles() []*RefactorFile {
+	ans := []*RefactorFile{}
+	for _, f := range r.Files {
+		if f.Content != f.Original {
+			ans = append(ans, f)
+		}
+	}
+	return ans
+}
+
+func (r *TmRefactor) Diff() string {
+	ans := ""
+	for _, f := range r.GetChangedFiles() {
+		ans += tools.UnifiedDiff(filepath.Join("a", f.Path), filepath.Join("b", f.Path),
+			f.Original, f.Content)
+	}
+	return ans
+}
+
+// Apply writes all the modified files. The contents are written in
+// temporary files before replace the files with rename. The original
+// files are maintained as backup until all the files are replaced so
+// on error the backups are moved back and every file has the original
+// content.
+func (r *TmRefactor) Apply() error {
+	files := r.GetChangedFiles()
+
+	removeFiles := func(suffix string, ff []*RefactorFile) {
+		for _, f := range ff {
+			os.Remove(f.Path + suffix)
+		}
+	}
+
+	// Write temporary files
+	for idx, f := range files {
+		mode := os.FileMode(0644)
+		if st, err := os.Stat(f.Path); err == nil {
+			mode = st.Mode().Perm()
+		}
+
+		err := ioutil.WriteFile(f.Path+tmpSuffix, []byte(f.Content), mode)
+		if err != nil {
+			removeFiles(tmpSuffix, files[:idx])
+			return err
+		}
+	}
+
+	for idx, f := range files {
+		err := os.Rename(f.Path, f.Path+backupSuffix)
+		if err == nil {
+			err = os.Rename(f.Path+tmpSuffix, f.Path)
+			if err != nil {
+				os.Rename(f.Path+backupSuffix, f.Path)
+			}
+		}
+
+		if err != nil {
+			removeFiles(tmpSuffix, files[idx:])
+			if rerr := r.restoreBackups(files[:idx]); rerr != nil {
+				return errors.New(fmt.Sprintf(
+					"Error on replace file %s (%s) and restore failed: %s",
+					f.Path, err.Error(), rerr.Error()))
+			}
+			return err
+		}
+	}
+
+	removeFiles(backupSuffix, files)
+
+	return nil
+}
+
+func (r *TmRefactor) restoreBackups(files []*RefactorFile) error {
+	var ans error
+	for _, f := range files {
+		err := os.Rename(f.Path+backupSuffix, f.Path)
+		if err != nil {
+			r.Logger.Error("Error on restore file " + f.Path + ": " + err.Error())
+			ans = err
+		}
+	}
+	return ans
+}
+
+// ApplyAndValidate writes the modified files and validates the
+// reloaded data. On validation errors the original files are restored.
+func (r *TmRefactor) ApplyAndValidate(config *specs.TimeMasterConfig) error {
+	err := r.Apply()
+	if err != nil {
+		return err
+	}
+
+	tm := loader.NewTimeMasterInstance(config)
+	err = tm.Load()
+	if err == nil {
+		err = tm.Validate(false)
+	}
+
+	if err != nil {
+		if rerr := r.Rollback(); rerr != nil {
+			return errors.New(fmt.Sprintf(
+				"Validation failed (%s) and rollback failed: %s",
+				err.Error(), rerr.Error()))
+		}
+		return errors.New("Validation failed, changes reverted: " + err.Error())
+	}
+
+	return nil
+}
+
+// Rollback restores the original content of the modified files.
+func (r *TmRefactor) Rollback() error {
+	return r.restore(r.GetChangedFiles())
+}
+
+func (r *TmRefactor) restore(files []*RefactorFile) error {
+	var ans error
+	for _, f := range files {
+		err := ioutil.WriteFile(f.Path, []byte(f.Original), 0644)
+		if err != nil {
+			r.Logger.Error("Error on restore file " + f.Path + ": " + err.Error())
+			ans = err
+		}
+	}
+	return ans
+}
+
+func (r *TmRefactor) RenameActivity(oldName, newName string) error {
+	if strings.Contains(newName, ".") || newName == "" {
+		return errors.New("Invalid activity name " + newName)
+	}
+
+	if _, _, err := r.Instance.GetActivityByName(oldName); err != nil {
+		return err
+	}
+
+	if _, _, err := r.Instance.GetActivityByName(newName); err == nil {
+		return errors.New("Activity " + newName + " already present")
+	}
+
+	file, aNode, err := r.findActivityNode(oldName)
+	if err != nil {
+		return err
+	}
+
+	content, err := applyScalarEdits(file.Content, []scalarEdit{
+		newScalarEdit(getMappingValue(aNode, "name"), newName),
+	})
+	if err != nil {
+		return err
+	}
+	file.Content = content
+
+	return r.updateReferences(oldName, newName)
+}
+
+func (r *TmRefactor) RenameTask(oldName, newName string) error {
+	oldLeafs := strings.Split(oldName, ".")
+	newLeafs := strings.Split(newName, ".")
+
+	if len(oldLeafs) < 2 {
+		return errors.New("Invalid task name " + oldName)
+	}
+	if len(newLeafs) < 2 {
+		return errors.New("Invalid task name " + newName)
+	}
+	for _, l := range newLeafs {
+		if l == "" {
+			return errors.New("Invalid task name " + newName)
+		}
+	}
+
+	if strings.HasPrefix(newName, oldName+".") {
+		return errors.New("Task " + oldName + " can't be moved under itself")
+	}
+
+	taskMap := r.Instance.GetAllTaskMap()
+	if _, ok := taskMap[oldName]; !ok {
+		return errors.New("Task " + oldName + " not found")
+	}
+	if _, ok := taskMap[newName]; ok {
+		return errors.New("Task " + newName + " already present")
+	}
+
+	oldParent := strings.Join(oldLeafs[:len(oldLeafs)-1], ".")
+	newParent := strings.Join(newLeafs[:len(newLeafs)-1], ".")
+	oldLeaf := oldLeafs[len(oldLeafs)-1]
+	newLeaf := newLeafs[len(newLeafs)-1]
+
+	if oldParent != newParent {
+		if len(newLeafs) == 2 {
+			if _, _, err := r.Instance.GetActivityByName(newParent); err != nil {
+				return err
+			}
+		} else if _, ok := taskMap[newParent]; !ok {
+			return errors.New("Parent task " + newParent + " not found")
+		}
+
+		if _, ok := taskMap[newParent+"."+oldLeaf]; ok && oldLeaf != newLeaf {
+			return errors.New(fmt.Sprintf(
+				"Task %s already present. Rename the task before the move.",
+				newParent+"."+oldLeaf))
+		}
+
+		err := r.moveTask(oldName, newParent)
+		if err != nil {
+			return err
+		}
+	}
+
+	if oldLeaf != newLeaf {
+		file, node, _, _, err := r.findTaskNode(newParent + "." + oldLeaf)
+		if err != nil {
+			return err
+		}
+
+		content, err := applyScalarEdits(file.Content, []scalarEdit{
+			newScalarEdit(getMappingValue(node, "name"), newLeaf),
+		})
+		if err != nil {
+			return err
+		}
+		file.Content = content
+	}
+
+	return r.updateReferences(oldName, newName)
+}
+
+func (r *TmRefactor) moveTask(oldName, newParent string) error {
+	srcFile, _, srcSeqKey, srcSeq, err := r.findTaskNode(oldName)
+	if err != nil {
+		return err
+	}
+
+	srcIdx := -1
+	leafs := strings.Split(oldName, ".")
+	for idx, n := range srcSeq.Content {
+		if getMappingValue(n, "name").Value == leafs[len(leafs)-1] {
+			srcIdx = idx
+			break
+		}
+	}
+
+	lines := strings.Split(srcFile.Content, "\n")
+	start, end, dashIndent, err := getSeqItemBlock(lines, srcSeq, srcIdx)
+	if err != nil {
+		return errors.New(srcFile.Path + ": " + err.Error())
+	}
+
+	block := make([]string, end-start+1)
+	copy(block, lines[start:end+1])
+	// Offset of the dash from the key of the sequence
+	dashOffset := dashIndent - (srcSeqKey.Column - 1)
+
+	// Remove the block and the key of the sequence if it's empty.
+	if len(srcSeq.Content) == 1 && srcSeqKey.Line < start+1 &&
+		strings.TrimSpace(lines[srcSeqKey.Line-1]) == srcSeqKey.Value+":" {
+		lines = append(lines[:srcSeqKey.Line-1], lines[end+1:]...)
+	} else {
+		lines = append(lines[:start], lines[end+1:]...)
+	}
+	srcFile.Content = strings.Join(lines, "\n")
+
+	// Retrieve the new parent from the updated content
+	var targetFile *RefactorFile
+	var parent *yaml.Node
+	var parentSeq *yaml.Node
+	parentIdx := -1
+	key := "subtasks"
+
+	if !strings.Contains(newParent, ".") {
+		key = "tasks"
+		targetFile, parent, err = r.findActivityNode(newParent)
+		if err != nil {
+			return err
+		}
+		if targetFile.Type == FILE_CLIENT {
+			root, _ := parseRootNode(targetFile.Content)
+			parentSeq = getMappingValue(root, "activities")
+			for idx, n := range parentSeq.Content {
+				if n == parent {
+					parentIdx = idx
+				}
+			}
+		}
+	} else {
+		targetFile, parent, _, parentSeq, err = r.findTaskNode(newParent)
+		if err != nil {
+			return err
+		}
+		pLeafs := strings.Split(newParent, ".")
+		for idx, n := range parentSeq.Content {
+			if getMappingValue(n, "name").Value == pLeafs[len(pLeafs)-1] {
+				parentIdx = idx
+				break
+			}
+		}
+	}
+
+	lines = strings.Split(targetFile.Content, "\n")
+	var newLines []string
+	insertAt := 0
+
+	seqKey, seq := getMappingKey(parent, key)
+	if seq != nil && seq.Kind == yaml.SequenceNode && len(seq.Content) > 0 {
+		_, lastEnd, _, err := getSeqItemBlock(lines, seq, len(seq.Content)-1)
+		if err != nil {
+			return errors.New(targetFile.Path + ": " + err.Error())
+		}
+		_, _, targetDash, err := getSeqItemBlock(lines, seq, 0)
+		if err != nil {
+			return errors.New(targetFile.Path + ": " + err.Error())
+		}
+
+		insertAt = lastEnd + 1
+		newLines = reindentLines(block, targetDash-dashIndent)
+
+	} else if seqKey != nil {
+		return errors.New(fmt.Sprintf("%s: unsupported format of %s at line %d",
+			targetFile.Path, key, seqKey.Line))
+	} else {
+		// POST: the parent is without the sequence key.
+		keyIndent := parent.Column - 1
+		if parentSeq != nil && parentIdx >= 0 {
+			_, pEnd, _, err := getSeqItemBlock(lines, parentSeq, parentIdx)
+			if err != nil {
+				return errors.New(targetFile.Path + ": " + err.Error())
+			}
+			insertAt = pEnd + 1
+		} else {
+			insertAt = getLastContentLine(lines) + 1
+		}
+
+		newLines = append([]string{strings.Repeat(" ", keyIndent) + key + ":"},
+			reindentLines(block, keyIndent+dashOffset-dashIndent)...)
+	}
+
+	lines = append(lines[:insertAt], append(newLines, lines[insertAt:]...)...)
+	targetFile.Content = strings.Join(lines, "\n")
+
+	return nil
+}
+
+// Update all references to the name of the task or activity.
+func (r *TmRefactor) updateReferences(oldName, newName string) error {
+	for _, f := range r.Files {
+		root, err := parseRootNode(f.Content)
+		if err != nil {
+			return errors.New("Error on parse file " + f.Path + ": " + err.Error())
+		}
+		if root == nil {
+			continue
+		}
+
+		edits := []scalarEdit{}
+		addEdit := func(n *yaml.Node) {
+			if n == nil || n.Kind != yaml.ScalarNode {
+				return
+			}
+			if v, ok := mapFullName(n.Value, oldName, newName); ok {
+				edits = append(edits, newScalarEdit(n, v))
+			}
+		}
+
+		switch f.Type {
+		case FILE_CLIENT:
+			for _, a := range getSequence(root, "activities") {
+				collectTasksReferences(a, "tasks", addEdit)
+			}
+		case FILE_ACTIVITY:
+			collectTasksReferences(root, "tasks", addEdit)
+		case FILE_TIMESHEET:
+			for _, rt := range getSequence(root, "timesheets") {
+				addEdit(getMappingValue(rt, "task"))
+			}
+		case FILE_SCENARIO:
+			for _, st := range getSequence(root, "task_prorities") {
+				addEdit(getMappingValue(st, "name"))
+			}
+			for _, sa := range getSequence(root, "activities_priorities") {
+				addEdit(getMappingValue(sa, "name"))
+			}
+			overrides := getMappingValue(root, "overrides")
+			for _, k := range []string{"tasks", "activities"} {
+				for _, o := range getSequence(overrides, k) {
+					addEdit(getMappingValue(o, "name"))
+				}
+			}
+		case FILE_MAPPER:
+			collectMapperReferences(root, addEdit)
+		}
+
+		if len(edits) > 0 {
+			f.Content, err = applyScalarEdits(f.Content, edits)
+			if err != nil {
+				return errors.New(f.Path + ": " + err.Error())
+			}
+			r.Logger.Debug(fmt.Sprintf("Updated %d references on file %s.",
+				len(edits), f.Path))
+		}
+	}
+
+	return nil
+}
+
+func collectTasksReferences(m *yaml.Node, key string, addEdit func(*yaml.Node)) {
+	for _, t := range getSequence(m, key) {
+		for _, dep := range getSequence(t, "depends") {
+			addEdit(dep)
+		}
+		collectTasksReferences(t, "subtasks", addEdit)
+	}
+}
+
+// The mappers use the key task to define the target task.
+func collectMapperReferences(n *yaml.Node, addEdit func(*yaml.Node)) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == "task" {
+				addEdit(n.Content[i+1])
+			} else {
+				collectMapperReferences(n.Content[i+1], addEdit)
+			}
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			collectMapperReferences(c, addEdit)
+		}
+	}
+}
+
+func (r *TmRefactor) findActivityNode(name string) (*RefactorFile, *yaml.Node, error) {
+	for _, f := range r.Files {
+		if f.Type != FILE_CLIENT && f.Type != FILE_ACTIVITY {
+			continue
+		}
+
+		root, err := parseRootNode(f.Content)
+		if err != nil {
+			return nil, nil, errors.New("Error on parse file " + f.Path + ": " + err.Error())
+		}
+		if root == nil {
+			continue
+		}
+
+		if f.Type == FILE_ACTIVITY {
+			if n := getMappingValue(root, "name"); n != nil && n.Value == name {
+				return f, root, nil
+			}
+		} else {
+			for _, a := range getSequence(root, "activities") {
+				if n := getMappingValue(a, "name"); n != nil && n.Value == name {
+					return f, a, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil, errors.New("No definition found for activity " + name)
+}
+
+// Return the file, the node of the task, the key and the sequence
+// node that contains the task.
+func (r *TmRefactor) findTaskNode(name string) (*RefactorFile, *yaml.Node, *yaml.Node, *yaml.Node, error) {
+	leafs := strings.Split(name, ".")
+
+	f, node, err := r.findActivityNode(leafs[0])
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	key := "tasks"
+	var seqKey, seq *yaml.Node
+	for _, leaf := range leafs[1:] {
+		seqKey, seq = getMappingKey(node, key)
+		if seq == nil || seq.Kind != yaml.SequenceNode {
+			return nil, nil, nil, nil, errors.New("No definition found for task " + name)
+		}
+
+		var found *yaml.Node
+		for _, t := range seq.Content {
+			if n := getMappingValue(t, "name"); n != nil && n.Value == leaf {
+				found = t
+				break
+			}
+		}
+		if found == nil {
+			return nil, nil, nil, nil, errors.New("No definition found for task " + name)
+		}
+
+		node = found
+		key = "subtasks"
+	}
+
+	return f, node, seqKey, seq, nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package refactor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	loader "github.com/geaaru/time-master/pkg/loader"
+	. "github.com/geaaru/time-master/pkg/refactor"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Files of the workspace used by the tests.
+var workspace = map[string]string{
+	"clients/client1.yml": `name: CLIENT1
+activities_dirs:
+  - acts
+activities:
+  - name: ACT1
+    priority: 1
+    tasks:
+      - name: dev
+        effort: 1d
+        subtasks:
+          - name: backend
+            effort: 1d
+      - name: test
+        effort: 1d
+        depends:
+          - ACT1.dev.backend
+`,
+	"clients/acts/act2.yml": `name: ACT2
+priority: 2
+tasks:
+  - name: support
+    effort: 2d
+    depends:
+      - ACT1.test
+`,
+	"timesheets/geaaru.yml": `name: geaaru
+timesheets:
+  - period:
+      start_period: "2026-09-01"
+    user: geaaru
+    task: ACT1.dev.backend
+    duration: 4h
+  - period:
+      start_period: "2026-09-01"
+    user: geaaru
+    task: ACT1.test
+    duration: 2h
+  - period:
+      start_period: "2026-09-02"
+    user: geaaru
+    task: ACT2.support
+    duration: 1h
+`,
+	"scenarios/s1.yml": `name: s1
+task_prorities:
+  - name: ACT1.test
+    priority: 1
+activities_priorities:
+  - name: ACT2
+    priority: 1
+`,
+	"mappers/jira.yml": `issues:
+  - jira_issue: PRJ-1
+    task: ACT1.test
+`,
+}
+
+var _ = Describe("Refactor Test", func() {
+
+	var tmpDir string
+	var config *specs.TimeMasterConfig
+
+	path := func(f string) string {
+		return filepath.Join(tmpDir, f)
+	}
+
+	read := func(f string) string {
+		data, err := ioutil.ReadFile(path(f))
+		Expect(err).Should(BeNil())
+		return string(data)
+	}
+
+	newRefactor := func() *TmRefactor {
+		tm := loader.NewTimeMasterInstance(config)
+		Expect(tm.Load()).Should(BeNil())
+
+		r, err := NewTmRefactor(tm, []string{path("mappers/jira.yml")})
+		Expect(err).Should(BeNil())
+		return r
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "tm-refactor")
+		Expect(err).Should(BeNil())
+
+		for f, content := range workspace {
+			Expect(os.MkdirAll(filepath.Dir(path(f)), os.ModePerm)).Should(BeNil())
+			Expect(ioutil.WriteFile(path(f), []byte(content), 0644)).Should(BeNil())
+		}
+
+		config = specs.NewTimeMasterConfig(nil)
+		config.GetWork().WorkHours = 8
+		config.ClientsDirs = []string{path("clients")}
+		config.TimesheetsDirs = []string{path("timesheets")}
+		config.ScenariosDirs = []string{path("scenarios")}
+		config.ResourcesDirs = []string{path("resources")}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	Context("Tasks", func() {
+
+		It("Rename", func() {
+			r := newRefactor()
+			Expect(r.RenameTask("ACT1.dev.backend", "ACT1.dev.api")).Should(BeNil())
+			Expect(len(r.GetChangedFiles())).To(Equal(2))
+			Expect(r.Apply()).Should(BeNil())
+
+			Expect(read("clients/client1.yml")).To(Equal(strings.NewReplacer(
+				"- name: backend", "- name: api",
+				"- ACT1.dev.backend", "- ACT1.dev.api",
+			).Replace(workspace["clients/client1.yml"])))
+			Expect(read("timesheets/geaaru.yml")).To(Equal(strings.Replace(
+				workspace["timesheets/geaaru.yml"], "ACT1.dev.backend", "ACT1.dev.api", 1)))
+		})
+
+		It("Move to another activity", func() {
+			r := newRefactor()
+			Expect(r.RenameTask("ACT1.test", "ACT2.test")).Should(BeNil())
+			Expect(r.Apply()).Should(BeNil())
+
+			Expect(read("clients/client1.yml")).To(Equal(`name: CLIENT1
+activities_dirs:
+  - acts
+activities:
+  - name: ACT1
+    priority: 1
+    tasks:
+      - name: dev
+        effort: 1d
+        subtasks:
+          - name: backend
+            effort: 1d
+`))
+			Expect(read("clients/acts/act2.yml")).To(Equal(`name: ACT2
+priority: 2
+tasks:
+  - name: support
+    effort: 2d
+    depends:
+      - ACT2.test
+  - name: test
+    effort: 1d
+    depends:
+      - ACT1.dev.backend
+`))
+			Expect(read("timesheets/geaaru.yml")).To(ContainSubstring("task: ACT2.test\n"))
+			Expect(read("scenarios/s1.yml")).To(ContainSubstring("- name: ACT2.test\n"))
+			Expect(read("mappers/jira.yml")).To(ContainSubstring("task: ACT2.test\n"))
+
+			// The workspace is loaded with the moved task.
+			tm := loader.NewTimeMasterInstance(config)
+			Expect(tm.Load()).Should(BeNil())
+			_, ok := tm.GetAllTaskMap()["ACT2.test"]
+			Expect(ok).To(Equal(true))
+		})
+
+		It("Move under itself", func() {
+			r := newRefactor()
+			Expect(r.RenameTask("ACT1.dev", "ACT1.dev.backend.dev")).ShouldNot(BeNil())
+			Expect(len(r.GetChangedFiles())).To(Equal(0))
+		})
+	})
+
+	Context("Activities", func() {
+
+		It("Rename", func() {
+			r := newRefactor()
+			Expect(r.RenameActivity("ACT2", "ACT3")).Should(BeNil())
+			Expect(r.Apply()).Should(BeNil())
+
+			Expect(read("clients/acts/act2.yml")).To(HavePrefix("name: ACT3\n"))
+			Expect(read("timesheets/geaaru.yml")).To(ContainSubstring("task: ACT3.support\n"))
+			Expect(read("scenarios/s1.yml")).To(ContainSubstring("- name: ACT3\n"))
+			Expect(read("clients/client1.yml")).To(Equal(workspace["clients/client1.yml"]))
+
+			Expect(r.RenameActivity("ACT3", "ACT1")).ShouldNot(BeNil())
+		})
+	})
+
+	Context("Apply", func() {
+
+		It("Rollback on a failing replace", func() {
+			r := newRefactor()
+			Expect(r.RenameTask("ACT1.test", "ACT1.check")).Should(BeNil())
+			Expect(len(r.GetChangedFiles())).To(Equal(5))
+
+			// The backup of the timesheets file can't be created.
+			backup := path("timesheets/geaaru.yml.tm-bak")
+			Expect(os.MkdirAll(filepath.Join(backup, "dir"), os.ModePerm)).Should(BeNil())
+
+			Expect(r.Apply()).ShouldNot(BeNil())
+			for f, content := range workspace {
+				Expect(read(f)).To(Equal(content))
+				_, err := os.Stat(path(f) + ".tm-tmp")
+				Expect(os.IsNotExist(err)).To(Equal(true))
+			}
+			_, err := os.Stat(path("clients/client1.yml.tm-bak"))
+			Expect(os.IsNotExist(err)).To(Equal(true))
+		})
+
+		It("Failing write of the temporary files", func() {
+			r := newRefactor()
+			Expect(r.RenameTask("ACT1.test", "ACT1.check")).Should(BeNil())
+
+			tmp := path("scenarios/s1.yml.tm-tmp")
+			Expect(os.MkdirAll(filepath.Join(tmp, "dir"), os.ModePerm)).Should(BeNil())
+
+			Expect(r.Apply()).ShouldNot(BeNil())
+			for f, content := range workspace {
+				Expect(read(f)).To(Equal(content))
+			}
+			_, err := os.Stat(path("clients/client1.yml.tm-tmp"))
+			Expect(os.IsNotExist(err)).To(Equal(true))
+		})
+
+		It("Diff of absolute paths", func() {
+			r := newRefactor()
+			Expect(r.RenameActivity("ACT2", "ACT3")).Should(BeNil())
+
+			diff := r.Diff()
+			Expect(diff).To(ContainSubstring("--- a" + path("clients/acts/act2.yml") + "\n"))
+			Expect(diff).To(ContainSubstring("+++ b" + path("clients/acts/act2.yml") + "\n"))
+			Expect(diff).To(ContainSubstring("-name: ACT2\n+name: ACT3\n"))
+		})
+	})
+})
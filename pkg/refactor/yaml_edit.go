@@ -0,0 +1,214 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A replacement of a scalar value at a specific position of a file.
+type scalarEdit struct {
+	Line   int
+	Column int
+	Old    string
+	New    string
+}
+
+func parseRootNode(content string) (*yaml.Node, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(content), doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	return doc.Content[0], nil
+}
+
+func getMappingKey(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
+
+func getMappingValue(m *yaml.Node, key string) *yaml.Node {
+	_, v := getMappingKey(m, key)
+	return v
+}
+
+func getSequence(m *yaml.Node, key string) []*yaml.Node {
+	v := getMappingValue(m, key)
+	if v == nil || v.Kind != yaml.SequenceNode {
+		return []*yaml.Node{}
+	}
+	return v.Content
+}
+
+func newScalarEdit(n *yaml.Node, value string) scalarEdit {
+	col := n.Column
+	if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		col++
+	}
+
+	return scalarEdit{
+		Line:   n.Line,
+		Column: col,
+		Old:    n.Value,
+		New:    value,
+	}
+}
+
+func applyScalarEdits(content string, edits []scalarEdit) (string, error) {
+	if len(edits) == 0 {
+		return content, nil
+	}
+
+	lines := strings.Split(content, "\n")
+
+	// Apply the edits from the end of the file to avoid to shift
+	// the columns of the other edits.
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].Line == edits[j].Line {
+			return edits[i].Column < edits[j].Column
+		}
+		return edits[i].Line < edits[j].Line
+	})
+
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		if e.Line < 1 || e.Line > len(lines) {
+			return "", errors.New(fmt.Sprintf("Invalid line %d", e.Line))
+		}
+
+		line := []rune(lines[e.Line-1])
+		start := e.Column - 1
+		old := []rune(e.Old)
+		if start < 0 || start+len(old) > len(line) || string(line[start:start+len(old)]) != e.Old {
+			return "", errors.New(fmt.Sprintf(
+				"Unexpected value at line %d column %d: %s not found",
+				e.Line, e.Column, e.Old))
+		}
+
+		lines[e.Line-1] = string(line[:start]) + e.New + string(line[start+len(old):])
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func lineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// Return the first and last line (0-based) of the block of the item
+// idx of the sequence seq and the indentation of the dash.
+func getSeqItemBlock(lines []string, seq *yaml.Node, idx int) (int, int, int, error) {
+	if seq.Style&yaml.FlowStyle != 0 {
+		return 0, 0, 0, errors.New("Flow style sequences are not supported")
+	}
+
+	item := seq.Content[idx]
+	start := item.Line - 1
+	dashIndent := item.Column - 3
+
+	if dashIndent < 0 || start >= len(lines) ||
+		len(lines[start]) <= dashIndent || lines[start][dashIndent] != '-' {
+		return 0, 0, 0, errors.New(fmt.Sprintf(
+			"Unsupported format of the sequence item at line %d", item.Line))
+	}
+
+	end := start
+	if idx+1 < len(seq.Content) {
+		end = seq.Content[idx+1].Line - 2
+	} else {
+		for i := start + 1; i < len(lines); i++ {
+			if !isBlankLine(lines[i]) && lineIndent(lines[i]) <= dashIndent {
+				break
+			}
+			end = i
+		}
+	}
+
+	for end > start && isBlankLine(lines[end]) {
+		end--
+	}
+
+	return start, end, dashIndent, nil
+}
+
+func getLastContentLine(lines []string) int {
+	ans := len(lines) - 1
+	for ans > 0 && isBlankLine(lines[ans]) {
+		ans--
+	}
+	return ans
+}
+
+func reindentLines(lines []string, delta int) []string {
+	ans := []string{}
+	for _, l := range lines {
+		if isBlankLine(l) || delta == 0 {
+			ans = append(ans, l)
+		} else if delta > 0 {
+			ans = append(ans, strings.Repeat(" ", delta)+l)
+		} else {
+			remove := -delta
+			if lineIndent(l) < remove {
+				remove = lineIndent(l)
+			}
+			ans = append(ans, l[remove:])
+		}
+	}
+	return ans
+}
+
+// Replace the full name with the new name. The references to the
+// children of the name are updated too.
+func mapFullName(value, oldName, newName string) (string, bool) {
+	if value == oldName {
+		return newName, true
+	}
+	if strings.HasPrefix(value, oldName+".") {
+		return newName + value[len(oldName):], true
+	}
+	return value, false
+}
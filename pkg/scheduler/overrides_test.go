@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package scheduler_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/scheduler"
+	specs "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduler Overrides Test", func() {
+
+	config := initConfig()
+
+	Context("New resource and effort override", func() {
+
+		completed := true
+		scenario := &specs.Scenario{
+			Name:      "test",
+			Scheduler: "simple",
+			NowTime:   "2020-09-04",
+			Overrides: &specs.ScenarioOverrides{
+				Tasks: []specs.ScenarioTaskOverride{
+					{
+						Name:         "ACTIVITY1.TASK1",
+						Effort:       "4d",
+						AddResources: []string{"user2"},
+					},
+					{
+						Name:      "ACTIVITY1.TASK2",
+						Completed: &completed,
+					},
+				},
+				Resources: []specs.ScenarioResourceOverride{
+					{
+						User:          "user2",
+						AvailableFrom: "2020-09-09",
+					},
+				},
+			},
+		}
+
+		client := specs.NewClient("TEST1")
+		activity := specs.NewActivity("ACTIVITY1", "")
+		activity.AddTask(specs.NewTask("TASK1", "", "2d", []string{"user1"}))
+		activity.AddTask(specs.NewTask("TASK2", "", "2d", []string{"user1"}))
+		client.AddActivity(*activity)
+
+		scheduler := NewSimpleScheduler(config, scenario)
+		scheduler.Resources = []specs.Resource{
+			*specs.NewResource("user1", "User One"),
+		}
+		scheduler.Timesheets = []specs.AgendaTimesheets{}
+		scheduler.Clients = []specs.Client{*client}
+
+		prevision, err := scheduler.BuildPrevision(SchedulerOpts{})
+
+		It("Prevision", func() {
+			Expect(err).Should(BeNil())
+			Expect(len(prevision.Schedule)).To(Equal(2))
+
+			Expect(prevision.Schedule[0].Timesheets).To(Equal(
+				[]specs.ResourceTimesheet{
+					*specs.NewResourceTimesheet("user1", "2020-09-07", "ACTIVITY1.TASK1", "28800s"),
+					*specs.NewResourceTimesheet("user1", "2020-09-08", "ACTIVITY1.TASK1", "28800s"),
+					*specs.NewResourceTimesheet("user1", "2020-09-09", "ACTIVITY1.TASK1", "28800s"),
+					*specs.NewResourceTimesheet("user2", "2020-09-09", "ACTIVITY1.TASK1", "28800s"),
+				},
+			))
+			Expect(prevision.Schedule[1].Progress).To(Equal(100.0))
+			Expect(len(prevision.Schedule[1].Timesheets)).To(Equal(0))
+		})
+
+		It("Original data not modified", func() {
+			Expect(client.Activities[0].Tasks[0].Effort).To(Equal("2d"))
+			Expect(len(scheduler.Clients[0].Activities[0].Tasks[0].AllocatedResource)).To(Equal(1))
+		})
+	})
+})
@@ -75,6 +75,8 @@ type DefaultScheduler struct {
 	Scenario     *specs.ScenarioSchedule
 	taskMap      map[string]*specs.TaskScheduled
 	ResourcesMap map[string]*ResourceDailyMap
+
+	resourcesOverridden bool
 }
 
 type ResourceDailyMap struct {
@@ -88,9 +90,13 @@ func (s *DefaultScheduler) GetConfig() *specs.TimeMasterConfig { return s.Config
 func (s *DefaultScheduler) GetLogger() *log.TmLogger           { return s.Logger }
 
 func (s *DefaultScheduler) Init() error {
+	err := s.applyResourcesOverrides()
+	if err != nil {
+		return err
+	}
 	s.initResourceMap()
 	s.initializeTasks()
-	return nil
+	return s.applyTasksOverrides()
 }
 
 func (s *DefaultScheduler) initResourceMap() {
@@ -447,6 +453,113 @@ func (s *DefaultScheduler) initializeTasks() {
 
 }
 
+// Apply the overrides of the scenario to the resources. The resources
+// are cloned to avoid changes on the loaded data.
+func (s *DefaultScheduler) applyResourcesOverrides() error {
+	overrides := s.Scenario.Scenario.GetOverrides()
+
+	if s.resourcesOverridden || len(overrides.Resources) == 0 {
+		return nil
+	}
+
+	resources, err := s.Scenario.Scenario.ApplyResourcesOverrides(s.Resources)
+	if err != nil {
+		return err
+	}
+	s.Logger.Debug(fmt.Sprintf("[%s] Applied overrides of %d resources.",
+		s.Scenario.Name, len(overrides.Resources)))
+
+	s.Resources = resources
+	s.resourcesOverridden = true
+
+	return nil
+}
+
+// Apply the overrides of the scenario to the tasks scheduled. The
+// activity overrides are applied before the task overrides.
+func (s *DefaultScheduler) applyTasksOverrides() error {
+	overrides := s.Scenario.Scenario.GetOverrides()
+
+	for _, ao := range overrides.Activities {
+		for idx, ts := range s.Scenario.Schedule {
+			if ts.Activity.Name != ao.Name {
+				continue
+			}
+
+			err := s.applyTaskOverride(&s.Scenario.Schedule[idx], ao.Completed,
+				"", ao.StartPeriod, ao.Resources, ao.AddResources)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, to := range overrides.Tasks {
+		ts, ok := s.taskMap[to.Name]
+		if !ok {
+			s.Logger.Debug(fmt.Sprintf("[%s] Task %s of the override not available.",
+				s.Scenario.Name, to.Name))
+			continue
+		}
+
+		err := s.applyTaskOverride(ts, to.Completed, to.Effort, to.StartPeriod,
+			to.Resources, to.AddResources)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *DefaultScheduler) applyTaskOverride(ts *specs.TaskScheduled,
+	completed *bool, effort, start string, resources, addResources []string) error {
+
+	if effort != "" {
+		_, err := time.ParseDuration(effort, s.Config.GetWork().WorkHours)
+		if err != nil {
+			return errors.New(fmt.Sprintf("[%s] Invalid effort override %s: %s",
+				ts.Task.Name, effort, err.Error()))
+		}
+		ts.Task.Effort = effort
+	}
+
+	if completed != nil {
+		ts.Task.Completed = *completed
+		if *completed {
+			ts.Progress = 100.0
+		} else {
+			ts.Progress = 0
+		}
+	}
+
+	if start != "" {
+		_, err := time.ParseTimestamp(start, true)
+		if err != nil {
+			return errors.New(fmt.Sprintf("[%s] Invalid start period override %s: %s",
+				ts.Task.Name, start, err.Error()))
+		}
+		// The start period is replaced by the timesheets if available.
+		ts.Period.StartPeriod = start
+	}
+
+	if len(resources) > 0 {
+		ts.Task.AllocatedResource = append([]string{}, resources...)
+	}
+
+	if len(addResources) > 0 {
+		allocated := append([]string{}, ts.Task.AllocatedResource...)
+		for _, r := range addResources {
+			if !ts.Task.HasResource(r) {
+				allocated = append(allocated, r)
+			}
+		}
+		ts.Task.AllocatedResource = allocated
+	}
+
+	return nil
+}
+
 func (s *DefaultScheduler) elaborateTimesheets(withPlan bool, opts SchedulerOpts) error {
 
 	// 1. Elaborate timesheet and calculate start / end of the task with effort.
@@ -82,6 +82,17 @@ func (s *SimpleScheduler) BuildPrevision(opts SchedulerOpts) (*specs.ScenarioSch
 
 	s.initializeTasks()
 
+	// Apply the overrides of the scenario over the cloned data
+	err = s.applyResourcesOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.applyTasksOverrides()
+	if err != nil {
+		return nil, err
+	}
+
 	// Assign resource timesheet to task scheduled
 	err = s.assignTimesheets()
 	if err != nil {
@@ -20,6 +20,10 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package specs
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"gopkg.in/yaml.v2"
 )
 
@@ -33,6 +37,37 @@ func AgengaTimesheetFromYaml(data []byte, file string) (*AgendaTimesheets, error
 	return ans, nil
 }
 
+func AgendaTimesheetsFromFile(file string) (*AgendaTimesheets, error) {
+	fileAbs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(fileAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	return AgengaTimesheetFromYaml(content, file)
+}
+
+func (a *AgendaTimesheets) Write2File(f string) error {
+	data, err := yaml.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	dirName := filepath.Dir(f)
+	if _, serr := os.Stat(dirName); serr != nil {
+		err = os.MkdirAll(dirName, os.ModePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(f, data, 0644)
+}
+
 func (a *AgendaTimesheets) AddResourceTimesheet(rt *ResourceTimesheet) {
 	a.Timesheets = append(a.Timesheets, *rt)
 }
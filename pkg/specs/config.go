@@ -38,6 +38,13 @@ type TimeMasterConfig struct {
 
 	Work TimeMasterConfigWork `mapstructure:"work,omitempty" json:"work,omitempty" yaml:"work,omitempty"`
 
+	Tracker TimeMasterConfigTracker `mapstructure:"tracker,omitempty" json:"tracker,omitempty" yaml:"tracker,omitempty"`
+
+	Jira  TimeMasterConfigJira  `mapstructure:"jira,omitempty" json:"jira,omitempty" yaml:"jira,omitempty"`
+	Kimai TimeMasterConfigKimai `mapstructure:"kimai,omitempty" json:"kimai,omitempty" yaml:"kimai,omitempty"`
+
+	Invoice TimeMasterConfigInvoice `mapstructure:"invoice,omitempty" json:"invoice,omitempty" yaml:"invoice,omitempty"`
+
 	ClientsDirs []string `mapstructure:"clients_dirs,omitempty" json:"clients_dirs,omitempty" yaml:"clients_dirs,omitempty"`
 
 	ResourcesDirs []string `mapstructure:"resources_dirs,omitempty" json:"resources_dirs,omitempty" yaml:"resources_dirs,omitempty"`
@@ -72,6 +79,67 @@ type TimeMasterConfigWork struct {
 	// Default number of hours for day
 	WorkHours           int `mapstructure:"work_hours,omitempty" json:"work_hours,omitempty" yaml:"work_hours,omitempty"`
 	TaskDefaultPriority int `mapstructure:"task_default_priority,omitempty" json:"task_default_priority,omitempty" yaml:"task_default_priority,omitempty"`
+	// Currency symbol of the costs and the offers in the spreadsheets
+	Currency string `mapstructure:"currency,omitempty" json:"currency,omitempty" yaml:"currency,omitempty"`
+}
+
+type TimeMasterConfigTracker struct {
+	// Path of the file where store the running timers
+	StateFile string `mapstructure:"state_file,omitempty" json:"state_file,omitempty" yaml:"state_file,omitempty"`
+	// Directory where write the timesheets of the stopped timers.
+	// If empty it's used the first directory of timesheets_dirs.
+	TimesheetsDir string `mapstructure:"timesheets_dir,omitempty" json:"timesheets_dir,omitempty" yaml:"timesheets_dir,omitempty"`
+	// Unit used to round the elapsed time (ex. 15m). Empty means no rounding.
+	Rounding string `mapstructure:"rounding,omitempty" json:"rounding,omitempty" yaml:"rounding,omitempty"`
+	// Rounding mode: up | down | nearest
+	RoundingMode string `mapstructure:"rounding_mode,omitempty" json:"rounding_mode,omitempty" yaml:"rounding_mode,omitempty"`
+}
+
+type TimeMasterConfigJira struct {
+	// Base url of the Jira instance (ex. https://myorg.atlassian.net)
+	Url string `mapstructure:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	// User used with the API token for the basic authentication.
+	// If empty the token is used as bearer token.
+	User string `mapstructure:"user,omitempty" json:"user,omitempty" yaml:"user,omitempty"`
+	// API token. It could be set with the env variable TM_JIRA__TOKEN.
+	Token string `mapstructure:"token,omitempty" json:"token,omitempty" yaml:"token,omitempty"`
+	// Number of elements retrieved for every request.
+	PageSize int `mapstructure:"page_size,omitempty" json:"page_size,omitempty" yaml:"page_size,omitempty"`
+	// Number of retries when the rate limit is reached.
+	MaxRetries int `mapstructure:"max_retries,omitempty" json:"max_retries,omitempty" yaml:"max_retries,omitempty"`
+	// Type of the Jira instance: cloud or server (Server and Data
+	// Center). If empty it's cloud for the urls of atlassian.net.
+	Deployment string `mapstructure:"deployment,omitempty" json:"deployment,omitempty" yaml:"deployment,omitempty"`
+}
+
+type TimeMasterConfigKimai struct {
+	// Base url of the Kimai instance (ex. https://kimai.myorg.com)
+	Url string `mapstructure:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	// User for the authentication with the X-AUTH headers of the
+	// old Kimai releases. If empty the token is used as bearer token.
+	User string `mapstructure:"user,omitempty" json:"user,omitempty" yaml:"user,omitempty"`
+	// API token. It could be set with the env variable TM_KIMAI__TOKEN.
+	Token string `mapstructure:"token,omitempty" json:"token,omitempty" yaml:"token,omitempty"`
+	// Number of elements retrieved for every request.
+	PageSize int `mapstructure:"page_size,omitempty" json:"page_size,omitempty" yaml:"page_size,omitempty"`
+	// Number of retries when the rate limit is reached.
+	MaxRetries int `mapstructure:"max_retries,omitempty" json:"max_retries,omitempty" yaml:"max_retries,omitempty"`
+}
+
+type TimeMasterConfigInvoice struct {
+	// Directory where are stored the issued invoices.
+	Dir string `mapstructure:"dir,omitempty" json:"dir,omitempty" yaml:"dir,omitempty"`
+	// Prefix of the invoice numbers (ex. INV-).
+	NumberPrefix string `mapstructure:"number_prefix,omitempty" json:"number_prefix,omitempty" yaml:"number_prefix,omitempty"`
+	// Default VAT percentage applied to the invoices.
+	Vat float64 `mapstructure:"vat,omitempty" json:"vat,omitempty" yaml:"vat,omitempty"`
+	// Unit used to round the billed time of every day (ex. 30m).
+	// Empty means no rounding.
+	Rounding string `mapstructure:"rounding,omitempty" json:"rounding,omitempty" yaml:"rounding,omitempty"`
+	// Rounding mode: up | down | nearest
+	RoundingMode string `mapstructure:"rounding_mode,omitempty" json:"rounding_mode,omitempty" yaml:"rounding_mode,omitempty"`
+	// Issuer details printed on the invoices.
+	Issuer string `mapstructure:"issuer,omitempty" json:"issuer,omitempty" yaml:"issuer,omitempty"`
 }
 
 func NewTimeMasterConfig(viper *v.Viper) *TimeMasterConfig {
@@ -87,6 +155,22 @@ func (c *TimeMasterConfig) GetWork() *TimeMasterConfigWork {
 	return &c.Work
 }
 
+func (c *TimeMasterConfig) GetTracker() *TimeMasterConfigTracker {
+	return &c.Tracker
+}
+
+func (c *TimeMasterConfig) GetJira() *TimeMasterConfigJira {
+	return &c.Jira
+}
+
+func (c *TimeMasterConfig) GetKimai() *TimeMasterConfigKimai {
+	return &c.Kimai
+}
+
+func (c *TimeMasterConfig) GetInvoice() *TimeMasterConfigInvoice {
+	return &c.Invoice
+}
+
 func (c *TimeMasterConfig) GetGeneral() *TimeMasterConfigGeneral {
 	return &c.General
 }
@@ -135,6 +219,32 @@ func GenDefault(viper *v.Viper) {
 
 	viper.SetDefault("work.work_hours", 8)
 	viper.SetDefault("work.task_default_priority", 100)
+	viper.SetDefault("work.currency", "")
+
+	viper.SetDefault("tracker.state_file", ".time-master.tracker")
+	viper.SetDefault("tracker.timesheets_dir", "")
+	viper.SetDefault("tracker.rounding", "15m")
+	viper.SetDefault("tracker.rounding_mode", "up")
+
+	viper.SetDefault("jira.url", "")
+	viper.SetDefault("jira.user", "")
+	viper.SetDefault("jira.token", "")
+	viper.SetDefault("jira.page_size", 50)
+	viper.SetDefault("jira.max_retries", 5)
+	viper.SetDefault("jira.deployment", "")
+
+	viper.SetDefault("kimai.url", "")
+	viper.SetDefault("kimai.user", "")
+	viper.SetDefault("kimai.token", "")
+	viper.SetDefault("kimai.page_size", 100)
+	viper.SetDefault("kimai.max_retries", 5)
+
+	viper.SetDefault("invoice.dir", "./invoices")
+	viper.SetDefault("invoice.number_prefix", "")
+	viper.SetDefault("invoice.vat", 0)
+	viper.SetDefault("invoice.rounding", "")
+	viper.SetDefault("invoice.rounding_mode", "up")
+	viper.SetDefault("invoice.issuer", "")
 
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.enable_logfile", false)
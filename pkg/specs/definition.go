@@ -34,6 +34,9 @@ type Scenario struct {
 	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 	File        string `json:"-" yaml:"-"`
 
+	// Name of the parent scenario
+	Extends string `json:"extends,omitempty" yaml:"extends,omitempty"`
+
 	ResourceCosts []ResourceCost `json:"resources_cost,omitempty" yaml:"resources_cost,omitempty"`
 	Rates         []ResourceRate `json:"rates,omitempty" yaml:"rates,omitempty"`
 
@@ -44,6 +47,57 @@ type Scenario struct {
 	// For scheduler simple
 	Tasks      []ScenarioTask     `json:"task_prorities,omitempty" yaml:"task_prorities,omitempty"`
 	Activities []ScenarioActivity `json:"activities_priorities,omitempty" yaml:"activities_priorities,omitempty"`
+
+	Overrides *ScenarioOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// ScenarioOverrides contains the changes applied to the data
+// only for the prevision of the scenario.
+type ScenarioOverrides struct {
+	Tasks      []ScenarioTaskOverride     `json:"tasks,omitempty" yaml:"tasks,omitempty"`
+	Activities []ScenarioActivityOverride `json:"activities,omitempty" yaml:"activities,omitempty"`
+	Resources  []ScenarioResourceOverride `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+type ScenarioTaskOverride struct {
+	Name        string `json:"name" yaml:"name"`
+	Effort      string `json:"effort,omitempty" yaml:"effort,omitempty"`
+	Completed   *bool  `json:"completed,omitempty" yaml:"completed,omitempty"`
+	StartPeriod string `json:"start_period,omitempty" yaml:"start_period,omitempty"`
+
+	Resources    []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	AddResources []string `json:"add_resources,omitempty" yaml:"add_resources,omitempty"`
+
+	// Daily cost and rate
+	Cost *float64 `json:"cost,omitempty" yaml:"cost,omitempty"`
+	Rate *float64 `json:"rate,omitempty" yaml:"rate,omitempty"`
+}
+
+type ScenarioActivityOverride struct {
+	Name        string `json:"name" yaml:"name"`
+	Completed   *bool  `json:"completed,omitempty" yaml:"completed,omitempty"`
+	StartPeriod string `json:"start_period,omitempty" yaml:"start_period,omitempty"`
+
+	Resources    []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	AddResources []string `json:"add_resources,omitempty" yaml:"add_resources,omitempty"`
+
+	// Daily cost and rate
+	Cost *float64 `json:"cost,omitempty" yaml:"cost,omitempty"`
+	Rate *float64 `json:"rate,omitempty" yaml:"rate,omitempty"`
+}
+
+// ScenarioResourceOverride permits to add availability constraints
+// to an existing resource or to define a new resource.
+type ScenarioResourceOverride struct {
+	User string `json:"user" yaml:"user"`
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	AvailableFrom string `json:"available_from,omitempty" yaml:"available_from,omitempty"`
+	AvailableTo   string `json:"available_to,omitempty" yaml:"available_to,omitempty"`
+
+	Holidays   []ResourceHolidays   `json:"holidays,omitempty" yaml:"holidays,omitempty"`
+	Sick       []ResourceSick       `json:"sick,omitempty" yaml:"sick,omitempty"`
+	Unemployed []ResourceUnemployed `json:"unemployed,omitempty" yaml:"unemployed,omitempty"`
 }
 
 type ScenarioTask struct {
@@ -62,7 +116,7 @@ type ScenarioSchedule struct {
 	*Scenario
 	File string `json:"-" yaml:"-"`
 
-	Schedule []TaskScheduled `json:schedule,omitempty yaml:"schedule,omitempty`
+	Schedule []TaskScheduled `json:"schedule,omitempty" yaml:"schedule,omitempty"`
 }
 
 type Activity struct {
@@ -190,6 +244,10 @@ type ResourceTimesheet struct {
 	Duration string `json:"duration" yaml:"duration"`
 	Note     string `json:"note,omitempty" yaml:"note,omitempty"`
 
+	// Source and id of the imported entry used on merge.
+	Source     string `json:"source,omitempty" yaml:"source,omitempty"`
+	ExternalId string `json:"external_id,omitempty" yaml:"external_id,omitempty"`
+
 	// Internal
 	Cost    float64 `json:"cost,omitempty" yaml:"cost,omitempty"`
 	Revenue float64 `json:"revenue,omitempty" yaml:"revenue,omitempty"`
@@ -48,7 +48,7 @@ type TimesheetReport struct {
 
 type TimesheetReportPerUser struct {
 	User         string                 `json:"user,omitempty" yaml:"user,omitempty"`
-	Events       []TimesheetReportEvent `json:"events,omitempty", yaml:"events,omitempty"`
+	Events       []TimesheetReportEvent `json:"events,omitempty" yaml:"events,omitempty"`
 	TotEffortSec int64                  `json:"tot_effort_sec,omitempty" yaml:"tot_effort_sec,omitempty"`
 	TotEffort    string                 `json:"tot_effort,omitempty" yaml:"tot_effort,omitempty"`
 }
@@ -138,3 +138,167 @@ func (s *Scenario) GetResourceRate4Date(dstr, resourceUser string) (float64, err
 
 	return ans, nil
 }
+
+// Extend returns a new scenario with the data of the parent scenario
+// merged. The entries of the scenario take precedence over the entries
+// of the parent.
+func (s *Scenario) Extend(parent *Scenario) *Scenario {
+	ans := &Scenario{
+		Name:        s.Name,
+		Description: s.Description,
+		File:        s.File,
+		Extends:     s.Extends,
+		NowTime:     s.NowTime,
+		Scheduler:   s.Scheduler,
+	}
+
+	if ans.Description == "" {
+		ans.Description = parent.Description
+	}
+	if ans.NowTime == "" {
+		ans.NowTime = parent.NowTime
+	}
+	if ans.Scheduler == "" {
+		ans.Scheduler = parent.Scheduler
+	}
+
+	// The first entry matched is used for costs, rates and priorities.
+	ans.ResourceCosts = append(append([]ResourceCost{}, s.ResourceCosts...),
+		parent.ResourceCosts...)
+	ans.Rates = append(append([]ResourceRate{}, s.Rates...), parent.Rates...)
+	ans.Tasks = append(append([]ScenarioTask{}, s.Tasks...), parent.Tasks...)
+	ans.Activities = append(append([]ScenarioActivity{}, s.Activities...),
+		parent.Activities...)
+
+	// The overrides are applied in order, so the parent entries are before.
+	if s.Overrides != nil || parent.Overrides != nil {
+		ans.Overrides = &ScenarioOverrides{}
+		for _, o := range []*ScenarioOverrides{parent.Overrides, s.Overrides} {
+			if o == nil {
+				continue
+			}
+			ans.Overrides.Tasks = append(ans.Overrides.Tasks, o.Tasks...)
+			ans.Overrides.Activities = append(ans.Overrides.Activities, o.Activities...)
+			ans.Overrides.Resources = append(ans.Overrides.Resources, o.Resources...)
+		}
+	}
+
+	return ans
+}
+
+func (s *Scenario) GetOverrides() *ScenarioOverrides {
+	if s.Overrides == nil {
+		return &ScenarioOverrides{}
+	}
+	return s.Overrides
+}
+
+// GetDailyCostOverride returns the daily cost defined for the task
+// or for the activity of the task or nil.
+func (s *Scenario) GetDailyCostOverride(task, activity string) *float64 {
+	o := s.GetOverrides()
+	for idx := len(o.Tasks) - 1; idx >= 0; idx-- {
+		if o.Tasks[idx].Name == task && o.Tasks[idx].Cost != nil {
+			return o.Tasks[idx].Cost
+		}
+	}
+	for idx := len(o.Activities) - 1; idx >= 0; idx-- {
+		if o.Activities[idx].Name == activity && o.Activities[idx].Cost != nil {
+			return o.Activities[idx].Cost
+		}
+	}
+	return nil
+}
+
+// GetDailyRateOverride returns the daily rate defined for the task
+// or for the activity of the task or nil.
+func (s *Scenario) GetDailyRateOverride(task, activity string) *float64 {
+	o := s.GetOverrides()
+	for idx := len(o.Tasks) - 1; idx >= 0; idx-- {
+		if o.Tasks[idx].Name == task && o.Tasks[idx].Rate != nil {
+			return o.Tasks[idx].Rate
+		}
+	}
+	for idx := len(o.Activities) - 1; idx >= 0; idx-- {
+		if o.Activities[idx].Name == activity && o.Activities[idx].Rate != nil {
+			return o.Activities[idx].Rate
+		}
+	}
+	return nil
+}
+
+// ApplyResourcesOverrides returns a copy of the resources with the
+// overrides of the scenario. The resources defined only on the
+// overrides are added.
+func (s *Scenario) ApplyResourcesOverrides(resources []Resource) ([]Resource, error) {
+	ans := make([]Resource, len(resources))
+	copy(ans, resources)
+
+	for _, ro := range s.GetOverrides().Resources {
+		var r *Resource
+
+		for idx := range ans {
+			if ans[idx].User == ro.User {
+				r = &ans[idx]
+				break
+			}
+		}
+
+		if r == nil {
+			// POST: new resource available only for the scenario
+			ans = append(ans, Resource{
+				User: ro.User,
+				Name: ro.User,
+			})
+			r = &ans[len(ans)-1]
+		}
+
+		err := ro.Apply(r)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf(
+				"Error on apply override of the resource %s: %s", ro.User, err.Error()))
+		}
+	}
+
+	return ans, nil
+}
+
+// Apply the override to the resource. The resource passed
+// must be a copy of the original resource.
+func (o *ScenarioResourceOverride) Apply(r *Resource) error {
+	if o.Name != "" {
+		r.Name = o.Name
+	}
+
+	// Copy slices to avoid changes on the original resource
+	r.Holidays = append(append([]ResourceHolidays{}, r.Holidays...), o.Holidays...)
+	r.Sick = append(append([]ResourceSick{}, r.Sick...), o.Sick...)
+	r.Unemployed = append(append([]ResourceUnemployed{}, r.Unemployed...), o.Unemployed...)
+
+	if o.AvailableFrom != "" {
+		from, err := time.ParseTimestamp(o.AvailableFrom, true)
+		if err != nil {
+			return err
+		}
+		r.AddUnemployed(ResourceUnemployed{
+			Period: &Period{
+				StartPeriod: "1970-01-01",
+				EndPeriod:   from.AddDate(0, 0, -1).Format("2006-01-02"),
+			},
+		})
+	}
+
+	if o.AvailableTo != "" {
+		to, err := time.ParseTimestamp(o.AvailableTo, true)
+		if err != nil {
+			return err
+		}
+		r.AddUnemployed(ResourceUnemployed{
+			Period: &Period{
+				StartPeriod: to.AddDate(0, 0, 1).Format("2006-01-02"),
+			},
+		})
+	}
+
+	return nil
+}
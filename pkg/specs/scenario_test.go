@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package specs_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/specs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scenario Test", func() {
+
+	Context("Extends", func() {
+
+		rate := float64(300)
+		parent := &Scenario{
+			Name:    "base",
+			NowTime: "2026-01-01",
+			Tasks: []ScenarioTask{
+				{Name: "ACT1.task1", Priority: 10},
+			},
+			Overrides: &ScenarioOverrides{
+				Tasks: []ScenarioTaskOverride{
+					{Name: "ACT1.task1", Effort: "5d"},
+				},
+			},
+		}
+
+		child := &Scenario{
+			Name:    "whatif",
+			Extends: "base",
+			Tasks: []ScenarioTask{
+				{Name: "ACT1.task1", Priority: 1},
+			},
+			Overrides: &ScenarioOverrides{
+				Activities: []ScenarioActivityOverride{
+					{Name: "ACT1", Rate: &rate},
+				},
+			},
+		}
+
+		s := child.Extend(parent)
+
+		It("Merge", func() {
+			Expect(s.Name).To(Equal("whatif"))
+			Expect(s.NowTime).To(Equal("2026-01-01"))
+			Expect(len(s.Tasks)).To(Equal(2))
+			Expect(s.Tasks[0].Priority).To(Equal(1))
+			Expect(len(s.GetOverrides().Tasks)).To(Equal(1))
+			Expect(*s.GetDailyRateOverride("ACT1.task1", "ACT1")).To(Equal(rate))
+			Expect(s.GetDailyCostOverride("ACT1.task1", "ACT1")).Should(BeNil())
+		})
+	})
+
+	Context("Resource override", func() {
+
+		It("Available from", func() {
+			r := NewResource("dev2", "Developer Two")
+			o := &ScenarioResourceOverride{
+				User:          "dev2",
+				AvailableFrom: "2026-03-02",
+			}
+
+			err := o.Apply(r)
+			Expect(err).Should(BeNil())
+
+			available, err := r.IsAvailable("2026-02-27")
+			Expect(err).Should(BeNil())
+			Expect(available).Should(Equal(false))
+			available, err = r.IsAvailable("2026-03-02")
+			Expect(err).Should(BeNil())
+			Expect(available).Should(Equal(true))
+		})
+	})
+})
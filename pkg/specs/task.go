@@ -194,6 +194,16 @@ func (t *Task) HasFlag(flag string) bool {
 	return false
 }
 
+func (t *Task) HasResource(user string) bool {
+	for _, r := range t.AllocatedResource {
+		if r == user {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (t *Task) HasLabelKey(key string) bool {
 	for k := range t.Labels {
 		if k == key {
@@ -215,3 +215,76 @@ func GetNextMonthFirstWorkDay(dstr string) (string, error) {
 	return fmt.Sprintf("%d-%02d-%02d",
 		nextDay.Year(), nextDay.Month(), nextDay.Day()), nil
 }
+
+// Round seconds to a multiple of unit. Supported modes: up | down | nearest
+func RoundSeconds(sec, unit int64, mode string) (int64, error) {
+	if unit <= 0 || sec%unit == 0 {
+		return sec, nil
+	}
+
+	down := (sec / unit) * unit
+
+	switch mode {
+	case "down":
+		return down, nil
+	case "up", "":
+		return down + unit, nil
+	case "nearest":
+		if sec-down >= unit-(sec-down) {
+			return down + unit, nil
+		}
+		return down, nil
+	default:
+		return -1, errors.New("Invalid rounding mode " + mode)
+	}
+}
+
+// Convert seconds to the canonical duration string. If days is true
+// and the seconds are a multiple of half work day the duration is
+// expressed in days (1d, 1.5d), otherwise in hours and minutes (1h30m).
+func Seconds2CanonicalDuration(sec int64, workHours int, days bool) (string, error) {
+	if sec <= 0 {
+		return "", errors.New("Seconds must be greather then 0")
+	}
+
+	daySecs := int64(workHours) * 60 * 60
+	if days && daySecs > 0 && sec >= daySecs && (sec*2)%daySecs == 0 {
+		if sec%daySecs == 0 {
+			return fmt.Sprintf("%dd", sec/daySecs), nil
+		}
+		return fmt.Sprintf("%d.5d", sec/daySecs), nil
+	}
+
+	ans := ""
+	if sec/3600 > 0 {
+		ans = fmt.Sprintf("%dh", sec/3600)
+	}
+	if (sec%3600)/60 > 0 {
+		ans += fmt.Sprintf("%dm", (sec%3600)/60)
+	}
+	if sec%60 > 0 {
+		ans += fmt.Sprintf("%ds", sec%60)
+	}
+
+	return ans, nil
+}
+
+// Split the period in a period for every day. The periods that cross
+// the midnight are splitted at the midnight of the location of start.
+func SplitDays(start, end time.Time) [][2]time.Time {
+	ans := [][2]time.Time{}
+
+	for start.Before(end) {
+		y, m, d := start.Date()
+		midnight := time.Date(y, m, d+1, 0, 0, 0, 0, start.Location())
+		if !midnight.Before(end) {
+			ans = append(ans, [2]time.Time{start, end})
+			break
+		}
+
+		ans = append(ans, [2]time.Time{start, midnight})
+		start = midnight
+	}
+
+	return ans
+}
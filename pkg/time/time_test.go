@@ -220,4 +220,84 @@ var _ = Describe("Time Test", func() {
 		})
 
 	})
+
+	Context("Round seconds", func() {
+
+		It("Round up 10m to 15m", func() {
+			sec, err := RoundSeconds(int64(60*10), int64(60*15), "up")
+			Expect(err).Should(BeNil())
+			Expect(sec).To(Equal(int64(60 * 15)))
+		})
+
+		It("Round down 20m to 15m", func() {
+			sec, err := RoundSeconds(int64(60*20), int64(60*15), "down")
+			Expect(err).Should(BeNil())
+			Expect(sec).To(Equal(int64(60 * 15)))
+		})
+
+		It("Round nearest 23m to 30m", func() {
+			sec, err := RoundSeconds(int64(60*23), int64(60*15), "nearest")
+			Expect(err).Should(BeNil())
+			Expect(sec).To(Equal(int64(60 * 30)))
+		})
+
+		It("Invalid mode", func() {
+			_, err := RoundSeconds(int64(60*23), int64(60*15), "foo")
+			Expect(err).ShouldNot(BeNil())
+		})
+
+	})
+
+	Context("Canonical duration", func() {
+
+		It("Convert 8h to 1d", func() {
+			d, err := Seconds2CanonicalDuration(int64(60*60*8), 8, true)
+			Expect(err).Should(BeNil())
+			Expect(d).To(Equal("1d"))
+		})
+
+		It("Convert 12h to 1.5d", func() {
+			d, err := Seconds2CanonicalDuration(int64(60*60*12), 8, true)
+			Expect(err).Should(BeNil())
+			Expect(d).To(Equal("1.5d"))
+		})
+
+		It("Convert 10h", func() {
+			d, err := Seconds2CanonicalDuration(int64(60*60*10), 8, true)
+			Expect(err).Should(BeNil())
+			Expect(d).To(Equal("10h"))
+		})
+
+		It("Convert 45m", func() {
+			d, err := Seconds2CanonicalDuration(int64(60*45), 8, false)
+			Expect(err).Should(BeNil())
+			Expect(d).To(Equal("45m"))
+		})
+
+		It("Convert 1d to 8h without days", func() {
+			d, err := Seconds2CanonicalDuration(int64(60*60*8), 8, false)
+			Expect(err).Should(BeNil())
+			Expect(d).To(Equal("8h"))
+		})
+	})
+
+	Context("Split days", func() {
+
+		It("Split at midnight", func() {
+			start := time.Date(2026, 9, 1, 22, 30, 0, 0, time.UTC)
+			end := time.Date(2026, 9, 3, 1, 0, 0, 0, time.UTC)
+			Expect(SplitDays(start, end)).To(Equal([][2]time.Time{
+				{start, time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC)},
+				{time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC)},
+				{time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC), end},
+			}))
+		})
+
+		It("Period ended at midnight", func() {
+			start := time.Date(2026, 9, 1, 22, 0, 0, 0, time.UTC)
+			end := time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC)
+			Expect(SplitDays(start, end)).To(Equal([][2]time.Time{{start, end}}))
+			Expect(SplitDays(end, start)).To(BeEmpty())
+		})
+	})
 })
@@ -0,0 +1,220 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffOp struct {
+	Kind byte
+	Line string
+}
+
+// UnifiedDiff returns the differences between the two texts in the
+// unified format. An empty string is returned if the texts are equal.
+func UnifiedDiff(fromFile, toFile, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+	ops := myersDiff(aLines, bLines)
+
+	const context = 3
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromFile, toFile))
+
+	// Group operations in hunks with context lines.
+	idx := 0
+	aLine, bLine := 1, 1
+	for idx < len(ops) {
+		if ops[idx].Kind == ' ' {
+			idx++
+			aLine++
+			bLine++
+			continue
+		}
+
+		// Found a change. Go back for the context.
+		start := idx
+		ctx := 0
+		for start > 0 && ctx < context && ops[start-1].Kind == ' ' {
+			start--
+			ctx++
+		}
+		hunkA := aLine - ctx
+		hunkB := bLine - ctx
+
+		// Find the end of the hunk merging changes with near context.
+		end := idx
+		for end < len(ops) {
+			if ops[end].Kind != ' ' {
+				end++
+				continue
+			}
+			n := 0
+			for end+n < len(ops) && ops[end+n].Kind == ' ' {
+				n++
+			}
+			if end+n < len(ops) && n <= context*2 {
+				end += n
+				continue
+			}
+			if n > context {
+				n = context
+			}
+			end += n
+			break
+		}
+
+		nA, nB := 0, 0
+		lines := []string{}
+		for _, op := range ops[start:end] {
+			switch op.Kind {
+			case ' ':
+				nA++
+				nB++
+			case '-':
+				nA++
+			case '+':
+				nB++
+			}
+			lines = append(lines, string(op.Kind)+op.Line)
+		}
+
+		// An empty range starts on the line before.
+		if nA == 0 {
+			hunkA--
+		}
+		if nB == 0 {
+			hunkB--
+		}
+
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", hunkA, nA, hunkB, nB))
+		for _, l := range lines {
+			sb.WriteString(l + "\n")
+		}
+
+		// Update counters to the end of the hunk.
+		for _, op := range ops[idx:end] {
+			switch op.Kind {
+			case ' ':
+				aLine++
+				bLine++
+			case '-':
+				aLine++
+			case '+':
+				bLine++
+			}
+		}
+		idx = end
+	}
+
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Implementation of the Myers algorithm.
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	trace := [][]int{}
+
+	for d := 0; d <= max; d++ {
+		vc := make([]int, len(v))
+		copy(vc, v)
+		trace = append(trace, vc)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return myersBacktrack(a, b, trace, d, offset)
+			}
+		}
+	}
+
+	return []diffOp{}
+}
+
+func myersBacktrack(a, b []string, trace [][]int, d, offset int) []diffOp {
+	ans := []diffOp{}
+	x, y := len(a), len(b)
+
+	for ; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ans = append(ans, diffOp{Kind: ' ', Line: a[x]})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				ans = append(ans, diffOp{Kind: '+', Line: b[y]})
+			} else {
+				x--
+				ans = append(ans, diffOp{Kind: '-', Line: a[x]})
+			}
+		}
+	}
+
+	// Reverse the operations
+	for i, j := 0, len(ans)-1; i < j; i, j = i+1, j-1 {
+		ans[i], ans[j] = ans[j], ans[i]
+	}
+
+	return ans
+}
@@ -0,0 +1,52 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package tools_test
+
+import (
+	. "github.com/geaaru/time-master/pkg/tools"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff Test", func() {
+
+	Context("UnifiedDiff", func() {
+
+		It("Equal texts", func() {
+			Expect(UnifiedDiff("a/f", "b/f", "x\ny\n", "x\ny\n")).To(Equal(""))
+		})
+
+		It("Hunks with context", func() {
+			a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+			b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+
+			Expect(UnifiedDiff("a/f", "b/f", a, b)).To(Equal(
+				"--- a/f\n+++ b/f\n" +
+					"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+					"@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n"))
+		})
+
+		It("New file", func() {
+			Expect(UnifiedDiff("a/f", "b/f", "", "x\n")).To(Equal(
+				"--- a/f\n+++ b/f\n@@ -0,0 +1,1 @@\n+x\n"))
+		})
+	})
+})
@@ -0,0 +1,59 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package tools_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/geaaru/time-master/pkg/tools"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lock", func() {
+
+	It("Exclusive lock", func() {
+		tmpDir, err := ioutil.TempDir("", "tm-lock")
+		Expect(err).Should(BeNil())
+		defer os.RemoveAll(tmpDir)
+
+		file := filepath.Join(tmpDir, ".lock")
+		lock, err := LockFile(file)
+		Expect(err).Should(BeNil())
+		Expect(Exists(file)).To(Equal(true))
+
+		acquired := make(chan bool)
+		go func() {
+			defer GinkgoRecover()
+			l, err := LockFile(file)
+			Expect(err).Should(BeNil())
+			acquired <- true
+			l.Unlock()
+		}()
+
+		Consistently(acquired, 200*time.Millisecond).ShouldNot(Receive())
+		Expect(lock.Unlock()).Should(BeNil())
+		Eventually(acquired, time.Second).Should(Receive())
+	})
+})
@@ -0,0 +1,57 @@
+//go:build windows
+
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package tools
+
+import (
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// LockFile creates the file if it doesn't exist and acquires an
+// exclusive lock over it with LockFileEx. The lock is blocking so
+// concurrent invocations wait until the lock is released.
+func LockFile(path string) (*FileLock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK,
+		0, math.MaxUint32, math.MaxUint32, &windows.Overlapped{})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &FileLock{File: f}, nil
+}
+
+// Unlock releases the lock and closes the file.
+func (l *FileLock) Unlock() error {
+	err := windows.UnlockFileEx(windows.Handle(l.File.Fd()), 0,
+		math.MaxUint32, math.MaxUint32, &windows.Overlapped{})
+	l.File.Close()
+	return err
+}
@@ -0,0 +1,397 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/geaaru/time-master/pkg/logger"
+	specs "github.com/geaaru/time-master/pkg/specs"
+	tmtime "github.com/geaaru/time-master/pkg/time"
+	"github.com/geaaru/time-master/pkg/tools"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	TRACKER_TIME_LAYOUT = "2006-01-02 15:04:05"
+	TRACKER_SOURCE      = "tracker"
+)
+
+// TmTracker handles the running timers of the users. The state is stored
+// in a YAML file protected by an exclusive lock.
+type TmTracker struct {
+	Logger    *log.TmLogger
+	Config    *specs.TimeMasterConfig
+	StateFile string
+
+	lock *tools.FileLock
+}
+
+type TrackerState struct {
+	Timers []TrackerTimer `json:"timers,omitempty" yaml:"timers,omitempty"`
+}
+
+type TrackerTimer struct {
+	User  string `json:"user" yaml:"user"`
+	Task  string `json:"task" yaml:"task"`
+	Start string `json:"start" yaml:"start"`
+	Note  string `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+func NewTmTracker(config *specs.TimeMasterConfig) *TmTracker {
+	ans := &TmTracker{
+		Config:    config,
+		Logger:    log.NewTmLogger(config),
+		StateFile: config.GetTracker().StateFile,
+	}
+
+	// Initialize logging
+	if config.GetLogging().EnableLogFile && config.GetLogging().Path != "" {
+		err := ans.Logger.InitLogger2File()
+		if err != nil {
+			ans.Logger.Fatal("Error on initialize logfile")
+		}
+	}
+
+	return ans
+}
+
+// Lock acquires an exclusive lock over the state file. The lock is
+// blocking so concurrent invocations wait until the lock is released.
+func (t *TmTracker) Lock() error {
+	if t.lock != nil {
+		return errors.New("Tracker state already locked")
+	}
+
+	var err error
+	t.lock, err = tools.LockFile(t.StateFile + ".lock")
+	return err
+}
+
+func (t *TmTracker) Unlock() error {
+	if t.lock == nil {
+		return nil
+	}
+
+	err := t.lock.Unlock()
+	t.lock = nil
+
+	return err
+}
+
+func (t *TmTracker) LoadState() (*TrackerState, error) {
+	ans := &TrackerState{
+		Timers: []TrackerTimer{},
+	}
+
+	if !tools.Exists(t.StateFile) {
+		return ans, nil
+	}
+
+	content, err := ioutil.ReadFile(t.StateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(content, ans); err != nil {
+		return nil, err
+	}
+
+	return ans, nil
+}
+
+func (t *TmTracker) WriteState(s *TrackerState) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	// Write a temporary file and rename it to avoid a truncated state
+	// in case of errors.
+	tmpFile := t.StateFile + ".tmp"
+	err = ioutil.WriteFile(tmpFile, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, t.StateFile)
+}
+
+func (s *TrackerState) GetTimer(user string) *TrackerTimer {
+	for idx := range s.Timers {
+		if s.Timers[idx].User == user {
+			return &s.Timers[idx]
+		}
+	}
+	return nil
+}
+
+func (s *TrackerState) RemoveTimer(user string) {
+	timers := []TrackerTimer{}
+	for _, timer := range s.Timers {
+		if timer.User != user {
+			timers = append(timers, timer)
+		}
+	}
+	s.Timers = timers
+}
+
+func (tt *TrackerTimer) GetStartTime() (time.Time, error) {
+	return time.ParseInLocation(TRACKER_TIME_LAYOUT, tt.Start, time.Local)
+}
+
+// Start creates a new timer for the user. If a timer is already running
+// for the user it's stopped and the related timesheets are returned.
+func (t *TmTracker) Start(user, task, note string, now time.Time) ([]specs.ResourceTimesheet, error) {
+	var ans []specs.ResourceTimesheet
+
+	err := t.Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer t.Unlock()
+
+	state, err := t.LoadState()
+	if err != nil {
+		return nil, err
+	}
+
+	if timer := state.GetTimer(user); timer != nil {
+		if timer.Task == task {
+			return nil, errors.New(fmt.Sprintf(
+				"Timer for task %s already running since %s", task, timer.Start))
+		}
+
+		ans, err = t.stopTimer(state, timer, "", now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	state.Timers = append(state.Timers, TrackerTimer{
+		User:  user,
+		Task:  task,
+		Start: now.Format(TRACKER_TIME_LAYOUT),
+		Note:  note,
+	})
+
+	return ans, t.WriteState(state)
+}
+
+// Stop the running timer of the user and write the timesheets.
+func (t *TmTracker) Stop(user, note string, now time.Time) ([]specs.ResourceTimesheet, error) {
+	err := t.Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer t.Unlock()
+
+	state, err := t.LoadState()
+	if err != nil {
+		return nil, err
+	}
+
+	timer := state.GetTimer(user)
+	if timer == nil {
+		return nil, errors.New("No timer running for user " + user)
+	}
+
+	ans, err := t.stopTimer(state, timer, note, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return ans, t.WriteState(state)
+}
+
+func (t *TmTracker) Status(user string) (*TrackerTimer, error) {
+	err := t.Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer t.Unlock()
+
+	state, err := t.LoadState()
+	if err != nil {
+		return nil, err
+	}
+
+	return state.GetTimer(user), nil
+}
+
+func (t *TmTracker) stopTimer(state *TrackerState, timer *TrackerTimer, note string, now time.Time) ([]specs.ResourceTimesheet, error) {
+	if note != "" {
+		if timer.Note != "" {
+			timer.Note = timer.Note + " - " + note
+		} else {
+			timer.Note = note
+		}
+	}
+
+	ans, err := t.Timer2Timesheets(timer, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The timesheets have the id of the timer so if the state isn't
+	// saved the next stop replaces the timesheets already written.
+	if len(ans) > 0 {
+		err = t.WriteTimesheets(timer.User, ans)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	state.RemoveTimer(timer.User)
+
+	return ans, nil
+}
+
+// Timer2Timesheets converts the elapsed time of the timer to a list of
+// timesheets, one for every day covered by the timer. The elapsed time
+// is rounded before the split and the difference is assigned to the
+// last day.
+func (t *TmTracker) Timer2Timesheets(timer *TrackerTimer, now time.Time) ([]specs.ResourceTimesheet, error) {
+	ans := []specs.ResourceTimesheet{}
+
+	start, err := timer.GetStartTime()
+	if err != nil {
+		return nil, err
+	}
+
+	if !now.After(start) {
+		return ans, nil
+	}
+
+	unit := int64(0)
+	if t.Config.GetTracker().Rounding != "" {
+		unit, err = tmtime.ParseDuration(t.Config.GetTracker().Rounding,
+			t.Config.GetWork().WorkHours)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dates := []string{}
+	seconds := []int64{}
+	total := int64(0)
+	// ResourceTimesheet is per day. I split the timer at midnight.
+	for _, p := range tmtime.SplitDays(start, now) {
+		secs := int64(p[1].Sub(p[0]).Seconds())
+		dates = append(dates, p[0].Format("2006-01-02"))
+		seconds = append(seconds, secs)
+		total += secs
+	}
+
+	rounded, err := tmtime.RoundSeconds(total, unit, t.Config.GetTracker().RoundingMode)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := rounded - total
+	for idx := len(seconds) - 1; idx >= 0 && diff != 0; idx-- {
+		seconds[idx] += diff
+		diff = 0
+		if seconds[idx] < 0 {
+			diff = seconds[idx]
+			seconds[idx] = 0
+		}
+	}
+
+	for idx, date := range dates {
+		if seconds[idx] <= 0 {
+			continue
+		}
+
+		duration, err := tmtime.Seconds2Duration(seconds[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		rt := specs.NewResourceTimesheet(timer.User, date, timer.Task, duration)
+		rt.Note = timer.Note
+		rt.Source = TRACKER_SOURCE
+		rt.ExternalId = fmt.Sprintf("%s@%s@%s", timer.User, timer.Start, date)
+		ans = append(ans, *rt)
+	}
+
+	return ans, nil
+}
+
+func (t *TmTracker) GetTimesheetsFile(user string) string {
+	dir := t.Config.GetTracker().TimesheetsDir
+	if dir == "" && len(t.Config.GetTimesheetsDirs()) > 0 {
+		dir = t.Config.GetTimesheetsDirs()[0]
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("tracker-%s.yml", user))
+}
+
+func (t *TmTracker) WriteTimesheets(user string, rts []specs.ResourceTimesheet) error {
+	var agenda *specs.AgendaTimesheets
+	var err error
+
+	file := t.GetTimesheetsFile(user)
+
+	if tools.Exists(file) {
+		agenda, err = specs.AgendaTimesheetsFromFile(file)
+		if err != nil {
+			return err
+		}
+	} else {
+		agenda = &specs.AgendaTimesheets{
+			Name:       fmt.Sprintf("Tracker of %s", user),
+			Timesheets: []specs.ResourceTimesheet{},
+		}
+	}
+
+	// Replace the timesheets of the same timer already written.
+	ids := make(map[string]bool, 0)
+	for _, rt := range rts {
+		ids[rt.ExternalId] = true
+	}
+	timesheets := []specs.ResourceTimesheet{}
+	for _, rt := range agenda.Timesheets {
+		if rt.Source == TRACKER_SOURCE && ids[rt.ExternalId] {
+			continue
+		}
+		timesheets = append(timesheets, rt)
+	}
+	agenda.Timesheets = timesheets
+
+	for idx := range rts {
+		agenda.AddResourceTimesheet(&rts[idx])
+	}
+
+	err = agenda.Write2File(file)
+	if err != nil {
+		return err
+	}
+
+	t.Logger.Debug(fmt.Sprintf("Updated file %s.", file))
+
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package tracker_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	specs "github.com/geaaru/time-master/pkg/specs"
+	. "github.com/geaaru/time-master/pkg/tracker"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracker Test", func() {
+
+	var tmpDir string
+	var tracker *TmTracker
+
+	config := specs.NewTimeMasterConfig(nil)
+	config.GetWork().WorkHours = 8
+
+	day := func(hour, min int) time.Time {
+		return time.Date(2026, 9, 1, hour, min, 0, 0, time.Local)
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "tm-tracker")
+		Expect(err).Should(BeNil())
+
+		config.GetTracker().TimesheetsDir = tmpDir
+		config.GetTracker().Rounding = "15m"
+		config.GetTracker().RoundingMode = "up"
+
+		tracker = NewTmTracker(config)
+		tracker.StateFile = filepath.Join(tmpDir, "state.yml")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	Context("Timer2Timesheets", func() {
+
+		It("Rounding", func() {
+			rts, err := tracker.Timer2Timesheets(&TrackerTimer{
+				User:  "geaaru",
+				Task:  "ACT1.dev",
+				Start: "2026-09-01 09:00:00",
+				Note:  "Login",
+			}, day(10, 20))
+			Expect(err).Should(BeNil())
+			Expect(len(rts)).To(Equal(1))
+			Expect(rts[0].Period.StartPeriod).To(Equal("2026-09-01"))
+			Expect(rts[0].Duration).To(Equal("1h30m"))
+			Expect(rts[0].Note).To(Equal("Login"))
+			Expect(rts[0].Source).To(Equal(TRACKER_SOURCE))
+		})
+
+		It("Split at midnight with a single rounding", func() {
+			rts, err := tracker.Timer2Timesheets(&TrackerTimer{
+				User:  "geaaru",
+				Task:  "ACT1.dev",
+				Start: "2026-09-01 23:55:00",
+			}, time.Date(2026, 9, 2, 0, 5, 0, 0, time.Local))
+			Expect(err).Should(BeNil())
+			Expect(len(rts)).To(Equal(2))
+			Expect(rts[0].Period.StartPeriod).To(Equal("2026-09-01"))
+			Expect(rts[0].Duration).To(Equal("0h5m"))
+			Expect(rts[1].Period.StartPeriod).To(Equal("2026-09-02"))
+			Expect(rts[1].Duration).To(Equal("0h10m"))
+			Expect(rts[0].ExternalId).ToNot(Equal(rts[1].ExternalId))
+		})
+
+		It("Rounding down over midnight", func() {
+			config.GetTracker().RoundingMode = "down"
+			rts, err := tracker.Timer2Timesheets(&TrackerTimer{
+				User:  "geaaru",
+				Task:  "ACT1.dev",
+				Start: "2026-09-01 23:00:00",
+			}, time.Date(2026, 9, 2, 0, 10, 0, 0, time.Local))
+			Expect(err).Should(BeNil())
+			Expect(len(rts)).To(Equal(1))
+			Expect(rts[0].Period.StartPeriod).To(Equal("2026-09-01"))
+			Expect(rts[0].Duration).To(Equal("1h"))
+		})
+
+		It("Without rounding", func() {
+			config.GetTracker().Rounding = ""
+			rts, err := tracker.Timer2Timesheets(&TrackerTimer{
+				User:  "geaaru",
+				Task:  "ACT1.dev",
+				Start: "2026-09-01 09:00:00",
+			}, day(9, 7))
+			Expect(err).Should(BeNil())
+			Expect(rts[0].Duration).To(Equal("0h7m"))
+		})
+	})
+
+	Context("Timers", func() {
+
+		It("Start, switch and stop", func() {
+			rts, err := tracker.Start("geaaru", "ACT1.dev", "", day(9, 0))
+			Expect(err).Should(BeNil())
+			Expect(len(rts)).To(Equal(0))
+
+			_, err = tracker.Start("geaaru", "ACT1.dev", "", day(9, 10))
+			Expect(err).ShouldNot(BeNil())
+
+			// Switch to another task
+			rts, err = tracker.Start("geaaru", "ACT1.test", "", day(10, 0))
+			Expect(err).Should(BeNil())
+			Expect(len(rts)).To(Equal(1))
+			Expect(rts[0].Task).To(Equal("ACT1.dev"))
+
+			timer, err := tracker.Status("geaaru")
+			Expect(err).Should(BeNil())
+			Expect(timer.Task).To(Equal("ACT1.test"))
+
+			rts, err = tracker.Stop("geaaru", "Done", day(10, 40))
+			Expect(err).Should(BeNil())
+			Expect(rts[0].Duration).To(Equal("0h45m"))
+			Expect(rts[0].Note).To(Equal("Done"))
+
+			timer, err = tracker.Status("geaaru")
+			Expect(err).Should(BeNil())
+			Expect(timer).To(BeNil())
+
+			_, err = tracker.Stop("geaaru", "", day(11, 0))
+			Expect(err).ShouldNot(BeNil())
+
+			agenda, err := specs.AgendaTimesheetsFromFile(tracker.GetTimesheetsFile("geaaru"))
+			Expect(err).Should(BeNil())
+			Expect(len(agenda.Timesheets)).To(Equal(2))
+			Expect(agenda.Timesheets[0].Duration).To(Equal("1h"))
+			Expect(agenda.Timesheets[1].Task).To(Equal("ACT1.test"))
+		})
+
+		It("Write the timesheets of a timer only once", func() {
+			timer := &TrackerTimer{
+				User:  "geaaru",
+				Task:  "ACT1.dev",
+				Start: "2026-09-01 09:00:00",
+			}
+
+			rts, err := tracker.Timer2Timesheets(timer, day(10, 0))
+			Expect(err).Should(BeNil())
+			Expect(tracker.WriteTimesheets("geaaru", rts)).Should(BeNil())
+
+			// The state isn't saved and the timer is stopped again.
+			rts, err = tracker.Timer2Timesheets(timer, day(11, 0))
+			Expect(err).Should(BeNil())
+			Expect(tracker.WriteTimesheets("geaaru", rts)).Should(BeNil())
+
+			agenda, err := specs.AgendaTimesheetsFromFile(tracker.GetTimesheetsFile("geaaru"))
+			Expect(err).Should(BeNil())
+			Expect(len(agenda.Timesheets)).To(Equal(1))
+			Expect(agenda.Timesheets[0].Duration).To(Equal("2h"))
+		})
+
+		It("Lock", func() {
+			Expect(tracker.Lock()).Should(BeNil())
+			Expect(tracker.Lock()).ShouldNot(BeNil())
+			Expect(tracker.Unlock()).Should(BeNil())
+			Expect(tracker.Lock()).Should(BeNil())
+			Expect(tracker.Unlock()).Should(BeNil())
+		})
+	})
+})
@@ -0,0 +1,498 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Types of the cells. The type defines the number format of the cell.
+const (
+	CELL_STRING = iota
+	CELL_NUMBER
+	CELL_INTEGER
+	CELL_DATE
+	CELL_HOURS
+	CELL_CURRENCY
+	CELL_PERCENT
+
+	nCellTypes
+)
+
+const (
+	// Index of the style of the header cells.
+	styleHeader = 2 * nCellTypes
+
+	// Max length of the name of a sheet.
+	SHEET_NAME_MAXLEN = 31
+
+	nsMain = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRels = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsPkg  = "http://schemas.openxmlformats.org/package/2006/relationships"
+)
+
+// Cell of a sheet. The dates are stored as serial numbers, the hours,
+// the currencies and the percentages as numbers with a number format.
+type Cell struct {
+	Type    int
+	Value   string
+	Number  float64
+	Formula string
+	Bold    bool
+}
+
+// Sheet of the workbook. The first row is the header.
+type Sheet struct {
+	Name   string
+	Header []string
+	Rows   [][]Cell
+	// Number of the rows frozen on top. Default the header row.
+	FreezeRows int
+
+	// Index of the first row without a total row.
+	dataStart int
+}
+
+// Workbook is a xlsx file with one or more sheets.
+type Workbook struct {
+	Sheets []*Sheet
+	// Currency symbol of the currency cells.
+	Currency string
+}
+
+func NewWorkbook() *Workbook {
+	return &Workbook{
+		Sheets: []*Sheet{},
+	}
+}
+
+func String(s string) Cell  { return Cell{Type: CELL_STRING, Value: s} }
+func Number(f float64) Cell { return Cell{Type: CELL_NUMBER, Number: f} }
+func Integer(i int64) Cell  { return Cell{Type: CELL_INTEGER, Number: float64(i)} }
+
+// Hours returns the cell with the seconds as hours.
+func Hours(secs int64) Cell {
+	return Cell{Type: CELL_HOURS, Number: float64(secs) / 3600}
+}
+
+func Currency(f float64) Cell { return Cell{Type: CELL_CURRENCY, Number: f} }
+
+// Percent returns the cell with a percentage between 0 and 100.
+func Percent(f float64) Cell {
+	return Cell{Type: CELL_PERCENT, Number: f / 100}
+}
+
+// Date returns the cell with the date as serial number of the
+// 1900 date system.
+func Date(t time.Time) Cell {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return Cell{Type: CELL_DATE, Number: t.Sub(base).Hours() / 24, Value: t.Format("2006-01-02")}
+}
+
+// DateString returns the date cell of a date in the format YYYY-MM-DD.
+// The values in other formats (for example the months) are strings.
+func DateString(d string) Cell {
+	t, err := time.Parse("2006-01-02", d)
+	if err != nil {
+		return String(d)
+	}
+	return Date(t)
+}
+
+// GetSheetName returns a valid name for a sheet.
+func GetSheetName(name string) string {
+	ans := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	ans = strings.Trim(ans, "'")
+	if ans == "" {
+		ans = "Sheet"
+	}
+	if utf8.RuneCountInString(ans) > SHEET_NAME_MAXLEN {
+		ans = string([]rune(ans)[0:SHEET_NAME_MAXLEN])
+	}
+	return ans
+}
+
+// AddSheet adds a sheet with the header. The name of the sheet is
+// sanitized and made unique.
+func (w *Workbook) AddSheet(name string, header ...string) *Sheet {
+	base := GetSheetName(name)
+	ans := base
+	for n := 2; w.GetSheet(ans) != nil; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		runes := []rune(base)
+		if len(runes)+len(suffix) > SHEET_NAME_MAXLEN {
+			runes = runes[0 : SHEET_NAME_MAXLEN-len(suffix)]
+		}
+		ans = string(runes) + suffix
+	}
+
+	s := &Sheet{
+		Name:       ans,
+		Header:     header,
+		Rows:       [][]Cell{},
+		FreezeRows: 1,
+	}
+	w.Sheets = append(w.Sheets, s)
+	return s
+}
+
+// GetSheet returns the sheet with the name (case insensitive) or nil.
+func (w *Workbook) GetSheet(name string) *Sheet {
+	for _, s := range w.Sheets {
+		if strings.EqualFold(s.Name, name) {
+			return s
+		}
+	}
+	return nil
+}
+
+func (s *Sheet) AddRow(cells ...Cell) {
+	s.Rows = append(s.Rows, cells)
+}
+
+// AddTotalRow adds a bold row with the label on the first column and
+// the sum of the columns of the rows added after the previous total row.
+// The type of the sum is the type of the first cell of the column.
+func (s *Sheet) AddTotalRow(label string, columns ...int) {
+	ncols := len(s.Header)
+	for _, c := range columns {
+		if c+1 > ncols {
+			ncols = c + 1
+		}
+	}
+
+	row := make([]Cell, ncols)
+	row[0] = Cell{Type: CELL_STRING, Value: label}
+
+	// The first row of the sheet is the header.
+	first := s.dataStart + 2
+	last := len(s.Rows) + 1
+
+	for _, c := range columns {
+		cell := Cell{Type: CELL_NUMBER}
+		found := false
+		for _, r := range s.Rows[s.dataStart:] {
+			if c >= len(r) || r[c].Type == CELL_STRING {
+				continue
+			}
+			if !found {
+				cell.Type = r[c].Type
+				found = true
+			}
+			cell.Number += r[c].Number
+		}
+		if last >= first {
+			cell.Formula = fmt.Sprintf("SUM(%s%d:%s%d)",
+				GetColumnName(c), first, GetColumnName(c), last)
+		}
+		row[c] = cell
+	}
+
+	for idx := range row {
+		row[idx].Bold = true
+	}
+
+	s.Rows = append(s.Rows, row)
+	s.dataStart = len(s.Rows)
+}
+
+// GetColumnName returns the name of the column with the index
+// (0 is A, 26 is AA).
+func GetColumnName(idx int) string {
+	ans := ""
+	for idx++; idx > 0; idx = (idx - 1) / 26 {
+		ans = string(rune('A'+(idx-1)%26)) + ans
+	}
+	return ans
+}
+
+// Write writes the xlsx file.
+func (w *Workbook) Write(out io.Writer) error {
+	if len(w.Sheets) == 0 {
+		return errors.New("No sheets available")
+	}
+
+	z := zip.NewWriter(out)
+
+	files := []struct {
+		Name    string
+		Content []byte
+	}{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", []byte(xml.Header + `<Relationships xmlns="` + nsPkg + `">` +
+			`<Relationship Id="rId1" Type="` + nsRels + `/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`)},
+		{"xl/workbook.xml", w.workbook()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+		{"xl/styles.xml", w.styles()},
+	}
+
+	for idx, s := range w.Sheets {
+		files = append(files, struct {
+			Name    string
+			Content []byte
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", idx+1), s.marshal()})
+	}
+
+	for _, f := range files {
+		fw, err := z.CreateHeader(&zip.FileHeader{
+			Name:     f.Name,
+			Method:   zip.Deflate,
+			Modified: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.Content); err != nil {
+			return err
+		}
+	}
+
+	return z.Close()
+}
+
+// WriteFile writes the xlsx file on the path.
+func (w *Workbook) WriteFile(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := w.Write(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func (w *Workbook) contentTypes() []byte {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for idx := range w.Sheets {
+		b.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, idx+1))
+	}
+	b.WriteString(`</Types>`)
+	return []byte(b.String())
+}
+
+func (w *Workbook) workbook() []byte {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRels + `"><sheets>`)
+	for idx, s := range w.Sheets {
+		b.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`,
+			escape(s.Name), idx+1, idx+1))
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return []byte(b.String())
+}
+
+func (w *Workbook) workbookRels() []byte {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="` + nsPkg + `">`)
+	for idx := range w.Sheets {
+		b.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`,
+			idx+1, nsRels, idx+1))
+	}
+	b.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`,
+		len(w.Sheets)+1, nsRels))
+	b.WriteString(`</Relationships>`)
+	return []byte(b.String())
+}
+
+// Return the number format of the currency cells.
+func (w *Workbook) getCurrencyFormat() string {
+	if w.Currency == "" {
+		return "#,##0.00"
+	}
+	return `#,##0.00 "` + strings.ReplaceAll(w.Currency, `"`, "") + `"`
+}
+
+// The styles of the cells are a normal and a bold style for every type
+// of cell and the style of the header.
+func (w *Workbook) styles() []byte {
+	// Number formats of the types of cells. 164 and 165 are custom formats.
+	numFmts := []int{0, 0, 1, 164, 2, 165, 10}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<styleSheet xmlns="` + nsMain + `">`)
+	b.WriteString(`<numFmts count="2">`)
+	b.WriteString(`<numFmt numFmtId="164" formatCode="yyyy-mm-dd"/>`)
+	b.WriteString(`<numFmt numFmtId="165" formatCode="` + escape(w.getCurrencyFormat()) + `"/>`)
+	b.WriteString(`</numFmts>`)
+	b.WriteString(`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font>` +
+		`<font><b/><sz val="11"/><name val="Calibri"/></font></fonts>`)
+	b.WriteString(`<fills count="3"><fill><patternFill patternType="none"/></fill>` +
+		`<fill><patternFill patternType="gray125"/></fill>` +
+		`<fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill></fills>`)
+	b.WriteString(`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`)
+	b.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	b.WriteString(fmt.Sprintf(`<cellXfs count="%d">`, 2*nCellTypes+1))
+	for font := 0; font < 2; font++ {
+		for _, n := range numFmts {
+			b.WriteString(fmt.Sprintf(
+				`<xf numFmtId="%d" fontId="%d" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="%d"/>`,
+				n, font, font))
+		}
+	}
+	b.WriteString(`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>`)
+	b.WriteString(`</cellXfs>`)
+	b.WriteString(`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>`)
+	b.WriteString(`</styleSheet>`)
+	return []byte(b.String())
+}
+
+func (c *Cell) getStyle() int {
+	if c.Bold {
+		return c.Type + nCellTypes
+	}
+	return c.Type
+}
+
+// Return the width of the text of the cell.
+func (c *Cell) getWidth() int {
+	switch c.Type {
+	case CELL_STRING:
+		return utf8.RuneCountInString(c.Value)
+	case CELL_DATE:
+		return 10
+	default:
+		return len(fmt.Sprintf("%.2f", c.Number)) + 2
+	}
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (s *Sheet) marshal() []byte {
+	ncols := len(s.Header)
+	for _, r := range s.Rows {
+		if len(r) > ncols {
+			ncols = len(r)
+		}
+	}
+
+	widths := make([]int, ncols)
+	for idx, h := range s.Header {
+		widths[idx] = utf8.RuneCountInString(h)
+	}
+	for _, r := range s.Rows {
+		for idx := range r {
+			if w := r[idx].getWidth(); w > widths[idx] {
+				widths[idx] = w
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="` + nsMain + `" xmlns:r="` + nsRels + `">`)
+
+	if s.FreezeRows > 0 {
+		b.WriteString(fmt.Sprintf(`<sheetViews><sheetView workbookViewId="0">`+
+			`<pane ySplit="%d" topLeftCell="A%d" activePane="bottomLeft" state="frozen"/>`+
+			`<selection pane="bottomLeft"/></sheetView></sheetViews>`,
+			s.FreezeRows, s.FreezeRows+1))
+	}
+
+	if ncols > 0 {
+		b.WriteString(`<cols>`)
+		for idx, w := range widths {
+			if w < 8 {
+				w = 8
+			} else if w > 80 {
+				w = 80
+			}
+			b.WriteString(fmt.Sprintf(`<col min="%d" max="%d" width="%d" customWidth="1"/>`,
+				idx+1, idx+1, w+2))
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	rowIdx := 1
+	if len(s.Header) > 0 {
+		b.WriteString(fmt.Sprintf(`<row r="%d">`, rowIdx))
+		for idx, h := range s.Header {
+			b.WriteString(fmt.Sprintf(`<c r="%s%d" s="%d" t="inlineStr"><is><t>%s</t></is></c>`,
+				GetColumnName(idx), rowIdx, styleHeader, escape(h)))
+		}
+		b.WriteString(`</row>`)
+		rowIdx++
+	}
+
+	for _, r := range s.Rows {
+		b.WriteString(fmt.Sprintf(`<row r="%d">`, rowIdx))
+		for idx := range r {
+			c := &r[idx]
+			ref := fmt.Sprintf("%s%d", GetColumnName(idx), rowIdx)
+			if c.Type == CELL_STRING {
+				if c.Value == "" {
+					continue
+				}
+				b.WriteString(fmt.Sprintf(`<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+					ref, c.getStyle(), escape(c.Value)))
+			} else if c.Formula != "" {
+				b.WriteString(fmt.Sprintf(`<c r="%s" s="%d"><f>%s</f><v>%s</v></c>`,
+					ref, c.getStyle(), escape(c.Formula), formatNumber(c.Number)))
+			} else {
+				b.WriteString(fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`,
+					ref, c.getStyle(), formatNumber(c.Number)))
+			}
+		}
+		b.WriteString(`</row>`)
+		rowIdx++
+	}
+	b.WriteString(`</sheetData>`)
+	b.WriteString(`</worksheet>`)
+
+	return []byte(b.String())
+}
@@ -0,0 +1,147 @@
+/*
+Copyright (C) 2020-2026  Daniele Rondina <geaaru@macaronios.org>
+Credits goes also to Gogs authors, some code portions and re-implemented design
+are also coming from the Gogs project, which is using the go-macaron framework
+and was really source of ispiration. Kudos to them!
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"time"
+
+	. "github.com/geaaru/time-master/pkg/xlsx"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func readZipFile(data []byte, name string) string {
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	Expect(err).Should(BeNil())
+	for _, f := range z.File {
+		if f.Name == name {
+			r, err := f.Open()
+			Expect(err).Should(BeNil())
+			defer r.Close()
+			content, err := ioutil.ReadAll(r)
+			Expect(err).Should(BeNil())
+			return string(content)
+		}
+	}
+	Fail("File " + name + " not found")
+	return ""
+}
+
+var _ = Describe("XLSX Test", func() {
+
+	Context("Cells", func() {
+
+		It("Column names", func() {
+			Expect(GetColumnName(0)).To(Equal("A"))
+			Expect(GetColumnName(25)).To(Equal("Z"))
+			Expect(GetColumnName(26)).To(Equal("AA"))
+			Expect(GetColumnName(701)).To(Equal("ZZ"))
+			Expect(GetColumnName(702)).To(Equal("AAA"))
+		})
+
+		It("Dates", func() {
+			Expect(DateString("2026-09-01").Number).To(Equal(float64(46266)))
+			Expect(DateString("1900-03-01").Number).To(Equal(float64(61)))
+			Expect(Date(time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)).Number).To(Equal(46266.5))
+			Expect(DateString("2026-09")).To(Equal(String("2026-09")))
+		})
+
+		It("Hours and percent", func() {
+			Expect(Hours(int64(5400)).Number).To(Equal(1.5))
+			Expect(Percent(25).Number).To(Equal(0.25))
+		})
+	})
+
+	Context("Sheets", func() {
+
+		It("Names", func() {
+			Expect(GetSheetName("ACME [Italy]: 2026/Q3")).To(Equal("ACME _Italy__ 2026_Q3"))
+			Expect(GetSheetName("")).To(Equal("Sheet"))
+
+			wb := NewWorkbook()
+			wb.AddSheet("A very long name of a client of the company")
+			s := wb.AddSheet("A very long name of a client of the company")
+			Expect(s.Name).To(Equal("A very long name of a clien (2)"))
+			Expect(len(s.Name)).To(BeNumerically("<=", SHEET_NAME_MAXLEN))
+			Expect(wb.GetSheet("a very long name of a client of")).NotTo(BeNil())
+		})
+
+		It("Total rows", func() {
+			wb := NewWorkbook()
+			s := wb.AddSheet("Tasks", "Task", "Work", "Cost")
+			s.AddRow(String("t1"), Hours(3600), Currency(10.5))
+			s.AddRow(String("t2"), Hours(1800), Currency(4))
+			s.AddTotalRow("Total", 1, 2)
+
+			Expect(len(s.Rows)).To(Equal(3))
+			Expect(s.Rows[2][1]).To(Equal(Cell{
+				Type: CELL_HOURS, Number: 1.5, Formula: "SUM(B2:B3)", Bold: true,
+			}))
+			Expect(s.Rows[2][2].Type).To(Equal(CELL_CURRENCY))
+			Expect(s.Rows[2][2].Number).To(Equal(14.5))
+
+			// The next total row sums only the new rows.
+			s.AddRow(String("t3"), Hours(7200), Currency(1))
+			s.AddTotalRow("Total", 1)
+			Expect(s.Rows[4][1].Formula).To(Equal("SUM(B5:B5)"))
+			Expect(s.Rows[4][1].Number).To(Equal(float64(2)))
+		})
+	})
+
+	Context("Write", func() {
+
+		It("Workbook", func() {
+			wb := NewWorkbook()
+			wb.Currency = "€"
+			s := wb.AddSheet("CLIENT1", "Date", "Task", "Work")
+			s.AddRow(DateString("2026-09-01"), String("a & b"), Hours(9000))
+			s.AddTotalRow("Total", 2)
+			wb.AddSheet("CLIENT2", "Date")
+
+			var buf bytes.Buffer
+			err := wb.Write(&buf)
+			Expect(err).Should(BeNil())
+			data := buf.Bytes()
+
+			Expect(readZipFile(data, "xl/workbook.xml")).To(ContainSubstring(
+				`<sheet name="CLIENT2" sheetId="2" r:id="rId2"/>`))
+			Expect(readZipFile(data, "[Content_Types].xml")).To(ContainSubstring(
+				`/xl/worksheets/sheet2.xml`))
+			Expect(readZipFile(data, "xl/styles.xml")).To(ContainSubstring(
+				`formatCode="#,##0.00 &#34;€&#34;"`))
+
+			sheet := readZipFile(data, "xl/worksheets/sheet1.xml")
+			Expect(sheet).To(ContainSubstring(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`))
+			Expect(sheet).To(ContainSubstring(`<c r="A2" s="3"><v>46266</v></c>`))
+			Expect(sheet).To(ContainSubstring(`<t xml:space="preserve">a &amp; b</t>`))
+			Expect(sheet).To(ContainSubstring(`<c r="C2" s="4"><v>2.5</v></c>`))
+			Expect(sheet).To(ContainSubstring(`<c r="C3" s="11"><f>SUM(C2:C2)</f><v>2.5</v></c>`))
+		})
+
+		It("Without sheets", func() {
+			var buf bytes.Buffer
+			Expect(NewWorkbook().Write(&buf)).ShouldNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,14 @@
+#!/bin/bash
+# Download the dist files of Frappe Gantt embedded in the HTML gantt.
+
+set -e
+
+FRAPPE_GANTT_VERSION=${FRAPPE_GANTT_VERSION:-0.6.1}
+URL=https://unpkg.com/frappe-gantt@${FRAPPE_GANTT_VERSION}
+ROOT_DIR=$(dirname $(dirname $(realpath $0)))
+DEST_DIR=${ROOT_DIR}/pkg/gantt/assets/frappe
+
+for f in dist/frappe-gantt.min.js dist/frappe-gantt.css LICENSE ; do
+  echo "Downloading ${URL}/${f}..."
+  curl -sSfL -o ${DEST_DIR}/$(basename ${f}) ${URL}/${f}
+done